@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// completeModNames completes the first argument of a command with the names of the metadata files in the pack.
+// Only pack.toml and index.toml are read, so that completion is fast and never accesses the network.
+func completeModNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Messages printed while loading the pack would be read as completions, so send them to stderr
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	pack, err := core.LoadPack()
+	var index core.Index
+	if err == nil {
+		index, err = pack.LoadIndex()
+	}
+	os.Stdout = stdout
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveError
+	}
+	return modSlugsWithPrefix(index, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// modSlugsWithPrefix returns the sorted names of the metadata files in the index (without the extension) that start
+// with the given prefix
+func modSlugsWithPrefix(index core.Index, prefix string) []string {
+	var slugs []string
+	for p, file := range index.Files {
+		if !file.IsMetaFile() {
+			continue
+		}
+		slug := getModSlug(path.Base(p))
+		if strings.HasPrefix(slug, prefix) {
+			slugs = append(slugs, slug)
+		}
+	}
+	slices.Sort(slugs)
+	return slices.Compact(slugs)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{removeCmd, UpdateCmd, pinCmd, unpinCmd} {
+		c.ValidArgsFunction = completeModNames
+	}
+}
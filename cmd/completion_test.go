@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func TestModSlugsWithPrefix(t *testing.T) {
+	index, err := core.ParseIndex([]byte(`hash-format = "sha256"
+
+[[files]]
+file = "mods/sodium.pw.toml"
+hash = "a"
+metafile = true
+
+[[files]]
+file = "mods/sodium-extra.pw.toml"
+hash = "b"
+metafile = true
+
+[[files]]
+file = "resourcepacks/lithium.pw.toml"
+hash = "c"
+metafile = true
+
+[[files]]
+file = "config/sodium-options.json"
+hash = "d"
+`), "index.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"", []string{"lithium", "sodium", "sodium-extra"}},
+		{"sod", []string{"sodium", "sodium-extra"}},
+		{"x", nil},
+	}
+	for _, tt := range tests {
+		if slugs := modSlugsWithPrefix(index, tt.prefix); !slices.Equal(slugs, tt.expected) {
+			t.Errorf("modSlugsWithPrefix(%q) = %v, expected %v", tt.prefix, slugs, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [old index] [new index]",
+	Short: "Show the files added, removed and updated between two versions of the index",
+	Long: `Show the files added, removed and updated between two versions of the index.
+Each version can be the path to an index file, a folder containing index.toml, or a git revision (e.g. HEAD~1) to read
+this pack's index from. If the new version isn't given, the current index on disk is used.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetBool("diff.json") {
+			// Send all other output (e.g. warnings) to stderr so it doesn't corrupt the JSON
+			os.Stdout = os.Stderr
+		}
+
+		oldSource, err := resolveDiffSource(args[0])
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		var newSource diffSource
+		if len(args) > 1 {
+			newSource, err = resolveDiffSource(args[1])
+		} else {
+			newSource, err = currentDiffSource("")
+		}
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		oldIndex, err := oldSource.loadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		newIndex, err := newSource.loadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		report := diffReport(core.DiffIndex(oldIndex, newIndex), oldSource, oldIndex, newSource, newIndex)
+		if viper.GetBool("diff.json") {
+			err = report.Write("-")
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			return
+		}
+		printDiffReport(report)
+	},
+}
+
+// diffSource reads the index and metadata files of a pack from disk, or from a git revision
+type diffSource struct {
+	// ref is the git revision to read files from, or empty to read files from disk
+	ref string
+	// indexFile is the path of the index file; for git revisions it is relative to the current directory
+	indexFile string
+}
+
+// resolveDiffSource finds the index file given by an argument to the diff command; either a path to an index file,
+// a folder containing index.toml, or a git revision
+func resolveDiffSource(arg string) (diffSource, error) {
+	stat, err := os.Stat(arg)
+	if err == nil {
+		if stat.IsDir() {
+			return diffSource{indexFile: filepath.Join(arg, "index.toml")}, nil
+		}
+		return diffSource{indexFile: arg}, nil
+	}
+	return currentDiffSource(arg)
+}
+
+// currentDiffSource returns the index file of the current pack, at the given git revision (or on disk, if empty)
+func currentDiffSource(ref string) (diffSource, error) {
+	pack, err := core.LoadPack()
+	if err != nil {
+		return diffSource{}, err
+	}
+	indexFile := filepath.Join(filepath.Dir(viper.GetString("pack-file")), filepath.FromSlash(pack.Index.File))
+	if ref != "" && filepath.IsAbs(indexFile) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return diffSource{}, err
+		}
+		indexFile, err = filepath.Rel(wd, indexFile)
+		if err != nil {
+			return diffSource{}, err
+		}
+	}
+	return diffSource{ref: ref, indexFile: indexFile}, nil
+}
+
+func (s diffSource) String() string {
+	if s.ref == "" {
+		return s.indexFile
+	}
+	return s.ref + ":" + filepath.ToSlash(s.indexFile)
+}
+
+func (s diffSource) readFile(path string) ([]byte, error) {
+	if s.ref == "" {
+		return os.ReadFile(path)
+	}
+	// Paths starting with ./ are relative to the current directory rather than the repository root
+	out, err := exec.Command("git", "show", s.ref+":./"+filepath.ToSlash(filepath.Clean(path))).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("failed to read %s at %s: %s", path, s.ref, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, s.ref, err)
+	}
+	return out, nil
+}
+
+func (s diffSource) loadIndex() (core.Index, error) {
+	data, err := s.readFile(s.indexFile)
+	if err != nil {
+		return core.Index{}, err
+	}
+	index, err := core.ParseIndex(data, s.indexFile)
+	if err != nil {
+		return core.Index{}, fmt.Errorf("failed to parse %s: %w", s, err)
+	}
+	return index, nil
+}
+
+func (s diffSource) loadMod(index core.Index, relPath string) (core.Mod, error) {
+	path := index.ResolveIndexPath(relPath)
+	data, err := s.readFile(path)
+	if err != nil {
+		return core.Mod{}, err
+	}
+	return core.ParseMod(data, path)
+}
+
+// diffReport creates a report of the changes between two versions of an index, reading metadata files for the names
+// and versions of changed mods
+func diffReport(changes []core.IndexChange, oldSource diffSource, oldIndex core.Index, newSource diffSource, newIndex core.Index) cmdshared.ChangeReport {
+	var report cmdshared.ChangeReport
+	for _, change := range changes {
+		fileChange := cmdshared.FileChange{
+			Path:          change.Path,
+			HashFormat:    change.NewHashFormat,
+			Hash:          change.NewHash,
+			OldHashFormat: change.OldHashFormat,
+			OldHash:       change.OldHash,
+		}
+		if change.MetaFile {
+			// Report the hashes of the files that the metadata files refer to, rather than the metadata files
+			fileChange.HashFormat, fileChange.Hash, fileChange.OldHashFormat, fileChange.OldHash = "", "", "", ""
+			if change.Type != core.FileAdded {
+				oldMod, err := oldSource.loadMod(oldIndex, change.Path)
+				if err != nil {
+					fmt.Printf("Warning: failed to read old version of %s: %v\n", change.Path, err)
+				} else {
+					fileChange.Name = oldMod.Name
+					fileChange.OldVersion = oldMod.FileName
+					fileChange.OldHashFormat, fileChange.OldHash = oldMod.Download.HashFormat, oldMod.Download.Hash
+				}
+			}
+			if change.Type != core.FileRemoved {
+				newMod, err := newSource.loadMod(newIndex, change.Path)
+				if err != nil {
+					fmt.Printf("Warning: failed to read new version of %s: %v\n", change.Path, err)
+				} else {
+					fileChange.Name = newMod.Name
+					fileChange.NewVersion = newMod.FileName
+					fileChange.HashFormat, fileChange.Hash = newMod.Download.HashFormat, newMod.Download.Hash
+				}
+			}
+		}
+
+		switch change.Type {
+		case core.FileAdded:
+			report.Added = append(report.Added, fileChange)
+		case core.FileRemoved:
+			report.Removed = append(report.Removed, fileChange)
+		case core.FileUpdated:
+			report.Modified = append(report.Modified, fileChange)
+		}
+	}
+	return report
+}
+
+// printDiffReport prints the changes in a report, using mod names and versions where they are known
+func printDiffReport(report cmdshared.ChangeReport) {
+	if len(report.Added)+len(report.Removed)+len(report.Modified) == 0 {
+		fmt.Println("No files changed")
+		return
+	}
+	describe := func(change cmdshared.FileChange) string {
+		if change.Name == "" {
+			return change.Path
+		}
+		return fmt.Sprintf("%s (%s)", change.Name, change.Path)
+	}
+	sections := []struct {
+		title   string
+		changes []cmdshared.FileChange
+	}{
+		{"Added", report.Added},
+		{"Removed", report.Removed},
+		{"Updated", report.Modified},
+	}
+	for _, section := range sections {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Printf("%s:\n", section.title)
+		for _, change := range section.changes {
+			switch {
+			case change.OldVersion != "" && change.NewVersion != "" && change.OldVersion != change.NewVersion:
+				fmt.Printf("  %s: %s -> %s\n", describe(change), change.OldVersion, change.NewVersion)
+			case change.NewVersion != "":
+				fmt.Printf("  %s: %s\n", describe(change), change.NewVersion)
+			case change.OldVersion != "":
+				fmt.Printf("  %s: %s\n", describe(change), change.OldVersion)
+			case change.OldHash != "" && change.Hash != "":
+				fmt.Printf("  %s: %s hash %s -> %s\n", describe(change), change.HashFormat, shortHash(change.OldHash), shortHash(change.Hash))
+			default:
+				fmt.Printf("  %s\n", describe(change))
+			}
+		}
+	}
+}
+
+// shortHash shortens a hash for display, like a short git commit hash
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().Bool("json", false, "Print the changes as JSON, in the same format as --report")
+	_ = viper.BindPFlag("diff.json", diffCmd.Flags().Lookup("json"))
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// bootstrapReleasesAPI is the GitHub API URL of the releases of packwiz-installer-bootstrap, which installs and
+// updates a pack from its URL when an instance is launched
+var bootstrapReleasesAPI = "https://api.github.com/repos/packwiz/packwiz-installer-bootstrap/releases/"
+
+const bootstrapJarName = "packwiz-installer-bootstrap.jar"
+
+// multiMCComponentUIDs maps the components used in pack.toml to the component UIDs used by MultiMC and Prism Launcher
+var multiMCComponentUIDs = map[string]string{
+	"minecraft":  "net.minecraft",
+	"fabric":     "net.fabricmc.fabric-loader",
+	"quilt":      "org.quiltmc.quilt-loader",
+	"forge":      "net.minecraftforge",
+	"neoforge":   "net.neoforged",
+	"liteloader": "com.mumfrey.liteloader",
+}
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the modpack for a launcher",
+}
+
+// exportMultiMCCmd represents the export multimc command
+var exportMultiMCCmd = &cobra.Command{
+	Use:     "multimc",
+	Short:   "Export the modpack as a MultiMC/Prism Launcher instance zip",
+	Aliases: []string{"prism"},
+	Long: `Export the modpack as a zip that can be imported as a MultiMC or Prism Launcher instance.
+Client mods and other files are included in the instance. With --pack-url, packwiz-installer-bootstrap is also included
+and run before the game is launched, so the instance is updated when the pack served from that URL changes.
+The latest release of packwiz-installer-bootstrap is used, unless a release is given with --bootstrap-version. The
+downloaded jar is checked against the SHA-256 hash published by GitHub, or the hash given with --bootstrap-hash; use
+--include-bootstrap=false to leave it out and add it to the instance yourself.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		packURL := viper.GetString("export.multimc.pack-url")
+		if packURL != "" && !strings.HasPrefix(packURL, "http://") && !strings.HasPrefix(packURL, "https://") {
+			fmt.Printf("Invalid pack URL %q, must be a http or https URL to pack.toml\n", packURL)
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+		includeBootstrap := viper.GetBool("export.multimc.include-bootstrap")
+		if packURL == "" && cmd.Flags().Changed("include-bootstrap") && includeBootstrap {
+			fmt.Println("--include-bootstrap requires --pack-url, the URL of the pack.toml file that the instance is updated from")
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		overrideRules, err := cmdshared.GetOverrideRules(pack, core.ClientSide, "export.multimc")
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		// Do a refresh to ensure files are up to date
+		err = index.Refresh()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = index.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.UpdateIndexHash()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		components, err := getMultiMCComponents(pack)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		fmt.Println("Reading external files...")
+		mods, err := index.LoadAllMods()
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		i := 0
+		// Only client mods are needed in a launcher instance
+		for _, mod := range mods {
+			if mod.Side == core.ClientSide || mod.Side == core.EmptySide || mod.Side == core.UniversalSide {
+				mods[i] = mod
+				i++
+			}
+		}
+		mods = mods[:i]
+
+		fileName := viper.GetString("export.multimc.output")
+		if fileName == "" {
+			fileName = pack.GetPackName() + "-multimc.zip"
+		}
+		expFile, err := os.Create(fileName)
+		if err != nil {
+			fmt.Printf("Failed to create zip: %s\n", err.Error())
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		exp := zip.NewWriter(expFile)
+
+		name := pack.Name
+		if name == "" {
+			name = pack.GetPackName()
+		}
+		err = writeMultiMCFiles(exp, name, components, packURL)
+		if err != nil {
+			_ = exp.Close()
+			_ = expFile.Close()
+			fmt.Println("Error creating instance files: " + err.Error())
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+
+		if len(mods) > 0 {
+			fmt.Printf("Retrieving %v external files to store in the instance...\n", len(mods))
+			fmt.Println("Disclaimer: you are responsible for ensuring you comply with ALL the licenses, or obtain appropriate permissions, for the files \"added to zip\" below")
+			fmt.Println()
+
+			session, err := core.CreateDownloadSession(mods, []string{})
+			if err != nil {
+				fmt.Printf("Error retrieving external files: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+
+			if err := cmdshared.ListManualDownloads(session); err != nil {
+				cmdshared.ExitWithError(err)
+			}
+
+			for dl := range session.StartDownloadsContext(cmd.Context(), cmdshared.DownloadProgressBar) {
+				_ = cmdshared.AddToZip(dl, exp, ".minecraft", &index)
+			}
+
+			err = session.SaveIndex()
+			if err != nil {
+				fmt.Printf("Error saving cache index: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+		}
+
+		cmdshared.AddNonMetafileFiles(&index, exp, ".minecraft", overrideRules)
+
+		if packURL != "" && includeBootstrap {
+			fmt.Println("Downloading packwiz-installer-bootstrap...")
+			err = addBootstrapJar(cmd.Context(), exp, viper.GetString("export.multimc.bootstrap-version"), viper.GetString("export.multimc.bootstrap-hash"))
+			if err != nil {
+				_ = exp.Close()
+				_ = expFile.Close()
+				fmt.Println("Error adding packwiz-installer-bootstrap: " + err.Error())
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+		}
+
+		err = exp.Close()
+		if err != nil {
+			fmt.Println("Error writing export file: " + err.Error())
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		err = expFile.Close()
+		if err != nil {
+			fmt.Println("Error writing export file: " + err.Error())
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+
+		fmt.Println("Modpack exported to " + fileName)
+	},
+}
+
+type multiMCComponent struct {
+	UID       string `json:"uid"`
+	Version   string `json:"version"`
+	Important bool   `json:"important,omitempty"`
+}
+
+// getMultiMCComponents returns the components of a MultiMC instance for the Minecraft and mod loader versions of a pack
+func getMultiMCComponents(pack core.Pack) ([]multiMCComponent, error) {
+	mcVersion, err := pack.GetMCVersion()
+	if err != nil {
+		return nil, err
+	}
+	components := []multiMCComponent{{UID: multiMCComponentUIDs["minecraft"], Version: mcVersion, Important: true}}
+	// Dependencies of components (e.g. Fabric intermediary mappings) are resolved by the launcher
+	for _, loader := range pack.GetLoaders() {
+		version := cmdshared.GetRawLoaderVersion(loader, mcVersion, pack.Versions[loader])
+		components = append(components, multiMCComponent{UID: multiMCComponentUIDs[loader], Version: version})
+	}
+	if version, ok := pack.Versions["liteloader"]; ok {
+		components = append(components, multiMCComponent{UID: multiMCComponentUIDs["liteloader"], Version: version})
+	}
+	return components, nil
+}
+
+// writeMultiMCFiles writes the instance configuration and component list of a MultiMC instance to a zip
+func writeMultiMCFiles(exp *zip.Writer, name string, components []multiMCComponent, packURL string) error {
+	cfgFile, err := exp.Create("instance.cfg")
+	if err != nil {
+		return err
+	}
+	cfg := "InstanceType=OneSix\nname=" + strings.ReplaceAll(name, "\n", " ") + "\n"
+	if packURL != "" {
+		cfg += "OverrideCommands=true\nPreLaunchCommand=\"$INST_JAVA\" -jar packwiz-installer-bootstrap.jar " + packURL + "\n"
+	}
+	_, err = io.WriteString(cfgFile, cfg)
+	if err != nil {
+		return err
+	}
+
+	packFile, err := exp.Create("mmc-pack.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(packFile)
+	enc.SetIndent("", "    ")
+	return enc.Encode(struct {
+		Components    []multiMCComponent `json:"components"`
+		FormatVersion int                `json:"formatVersion"`
+	}{components, 1})
+}
+
+type bootstrapAsset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	// Digest is the hash of the asset calculated by GitHub, such as sha256:<hex>; it isn't available for older releases
+	Digest string `json:"digest"`
+}
+
+// getBootstrapAsset returns the packwiz-installer-bootstrap jar of the given release, or the latest release if version
+// is empty
+func getBootstrapAsset(ctx context.Context, version string) (bootstrapAsset, error) {
+	releaseURL := bootstrapReleasesAPI + "latest"
+	if version != "" {
+		releaseURL = bootstrapReleasesAPI + "tags/" + url.PathEscape(version)
+	}
+	resp, err := core.GetWithProgress(ctx, nil, releaseURL, "application/vnd.github+json", nil)
+	if err != nil {
+		return bootstrapAsset{}, fmt.Errorf("failed to get release: %w", err)
+	}
+	defer resp.Body.Close()
+	var release struct {
+		TagName string           `json:"tag_name"`
+		Assets  []bootstrapAsset `json:"assets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return bootstrapAsset{}, fmt.Errorf("failed to parse release: %w", err)
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == bootstrapJarName {
+			return asset, nil
+		}
+	}
+	return bootstrapAsset{}, fmt.Errorf("release %s doesn't contain %s", release.TagName, bootstrapJarName)
+}
+
+// downloadBootstrapJar downloads the packwiz-installer-bootstrap jar, checking that its SHA-256 hash matches
+// expectedHash, or the hash published by GitHub if expectedHash is empty
+func downloadBootstrapJar(ctx context.Context, asset bootstrapAsset, expectedHash string) ([]byte, error) {
+	if expectedHash == "" {
+		var ok bool
+		expectedHash, ok = strings.CutPrefix(asset.Digest, "sha256:")
+		if !ok {
+			return nil, errors.New("GitHub doesn't publish a hash for this release of packwiz-installer-bootstrap, so it can't be verified; use --bootstrap-hash to give its SHA-256 hash")
+		}
+	}
+	resp, err := core.GetWithProgress(ctx, nil, asset.DownloadURL, "application/java-archive", cmdshared.NewProgressBar(bootstrapJarName))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+	if actualHash := hex.EncodeToString(hash[:]); !strings.EqualFold(actualHash, expectedHash) {
+		return nil, fmt.Errorf("hash of %s (%s) doesn't match the expected hash (%s)", bootstrapJarName, actualHash, expectedHash)
+	}
+	return data, nil
+}
+
+// addBootstrapJar downloads and verifies packwiz-installer-bootstrap, adding it to the .minecraft folder of a zip
+func addBootstrapJar(ctx context.Context, exp *zip.Writer, version string, expectedHash string) error {
+	asset, err := getBootstrapAsset(ctx, version)
+	if err != nil {
+		return err
+	}
+	// The jar is verified before it is added, as files can't be removed from the zip afterwards
+	data, err := downloadBootstrapJar(ctx, asset, expectedHash)
+	if err != nil {
+		return err
+	}
+	jarFile, err := exp.Create(".minecraft/" + bootstrapJarName)
+	if err != nil {
+		return err
+	}
+	_, err = jarFile.Write(data)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportMultiMCCmd)
+
+	exportMultiMCCmd.Flags().StringP("output", "o", "", "The file to export the instance to")
+	_ = viper.BindPFlag("export.multimc.output", exportMultiMCCmd.Flags().Lookup("output"))
+	exportMultiMCCmd.Flags().String("pack-url", "", "The URL of the pack.toml file that the instance is updated from with packwiz-installer-bootstrap")
+	_ = viper.BindPFlag("export.multimc.pack-url", exportMultiMCCmd.Flags().Lookup("pack-url"))
+	exportMultiMCCmd.Flags().Bool("include-bootstrap", true, "Include packwiz-installer-bootstrap in the instance when --pack-url is set")
+	_ = viper.BindPFlag("export.multimc.include-bootstrap", exportMultiMCCmd.Flags().Lookup("include-bootstrap"))
+	exportMultiMCCmd.Flags().String("bootstrap-version", "", "The release of packwiz-installer-bootstrap to include, such as v0.0.3 (defaults to the latest release)")
+	_ = viper.BindPFlag("export.multimc.bootstrap-version", exportMultiMCCmd.Flags().Lookup("bootstrap-version"))
+	exportMultiMCCmd.Flags().String("bootstrap-hash", "", "The SHA-256 hash that the packwiz-installer-bootstrap jar must have (defaults to the hash published by GitHub)")
+	_ = viper.BindPFlag("export.multimc.bootstrap-hash", exportMultiMCCmd.Flags().Lookup("bootstrap-hash"))
+	cmdshared.AddOverrideFlags(exportMultiMCCmd, "export.multimc")
+}
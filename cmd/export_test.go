@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func TestMultiMCComponents(t *testing.T) {
+	pack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "forge": "1.20.1-47.2.0"}}
+	components, err := getMultiMCComponents(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []multiMCComponent{
+		{UID: "net.minecraft", Version: "1.20.1", Important: true},
+		{UID: "net.minecraftforge", Version: "47.2.0"},
+	}
+	if !slices.Equal(components, expected) {
+		t.Errorf("Expected components %v, got %v", expected, components)
+	}
+
+	if _, err := getMultiMCComponents(core.Pack{Versions: map[string]string{"fabric": "0.15.0"}}); err == nil {
+		t.Error("Expected an error for a pack without a Minecraft version")
+	}
+}
+
+func TestWriteMultiMCFiles(t *testing.T) {
+	buf := new(bytes.Buffer)
+	exp := zip.NewWriter(buf)
+	components := []multiMCComponent{{UID: "net.minecraft", Version: "1.20.1", Important: true}}
+	if err := writeMultiMCFiles(exp, "Test Pack", components, "https://example.com/pack.toml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := exp.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(data)
+	}
+
+	cfg := files["instance.cfg"]
+	if !strings.Contains(cfg, "name=Test Pack\n") ||
+		!strings.Contains(cfg, "PreLaunchCommand=\"$INST_JAVA\" -jar packwiz-installer-bootstrap.jar https://example.com/pack.toml\n") {
+		t.Errorf("Unexpected instance.cfg: %s", cfg)
+	}
+	var mmcPack struct {
+		Components    []multiMCComponent `json:"components"`
+		FormatVersion int                `json:"formatVersion"`
+	}
+	if err := json.Unmarshal([]byte(files["mmc-pack.json"]), &mmcPack); err != nil {
+		t.Fatalf("Failed to parse mmc-pack.json: %v", err)
+	}
+	if mmcPack.FormatVersion != 1 || !slices.Equal(mmcPack.Components, components) {
+		t.Errorf("Unexpected mmc-pack.json: %s", files["mmc-pack.json"])
+	}
+}
+
+func TestAddBootstrapJar(t *testing.T) {
+	jar := []byte("bootstrap jar")
+	hash := sha256.Sum256(jar)
+	jarHash := hex.EncodeToString(hash[:])
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/releases/latest", "/releases/tags/v1.0.0":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"tag_name": "v1.0.0",
+				"assets": []bootstrapAsset{
+					{Name: "sources.jar", DownloadURL: server.URL + "/sources.jar"},
+					{Name: bootstrapJarName, DownloadURL: server.URL + "/bootstrap.jar", Digest: "sha256:" + jarHash},
+				},
+			})
+		case "/releases/tags/v0.0.1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"tag_name": "v0.0.1",
+				"assets":   []bootstrapAsset{{Name: bootstrapJarName, DownloadURL: server.URL + "/bootstrap.jar"}},
+			})
+		case "/bootstrap.jar":
+			_, _ = w.Write(jar)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	oldAPI := bootstrapReleasesAPI
+	bootstrapReleasesAPI = server.URL + "/releases/"
+	defer func() { bootstrapReleasesAPI = oldAPI }()
+
+	addJar := func(version string, expectedHash string) (map[string]string, error) {
+		buf := new(bytes.Buffer)
+		exp := zip.NewWriter(buf)
+		if err := addBootstrapJar(context.Background(), exp, version, expectedHash); err != nil {
+			return nil, err
+		}
+		if err := exp.Close(); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := make(map[string]string)
+		for _, f := range zr.File {
+			r, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[f.Name] = string(data)
+		}
+		return files, nil
+	}
+
+	for _, version := range []string{"", "v1.0.0"} {
+		files, err := addJar(version, "")
+		if err != nil {
+			t.Fatalf("Failed to add bootstrap jar for version %q: %v", version, err)
+		}
+		if files[".minecraft/"+bootstrapJarName] != string(jar) {
+			t.Errorf("Unexpected files for version %q: %v", version, files)
+		}
+	}
+	if _, err := addJar("", strings.Repeat("0", 64)); err == nil || !strings.Contains(err.Error(), "doesn't match") {
+		t.Errorf("Expected a hash mismatch error, got %v", err)
+	}
+	if _, err := addJar("v0.0.1", ""); err == nil || !strings.Contains(err.Error(), "--bootstrap-hash") {
+		t.Errorf("Expected an error for a release without a published hash, got %v", err)
+	}
+	if _, err := addJar("v0.0.1", strings.ToUpper(jarHash)); err != nil {
+		t.Errorf("Expected the given hash to be used for a release without a published hash, got %v", err)
+	}
+	if _, err := addJar("v9.9.9", ""); err == nil {
+		t.Error("Expected an error for a missing release")
+	}
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// URLs that server loader installers are downloaded from
+var (
+	fabricMetaAPI    = "https://meta.fabricmc.net/v2/"
+	quiltMavenURL    = "https://maven.quiltmc.org/repository/release/"
+	forgeMavenURL    = "https://maven.minecraftforge.net/"
+	neoforgeMavenURL = "https://maven.neoforged.net/releases/"
+)
+
+// exportServerZipCmd represents the export server-zip command
+var exportServerZipCmd = &cobra.Command{
+	Use:   "server-zip",
+	Short: "Export the modpack as a zip of server files, with the server mods already downloaded",
+	Long: `Export the modpack as a zip that can be extracted into a dedicated server folder.
+Files for the server (with the server or both side) are downloaded (or read from the cache) and placed at their paths
+in the pack, such as mods/; client-only files are left out. Other files in the pack, such as configs, are included
+as they are. With --include-loader, the server installer or launcher of the pack's mod loader is also added to the
+root of the zip, if it can be found.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		overrideRules, err := cmdshared.GetOverrideRules(pack, core.ServerSide, "export.server-zip")
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		// Do a refresh to ensure files are up to date
+		err = index.Refresh()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = index.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.UpdateIndexHash()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		fmt.Println("Reading external files...")
+		mods, err := index.LoadAllMods()
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		mods = filterServerMods(mods)
+
+		fileName := viper.GetString("export.server-zip.output")
+		if fileName == "" {
+			fileName = pack.GetPackName() + "-server.zip"
+		}
+		expFile, err := os.Create(fileName)
+		if err != nil {
+			fmt.Printf("Failed to create zip: %s\n", err.Error())
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		exp := zip.NewWriter(expFile)
+
+		failed := false
+		if len(mods) > 0 {
+			fmt.Printf("Retrieving %v external files to store in the zip...\n", len(mods))
+			fmt.Println("Disclaimer: you are responsible for ensuring you comply with ALL the licenses, or obtain appropriate permissions, for the files \"added to zip\" below")
+			fmt.Println()
+
+			session, err := core.CreateDownloadSession(mods, []string{})
+			if err != nil {
+				fmt.Printf("Error retrieving external files: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+
+			if err := cmdshared.ListManualDownloads(session); err != nil {
+				cmdshared.ExitWithError(err)
+			}
+
+			for dl := range session.StartDownloadsContext(cmd.Context(), cmdshared.DownloadProgressBar) {
+				if !cmdshared.AddToZip(dl, exp, "", &index) {
+					failed = true
+				}
+			}
+
+			err = session.SaveIndex()
+			if err != nil {
+				fmt.Printf("Error saving cache index: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+		}
+
+		cmdshared.AddNonMetafileFiles(&index, exp, "", overrideRules)
+
+		if viper.GetBool("export.server-zip.include-loader") {
+			err = addServerLoaderInstaller(cmd.Context(), exp, pack)
+			if err != nil {
+				fmt.Printf("Warning: the mod loader wasn't added to the zip: %v\n", err)
+			}
+		}
+
+		err = exp.Close()
+		if err != nil {
+			fmt.Println("Error writing export file: " + err.Error())
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		err = expFile.Close()
+		if err != nil {
+			fmt.Println("Error writing export file: " + err.Error())
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+
+		fmt.Println("Modpack exported to " + fileName)
+		if failed {
+			fmt.Println("Some files couldn't be added to the zip; the server may not work without them")
+			cmdshared.Exit(cmdshared.ExitGeneric)
+		}
+	},
+}
+
+// filterServerMods returns the mods that are used on the server
+func filterServerMods(mods []*core.Mod) []*core.Mod {
+	var serverMods []*core.Mod
+	for _, mod := range mods {
+		if mod.Side == core.ServerSide || mod.Side == core.EmptySide || mod.Side == core.UniversalSide {
+			serverMods = append(serverMods, mod)
+		}
+	}
+	return serverMods
+}
+
+// serverLoaderInstaller is a jar that installs or launches the server of a mod loader
+type serverLoaderInstaller struct {
+	FileName string
+	URL      string
+	// HashURL is the URL of the SHA-1 hash published next to the jar, or empty if there isn't one
+	HashURL string
+}
+
+// getServerLoaderInstaller returns the server installer (or launcher, for Fabric) of the mod loader used by the pack
+func getServerLoaderInstaller(ctx context.Context, pack core.Pack) (serverLoaderInstaller, error) {
+	mcVersion, err := pack.GetMCVersion()
+	if err != nil {
+		return serverLoaderInstaller{}, err
+	}
+	loaders := pack.GetLoaders()
+	if len(loaders) == 0 {
+		return serverLoaderInstaller{}, errors.New("the pack doesn't use a mod loader")
+	}
+	loader := loaders[0]
+	version := cmdshared.GetRawLoaderVersion(loader, mcVersion, pack.Versions[loader])
+
+	// mavenJar returns an installer from a Maven repository, which publishes SHA-1 hashes next to each file
+	mavenJar := func(repo string, group string, artifact string, version string) serverLoaderInstaller {
+		fileName := artifact + "-" + version + "-installer.jar"
+		url := repo + group + "/" + artifact + "/" + version + "/" + fileName
+		return serverLoaderInstaller{FileName: fileName, URL: url, HashURL: url + ".sha1"}
+	}
+	switch loader {
+	case "fabric":
+		installerVersion, err := getFabricInstallerVersion(ctx)
+		if err != nil {
+			return serverLoaderInstaller{}, err
+		}
+		return serverLoaderInstaller{
+			FileName: "fabric-server-mc." + mcVersion + "-loader." + version + "-launcher." + installerVersion + ".jar",
+			URL:      fabricMetaAPI + "versions/loader/" + mcVersion + "/" + version + "/" + installerVersion + "/server/jar",
+		}, nil
+	case "quilt":
+		_, installerVersion, err := core.FetchMavenVersionList(quiltMavenURL + "org/quiltmc/quilt-installer/maven-metadata.xml")(mcVersion)
+		if err != nil {
+			return serverLoaderInstaller{}, fmt.Errorf("failed to get the latest Quilt installer: %w", err)
+		}
+		return mavenJar(quiltMavenURL, "org/quiltmc", "quilt-installer", installerVersion), nil
+	case "forge":
+		return mavenJar(forgeMavenURL, "net/minecraftforge", "forge", mcVersion+"-"+version), nil
+	case "neoforge":
+		if mcVersion == "1.20.1" {
+			// NeoForge for 1.20.1 is published as Forge
+			return mavenJar(neoforgeMavenURL, "net/neoforged", "forge", mcVersion+"-"+version), nil
+		}
+		return mavenJar(neoforgeMavenURL, "net/neoforged", "neoforge", version), nil
+	default:
+		return serverLoaderInstaller{}, fmt.Errorf("there is no known server installer for %s", loader)
+	}
+}
+
+// getFabricInstallerVersion returns the latest stable version of the Fabric installer
+func getFabricInstallerVersion(ctx context.Context) (string, error) {
+	resp, err := core.GetWithProgress(ctx, nil, fabricMetaAPI+"versions/installer", "application/json", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the latest Fabric installer: %w", err)
+	}
+	defer resp.Body.Close()
+	var versions []struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return "", fmt.Errorf("failed to parse Fabric installer versions: %w", err)
+	}
+	for _, v := range versions {
+		if v.Stable {
+			return v.Version, nil
+		}
+	}
+	return "", errors.New("no stable Fabric installer found")
+}
+
+// addServerLoaderInstaller downloads the server installer of the pack's mod loader, adding it to the root of a zip
+func addServerLoaderInstaller(ctx context.Context, exp *zip.Writer, pack core.Pack) error {
+	installer, err := getServerLoaderInstaller(ctx, pack)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Downloading %s...\n", installer.FileName)
+	// The jar is verified before it is added, as files can't be removed from the zip afterwards
+	data, err := downloadServerLoaderInstaller(ctx, installer)
+	if err != nil {
+		return err
+	}
+	jarFile, err := exp.Create(installer.FileName)
+	if err != nil {
+		return err
+	}
+	_, err = jarFile.Write(data)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s added to zip\n", installer.FileName)
+	return nil
+}
+
+// downloadServerLoaderInstaller downloads an installer, checking it against its published hash if there is one
+func downloadServerLoaderInstaller(ctx context.Context, installer serverLoaderInstaller) ([]byte, error) {
+	resp, err := core.GetWithProgress(ctx, nil, installer.URL, "application/java-archive", cmdshared.NewProgressBar(installer.FileName))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if installer.HashURL == "" {
+		return data, nil
+	}
+
+	hashResp, err := core.GetWithProgress(ctx, nil, installer.HashURL, "text/plain", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the hash of %s: %w", installer.FileName, err)
+	}
+	defer hashResp.Body.Close()
+	hashData, err := io.ReadAll(hashResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	expectedHash := strings.TrimSpace(string(hashData))
+	hash := sha1.Sum(data)
+	if actualHash := hex.EncodeToString(hash[:]); !strings.EqualFold(actualHash, expectedHash) {
+		return nil, fmt.Errorf("hash of %s (%s) doesn't match the expected hash (%s)", installer.FileName, actualHash, expectedHash)
+	}
+	return data, nil
+}
+
+func init() {
+	exportCmd.AddCommand(exportServerZipCmd)
+
+	exportServerZipCmd.Flags().StringP("output", "o", "", "The file to export the server files to")
+	_ = viper.BindPFlag("export.server-zip.output", exportServerZipCmd.Flags().Lookup("output"))
+	exportServerZipCmd.Flags().Bool("include-loader", false, "Also add the server installer of the pack's mod loader (the server launcher for Fabric)")
+	_ = viper.BindPFlag("export.server-zip.include-loader", exportServerZipCmd.Flags().Lookup("include-loader"))
+	cmdshared.AddOverrideFlags(exportServerZipCmd, "export.server-zip")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func TestFilterServerMods(t *testing.T) {
+	mods := []*core.Mod{
+		{Name: "Client", Side: core.ClientSide},
+		{Name: "Server", Side: core.ServerSide},
+		{Name: "Both", Side: core.UniversalSide},
+		{Name: "Unset", Side: core.EmptySide},
+	}
+	var names []string
+	for _, mod := range filterServerMods(mods) {
+		names = append(names, mod.Name)
+	}
+	if strings.Join(names, ",") != "Server,Both,Unset" {
+		t.Errorf("Expected client-only mods to be left out, got %v", names)
+	}
+}
+
+func TestServerLoaderInstaller(t *testing.T) {
+	jar := []byte("installer jar")
+	hash := sha1.Sum(jar)
+	jarHash := hex.EncodeToString(hash[:])
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fabric/versions/installer":
+			_, _ = w.Write([]byte(`[{"version": "1.1.0", "stable": false}, {"version": "1.0.1", "stable": true}]`))
+		case "/fabric/versions/loader/1.20.1/0.15.0/1.0.1/server/jar",
+			"/neoforge/net/neoforged/neoforge/20.4.80/neoforge-20.4.80-installer.jar",
+			"/neoforge/net/neoforged/forge/1.20.1-47.1.0/forge-1.20.1-47.1.0-installer.jar":
+			_, _ = w.Write(jar)
+		case "/neoforge/net/neoforged/neoforge/20.4.80/neoforge-20.4.80-installer.jar.sha1":
+			_, _ = w.Write([]byte(jarHash + "\n"))
+		case "/neoforge/net/neoforged/forge/1.20.1-47.1.0/forge-1.20.1-47.1.0-installer.jar.sha1":
+			_, _ = w.Write([]byte(strings.Repeat("0", 40)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	oldFabric, oldNeoForge := fabricMetaAPI, neoforgeMavenURL
+	fabricMetaAPI, neoforgeMavenURL = server.URL+"/fabric/", server.URL+"/neoforge/"
+	defer func() { fabricMetaAPI, neoforgeMavenURL = oldFabric, oldNeoForge }()
+
+	addInstaller := func(versions map[string]string) (map[string]string, error) {
+		t.Helper()
+		buf := new(bytes.Buffer)
+		exp := zip.NewWriter(buf)
+		if err := addServerLoaderInstaller(context.Background(), exp, core.Pack{Versions: versions}); err != nil {
+			return nil, err
+		}
+		if err := exp.Close(); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := make(map[string]string)
+		for _, f := range zr.File {
+			files[f.Name] = f.Name
+		}
+		return files, nil
+	}
+
+	files, err := addInstaller(map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"})
+	if err != nil || files["fabric-server-mc.1.20.1-loader.0.15.0-launcher.1.0.1.jar"] == "" {
+		t.Errorf("Expected the Fabric server launcher to be added, got %v (%v)", files, err)
+	}
+	files, err = addInstaller(map[string]string{"minecraft": "1.20.4", "neoforge": "20.4.80"})
+	if err != nil || files["neoforge-20.4.80-installer.jar"] == "" {
+		t.Errorf("Expected the NeoForge installer to be added, got %v (%v)", files, err)
+	}
+	if _, err := addInstaller(map[string]string{"minecraft": "1.20.1", "neoforge": "1.20.1-47.1.0"}); err == nil || !strings.Contains(err.Error(), "doesn't match") {
+		t.Errorf("Expected a hash mismatch error, got %v", err)
+	}
+	if _, err := addInstaller(map[string]string{"minecraft": "1.20.1"}); err == nil {
+		t.Error("Expected an error for a pack without a mod loader")
+	}
+
+	installer, err := getServerLoaderInstaller(context.Background(), core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "forge": "47.2.0"}})
+	if err != nil || installer.URL != forgeMavenURL+"net/minecraftforge/forge/1.20.1-47.2.0/forge-1.20.1-47.2.0-installer.jar" {
+		t.Errorf("Unexpected Forge installer %v (%v)", installer, err)
+	}
+}
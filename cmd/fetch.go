@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// fetchCmd represents the fetch command
+var fetchCmd = &cobra.Command{
+	Use:     "fetch",
+	Aliases: []string{"download"},
+	Short:   "Download every file referenced by the index into the download cache, so the pack can be exported offline",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs := viper.GetInt("fetch.jobs")
+		if jobs < 1 {
+			fmt.Println("--jobs must be at least 1")
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		mods, err := index.LoadAllMods()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		session, err := core.CreateDownloadSession(mods, []string{})
+		if err != nil {
+			fmt.Printf("Error retrieving external files: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		if err := cmdshared.ListManualDownloads(session); err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		// Progress bars of several files would overwrite each other, so they are only shown when downloading one at a time
+		var progress func(mod *core.Mod) core.ProgressFunc
+		if jobs == 1 {
+			progress = cmdshared.DownloadProgressBar
+		}
+
+		cached, downloaded, failed := 0, 0, 0
+		done := 0
+		for dl := range session.StartDownloadsParallel(cmd.Context(), progress, jobs) {
+			done++
+			if dl.Error != nil {
+				failed++
+				fmt.Printf("[%d/%d] Failed to download %s: %v\n", done, len(mods), dl.Mod.Name, dl.Error)
+				continue
+			}
+			_ = dl.File.Close()
+			for _, warning := range dl.Warnings {
+				fmt.Printf("Warning for %s: %v\n", dl.Mod.Name, warning)
+			}
+			if dl.FromCache {
+				cached++
+			} else {
+				downloaded++
+				fmt.Printf("[%d/%d] Downloaded %s\n", done, len(mods), dl.Mod.Name)
+			}
+		}
+
+		err = session.SaveIndex()
+		if err != nil {
+			fmt.Printf("Error saving cache index: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+
+		fmt.Printf("%d files downloaded, %d already cached, %d failed\n", downloaded, cached, failed)
+		if failed > 0 {
+			cmdshared.Exit(cmdshared.ExitGeneric)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fetchCmd)
+
+	fetchCmd.Flags().IntP("jobs", "j", 4, "The number of files to download at once")
+	_ = viper.BindPFlag("fetch.jobs", fetchCmd.Flags().Lookup("jobs"))
+}
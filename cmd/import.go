@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import [file or URL]",
+	Short: "Import the mods and files of a modpack (a CurseForge or Modrinth modpack, or a zip of files) into this pack",
+	Long: `Import the mods and files of a modpack into this pack, detecting the format of the modpack.
+CurseForge modpacks are detected by manifest.json, and Modrinth modpacks by modrinth.index.json; other zip files are
+imported as override files. The Minecraft and mod loader versions of the pack are set to those of the modpack.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			fmt.Println(err)
+			fmt.Printf("To create a new pack from this modpack, use packwiz init --from %s\n", args[0])
+			cmdshared.Exit(cmdshared.ExitGeneric)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		imported := readImportedPack(cmd.Context(), args[0])
+		for component, version := range imported.Versions() {
+			packVersion, ok := pack.Versions[component]
+			if !ok {
+				fmt.Println("Set " + core.ComponentToFriendlyName(component) + " version to " + version)
+			} else if packVersion != version {
+				fmt.Println("Set " + core.ComponentToFriendlyName(component) + " version to " + version + " (previously " + packVersion + ")")
+			}
+			pack.Versions[component] = version
+		}
+
+		err = imported.Import(&index)
+		if err != nil {
+			fmt.Printf("Failed to import modpack: %s\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		err = index.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.UpdateIndexHash()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		fmt.Println("Modpack imported!")
+	},
+}
+
+// readImportedPack reads the modpack at the given path or URL, using the first importer that supports it. Zip files
+// that aren't in a supported format are imported as override files.
+func readImportedPack(ctx context.Context, from string) core.ImportedPack {
+	sourcePath := from
+	if strings.HasPrefix(from, "http://") || strings.HasPrefix(from, "https://") {
+		fmt.Printf("Downloading %s...\n", from)
+		var err error
+		sourcePath, err = cmdshared.DownloadModpack(ctx, from)
+		if err != nil {
+			fmt.Printf("Error downloading modpack: %s\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+	}
+
+	// Sort importers so the detection order is consistent
+	names := make([]string, 0, len(core.PackImporters))
+	for name := range core.PackImporters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		imported, ok, err := core.PackImporters[name].ReadPack(sourcePath)
+		if err != nil {
+			fmt.Printf("Error reading %s: %s\n", from, err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		if ok {
+			fmt.Printf("Importing %s modpack %s\n", name, imported.Name())
+			return imported
+		}
+	}
+	if zr, err := zip.OpenReader(sourcePath); err == nil {
+		_ = zr.Close()
+		fmt.Printf("Warning: can't detect the format of %s (supported formats: %s); importing all of its files as override files\n", from, strings.Join(names, ", "))
+		return overridesImportedPack{sourcePath}
+	}
+	fmt.Printf("Can't detect the format of %s; supported formats: %s\n", from, strings.Join(names, ", "))
+	cmdshared.Exit(cmdshared.ExitGeneric)
+	return nil
+}
+
+// overridesImportedPack is a zip file that isn't in a supported modpack format, imported by copying all of its files
+// into the pack
+type overridesImportedPack struct {
+	path string
+}
+
+func (p overridesImportedPack) Name() string {
+	return strings.TrimSuffix(filepath.Base(p.path), filepath.Ext(p.path))
+}
+
+func (p overridesImportedPack) PackAuthor() string {
+	return ""
+}
+
+func (p overridesImportedPack) PackVersion() string {
+	return ""
+}
+
+func (p overridesImportedPack) Versions() map[string]string {
+	return map[string]string{}
+}
+
+func (p overridesImportedPack) Import(index *core.Index) error {
+	zr, err := zip.OpenReader(p.path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	copied := 0
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		err = extractImportFile(f, index)
+		if err != nil {
+			fmt.Printf("Failed to copy file \"%s\": %s\n", f.Name, err)
+			continue
+		}
+		copied++
+	}
+	fmt.Printf("Successfully copied %d/%d files!\n", copied, len(zr.File))
+	return index.Refresh()
+}
+
+// extractImportFile copies a file from a zip into the pack, at the same path relative to the pack root
+func extractImportFile(f *zip.File, index *core.Index) error {
+	relPath := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+	if path.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return fmt.Errorf("invalid path %s", f.Name)
+	}
+	destPath := index.ResolveIndexPath(relPath)
+	err := os.MkdirAll(filepath.Dir(destPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dest, src)
+	if err != nil {
+		_ = dest.Close()
+		return err
+	}
+	return dest.Close()
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
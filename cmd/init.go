@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,6 +12,7 @@ import (
 	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -25,10 +25,18 @@ var initCmd = &cobra.Command{
 		_, err := os.Stat(viper.GetString("pack-file"))
 		if err == nil && !viper.GetBool("init.reinit") {
 			fmt.Println("Modpack metadata file already exists, use -r to override!")
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitGeneric)
 		} else if err != nil && !os.IsNotExist(err) {
 			fmt.Printf("Error checking pack file: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+
+		// Read the modpack to import, if one was given, so its metadata can be used as the default values
+		var imported core.ImportedPack
+		importedVersions := make(map[string]string)
+		if from := viper.GetString("init.from"); len(from) > 0 {
+			imported = readImportedPack(cmd.Context(), from)
+			importedVersions = imported.Versions()
 		}
 
 		name, err := cmd.Flags().GetString("name")
@@ -39,29 +47,39 @@ var initCmd = &cobra.Command{
 			if err == nil {
 				directoryName = filepath.Base(wd)
 			}
-			if directoryName != "." && len(directoryName) > 0 {
+			if imported != nil && len(imported.Name()) > 0 {
+				name = initPrompt("Modpack name ["+imported.Name()+"]: ", imported.Name())
+			} else if directoryName != "." && len(directoryName) > 0 {
 				// Turn directory name into a space-seperated proper name
 				name = titlecase.Title(strings.ReplaceAll(strings.ReplaceAll(strings.Join(camelcase.Split(directoryName), " "), " - ", " "), " _ ", " "))
-				name = initReadValue("Modpack name ["+name+"]: ", name)
+				name = initPrompt("Modpack name ["+name+"]: ", name)
 			} else {
-				name = initReadValue("Modpack name: ", "")
+				name = initPrompt("Modpack name: ", "")
 			}
 		}
 
 		author, err := cmd.Flags().GetString("author")
 		if err != nil || len(author) == 0 {
-			author = initReadValue("Author: ", "")
+			if imported != nil && len(imported.PackAuthor()) > 0 {
+				author = initPrompt("Author ["+imported.PackAuthor()+"]: ", imported.PackAuthor())
+			} else {
+				author = initPrompt("Author: ", "")
+			}
 		}
 
 		version, err := cmd.Flags().GetString("version")
 		if err != nil || len(version) == 0 {
-			version = initReadValue("Version [1.0.0]: ", "1.0.0")
+			defaultVersion := "1.0.0"
+			if imported != nil && len(imported.PackVersion()) > 0 {
+				defaultVersion = imported.PackVersion()
+			}
+			version = initPrompt("Version ["+defaultVersion+"]: ", defaultVersion)
 		}
 
 		mcVersions, err := cmdshared.GetValidMCVersions()
 		if err != nil {
 			fmt.Printf("Failed to get latest minecraft versions: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		mcVersion := viper.GetString("init.mc-version")
@@ -74,15 +92,29 @@ var initCmd = &cobra.Command{
 			}
 			if viper.GetBool("init.latest") {
 				mcVersion = latestVersion
+			} else if importedVersion, ok := importedVersions["minecraft"]; ok {
+				mcVersion = initPrompt("Minecraft version ["+importedVersion+"]: ", importedVersion)
 			} else {
-				mcVersion = initReadValue("Minecraft version ["+latestVersion+"]: ", latestVersion)
+				mcVersion = initPrompt("Minecraft version ["+latestVersion+"]: ", latestVersion)
 			}
 		}
-		mcVersions.CheckValid(mcVersion)
+		err = mcVersions.CheckValid(mcVersion)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
 
 		modLoaderName := strings.ToLower(viper.GetString("init.modloader"))
 		if len(modLoaderName) == 0 {
-			modLoaderName = strings.ToLower(initReadValue("Mod loader [quilt]: ", "quilt"))
+			defaultLoader := "quilt"
+			if imported != nil {
+				defaultLoader = "none"
+				for component := range importedVersions {
+					if _, ok := core.ModLoaders[component]; ok {
+						defaultLoader = component
+					}
+				}
+			}
+			modLoaderName = strings.ToLower(initPrompt("Mod loader ["+defaultLoader+"]: ", defaultLoader))
 		}
 
 		loader, ok := core.ModLoaders[modLoaderName]
@@ -92,25 +124,25 @@ var initCmd = &cobra.Command{
 				versions, latestVersion, err := loader.VersionListGetter(mcVersion)
 				if err != nil {
 					fmt.Printf("Error loading versions: %s\n", err)
-					os.Exit(1)
+					cmdshared.Exit(cmdshared.ExitCode(err))
 				}
 				componentVersion := viper.GetString("init." + loader.Name + "-version")
 				if len(componentVersion) == 0 {
 					if viper.GetBool("init." + loader.Name + "-latest") {
 						componentVersion = latestVersion
 					} else {
-						componentVersion = initReadValue(loader.FriendlyName+" version ["+latestVersion+"]: ", latestVersion)
+						if importedVersion, ok := importedVersions[loader.Name]; ok {
+							latestVersion = importedVersion
+						}
+						componentVersion = initPrompt(loader.FriendlyName+" version ["+latestVersion+"]: ", latestVersion)
 					}
 				}
-				v := componentVersion
 				// Forge uses a format where they prefix their version with their supported minecraft version. NeoForge
 				// did this too, but only during the 1.20.1 days, they've since switched formats.
-				if loader.Name == "forge" || (loader.Name == "neoforge" && mcVersion == "1.20.1") {
-					v = cmdshared.GetRawForgeVersion(componentVersion)
-				}
+				v := cmdshared.GetRawLoaderVersion(loader.Name, mcVersion, componentVersion)
 				if !slices.Contains(versions, v) {
 					fmt.Println("Given " + loader.FriendlyName + " version cannot be found!")
-					os.Exit(1)
+					cmdshared.Exit(cmdshared.ExitNotFound)
 				}
 				modLoaderVersions[loader.Name] = v
 			} else {
@@ -123,7 +155,7 @@ var initCmd = &cobra.Command{
 					i++
 				}
 				fmt.Println(strings.Join(keys, ", "))
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitGeneric)
 			}
 		}
 
@@ -131,15 +163,15 @@ var initCmd = &cobra.Command{
 		_, err = os.Stat(indexFilePath)
 		if os.IsNotExist(err) {
 			// Create file
-			err = os.WriteFile(indexFilePath, []byte{}, 0644)
+			err = os.WriteFile(indexFilePath, []byte("hash-format = \""+core.GetDefaultHashFormat()+"\"\n"), 0644)
 			if err != nil {
 				fmt.Printf("Error creating index file: %s\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 			fmt.Println(indexFilePath + " created!")
 		} else if err != nil {
 			fmt.Printf("Error checking index file: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		// Create the pack
@@ -168,28 +200,30 @@ var initCmd = &cobra.Command{
 		// Refresh the index and pack
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		if imported != nil {
+			err = imported.Import(&index)
+			if err != nil {
+				fmt.Printf("Failed to import modpack: %s\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
 		}
 		err = index.Refresh()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = index.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.UpdateIndexHash()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		fmt.Println(viper.GetString("pack-file") + " created!")
 	},
@@ -213,6 +247,15 @@ func init() {
 	_ = viper.BindPFlag("init.reinit", initCmd.Flags().Lookup("reinit"))
 	initCmd.Flags().String("modloader", "", "The mod loader to use (omit to define interactively)")
 	_ = viper.BindPFlag("init.modloader", initCmd.Flags().Lookup("modloader"))
+	initCmd.Flags().String("from", "", "A modpack file, folder or URL to import mods and files from; its metadata is used for values that aren't given")
+	_ = viper.BindPFlag("init.from", initCmd.Flags().Lookup("from"))
+	// Allow --loader as an alias of --modloader
+	initCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "loader" {
+			name = "modloader"
+		}
+		return pflag.NormalizedName(name)
+	})
 
 	// ok this is epic
 	for _, loader := range core.ModLoaders {
@@ -223,21 +266,11 @@ func init() {
 	}
 }
 
-func initReadValue(prompt string, def string) string {
-	fmt.Print(prompt)
-	if viper.GetBool("non-interactive") {
-		fmt.Printf("%s\n", def)
-		return def
-	}
-	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+// initPrompt asks the user for a value of the new pack, exiting if they can't be prompted
+func initPrompt(prompt string, def string) string {
+	value, err := cmdshared.PromptValue(prompt, def)
 	if err != nil {
-		fmt.Printf("Error reading input: %s\n", err)
-		os.Exit(1)
-	}
-	// Trims both CR and LF
-	value = strings.TrimSpace(strings.TrimRight(value, "\r\n"))
-	if len(value) > 0 {
-		return value
+		cmdshared.ExitWithError(err)
 	}
-	return def
+	return value
 }
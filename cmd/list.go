@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"text/tabwriter"
 
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,22 +25,19 @@ var listCmd = &cobra.Command{
 		// Load pack
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		// Load index
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		// Load mods
 		mods, err := index.LoadAllMods()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		// Filter mods by side
@@ -44,17 +45,35 @@ var listCmd = &cobra.Command{
 			side := viper.GetString("list.side")
 			if side != core.UniversalSide && side != core.ServerSide && side != core.ClientSide {
 				fmt.Printf("Invalid side %q, must be one of client, server, or both (default)\n", side)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitUsage)
 			}
 
-			i := 0
-			for _, mod := range mods {
-				if mod.Side == side || mod.Side == core.EmptySide || mod.Side == core.UniversalSide || side == core.UniversalSide {
-					mods[i] = mod
-					i++
-				}
+			mods = filterListSide(mods, side)
+		}
+
+		if viper.GetBool("list.outdated") {
+			outdated, failures := checkOutdated(mods, pack, core.Updaters)
+			if viper.GetBool("list.json") {
+				printJSON(outdated)
+			} else {
+				printOutdated(outdated)
+			}
+			// Failures are written to stderr, so they don't corrupt JSON output
+			for _, f := range failures {
+				_, _ = fmt.Fprintf(os.Stderr, "Failed to check updates for %s: %v\n", f.Name, f.Err)
+			}
+			if len(failures) > 0 {
+				cmdshared.Exit(cmdshared.ExitGeneric)
 			}
-			mods = mods[:i]
+			if len(outdated) > 0 {
+				cmdshared.Exit(cmdshared.ExitUpdatesAvailable)
+			}
+			return
+		}
+
+		if viper.GetBool("list.json") {
+			printModsJSON(mods)
+			return
 		}
 
 		sort.Slice(mods, func(i, j int) bool {
@@ -64,22 +83,185 @@ var listCmd = &cobra.Command{
 		// Print mods
 		if viper.GetBool("list.version") {
 			for _, mod := range mods {
-				fmt.Printf("%s (%s)\n", mod.Name, mod.FileName)
+				fmt.Printf("%s (%s) [%s]\n", mod.Name, mod.FileName, getListSide(mod))
 			}
 		} else {
 			for _, mod := range mods {
-				fmt.Println(mod.Name)
+				fmt.Printf("%s [%s]\n", mod.Name, getListSide(mod))
 			}
 		}
 	},
 }
 
+// filterListSide returns the mods with exactly the given side, treating mods without a side as both; so filtering by
+// server only returns server-only mods
+func filterListSide(mods []*core.Mod, side string) []*core.Mod {
+	var filtered []*core.Mod
+	for _, mod := range mods {
+		if getListSide(mod) == side {
+			filtered = append(filtered, mod)
+		}
+	}
+	return filtered
+}
+
+// getListSide returns the side of a mod, treating mods without a side as both
+func getListSide(mod *core.Mod) string {
+	if mod.Side == core.EmptySide {
+		return core.UniversalSide
+	}
+	return mod.Side
+}
+
+type listJSONEntry struct {
+	Name     string `json:"name"`
+	FileName string `json:"filename"`
+	Side     string `json:"side"`
+	Source   string `json:"source"`
+	Version  string `json:"version"`
+	Hash     string `json:"hash"`
+}
+
+// listVersionKeys lists the update systems with the key of their update data that identifies the installed version, in
+// the order they are chosen in when a mod has update data for more than one
+var listVersionKeys = []struct {
+	source string
+	key    string
+}{
+	{"modrinth", "version"},
+	{"curseforge", "file-id"},
+	{"github", "tag"},
+}
+
+// getListSource returns the update system of a mod (or "url" if it doesn't have one) and its installed version
+func getListSource(mod *core.Mod) (source string, version string) {
+	for _, v := range listVersionKeys {
+		if data, ok := mod.Update[v.source]; ok {
+			if version, ok := data[v.key]; ok {
+				return v.source, fmt.Sprint(version)
+			}
+			return v.source, ""
+		}
+	}
+	return "url", ""
+}
+
+// printModsJSON prints the given mods as a JSON array, sorted by file name
+func printModsJSON(mods []*core.Mod) {
+	sort.Slice(mods, func(i, j int) bool {
+		if mods[i].FileName == mods[j].FileName {
+			return mods[i].Name < mods[j].Name
+		}
+		return mods[i].FileName < mods[j].FileName
+	})
+
+	entries := make([]listJSONEntry, 0, len(mods))
+	for _, mod := range mods {
+		source, version := getListSource(mod)
+		entries = append(entries, listJSONEntry{
+			Name:     mod.Name,
+			FileName: mod.FileName,
+			Side:     getListSide(mod),
+			Source:   source,
+			Version:  version,
+			Hash:     mod.Download.Hash,
+		})
+	}
+
+	printJSON(entries)
+}
+
+// printJSON prints v as JSON, in the format chosen with --json-format
+func printJSON(v interface{}) {
+	if err := cmdshared.WriteJSON(os.Stdout, v); err != nil {
+		cmdshared.ExitWithError(err)
+	}
+}
+
+type outdatedEntry struct {
+	Name             string `json:"name"`
+	FileName         string `json:"filename"`
+	Source           string `json:"source"`
+	CurrentVersion   string `json:"current-version"`
+	AvailableVersion string `json:"available-version"`
+}
+
+// checkOutdated checks the given mods for updates, checking each update system in parallel (requests are still limited
+// by the rate limit of each source). Pinned mods aren't checked. The mods with updates are returned sorted by name,
+// along with the mods that couldn't be checked.
+func checkOutdated(mods []*core.Mod, pack core.Pack, updaters map[string]core.Updater) ([]outdatedEntry, []updateFailure) {
+	unpinned := slices.DeleteFunc(slices.Clone(mods), func(mod *core.Mod) bool {
+		return mod.Pin
+	})
+	filesWithUpdater, _ := groupModsByUpdater(unpinned, "", updaters)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	outdated := []outdatedEntry{}
+	var failures []updateFailure
+	for source, sourceMods := range filesWithUpdater {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks, err := updaters[source].CheckUpdate(sourceMods, pack)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				for _, mod := range sourceMods {
+					failures = append(failures, updateFailure{mod.Name, err})
+				}
+				return
+			}
+			for i, check := range checks {
+				if check.Error != nil {
+					failures = append(failures, updateFailure{sourceMods[i].Name, check.Error})
+				} else if check.UpdateAvailable {
+					outdated = append(outdated, outdatedEntry{
+						Name:             sourceMods[i].Name,
+						FileName:         sourceMods[i].FileName,
+						Source:           source,
+						CurrentVersion:   check.CurrentVersion,
+						AvailableVersion: check.NewVersion,
+					})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	slices.SortFunc(outdated, func(a, b outdatedEntry) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	slices.SortFunc(failures, func(a, b updateFailure) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return outdated, failures
+}
+
+// printOutdated prints a table of the mods that have updates available
+func printOutdated(outdated []outdatedEntry) {
+	if len(outdated) == 0 {
+		fmt.Println("All files are up to date!")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Name\tCurrent version\tAvailable version\tSource")
+	for _, e := range outdated {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Name, e.CurrentVersion, e.AvailableVersion, e.Source)
+	}
+	_ = w.Flush()
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().BoolP("version", "v", false, "Print name and version")
 	_ = viper.BindPFlag("list.version", listCmd.Flags().Lookup("version"))
-	listCmd.Flags().StringP("side", "s", "", "Filter mods by side (e.g., client or server)")
+	listCmd.Flags().StringP("side", "s", "", "Only list mods with exactly this side (client, server or both); mods without a side are treated as both, so they are only listed with both")
 	_ = viper.BindPFlag("list.side", listCmd.Flags().Lookup("side"))
+	listCmd.Flags().Bool("json", false, "Print mods as a JSON array, for use in scripts")
+	_ = viper.BindPFlag("list.json", listCmd.Flags().Lookup("json"))
+	listCmd.Flags().Bool("outdated", false, "Only list mods with updates available (without changing any files), exiting with code 6 if any are found; pinned mods are skipped")
+	_ = viper.BindPFlag("list.outdated", listCmd.Flags().Lookup("outdated"))
 
 }
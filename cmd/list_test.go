@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// outdatedTestUpdater reports an update for files named "old" and "pinned", and fails for files named "broken"
+type outdatedTestUpdater struct{}
+
+func (outdatedTestUpdater) ParseUpdate(data map[string]interface{}) (interface{}, error) {
+	return data, nil
+}
+
+func (outdatedTestUpdater) CheckUpdate(mods []*core.Mod, _ core.Pack) ([]core.UpdateCheck, error) {
+	checks := make([]core.UpdateCheck, len(mods))
+	for i, mod := range mods {
+		switch mod.Name {
+		case "old", "pinned":
+			checks[i] = core.UpdateCheck{UpdateAvailable: true, CurrentVersion: mod.FileName, NewVersion: "new.jar"}
+		case "broken":
+			checks[i].Error = errors.New("no compatible version")
+		}
+	}
+	return checks, nil
+}
+
+func (outdatedTestUpdater) DoUpdate([]*core.Mod, []interface{}) error {
+	return errors.New("list must not update files")
+}
+
+func TestCheckOutdated(t *testing.T) {
+	update := map[string]map[string]interface{}{"test": {}}
+	mods := []*core.Mod{
+		{Name: "old", FileName: "old.jar", Update: update},
+		{Name: "current", FileName: "current.jar", Update: update},
+		{Name: "pinned", FileName: "pinned.jar", Pin: true, Update: update},
+		{Name: "broken", FileName: "broken.jar", Update: update},
+		{Name: "url", FileName: "url.jar"},
+	}
+	outdated, failures := checkOutdated(mods, core.Pack{}, map[string]core.Updater{"test": outdatedTestUpdater{}})
+
+	expected := outdatedEntry{Name: "old", FileName: "old.jar", Source: "test", CurrentVersion: "old.jar", AvailableVersion: "new.jar"}
+	if len(outdated) != 1 || outdated[0] != expected {
+		t.Errorf("Expected only %v to be outdated, got %v", expected, outdated)
+	}
+	if len(failures) != 1 || failures[0].Name != "broken" {
+		t.Errorf("Expected broken to fail, got %v", failures)
+	}
+}
+
+func TestListOutdatedExitCode(t *testing.T) {
+	t.Cleanup(func() {
+		_ = listCmd.Flags().Set("outdated", "false")
+	})
+
+	writeUpdateTestPack(t, "current")
+	if code := executeForExitCode(t, "list", "--outdated"); code != cmdshared.ExitOK {
+		t.Errorf("Expected exit code %d when no files are outdated, got %d", cmdshared.ExitOK, code)
+	}
+	writeUpdateTestPack(t, "current", "old")
+	if code := executeForExitCode(t, "list", "--outdated"); code != cmdshared.ExitUpdatesAvailable {
+		t.Errorf("Expected exit code %d when files are outdated, got %d", cmdshared.ExitUpdatesAvailable, code)
+	}
+	writeUpdateTestPack(t, "old", "broken")
+	if code := executeForExitCode(t, "list", "--outdated"); code != cmdshared.ExitGeneric {
+		t.Errorf("Expected exit code %d when checking for updates fails, got %d", cmdshared.ExitGeneric, code)
+	}
+}
+
+func TestGetListSource(t *testing.T) {
+	mod := &core.Mod{Update: map[string]map[string]interface{}{
+		"github":     {"tag": "v1.0.0"},
+		"curseforge": {"file-id": int64(1234)},
+		"modrinth":   {"version": "ABCDEFGH"},
+	}}
+	// Map iteration order is random, so check several times
+	for i := 0; i < 20; i++ {
+		if source, version := getListSource(mod); source != "modrinth" || version != "ABCDEFGH" {
+			t.Fatalf("Expected modrinth ABCDEFGH, got %s %s", source, version)
+		}
+	}
+	delete(mod.Update, "modrinth")
+	if source, version := getListSource(mod); source != "curseforge" || version != "1234" {
+		t.Errorf("Expected curseforge 1234, got %s %s", source, version)
+	}
+	if source, version := getListSource(&core.Mod{}); source != "url" || version != "" {
+		t.Errorf("Expected url for a mod without update data, got %s %s", source, version)
+	}
+}
+
+func TestFilterListSide(t *testing.T) {
+	mods := []*core.Mod{
+		{Name: "server", Side: core.ServerSide},
+		{Name: "client", Side: core.ClientSide},
+		{Name: "both", Side: core.UniversalSide},
+		{Name: "unset", Side: core.EmptySide},
+	}
+	tests := []struct {
+		side     string
+		expected []string
+	}{
+		{core.ServerSide, []string{"server"}},
+		{core.ClientSide, []string{"client"}},
+		{core.UniversalSide, []string{"both", "unset"}},
+	}
+	for _, tt := range tests {
+		var names []string
+		for _, mod := range filterListSide(mods, tt.side) {
+			names = append(names, mod.Name)
+		}
+		if !slices.Equal(names, tt.expected) {
+			t.Errorf("Expected --side %s to list %v, got %v", tt.side, tt.expected, names)
+		}
+	}
+}
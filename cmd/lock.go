@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Record the exact version of every metadata file in packwiz.lock",
+	Long: `Record the exact version of every metadata file in packwiz.lock: the file downloaded, its hash and the version IDs
+of its update source. Once packwiz.lock exists, packwiz update keeps it up to date, and packwiz sync --locked restores
+metadata files to the versions recorded in it.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		lock, err := index.NewLock()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = index.WriteLock(lock)
+		if err != nil {
+			fmt.Printf("Error writing %s: %v\n", core.LockFile, err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("Locked %d files in %s\n", len(lock.Files), core.LockFile)
+	},
+}
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Compare metadata files with packwiz.lock, or restore them to the locked versions with --locked",
+	Long: `Compare metadata files with the versions recorded in packwiz.lock by packwiz lock, exiting with a non-zero code if
+any differ. With --locked, metadata files are restored to the locked versions instead (keeping their other settings,
+such as tags and pins), and missing metadata files are recreated; metadata files that aren't locked are left alone.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		lock, err := index.LoadLock()
+		if err != nil {
+			if os.IsNotExist(err) {
+				fmt.Printf("No %s file found, run 'packwiz lock' to create one!\n", core.LockFile)
+				cmdshared.Exit(cmdshared.ExitNotFound)
+			}
+			fmt.Printf("Error reading %s: %v\n", core.LockFile, err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+
+		if !viper.GetBool("sync.locked") {
+			diffs, err := lock.Compare(index)
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			if len(diffs) == 0 {
+				fmt.Printf("All metadata files match %s\n", core.LockFile)
+				return
+			}
+			for _, diff := range diffs {
+				fmt.Printf("%s: %s\n", diff.Path, diff.Problem)
+			}
+			fmt.Printf("%d metadata files don't match %s; use --locked to restore them, or packwiz lock to lock the current versions\n", len(diffs), core.LockFile)
+			cmdshared.Exit(cmdshared.ExitGeneric)
+		}
+
+		restored, err := lock.Restore(&index)
+		if err != nil {
+			fmt.Printf("Error restoring metadata files: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		for _, path := range restored {
+			fmt.Printf("Restored %s\n", path)
+		}
+		if len(restored) == 0 {
+			fmt.Printf("All metadata files already match %s\n", core.LockFile)
+			return
+		}
+		err = index.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.UpdateIndexHash()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		fmt.Printf("Restored %d metadata files from %s\n", len(restored), core.LockFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().Bool("locked", false, "Restore metadata files to the versions recorded in packwiz.lock")
+	_ = viper.BindPFlag("sync.locked", syncCmd.Flags().Lookup("locked"))
+}
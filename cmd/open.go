@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/skratchdot/open-golang/open"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// openCmd represents the open command
+var openCmd = &cobra.Command{
+	Use:     "open [name]",
+	Short:   "Open the project page of a file in your browser",
+	Aliases: []string{"doc"},
+	Long: `Open the project page of a file in your browser, on the site it was installed from (Modrinth, CurseForge or
+GitHub). With --print, the URL is printed instead.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeModNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		modPath, ok := ResolveModName(index, args[0])
+		if !ok {
+			fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
+			cmdshared.Exit(cmdshared.ExitNotFound)
+		}
+		modData, err := core.LoadMod(modPath)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		url, err := modData.GetProjectPage()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		if viper.GetBool("open.print") {
+			fmt.Println(url)
+			return
+		}
+		fmt.Println("Opening browser...")
+		err = open.Start(url)
+		if err != nil {
+			fmt.Println("Opening page failed, direct link:")
+			fmt.Println(url)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+
+	openCmd.Flags().Bool("print", false, "Print the URL of the project page instead of opening it")
+	_ = viper.BindPFlag("open.print", openCmd.Flags().Lookup("print"))
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// PackPatch describes how to update an installed copy of an old version of the pack to the current version, for
+// exporting patch packs that only contain the files that changed
+type PackPatch struct {
+	// Since is the old version of the pack, as given to packwiz diff
+	Since string
+	// Changed contains the index paths of the files (including metadata files) that were added or updated
+	Changed map[string]bool
+	// Removed lists the paths of the installed files that must be removed, relative to the pack root; for metadata
+	// files, these are the paths of the files they download
+	Removed []string
+}
+
+// GetPackPatch compares the index with an old version of it, given as to packwiz diff: the path to an index file, a
+// folder containing index.toml, or a git revision. The metadata files of the old version must be readable, as they
+// give the paths of the files that were installed.
+func GetPackPatch(since string, index core.Index) (PackPatch, error) {
+	oldSource, err := resolveDiffSource(since)
+	if err != nil {
+		return PackPatch{}, err
+	}
+	oldIndex, err := oldSource.loadIndex()
+	if err != nil {
+		return PackPatch{}, err
+	}
+	patch, err := packPatch(oldSource, oldIndex, index)
+	if err != nil {
+		return PackPatch{}, err
+	}
+	patch.Since = since
+	return patch, nil
+}
+
+func packPatch(oldSource diffSource, oldIndex core.Index, index core.Index) (PackPatch, error) {
+	patch := PackPatch{Changed: make(map[string]bool)}
+	// Files installed by the new version aren't removed, even if an old file was at the same path
+	installed := make(map[string]bool)
+	for p, holder := range index.Files {
+		if !holder.IsMetaFile() {
+			installed[p] = true
+			continue
+		}
+		mod, err := core.LoadMod(index.ResolveIndexPath(p))
+		if err != nil {
+			return PackPatch{}, err
+		}
+		destPath, err := index.RelIndexPath(mod.GetDestFilePath())
+		if err != nil {
+			return PackPatch{}, err
+		}
+		installed[destPath] = true
+	}
+
+	for _, change := range core.DiffIndex(oldIndex, index) {
+		if change.Type != core.FileRemoved {
+			patch.Changed[change.Path] = true
+		}
+		if change.Type == core.FileAdded {
+			continue
+		}
+		removed := change.Path
+		if change.MetaFile {
+			oldMod, err := oldSource.loadMod(oldIndex, change.Path)
+			if err != nil {
+				return PackPatch{}, fmt.Errorf("failed to read old version of %s: %w", change.Path, err)
+			}
+			removed, err = oldIndex.RelIndexPath(oldMod.GetDestFilePath())
+			if err != nil {
+				return PackPatch{}, err
+			}
+		}
+		if !installed[removed] {
+			patch.Removed = append(patch.Removed, removed)
+		}
+	}
+	slices.Sort(patch.Removed)
+	patch.Removed = slices.Compact(patch.Removed)
+	return patch, nil
+}
+
+// FilterMods returns the mods whose metadata files were added or updated
+func (p PackPatch) FilterMods(mods []*core.Mod, index core.Index) []*core.Mod {
+	var changed []*core.Mod
+	for _, mod := range mods {
+		relPath, err := index.RelIndexPath(mod.GetFilePath())
+		if err == nil && p.Changed[relPath] {
+			changed = append(changed, mod)
+		}
+	}
+	return changed
+}
+
+// FilterIndex returns a copy of the index containing only the files that were added or updated
+func (p PackPatch) FilterIndex(index core.Index) core.Index {
+	filtered := index
+	filtered.Files = make(core.IndexFiles)
+	for path, holder := range index.Files {
+		if p.Changed[path] {
+			filtered.Files[path] = holder
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// writePatchTestPack writes an index and metadata files to a folder; files are given as path -> hash, and metadata
+// files as path -> the name of the file they download
+func writePatchTestPack(t *testing.T, dir string, files map[string]string, metaFiles map[string]string) {
+	t.Helper()
+	var index strings.Builder
+	index.WriteString("hash-format = \"sha256\"\n")
+	for _, p := range slices.Sorted(maps.Keys(files)) {
+		_, isMeta := metaFiles[p]
+		index.WriteString("\n[[files]]\nfile = \"" + p + "\"\nhash = \"" + files[p] + "\"\n")
+		if isMeta {
+			index.WriteString("metafile = true\n")
+			path := filepath.Join(dir, filepath.FromSlash(p))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			contents := "name = \"" + p + "\"\nfilename = \"" + metaFiles[p] + "\"\n"
+			if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.toml"), []byte(index.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPackPatch(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	writePatchTestPack(t, oldDir, map[string]string{
+		"mods/updated.pw.toml":   "1111",
+		"mods/unchanged.pw.toml": "2222",
+		"mods/removed.pw.toml":   "3333",
+		"mods/replaced.pw.toml":  "4444",
+		"config/changed.cfg":     "5555",
+		"config/same.cfg":        "6666",
+		"config/removed.cfg":     "7777",
+	}, map[string]string{
+		"mods/updated.pw.toml":   "updated-1.0.jar",
+		"mods/unchanged.pw.toml": "unchanged.jar",
+		"mods/removed.pw.toml":   "removed.jar",
+		"mods/replaced.pw.toml":  "replaced.jar",
+	})
+	writePatchTestPack(t, newDir, map[string]string{
+		"mods/updated.pw.toml":   "1112",
+		"mods/unchanged.pw.toml": "2222",
+		"mods/added.pw.toml":     "8888",
+		"mods/replaced.jar":      "9999",
+		"config/changed.cfg":     "5556",
+		"config/same.cfg":        "6666",
+	}, map[string]string{
+		"mods/updated.pw.toml":   "updated-1.1.jar",
+		"mods/unchanged.pw.toml": "unchanged.jar",
+		"mods/added.pw.toml":     "added.jar",
+	})
+	index, err := core.LoadIndex(filepath.Join(newDir, "index.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch, err := GetPackPatch(oldDir, index)
+	if err != nil {
+		t.Fatalf("Failed to compare indexes: %v", err)
+	}
+	changed := slices.Sorted(maps.Keys(patch.Changed))
+	expectedChanged := []string{"config/changed.cfg", "mods/added.pw.toml", "mods/replaced.jar", "mods/updated.pw.toml"}
+	if !slices.Equal(changed, expectedChanged) {
+		t.Errorf("Expected changed files %v, got %v", expectedChanged, changed)
+	}
+	// The old version of an updated file is removed, but files that are installed again at the same path aren't
+	expectedRemoved := []string{"config/removed.cfg", "mods/removed.jar", "mods/updated-1.0.jar"}
+	if !slices.Equal(patch.Removed, expectedRemoved) {
+		t.Errorf("Expected removed files %v, got %v", expectedRemoved, patch.Removed)
+	}
+
+	filtered := patch.FilterIndex(index)
+	if files := slices.Sorted(maps.Keys(filtered.Files)); !slices.Equal(files, expectedChanged) {
+		t.Errorf("Expected only changed files in the filtered index, got %v", files)
+	}
+	mods, err := index.LoadAllMods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var modNames []string
+	for _, mod := range patch.FilterMods(mods, index) {
+		modNames = append(modNames, mod.Name)
+	}
+	slices.Sort(modNames)
+	if !slices.Equal(modNames, []string{"mods/added.pw.toml", "mods/updated.pw.toml"}) {
+		t.Errorf("Expected only added and updated mods, got %v", modNames)
+	}
+
+	// Removals can't be found without the old metadata files
+	if err := os.Remove(filepath.Join(oldDir, "mods", "removed.pw.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetPackPatch(oldDir, index); err == nil {
+		t.Error("Expected an error when an old metadata file is missing")
+	}
+}
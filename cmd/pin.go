@@ -2,8 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/cobra"
 )
@@ -12,56 +12,48 @@ func pinMod(args []string, pinned bool) {
 	fmt.Println("Loading modpack...")
 	pack, err := core.LoadPack()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 	index, err := pack.LoadIndex()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
-	modPath, ok := index.FindMod(args[0])
+	modPath, ok := ResolveModName(index, args[0])
 	if !ok {
 		fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
-		os.Exit(1)
+		cmdshared.Exit(cmdshared.ExitNotFound)
 	}
 	modData, err := core.LoadMod(modPath)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 	modData.Pin = pinned
 	format, hash, err := modData.Write()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 	err = index.RefreshFileWithHash(modPath, format, hash, true)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 	err = index.Write()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 	err = pack.UpdateIndexHash()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 	err = pack.Write()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 
 	message := "pinned"
 	if !pinned {
 		message = "unpinned"
 	}
-	fmt.Printf("%s %s successfully!\n", args[0], message)
+	fmt.Printf("%s %s successfully!\n", getModSlug(modPath), message)
 }
 
 // pinCmd represents the pin command
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+const pinTestMod = `name = "A"
+filename = "a-1.0.jar"
+side = "both"
+
+[download]
+url = "https://cdn.modrinth.com/data/AAAAAAAA/versions/BBBBBBBB/a-1.0.jar"
+hash-format = "sha1"
+hash = "abc"
+
+[update]
+[update.modrinth]
+mod-id = "AAAAAAAA"
+version = "BBBBBBBB"
+`
+
+// pinTestUpdater is a stand-in for the modrinth updater, which can't be imported here
+type pinTestUpdater struct{}
+
+func (pinTestUpdater) ParseUpdate(data map[string]interface{}) (interface{}, error) {
+	return data, nil
+}
+
+func (pinTestUpdater) CheckUpdate(mods []*core.Mod, _ core.Pack) ([]core.UpdateCheck, error) {
+	return make([]core.UpdateCheck, len(mods)), nil
+}
+
+func (pinTestUpdater) DoUpdate([]*core.Mod, []interface{}) error {
+	return nil
+}
+
+// setupPinTestPack creates a pack containing a single Modrinth mod, and returns the path to its metafile
+func setupPinTestPack(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"pack.toml": `name = "test"
+pack-format = "packwiz:1.1.0"
+
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+
+[versions]
+fabric = "0.14.21"
+minecraft = "1.20.1"
+`,
+		"index.toml": `hash-format = "sha256"
+
+[[files]]
+file = "mods/a.pw.toml"
+hash = ""
+metafile = true
+`,
+		"mods/a.pw.toml": pinTestMod,
+	}
+	for name, contents := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok := core.Updaters["modrinth"]; !ok {
+		core.Updaters["modrinth"] = pinTestUpdater{}
+		t.Cleanup(func() { delete(core.Updaters, "modrinth") })
+	}
+
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", filepath.Join(dir, "pack.toml"))
+	t.Cleanup(func() { viper.Set("pack-file", oldPackFile) })
+	return filepath.Join(dir, "mods", "a.pw.toml")
+}
+
+func loadPinTestMod(t *testing.T, modPath string) core.Mod {
+	t.Helper()
+	mod, err := core.LoadMod(modPath)
+	if err != nil {
+		t.Fatalf("Failed to load mod: %v", err)
+	}
+	if _, ok := mod.Update["modrinth"]; !ok {
+		t.Fatalf("Expected update metadata to be kept, got %v", mod.Update)
+	}
+	return mod
+}
+
+func TestPinMod(t *testing.T) {
+	modPath := setupPinTestPack(t)
+
+	pinMod([]string{"a"}, true)
+	mod := loadPinTestMod(t, modPath)
+	if !mod.Pin {
+		t.Error("Expected mod to be pinned")
+	}
+	if !skipPinned(&mod) {
+		t.Error("Expected update to skip a pinned mod")
+	}
+
+	pinMod([]string{"a"}, false)
+	mod = loadPinTestMod(t, modPath)
+	if mod.Pin {
+		t.Error("Expected mod to be unpinned")
+	}
+	if skipPinned(&mod) {
+		t.Error("Expected update not to skip an unpinned mod")
+	}
+}
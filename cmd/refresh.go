@@ -3,8 +3,9 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/viper"
-	"os"
+	"strings"
 
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/cobra"
 )
@@ -18,8 +19,7 @@ var refreshCmd = &cobra.Command{
 		fmt.Println("Loading modpack...")
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		build, err := cmd.Flags().GetBool("build")
 		if err == nil && build {
@@ -27,30 +27,67 @@ var refreshCmd = &cobra.Command{
 		} else if viper.GetBool("no-internal-hashes") {
 			fmt.Println("Note: no-internal-hashes mode is set, no hashes will be saved. Use --build to override this for distribution.")
 		}
+		err = core.ValidateProjectTypeFolders()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		if viper.GetBool("refresh.index-hash-only") {
+			// The index has been parsed, so its hash can be stored without rehashing the files it lists
+			err = pack.UpdateIndexHash()
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			err = pack.Write()
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			fmt.Println("Index hash updated!")
+			return
+		}
+		// Only convert existing packs when a default hash format has been explicitly chosen
+		if hashFormat := core.GetDefaultHashFormat(); viper.IsSet("default-hash-format") && index.HashFormat != hashFormat {
+			fmt.Printf("Changing the index hash format from %s to %s\n", index.HashFormat, hashFormat)
+			index.HashFormat = hashFormat
 		}
 		err = index.Refresh()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		sideRules, err := pack.GetSideRules()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		updated, err := index.ApplySideRules(sideRules)
+		if err != nil {
+			fmt.Printf("Failed to apply side rules: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		if updated > 0 {
+			fmt.Printf("Set the side of %d files using side rules\n", updated)
 		}
 		err = index.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.UpdateIndexHash()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		// Only files already in the download cache are checked, as refreshing shouldn't download anything
+		conflicts, _, err := index.FindModIDConflicts(cmd.Context(), false)
+		if err != nil {
+			fmt.Printf("Failed to check for conflicting mod IDs: %v\n", err)
+		}
+		for _, conflict := range conflicts {
+			fmt.Printf("Warning: mod ID %q is declared by more than one file, so the mod loader may refuse to start: %s\n", conflict.ModID, strings.Join(conflict.Paths, ", "))
 		}
 		fmt.Println("Index refreshed!")
 	},
@@ -58,6 +95,17 @@ var refreshCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(refreshCmd)
+	cmdshared.AddHooks(refreshCmd, "refresh")
 
 	refreshCmd.Flags().Bool("build", false, "Only has an effect in no-internal-hashes mode: generates internal hashes for distribution with packwiz-installer")
+	refreshCmd.Flags().Bool("strict", false, "Fail if multiple metadata files install the same file or project, rather than warning")
+	_ = viper.BindPFlag("refresh.strict", refreshCmd.Flags().Lookup("strict"))
+	refreshCmd.Flags().Bool("incremental", false, "Only hash files that have changed since the last incremental refresh, using the modification times and sizes stored in "+core.RefreshCacheFile)
+	_ = viper.BindPFlag("refresh.incremental", refreshCmd.Flags().Lookup("incremental"))
+	refreshCmd.Flags().Bool("force", false, "Hash all files, ignoring the modification times and sizes stored by --incremental")
+	_ = viper.BindPFlag("refresh.force", refreshCmd.Flags().Lookup("force"))
+	refreshCmd.Flags().IntP("jobs", "j", 0, "The number of files to hash in parallel (defaults to the number of CPUs)")
+	_ = viper.BindPFlag("refresh.jobs", refreshCmd.Flags().Lookup("jobs"))
+	refreshCmd.Flags().Bool("index-hash-only", false, "Only update the hash of the index stored in pack.toml, without hashing the files in the index (e.g. after editing the index manually)")
+	_ = viper.BindPFlag("refresh.index-hash-only", refreshCmd.Flags().Lookup("index-hash-only"))
 }
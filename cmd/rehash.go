@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/0byte-coding/packwiz/cmdshared"
 
@@ -22,36 +21,35 @@ var rehashCmd = &cobra.Command{
 		// Load pack
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		// Load index
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		// Load mods
 		mods, err := index.LoadAllMods()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
-		if !slices.Contains([]string{"sha1", "sha512", "sha256"}, args[0]) {
+		if !slices.Contains([]string{"sha1", "sha512", "sha256", "blake3"}, args[0]) {
 			fmt.Printf("Hash format '%s' is not supported\n", args[0])
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitUsage)
 		}
 
 		session, err := core.CreateDownloadSession(mods, []string{args[0]})
 		if err != nil {
 			fmt.Printf("Error retrieving external files: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
-		cmdshared.ListManualDownloads(session)
+		if err := cmdshared.ListManualDownloads(session); err != nil {
+			cmdshared.ExitWithError(err)
+		}
 
 		for dl := range session.StartDownloads() {
 			if dl.Error != nil {
@@ -62,7 +60,7 @@ var rehashCmd = &cobra.Command{
 				_, _, err := dl.Mod.Write()
 				if err != nil {
 					fmt.Printf("Error saving mod %s: %v\n", dl.Mod.Name, err)
-					os.Exit(1)
+					cmdshared.Exit(cmdshared.ExitCode(err))
 				}
 			}
 			// TODO pass the hash to index instead of recomputing from scratch
@@ -71,31 +69,31 @@ var rehashCmd = &cobra.Command{
 		err = session.SaveIndex()
 		if err != nil {
 			fmt.Printf("Error saving cache index: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		err = index.Refresh()
 		if err != nil {
 			fmt.Printf("Error refreshing index: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		err = index.Write()
 		if err != nil {
 			fmt.Printf("Error writing index: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		err = pack.UpdateIndexHash()
 		if err != nil {
 			fmt.Printf("Error updating index hash: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		err = pack.Write()
 		if err != nil {
 			fmt.Printf("Error writing pack: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 	},
 }
@@ -3,9 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // removeCmd represents the remove command
@@ -15,53 +18,151 @@ var removeCmd = &cobra.Command{
 	Aliases: []string{"delete", "uninstall", "rm"},
 	Args:    cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := cmdshared.RedirectOutputForReport("remove.report"); err != nil {
+			cmdshared.ExitWithError(err)
+		}
 		fmt.Println("Loading modpack...")
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
-		resolvedMod, ok := index.FindMod(args[0])
+		resolvedMod, ok := ResolveModName(index, args[0])
 		if !ok {
 			fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitNotFound)
 		}
-		err = os.Remove(resolvedMod)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+		toRemove := []string{resolvedMod}
+		if viper.GetBool("remove.with-deps") {
+			orphans, err := getOrphanedDependencies(index, resolvedMod)
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			if len(orphans) == 0 {
+				fmt.Println("No dependencies would be left unused")
+			} else {
+				fmt.Printf("The following files will be removed:\n  %s\n", getModSlug(resolvedMod))
+				for _, mod := range orphans {
+					fmt.Printf("  %s (dependency)\n", getModSlug(mod.GetFilePath()))
+					toRemove = append(toRemove, mod.GetFilePath())
+				}
+				yes, err := cmdshared.PromptYesNo("Do you want to continue? [Y/n]: ")
+				if err != nil {
+					cmdshared.ExitWithError(err)
+				}
+				if !yes {
+					fmt.Println("Cancelled!")
+					return
+				}
+			}
 		}
-		fmt.Println("Removing file from index...")
-		err = index.RemoveFile(resolvedMod)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+
+		keepFile := viper.GetBool("remove.keep-file")
+		var keptFiles []string
+		var report cmdshared.ChangeReport
+		for _, modPath := range toRemove {
+			keptFile, err := removeModFile(&index, modPath, keepFile, &report)
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			if keptFile != "" {
+				keptFiles = append(keptFiles, keptFile)
+			}
+		}
+		if keepFile {
+			// Refresh so that the kept file is indexed in place of the metadata file
+			err = index.Refresh()
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
 		}
 		err = index.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.UpdateIndexHash()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
-		fmt.Printf("%s removed successfully!\n", args[0])
+		if err := cmdshared.WriteReport("remove.report", report); err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		for _, modPath := range toRemove {
+			fmt.Printf("%s removed successfully!\n", getModSlug(modPath))
+		}
+		for _, keptFile := range keptFiles {
+			fmt.Printf("Warning: %s has been kept, and is now unmanaged by packwiz\n", keptFile)
+		}
 	},
 }
 
+// getOrphanedDependencies returns the mods that were added as dependencies and are only needed by the given mod (or by
+// other such dependencies), so they can be removed along with it
+func getOrphanedDependencies(index core.Index, modPath string) ([]*core.Mod, error) {
+	mods, err := index.LoadAllMods()
+	if err != nil {
+		return nil, err
+	}
+	tree := newDependencyTree(mods)
+	mod := tree.find(modPath)
+	if mod == nil {
+		return nil, fmt.Errorf("failed to load %s", modPath)
+	}
+	return tree.orphanedBy(mod), nil
+}
+
+// removeModFile removes a metadata file and its index entry, adding it to the report if one is requested. If keepFile
+// is set, the path of the kept local copy of the file is returned.
+func removeModFile(index *core.Index, modPath string, keepFile bool, report *cmdshared.ChangeReport) (string, error) {
+	var keptFile string
+	if keepFile || viper.GetString("remove.report") != "" {
+		modData, err := core.LoadMod(modPath)
+		if err != nil {
+			return "", err
+		}
+		change := cmdshared.NewFileChange(index, &modData)
+		// Hashes are only reported for the new state of files
+		change.OldVersion, change.HashFormat, change.Hash = modData.FileName, "", ""
+		report.Removed = append(report.Removed, change)
+
+		if keepFile {
+			// Only look for the file next to the metadata file, so nothing outside the mod's folder is touched
+			if filepath.Base(modData.FileName) == modData.FileName {
+				keptFile = filepath.Join(filepath.Dir(modPath), modData.FileName)
+				if _, err := os.Stat(keptFile); err != nil {
+					keptFile = ""
+				}
+			}
+			if keptFile == "" {
+				fmt.Printf("Warning: %s was not found next to the metadata file; place it there manually to keep using it\n", modData.FileName)
+			}
+		}
+	}
+	err := os.Remove(modPath)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Removing %s from index...\n", getModSlug(modPath))
+	return keptFile, index.RemoveFile(modPath)
+}
+
 func init() {
 	rootCmd.AddCommand(removeCmd)
+	cmdshared.AddHooks(removeCmd, "remove")
+
+	removeCmd.Flags().Bool("keep-file", false, "Remove the metadata file but keep the local copy of the file (if present), as an unmanaged file")
+	_ = viper.BindPFlag("remove.keep-file", removeCmd.Flags().Lookup("keep-file"))
+	removeCmd.Flags().String("report", "", "Write a JSON summary of the removed files to this file (or stdout, if \"-\", with other output sent to stderr)")
+	_ = viper.BindPFlag("remove.report", removeCmd.Flags().Lookup("report"))
+	removeCmd.Flags().Bool("with-deps", false, "Also remove dependencies that were added automatically and are no longer needed by any other mod")
+	_ = viper.BindPFlag("remove.with-deps", removeCmd.Flags().Lookup("with-deps"))
 }
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+// captureStdout returns what is written to stdout while running f
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = w
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+	f()
+	_ = w.Close()
+	return <-output
+}
+
+// writeRemoveTestPack creates a pack with the given files, listing the metadata files in the index, and uses it as the
+// pack file. The path of the pack root is returned.
+func writeRemoveTestPack(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	index := "hash-format = \"sha256\"\n"
+	for name, contents := range files {
+		if strings.HasSuffix(name, core.MetaExtension) {
+			index += "[[files]]\nfile = \"" + name + "\"\nhash = \"\"\nmetafile = true\n"
+		}
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	packToml := "name = \"test\"\npack-format = \"packwiz:1.1.0\"\n[index]\nfile = \"index.toml\"\nhash-format = \"sha256\"\nhash = \"\"\n"
+	for name, contents := range map[string]string{"pack.toml": packToml, "index.toml": index} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", filepath.Join(dir, "pack.toml"))
+	t.Cleanup(func() { viper.Set("pack-file", oldPackFile) })
+	return dir
+}
+
+func removeTestMod(fileName string) string {
+	return "name = \"" + fileName + "\"\nfilename = \"" + fileName + "\"\n[download]\n" +
+		"url = \"https://example.com/mod.jar\"\nhash-format = \"sha1\"\nhash = \"abc\"\n"
+}
+
+func TestRemoveKeepFile(t *testing.T) {
+	t.Cleanup(func() {
+		_ = removeCmd.Flags().Set("keep-file", "false")
+	})
+	dir := writeRemoveTestPack(t, map[string]string{
+		"mods/a.pw.toml":     removeTestMod("a.jar"),
+		"mods/a.jar":         "kept jar",
+		"mods/b.pw.toml":     removeTestMod("b.jar"),
+		"mods/b.jar":         "other jar",
+		"mods/evil.pw.toml":  removeTestMod("../a.jar"),
+		"a.jar":              "outside jar",
+		"config/a.jar":       "config jar",
+		"mods/sub/c.pw.toml": removeTestMod("a.jar"),
+		"mods/sub/a.jar":     "sub jar",
+	})
+	outsideFiles := []string{"mods/b.pw.toml", "mods/b.jar", "a.jar", "config/a.jar", "mods/sub/c.pw.toml", "mods/sub/a.jar"}
+	before := make(map[string]os.FileInfo)
+	for _, name := range outsideFiles {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		before[name] = info
+	}
+
+	var code int
+	output := captureStdout(t, func() {
+		code = executeForExitCode(t, "remove", "a", "--keep-file")
+	})
+	if code != cmdshared.ExitOK {
+		t.Fatalf("Expected remove to succeed, got exit code %d:\n%s", code, output)
+	}
+	if !strings.Contains(output, "Warning: "+filepath.Join(dir, "mods", "a.jar")+" has been kept, and is now unmanaged by packwiz") {
+		t.Errorf("Expected a warning that the file is now unmanaged, got:\n%s", output)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "mods", "a.pw.toml")); !os.IsNotExist(err) {
+		t.Errorf("Expected the metadata file to be removed, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "mods", "a.jar")); err != nil || string(data) != "kept jar" {
+		t.Errorf("Expected the jar to be kept, got %q (%v)", data, err)
+	}
+	index, err := core.LoadIndex(filepath.Join(dir, "index.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := index.Files["mods/a.pw.toml"]; ok {
+		t.Error("Expected the metadata file to be removed from the index")
+	}
+	if file, ok := index.Files["mods/a.jar"]; !ok || file.IsMetaFile() {
+		t.Errorf("Expected the jar to be indexed as an unmanaged file, got %v", index.Files)
+	}
+
+	for _, name := range outsideFiles {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("Expected %s to be kept: %v", name, err)
+		} else if !info.ModTime().Equal(before[name].ModTime()) || info.Size() != before[name].Size() {
+			t.Errorf("Expected %s not to be changed", name)
+		}
+	}
+
+	// A file name pointing outside the metadata file's folder isn't kept, and the file it points to is left alone
+	output = captureStdout(t, func() {
+		code = executeForExitCode(t, "remove", "evil", "--keep-file")
+	})
+	if code != cmdshared.ExitOK {
+		t.Fatalf("Expected remove to succeed, got exit code %d:\n%s", code, output)
+	}
+	if !strings.Contains(output, "../a.jar was not found next to the metadata file") || strings.Contains(output, "is now unmanaged") {
+		t.Errorf("Expected a warning that the file wasn't kept, got:\n%s", output)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "a.jar")); err != nil || string(data) != "outside jar" {
+		t.Errorf("Expected the file outside the mods folder not to be changed, got %q (%v)", data, err)
+	}
+}
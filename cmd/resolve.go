@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// modNameCandidate is a metadata file that a mod name given by the user can be matched against
+type modNameCandidate struct {
+	Path string
+	// Slug is the name of the metadata file, without the extension
+	Slug     string
+	Name     string
+	FileName string
+}
+
+// ResolveModName finds the metadata file for the mod with the given name. Exact matches of the metadata file name are
+// returned immediately; otherwise the name is fuzzy matched against metadata file names, mod names and file names.
+// A single close match is returned if the user confirms it, and multiple matches are listed.
+func ResolveModName(index core.Index, name string) (string, bool) {
+	if modPath, ok := index.FindMod(name); ok {
+		return modPath, true
+	}
+
+	mods, err := index.LoadAllMods()
+	if err != nil {
+		fmt.Println(err)
+		return "", false
+	}
+	candidates := make([]modNameCandidate, len(mods))
+	for i, mod := range mods {
+		candidates[i] = modNameCandidate{
+			Path:     mod.GetFilePath(),
+			Slug:     getModSlug(mod.GetFilePath()),
+			Name:     mod.Name,
+			FileName: mod.FileName,
+		}
+	}
+
+	matches := matchModName(name, candidates)
+	if len(matches) == 1 {
+		yes, err := cmdshared.PromptYesNo(fmt.Sprintf("Did you mean %s (%s)? [Y/n]: ", matches[0].Slug, matches[0].Name))
+		if err != nil {
+			fmt.Println(err)
+			return "", false
+		}
+		if yes {
+			return matches[0].Path, true
+		}
+	} else if len(matches) > 1 {
+		fmt.Printf("Multiple files match %s:\n", name)
+		for _, match := range matches {
+			fmt.Printf("\t%s (%s)\n", match.Slug, match.Name)
+		}
+	}
+	return "", false
+}
+
+// getModSlug returns the name of a metadata file without the extension, as accepted by ResolveModName
+func getModSlug(modPath string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(filepath.Base(modPath), core.MetaExtension), core.MetaExtensionOld)
+}
+
+// matchModName returns the candidates that closely match the given name, in the order they were given
+func matchModName(name string, candidates []modNameCandidate) []modNameCandidate {
+	query := normalizeModName(name)
+	if query == "" {
+		return nil
+	}
+	// Allow roughly one typo per four characters
+	maxDistance := max(1, len(query)/4)
+
+	var matches []modNameCandidate
+	for _, candidate := range candidates {
+		fileName := strings.TrimSuffix(candidate.FileName, filepath.Ext(candidate.FileName))
+		for _, v := range []string{candidate.Slug, candidate.Name, fileName} {
+			normalized := normalizeModName(v)
+			// Substrings of longer names (e.g. file names with versions) are only matched for non-trivial queries
+			if (len(query) >= 3 && strings.Contains(normalized, query)) || levenshteinDistance(query, normalized) <= maxDistance {
+				matches = append(matches, candidate)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// normalizeModName lowercases a name and removes separators, so that e.g. "Fabric API" matches "fabric-api"
+func normalizeModName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// levenshteinDistance returns the number of single character edits needed to change a into b
+func levenshteinDistance(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestMatchModName(t *testing.T) {
+	candidates := []modNameCandidate{
+		{Path: "mods/fabric-api.pw.toml", Slug: "fabric-api", Name: "Fabric API", FileName: "fabric-api-0.92.0+1.20.1.jar"},
+		{Path: "mods/sodium.pw.toml", Slug: "sodium", Name: "Sodium", FileName: "sodium-fabric-mc1.20.1-0.5.3.jar"},
+		{Path: "mods/sodium-extra.pw.toml", Slug: "sodium-extra", Name: "Sodium Extra", FileName: "sodium-extra-0.5.1.jar"},
+		{Path: "mods/lithium.pw.toml", Slug: "lithium", Name: "Lithium", FileName: "lithium-fabric-mc1.20.1-0.11.2.jar"},
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"fabricapi", []string{"fabric-api"}},
+		{"Fabric API", []string{"fabric-api"}},
+		{"fabirc-api", []string{"fabric-api"}},
+		{"lithum", []string{"lithium"}},
+		{"sodium", []string{"sodium", "sodium-extra"}},
+		{"sodium-extr", []string{"sodium-extra"}},
+		{"xyz", nil},
+		{"-", nil},
+	}
+	for _, tt := range tests {
+		matches := matchModName(tt.query, candidates)
+		var slugs []string
+		for _, match := range matches {
+			slugs = append(slugs, match.Slug)
+		}
+		if len(slugs) != len(tt.expected) {
+			t.Errorf("%q: expected matches %v, got %v", tt.query, tt.expected, slugs)
+			continue
+		}
+		for i := range slugs {
+			if slugs[i] != tt.expected[i] {
+				t.Errorf("%q: expected matches %v, got %v", tt.query, tt.expected, slugs)
+				break
+			}
+		}
+	}
+}
+
+func TestLevenshteinDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"fabric", "fabirc", 2},
+	}
+	for _, tt := range tests {
+		if d := levenshteinDistance(tt.a, tt.b); d != tt.expected {
+			t.Errorf("levenshteinDistance(%q, %q) = %d, expected %d", tt.a, tt.b, d, tt.expected)
+		}
+	}
+}
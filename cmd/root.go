@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/pflag"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -19,13 +19,34 @@ var cfgFile string
 var rootCmd = &cobra.Command{
 	Use:   "packwiz",
 	Short: "A command line tool for creating Minecraft modpacks",
+	Long:  "A command line tool for creating Minecraft modpacks\n\n" + cmdshared.ExitCodesHelp,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		commandRunning = true
+	},
+	// Errors are reported by Execute
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
-// Execute starts the root command for packwiz
+// commandRunning is set once the arguments and flags of a command have been parsed, before it runs
+var commandRunning bool
+
+// Execute starts the root command for packwiz. This is the only place that packwiz exits when a command returns an
+// error: errors from parsing arguments and flags are shown with the usage of the command and exit with
+// cmdshared.ExitUsage, and errors returned by the command exit with the code from cmdshared.ExitCode.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	commandRunning = false
+	cmd, err := rootCmd.ExecuteC()
+	cmdshared.RunCleanups()
+	if err == nil {
+		return
+	}
+	if !commandRunning {
+		cmd.PrintErrln(cmd.ErrPrefix(), err.Error())
+		cmd.Println(cmd.UsageString())
+		cmdshared.Exit(cmdshared.ExitUsage)
 	}
+	cmdshared.ExitWithError(err)
 }
 
 // Add adds a new command as a subcommand to packwiz
@@ -45,6 +66,10 @@ func init() {
 		if name == "mods-folder" {
 			return "meta-folder"
 		}
+		// Make no-prompt an alias for yes
+		if name == "no-prompt" {
+			return "yes"
+		}
 		return pflag.NormalizedName(name)
 	})
 
@@ -58,23 +83,47 @@ func init() {
 
 	defaultCacheDir, err := core.GetPackwizCache()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 	rootCmd.PersistentFlags().String("cache", defaultCacheDir, "The directory where packwiz will cache downloaded mods")
 	_ = viper.BindPFlag("cache.directory", rootCmd.PersistentFlags().Lookup("cache"))
+	rootCmd.PersistentFlags().Bool("offline", false, "Don't access the network; files that need downloading must already be in the cache")
+	_ = viper.BindPFlag("offline", rootCmd.PersistentFlags().Lookup("offline"))
 
 	file, err := core.GetPackwizLocalStore()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmdshared.ExitWithError(err)
 	}
 	file = filepath.Join(file, ".packwiz.toml")
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "The config file to use (default \""+file+"\")")
 
 	var nonInteractive bool
-	rootCmd.PersistentFlags().BoolVarP(&nonInteractive, "yes", "y", false, "Accept all prompts with the default or \"yes\" option (non-interactive mode) - may pick unwanted options in search results")
+	rootCmd.PersistentFlags().BoolVarP(&nonInteractive, "yes", "y", false, "Accept all prompts with the default or \"yes\" option (non-interactive mode, alias --no-prompt) - may pick unwanted options in search results. Without it, commands that need input fail if stdin isn't a terminal")
 	_ = viper.BindPFlag("non-interactive", rootCmd.PersistentFlags().Lookup("yes"))
+
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Don't print progress messages, such as retries when rate limited")
+	_ = viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet"))
+
+	rootCmd.PersistentFlags().String("json-format", "", "The format of JSON output, such as list --json and --report: \"pretty\" or \"compact\" (default pretty on a terminal, compact otherwise)")
+	_ = viper.BindPFlag("json-format", rootCmd.PersistentFlags().Lookup("json-format"))
+
+	rootCmd.PersistentFlags().String("cf-api-key", "", "The CurseForge API key to use, overriding the CF_API_KEY and CF_API_KEY_FILE environment variables and the key stored by packwiz settings cf-api-key")
+	_ = viper.BindPFlag("cf-api-key", rootCmd.PersistentFlags().Lookup("cf-api-key"))
+
+	rootCmd.PersistentFlags().Int("max-retries", core.DefaultMaxRetries, "The maximum number of times to retry a request when rate limited by an API")
+	_ = viper.BindPFlag("rate-limit.max-retries", rootCmd.PersistentFlags().Lookup("max-retries"))
+
+	rootCmd.PersistentFlags().Duration("backoff-base", core.DefaultBackoffBase, "The time to wait before retrying a rate limited request when the API doesn't specify one, doubling for each retry")
+	_ = viper.BindPFlag("rate-limit.backoff-base", rootCmd.PersistentFlags().Lookup("backoff-base"))
+
+	rootCmd.PersistentFlags().Bool("throttle", true, "Slow down requests when an API reports that few requests remain before being rate limited")
+	_ = viper.BindPFlag("rate-limit.throttle", rootCmd.PersistentFlags().Lookup("throttle"))
+
+	rootCmd.PersistentFlags().Int("throttle-threshold", core.DefaultThrottleThreshold, "The number of remaining requests below which requests are slowed down, when --throttle is enabled")
+	_ = viper.BindPFlag("rate-limit.throttle-threshold", rootCmd.PersistentFlags().Lookup("throttle-threshold"))
+
+	rootCmd.PersistentFlags().Int("server-error-retries", core.DefaultServerErrorRetries, "The maximum number of times to retry a CurseForge API lookup when the server responds with a 5xx error (0 to disable)")
+	_ = viper.BindPFlag("rate-limit.server-error-retries", rootCmd.PersistentFlags().Lookup("server-error-retries"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -85,8 +134,7 @@ func initConfig() {
 	} else {
 		dir, err := core.GetPackwizLocalStore()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		viper.AddConfigPath(dir)
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/spf13/cobra"
+)
+
+// exitPanic is used to stop a command when it exits, as the test can't exit
+type exitPanic int
+
+// executeForExitCode runs packwiz with the given arguments, returning the code it exits with
+func executeForExitCode(t *testing.T, args ...string) (code int) {
+	t.Helper()
+	oldExit := cmdshared.ExitFunc
+	cmdshared.ExitFunc = func(code int) {
+		panic(exitPanic(code))
+	}
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	t.Cleanup(func() {
+		cmdshared.ExitFunc = oldExit
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		_ = rootCmd.PersistentFlags().Set("pack-file", "pack.toml")
+	})
+
+	defer func() {
+		if r := recover(); r != nil {
+			exit, ok := r.(exitPanic)
+			if !ok {
+				panic(r)
+			}
+			code = int(exit)
+		}
+	}()
+	Execute()
+	return cmdshared.ExitOK
+}
+
+func TestExitCodes(t *testing.T) {
+	missingPack := filepath.Join(t.TempDir(), "pack.toml")
+	if code := executeForExitCode(t, "refresh", "--pack-file", missingPack); code != cmdshared.ExitNotFound {
+		t.Errorf("Expected exit code %d for a missing pack, got %d", cmdshared.ExitNotFound, code)
+	}
+	if code := executeForExitCode(t, "refresh", "--not-a-flag"); code != cmdshared.ExitUsage {
+		t.Errorf("Expected exit code %d for an unknown flag, got %d", cmdshared.ExitUsage, code)
+	}
+
+	// Errors returned by commands exit with the code for the error
+	returnErr := errors.New("something went wrong")
+	errCmd := &cobra.Command{
+		Use:  "exit-code-test",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return returnErr
+		},
+	}
+	rootCmd.AddCommand(errCmd)
+	t.Cleanup(func() { rootCmd.RemoveCommand(errCmd) })
+	if code := executeForExitCode(t, "exit-code-test"); code != cmdshared.ExitGeneric {
+		t.Errorf("Expected exit code %d for a returned error, got %d", cmdshared.ExitGeneric, code)
+	}
+	returnErr = cmdshared.WithExitCode(errors.New("unknown loader"), cmdshared.ExitUsage)
+	if code := executeForExitCode(t, "exit-code-test"); code != cmdshared.ExitUsage {
+		t.Errorf("Expected exit code %d for a returned usage error, got %d", cmdshared.ExitUsage, code)
+	}
+	returnErr = nil
+	if code := executeForExitCode(t, "exit-code-test"); code != cmdshared.ExitOK {
+		t.Errorf("Expected exit code %d when no error is returned, got %d", cmdshared.ExitOK, code)
+	}
+	if code := executeForExitCode(t, "exit-code-test", "extra-arg"); code != cmdshared.ExitUsage {
+		t.Errorf("Expected exit code %d for invalid arguments, got %d", cmdshared.ExitUsage, code)
+	}
+}
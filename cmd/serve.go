@@ -5,7 +5,6 @@ import (
 	_ "embed"
 	"fmt"
 	"html/template"
-	"io"
 	"net/http"
 	"os"
 	"path"
@@ -14,6 +13,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -40,21 +40,33 @@ var serveCmd = &cobra.Command{
 			fmt.Println("Loading modpack...")
 			pack, err := core.LoadPack()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				cmdshared.ExitWithError(err)
 			}
 			index, err := pack.LoadIndex()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				cmdshared.ExitWithError(err)
+			}
+			if !viper.GetBool("serve.refresh") {
+				// Without refreshing, clients would fail to install the pack if any hashes are out of date
+				var mismatches []core.HashMismatch
+				if mismatch := pack.VerifyIndexHash(); mismatch != nil {
+					mismatches = append(mismatches, *mismatch)
+				}
+				mismatches = append(mismatches, index.Verify()...)
+				if len(mismatches) > 0 {
+					fmt.Println("The index is out of date; run packwiz refresh, or use --refresh to refresh it when served:")
+					for _, mismatch := range mismatches {
+						fmt.Println(mismatch)
+					}
+					cmdshared.Exit(cmdshared.ExitGeneric)
+				}
 			}
 			packServeDir := filepath.Dir(viper.GetString("pack-file"))
 			packFileName := filepath.Base(viper.GetString("pack-file"))
 
 			t, err := template.New("index-page").Parse(indexPage)
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				cmdshared.ExitWithError(err)
 			}
 
 			indexPageBuf := new(bytes.Buffer)
@@ -126,17 +138,18 @@ var serveCmd = &cobra.Command{
 					_, _ = w.Write([]byte("File not found"))
 					return
 				}
-				_, err = io.Copy(w, f)
-				err2 := f.Close()
-				if err == nil {
-					err = err2
-				}
+				defer f.Close()
+				info, err := f.Stat()
 				if err != nil {
 					fmt.Printf("Error reading file \"%s\": %s\n", destPath, err)
 					w.WriteHeader(500)
 					_, _ = w.Write([]byte("Failed to read file"))
 					return
 				}
+				if contentType := serveContentType(urlPath); contentType != "" {
+					w.Header().Set("Content-Type", contentType)
+				}
+				http.ServeContent(w, req, urlPath, info.ModTime(), f)
 			})
 		}
 
@@ -144,11 +157,25 @@ var serveCmd = &cobra.Command{
 		err := http.ListenAndServe(":"+port, nil)
 		if err != nil {
 			fmt.Printf("Error running server: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 	},
 }
 
+// serveContentType returns the content type to serve a file with, for types that aren't known by the mime package
+// on all systems; otherwise it is detected from the extension or contents
+func serveContentType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".toml":
+		return "application/toml"
+	case ".jar":
+		return "application/java-archive"
+	case ".zip", ".mrpack":
+		return "application/zip"
+	}
+	return ""
+}
+
 func doServeRefresh(pack *core.Pack, index *core.Index) error {
 	var err error
 	*pack, err = core.LoadPack()
@@ -185,7 +212,7 @@ func init() {
 
 	serveCmd.Flags().IntP("port", "p", 8080, "The port to run the server on")
 	_ = viper.BindPFlag("serve.port", serveCmd.Flags().Lookup("port"))
-	serveCmd.Flags().BoolP("refresh", "r", true, "Automatically refresh the index file")
+	serveCmd.Flags().BoolP("refresh", "r", true, "Automatically refresh the index file; if disabled, the server won't start when the index is out of date")
 	_ = viper.BindPFlag("serve.refresh", serveCmd.Flags().Lookup("refresh"))
 	serveCmd.Flags().Bool("basic", false, "Disable refreshing and allow all files in the directory, rather than just files listed in the index")
 	_ = viper.BindPFlag("serve.basic", serveCmd.Flags().Lookup("basic"))
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// signCmd represents the sign command
+var signCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Write a detached ed25519 signature of the index file, so others can check that the pack came from you",
+	Long: `Write a detached ed25519 signature of the index file (as it is on disk) next to it, as index.toml.sig. The index
+file contains the hashes of every file in the pack, so the signature covers all of them; run packwiz refresh before
+signing. Signing the same index with the same key always produces the same signature.
+
+The key must be an ed25519 private key in PEM format, which can be created with:
+  openssl genpkey -algorithm ed25519 -out key.pem
+and its public key, to give to others, with:
+  openssl pkey -in key.pem -pubout -out key.pub.pem`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("sign.key") == "" {
+			fmt.Println("The private key to sign with must be given with --key")
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+		key, err := core.LoadPrivateKey(viper.GetString("sign.key"))
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = index.Sign(key)
+		if err != nil {
+			fmt.Printf("Error signing index: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("Signature written to %s\n", index.SignaturePath())
+	},
+}
+
+// verifySignatureCmd represents the verify-signature command
+var verifySignatureCmd = &cobra.Command{
+	Use:   "verify-signature",
+	Short: "Check the signature of the index file written by packwiz sign",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetString("verify-signature.pubkey") == "" {
+			fmt.Println("The public key to check the signature with must be given with --pubkey")
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+		key, err := core.LoadPublicKey(viper.GetString("verify-signature.pubkey"))
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = index.VerifySignature(key)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("The index isn't signed: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		} else if err != nil {
+			fmt.Printf("Signature verification failed: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Println("The signature of the index is valid")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(signCmd)
+	rootCmd.AddCommand(verifySignatureCmd)
+
+	signCmd.Flags().String("key", "", "The ed25519 private key to sign with, in PEM format")
+	_ = viper.BindPFlag("sign.key", signCmd.Flags().Lookup("key"))
+	verifySignatureCmd.Flags().String("pubkey", "", "The ed25519 public key to check the signature with, in PEM format")
+	_ = viper.BindPFlag("verify-signature.pubkey", verifySignatureCmd.Flags().Lookup("pubkey"))
+}
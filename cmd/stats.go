@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show a summary of the modpack: its versions, the number of mods by source and side, and the total download size",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		mods, err := index.LoadAllMods()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		stats := newPackStats(pack, mods)
+		if viper.GetBool("stats.json") {
+			printJSON(stats)
+			return
+		}
+		stats.print(os.Stdout)
+	},
+}
+
+// packStats summarises the metadata files in a pack
+type packStats struct {
+	Name      string            `json:"name"`
+	Version   string            `json:"version"`
+	Minecraft string            `json:"minecraft"`
+	Loaders   map[string]string `json:"loaders"`
+	Mods      int               `json:"mods"`
+	Sources   map[string]int    `json:"sources"`
+	Sides     map[string]int    `json:"sides"`
+	// TotalSize is the sum of the sizes of the mods whose size is known
+	TotalSize int64 `json:"total-size"`
+	// UnknownSize lists the names of mods without a filesize
+	UnknownSize []string `json:"unknown-size"`
+}
+
+func newPackStats(pack core.Pack, mods []*core.Mod) packStats {
+	stats := packStats{
+		Name:        pack.Name,
+		Version:     pack.Version,
+		Minecraft:   pack.Versions["minecraft"],
+		Loaders:     make(map[string]string),
+		Mods:        len(mods),
+		Sources:     make(map[string]int),
+		Sides:       make(map[string]int),
+		UnknownSize: []string{},
+	}
+	for _, loader := range pack.GetLoaders() {
+		stats.Loaders[loader] = pack.Versions[loader]
+	}
+	for _, mod := range mods {
+		source, _ := getListSource(mod)
+		stats.Sources[source]++
+		stats.Sides[getListSide(mod)]++
+		if mod.Download.FileSize > 0 {
+			stats.TotalSize += mod.Download.FileSize
+		} else {
+			stats.UnknownSize = append(stats.UnknownSize, mod.Name)
+		}
+	}
+	slices.SortFunc(stats.UnknownSize, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return stats
+}
+
+func (s packStats) print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "%s %s\n", s.Name, s.Version)
+	versions := []string{"Minecraft " + s.Minecraft}
+	for _, loader := range slices.Sorted(maps.Keys(s.Loaders)) {
+		versions = append(versions, loader+" "+s.Loaders[loader])
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(versions, ", "))
+
+	_, _ = fmt.Fprintf(w, "\nMods: %d\n", s.Mods)
+	printCounts := func(title string, counts map[string]int) {
+		_, _ = fmt.Fprintf(w, "By %s:\n", title)
+		for _, key := range slices.Sorted(maps.Keys(counts)) {
+			_, _ = fmt.Fprintf(w, "  %s: %d\n", key, counts[key])
+		}
+	}
+	printCounts("source", s.Sources)
+	printCounts("side", s.Sides)
+
+	_, _ = fmt.Fprintf(w, "\nTotal download size: %s\n", cmdshared.FormatSize(s.TotalSize))
+	if len(s.UnknownSize) > 0 {
+		_, _ = fmt.Fprintf(w, "Unknown size (not included in the total): %s\n", strings.Join(s.UnknownSize, ", "))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().Bool("json", false, "Print the summary as JSON, for use in scripts")
+	_ = viper.BindPFlag("stats.json", statsCmd.Flags().Lookup("json"))
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func TestPackStats(t *testing.T) {
+	pack := core.Pack{Name: "Test", Version: "1.0.0", Versions: map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"}}
+	mods := []*core.Mod{
+		{Name: "A", Side: core.ClientSide, Download: core.ModDownload{FileSize: 1024},
+			Update: map[string]map[string]interface{}{"modrinth": {"mod-id": "a", "version": "v"}}},
+		{Name: "B", Download: core.ModDownload{FileSize: 2048},
+			Update: map[string]map[string]interface{}{"curseforge": {"project-id": 1, "file-id": 2}}},
+		{Name: "C", Side: core.UniversalSide},
+	}
+
+	stats := newPackStats(pack, mods)
+	if stats.Mods != 3 || stats.TotalSize != 3072 {
+		t.Errorf("Expected 3 mods totalling 3072 bytes, got %d mods totalling %d bytes", stats.Mods, stats.TotalSize)
+	}
+	if stats.Sources["modrinth"] != 1 || stats.Sources["curseforge"] != 1 || stats.Sources["url"] != 1 {
+		t.Errorf("Unexpected counts by source: %v", stats.Sources)
+	}
+	if stats.Sides["client"] != 1 || stats.Sides["both"] != 2 {
+		t.Errorf("Unexpected counts by side: %v", stats.Sides)
+	}
+	if len(stats.UnknownSize) != 1 || stats.UnknownSize[0] != "C" {
+		t.Errorf("Expected C to have an unknown size, got %v", stats.UnknownSize)
+	}
+
+	var out bytes.Buffer
+	stats.print(&out)
+	for _, expected := range []string{"Minecraft 1.20.1, fabric 0.15.0", "Total download size: 3.0 KiB", "Unknown size (not included in the total): C"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
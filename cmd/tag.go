@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// tagMod adds or removes the given tags on a metadata file
+func tagMod(args []string, add bool) {
+	tags := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		tag, err := core.NormalizeTag(arg)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		tags[i] = tag
+	}
+
+	fmt.Println("Loading modpack...")
+	pack, err := core.LoadPack()
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+	index, err := pack.LoadIndex()
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+	modPath, ok := ResolveModName(index, args[0])
+	if !ok {
+		fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
+		cmdshared.Exit(cmdshared.ExitNotFound)
+	}
+	modData, err := core.LoadMod(modPath)
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+
+	changed := false
+	for _, tag := range tags {
+		if add {
+			changed = modData.AddTag(tag) || changed
+		} else {
+			changed = modData.RemoveTag(tag) || changed
+		}
+	}
+	if !changed {
+		if add {
+			fmt.Printf("%s already has these tags\n", getModSlug(modPath))
+		} else {
+			fmt.Printf("%s doesn't have these tags\n", getModSlug(modPath))
+		}
+		return
+	}
+
+	format, hash, err := modData.Write()
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+	err = index.RefreshFileWithHash(modPath, format, hash, true)
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+	err = index.Write()
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+	err = pack.UpdateIndexHash()
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+	err = pack.Write()
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+	fmt.Printf("Tags of %s: %v\n", getModSlug(modPath), modData.Tags)
+}
+
+// tagCmd represents the tag command
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage the tags of files, used to export only some of the files in a pack",
+	Long: `Manage the tags of files, which are freeform labels stored in their metadata files.
+Exports can include or exclude files by their tags (e.g. packwiz modrinth export --include-tag lite), so several
+variants of a pack can be exported from the same files.`,
+}
+
+// tagAddCmd represents the tag add command
+var tagAddCmd = &cobra.Command{
+	Use:               "add [name] [tags...]",
+	Short:             "Add tags to a file",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeModNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		tagMod(args, true)
+	},
+}
+
+// tagRemoveCmd represents the tag remove command
+var tagRemoveCmd = &cobra.Command{
+	Use:               "remove [name] [tags...]",
+	Short:             "Remove tags from a file",
+	Aliases:           []string{"rm"},
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeModNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		tagMod(args, false)
+	},
+}
+
+// tagListCmd represents the tag list command
+var tagListCmd = &cobra.Command{
+	Use:     "list [tag]",
+	Short:   "List the tags used in the pack with the number of files that have them, or the files with a tag",
+	Aliases: []string{"ls"},
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		mods, err := index.LoadAllMods()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		if len(args) > 0 {
+			var names []string
+			for _, mod := range mods {
+				if mod.HasTag(args[0]) {
+					names = append(names, mod.Name)
+				}
+			}
+			if len(names) == 0 {
+				fmt.Printf("No files are tagged %s\n", args[0])
+				return
+			}
+			slices.Sort(names)
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return
+		}
+
+		counts := make(map[string]int)
+		for _, mod := range mods {
+			for _, tag := range mod.Tags {
+				counts[tag]++
+			}
+		}
+		if len(counts) == 0 {
+			fmt.Println("No files have tags")
+			return
+		}
+		tags := make([]string, 0, len(counts))
+		for tag := range counts {
+			tags = append(tags, tag)
+		}
+		slices.Sort(tags)
+		for _, tag := range tags {
+			fmt.Printf("%s: %d\n", tag, counts[tag])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagListCmd)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTagMod(t *testing.T) {
+	modPath := setupPinTestPack(t)
+
+	tagMod([]string{"a", "lite", " full "}, true)
+	mod := loadPinTestMod(t, modPath)
+	if !slices.Equal(mod.Tags, []string{"full", "lite"}) {
+		t.Errorf("Expected tags to be added, got %v", mod.Tags)
+	}
+
+	tagMod([]string{"a", "full", "missing"}, false)
+	mod = loadPinTestMod(t, modPath)
+	if !slices.Equal(mod.Tags, []string{"lite"}) {
+		t.Errorf("Expected tag to be removed, got %v", mod.Tags)
+	}
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// treeCmd represents the tree command
+var treeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show the dependencies of the mods in the modpack",
+	Long: `Show the dependencies of the mods in the modpack, as recorded when they were added.
+Mods added automatically as a dependency are marked with (dependency); they are only shown at the top level if no
+other mod depends on them. Dependencies of mods added before dependencies were recorded aren't known.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		mods, err := index.LoadAllMods()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		tree := newDependencyTree(mods)
+		reverse := viper.GetBool("tree.reverse")
+		if name := viper.GetString("tree.mod"); name != "" {
+			modPath, ok := ResolveModName(index, name)
+			mod := tree.find(modPath)
+			if !ok || mod == nil {
+				fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
+				cmdshared.Exit(cmdshared.ExitNotFound)
+			}
+			tree.print(os.Stdout, []*core.Mod{mod}, reverse)
+		} else if reverse {
+			fmt.Println("--reverse requires a mod to be given with --mod")
+			cmdshared.Exit(cmdshared.ExitUsage)
+		} else {
+			tree.print(os.Stdout, tree.roots(), false)
+		}
+	},
+}
+
+// dependencyTree stores the dependency relationships between the mods in a pack
+type dependencyTree struct {
+	mods []*core.Mod
+	// byProject maps source:id keys (e.g. modrinth:AANobbMI) to mods
+	byProject map[string]*core.Mod
+	// dependencies and dependents store the edges between mods; dependencies that aren't in the pack are stored
+	// in missing as source:id keys
+	dependencies map[*core.Mod][]*core.Mod
+	dependents   map[*core.Mod][]*core.Mod
+	missing      map[*core.Mod][]string
+}
+
+// getModProjectKey returns the update source of a mod and its project ID in that source, as a source:id key
+func getModProjectKey(mod *core.Mod) (string, string, bool) {
+	for source, data := range mod.Update {
+		for _, key := range []string{"mod-id", "project-id"} {
+			if id, ok := data[key]; ok {
+				return source, fmt.Sprintf("%s:%v", source, id), true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func newDependencyTree(mods []*core.Mod) *dependencyTree {
+	mods = slices.Clone(mods)
+	slices.SortFunc(mods, func(a, b *core.Mod) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	t := &dependencyTree{
+		mods:         mods,
+		byProject:    make(map[string]*core.Mod),
+		dependencies: make(map[*core.Mod][]*core.Mod),
+		dependents:   make(map[*core.Mod][]*core.Mod),
+		missing:      make(map[*core.Mod][]string),
+	}
+	for _, mod := range mods {
+		if _, key, ok := getModProjectKey(mod); ok {
+			t.byProject[key] = mod
+		}
+	}
+	for _, mod := range mods {
+		source, _, ok := getModProjectKey(mod)
+		if !ok {
+			continue
+		}
+		// Dependencies are stored as IDs from the same source as the mod
+		for _, id := range mod.Dependencies {
+			key := source + ":" + id
+			if dep, ok := t.byProject[key]; ok {
+				t.dependencies[mod] = append(t.dependencies[mod], dep)
+				t.dependents[dep] = append(t.dependents[dep], mod)
+			} else {
+				t.missing[mod] = append(t.missing[mod], key)
+			}
+		}
+	}
+	// Mods are sorted by name, so sort the edges by their position
+	position := make(map[*core.Mod]int, len(mods))
+	for i, mod := range mods {
+		position[mod] = i
+	}
+	byPosition := func(a, b *core.Mod) int {
+		return position[a] - position[b]
+	}
+	for _, edges := range []map[*core.Mod][]*core.Mod{t.dependencies, t.dependents} {
+		for _, v := range edges {
+			slices.SortFunc(v, byPosition)
+		}
+	}
+	return t
+}
+
+// find returns the mod with the given metadata file path, or nil if it isn't in the pack
+func (t *dependencyTree) find(modPath string) *core.Mod {
+	for _, mod := range t.mods {
+		if filepath.Clean(mod.GetFilePath()) == filepath.Clean(modPath) {
+			return mod
+		}
+	}
+	return nil
+}
+
+// roots returns the mods that were added explicitly, and dependencies that no other mod depends on anymore
+func (t *dependencyTree) roots() []*core.Mod {
+	var roots []*core.Mod
+	for _, mod := range t.mods {
+		if !mod.AddedAsDependency || len(t.dependents[mod]) == 0 {
+			roots = append(roots, mod)
+		}
+	}
+	return roots
+}
+
+// orphanedBy returns the mods that were added as dependencies and would no longer be needed by any other mod if the
+// given mod was removed, including dependencies of those mods. Mods added directly are never returned.
+func (t *dependencyTree) orphanedBy(mod *core.Mod) []*core.Mod {
+	removed := map[*core.Mod]bool{mod: true}
+	for changed := true; changed; {
+		changed = false
+		for _, dep := range t.mods {
+			if removed[dep] || !dep.AddedAsDependency || len(t.dependents[dep]) == 0 {
+				continue
+			}
+			// Only dependencies that were needed by a removed mod are orphaned, not those that were already unused
+			if !slices.ContainsFunc(t.dependents[dep], func(m *core.Mod) bool { return removed[m] }) {
+				continue
+			}
+			if !slices.ContainsFunc(t.dependents[dep], func(m *core.Mod) bool { return !removed[m] }) {
+				removed[dep] = true
+				changed = true
+			}
+		}
+	}
+	var orphans []*core.Mod
+	for _, m := range t.mods {
+		if removed[m] && m != mod {
+			orphans = append(orphans, m)
+		}
+	}
+	return orphans
+}
+
+func (t *dependencyTree) print(w io.Writer, roots []*core.Mod, reverse bool) {
+	for _, mod := range roots {
+		_, _ = fmt.Fprintln(w, treeLabel(mod))
+		t.printChildren(w, mod, "", reverse, map[*core.Mod]bool{mod: true})
+	}
+}
+
+// printChildren prints the dependencies (or dependents, if reverse is set) of a mod, indented by prefix. Mods on the
+// current path are tracked to avoid looping on circular dependencies.
+func (t *dependencyTree) printChildren(w io.Writer, mod *core.Mod, prefix string, reverse bool, path map[*core.Mod]bool) {
+	var children []*core.Mod
+	var missing []string
+	if reverse {
+		children = t.dependents[mod]
+	} else {
+		children = t.dependencies[mod]
+		missing = t.missing[mod]
+	}
+
+	count := len(children) + len(missing)
+	for i, child := range children {
+		branch, indent := "├── ", "│   "
+		if i == count-1 {
+			branch, indent = "└── ", "    "
+		}
+		if path[child] {
+			_, _ = fmt.Fprintf(w, "%s%s%s (circular)\n", prefix, branch, treeLabel(child))
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s%s%s\n", prefix, branch, treeLabel(child))
+		path[child] = true
+		t.printChildren(w, child, prefix+indent, reverse, path)
+		delete(path, child)
+	}
+	for i, key := range missing {
+		branch := "├── "
+		if len(children)+i == count-1 {
+			branch = "└── "
+		}
+		_, _ = fmt.Fprintf(w, "%s%s%s (not in pack)\n", prefix, branch, key)
+	}
+}
+
+func treeLabel(mod *core.Mod) string {
+	if mod.AddedAsDependency {
+		return mod.Name + " (dependency)"
+	}
+	return mod.Name
+}
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+
+	treeCmd.Flags().String("mod", "", "Only show the dependencies of the given mod")
+	_ = viper.BindPFlag("tree.mod", treeCmd.Flags().Lookup("mod"))
+	treeCmd.Flags().Bool("reverse", false, "Show the mods that depend on the mod given with --mod, rather than its dependencies")
+	_ = viper.BindPFlag("tree.reverse", treeCmd.Flags().Lookup("reverse"))
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func treeTestMod(name string, id string, deps []string, addedAsDependency bool) *core.Mod {
+	return &core.Mod{
+		Name:              name,
+		Dependencies:      deps,
+		AddedAsDependency: addedAsDependency,
+		Update: map[string]map[string]interface{}{
+			"modrinth": {"mod-id": id, "version": "v"},
+		},
+	}
+}
+
+func TestDependencyTree(t *testing.T) {
+	api := treeTestMod("API", "a", nil, true)
+	lib := treeTestMod("Lib", "l", []string{"a", "x"}, true)
+	mod := treeTestMod("Mod", "m", []string{"l", "a"}, false)
+	orphan := treeTestMod("Orphan", "o", nil, true)
+	tree := newDependencyTree([]*core.Mod{orphan, mod, lib, api})
+
+	var out bytes.Buffer
+	tree.print(&out, tree.roots(), false)
+	expected := `Mod
+├── API (dependency)
+└── Lib (dependency)
+    ├── API (dependency)
+    └── modrinth:x (not in pack)
+Orphan (dependency)
+`
+	if out.String() != expected {
+		t.Errorf("Expected tree:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	tree.print(&out, []*core.Mod{api}, true)
+	expected = `API (dependency)
+├── Lib (dependency)
+│   └── Mod
+└── Mod
+`
+	if out.String() != expected {
+		t.Errorf("Expected reverse tree:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestDependencyTreeOrphanedBy(t *testing.T) {
+	api := treeTestMod("API", "a", nil, true)
+	lib := treeTestMod("Lib", "l", []string{"a"}, true)
+	shared := treeTestMod("Shared", "s", nil, true)
+	direct := treeTestMod("Direct", "d", nil, false)
+	mod := treeTestMod("Mod", "m", []string{"l", "s", "d"}, false)
+	other := treeTestMod("Other", "o", []string{"s"}, false)
+	unused := treeTestMod("Unused", "u", nil, true)
+	tree := newDependencyTree([]*core.Mod{api, lib, shared, direct, mod, other, unused})
+
+	var names []string
+	for _, m := range tree.orphanedBy(mod) {
+		names = append(names, m.Name)
+	}
+	// Shared is still needed by Other, Direct was added directly and Unused wasn't needed by Mod
+	expected := []string{"API", "Lib"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Expected orphaned dependencies %v, got %v", expected, names)
+	}
+
+	if orphans := tree.orphanedBy(other); len(orphans) != 0 {
+		t.Errorf("Expected no orphaned dependencies when removing Other, got %d", len(orphans))
+	}
+}
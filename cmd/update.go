@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
@@ -17,45 +21,50 @@ var UpdateCmd = &cobra.Command{
 	Aliases: []string{"upgrade"},
 	Args:    cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		// TODO: --check flag?
 		// TODO: specify multiple files to update at once?
 
+		if err := cmdshared.RedirectOutputForReport("update.report"); err != nil {
+			cmdshared.ExitWithError(err)
+		}
 		fmt.Println("Loading modpack...")
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
+		dryRun := viper.GetBool("update.dry-run")
+		var dryRunUpdates []dryRunUpdate
+		report := cmdshared.ChangeReport{DryRun: dryRun}
+		defer func() {
+			writeUpdateReport(report)
+		}()
+
 		var singleUpdatedName string
+		var summary updateSummary
+		source := viper.GetString("update.source")
 		if viper.GetBool("update.all") {
-			filesWithUpdater := make(map[string][]*core.Mod)
+			if versionID, _ := getRequestedVersion(); versionID != "" {
+				fmt.Println("A specific version can only be requested when updating a single file")
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
+			if _, ok := core.Updaters[source]; source != "" && !ok {
+				fmt.Printf("Unknown update source %s; must be one of %s\n", source, strings.Join(slices.Sorted(maps.Keys(core.Updaters)), ", "))
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
+
 			fmt.Println("Reading metadata files...")
 			mods, err := index.LoadAllMods()
 			if err != nil {
 				fmt.Printf("Failed to update all files: %v\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
-			for _, modData := range mods {
-				updaterFound := false
-				for k := range modData.Update {
-					slice, ok := filesWithUpdater[k]
-					if !ok {
-						_, ok = core.Updaters[k]
-						if !ok {
-							continue
-						}
-						slice = []*core.Mod{}
-					}
-					updaterFound = true
-					filesWithUpdater[k] = append(slice, modData)
-				}
-				if !updaterFound {
+			filesWithUpdater, withoutUpdater := groupModsByUpdater(mods, source, core.Updaters)
+			if source == "" {
+				for _, modData := range withoutUpdater {
 					fmt.Printf("A supported update system for \"%s\" cannot be found.\n", modData.Name)
 				}
 			}
@@ -64,153 +73,231 @@ var UpdateCmd = &cobra.Command{
 			updatesFound := false
 			updatableFiles := make(map[string][]*core.Mod)
 			updaterCachedStateMap := make(map[string][]interface{})
+			updateChecksMap := make(map[string][]core.UpdateCheck)
 			for k, v := range filesWithUpdater {
 				checks, err := core.Updaters[k].CheckUpdate(v, pack)
 				if err != nil {
-					// TODO: do we return err code 1?
 					fmt.Printf("Failed to check updates for %s: %s\n", k, err.Error())
+					for _, modData := range v {
+						summary.fail(modData.Name, err)
+					}
 					continue
 				}
 				for i, check := range checks {
 					if check.Error != nil {
-						// TODO: do we return err code 1?
 						fmt.Printf("Failed to check updates for %s: %s\n", v[i].Name, check.Error.Error())
+						summary.fail(v[i].Name, check.Error)
+						continue
+					}
+					if !check.UpdateAvailable {
+						summary.unchanged++
+						continue
+					}
+					if skipPinned(v[i]) {
+						summary.unchanged++
 						continue
 					}
-					if check.UpdateAvailable {
-						if v[i].Pin {
-							fmt.Printf("Update skipped for pinned mod %s\n", v[i].Name)
-							continue
-						}
-
-						if !updatesFound {
-							fmt.Println("Updates found:")
-							updatesFound = true
-						}
-						fmt.Printf("%s: %s\n", v[i].Name, check.UpdateString)
-						updatableFiles[k] = append(updatableFiles[k], v[i])
-						updaterCachedStateMap[k] = append(updaterCachedStateMap[k], check.CachedState)
+
+					if dryRun {
+						dryRunUpdates = append(dryRunUpdates, dryRunUpdate{v[i].Name, check, k})
+						report.Modified = append(report.Modified, updateChange(&index, v[i], check, true))
+						updatesFound = true
+						continue
 					}
+
+					if !updatesFound {
+						fmt.Println("Updates found:")
+						updatesFound = true
+					}
+					fmt.Printf("%s: %s\n", v[i].Name, check.UpdateString)
+					updatableFiles[k] = append(updatableFiles[k], v[i])
+					updaterCachedStateMap[k] = append(updaterCachedStateMap[k], check.CachedState)
+					updateChecksMap[k] = append(updateChecksMap[k], check)
 				}
 			}
 
 			if !updatesFound {
+				if len(summary.failures) > 0 {
+					summary.print(dryRun)
+					writeUpdateReport(report)
+					cmdshared.Exit(cmdshared.ExitGeneric)
+				}
 				fmt.Println("All files are up to date!")
 				return
 			}
 
-			if !cmdshared.PromptYesNo("Do you want to update? [Y/n]: ") {
+			if dryRun {
+				printDryRunUpdates(dryRunUpdates)
+				summary.updated = len(dryRunUpdates)
+				summary.print(true)
+				writeUpdateReport(report)
+				if len(summary.failures) > 0 {
+					cmdshared.Exit(cmdshared.ExitGeneric)
+				}
+				cmdshared.Exit(cmdshared.ExitUpdatesAvailable)
+			}
+
+			yes, err := cmdshared.PromptYesNo("Do you want to update? [Y/n]: ")
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			if !yes {
 				fmt.Println("Cancelled!")
 				return
 			}
 
 			for k, v := range updatableFiles {
-				err := core.Updaters[k].DoUpdate(v, updaterCachedStateMap[k])
-				if err != nil {
-					// TODO: do we return err code 1?
-					fmt.Println(err.Error())
-					continue
-				}
-				for _, modData := range v {
+				errs := doUpdates(core.Updaters[k], v, updaterCachedStateMap[k])
+				for i, modData := range v {
+					if errs[i] != nil {
+						fmt.Printf("Failed to update %s: %v\n", modData.Name, errs[i])
+						summary.fail(modData.Name, errs[i])
+						continue
+					}
 					format, hash, err := modData.Write()
 					if err != nil {
 						fmt.Println(err.Error())
+						summary.fail(modData.Name, err)
 						continue
 					}
 					err = index.RefreshFileWithHash(modData.GetFilePath(), format, hash, true)
 					if err != nil {
 						fmt.Println(err.Error())
+						summary.fail(modData.Name, err)
 						continue
 					}
+					summary.updated++
+					report.Modified = append(report.Modified, updateChange(&index, modData, updateChecksMap[k][i], false))
 				}
 			}
 		} else {
+			if source != "" {
+				fmt.Println("--source can only be used with --all")
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
 			if len(args) < 1 || len(args[0]) == 0 {
 				fmt.Println("Must specify a valid file, or use the --all flag!")
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitUsage)
 			}
-			modPath, ok := index.FindMod(args[0])
+			modPath, ok := ResolveModName(index, args[0])
 			if !ok {
 				fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitNotFound)
 			}
 			modData, err := core.LoadMod(modPath)
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				cmdshared.ExitWithError(err)
 			}
-			if modData.Pin {
-				fmt.Println("Version is pinned; run the unpin command to allow updating")
-				os.Exit(1)
+			if skipPinned(&modData) {
+				fmt.Println("Run the unpin command to allow updating it")
+				return
 			}
 			singleUpdatedName = modData.Name
-			updaterFound := false
-			for k := range modData.Update {
-				updater, ok := core.Updaters[k]
-				if !ok {
-					continue
+
+			var updaterName string
+			var updater core.Updater
+			var check core.UpdateCheck
+			if versionID, source := getRequestedVersion(); versionID != "" {
+				versionUpdater, ok := core.Updaters[source].(core.VersionUpdater)
+				if _, hasSource := modData.Update[source]; !ok || !hasSource {
+					fmt.Printf("\"%s\" is not a %s file, so a %s version can't be specified\n", modData.Name, source, source)
+					cmdshared.Exit(cmdshared.ExitUsage)
 				}
-				updaterFound = true
+				updaterName, updater = source, versionUpdater
 
-				check, err := updater.CheckUpdate([]*core.Mod{&modData}, pack)
+				check, err = versionUpdater.CheckUpdateToVersion(&modData, versionID, pack)
 				if err != nil {
-					fmt.Println(err)
-					os.Exit(1)
+					cmdshared.ExitWithError(err)
 				}
-				if len(check) != 1 {
-					fmt.Println("Invalid update check response")
-					os.Exit(1)
+				if !check.UpdateAvailable {
+					fmt.Printf("\"%s\" is already at this version!\n", modData.Name)
+					return
 				}
-
-				if check[0].UpdateAvailable {
-					fmt.Printf("Update available: %s\n", check[0].UpdateString)
-
-					err = updater.DoUpdate([]*core.Mod{&modData}, []interface{}{check[0].CachedState})
-					if err != nil {
-						fmt.Println(err)
-						os.Exit(1)
+			} else {
+				for k := range modData.Update {
+					u, ok := core.Updaters[k]
+					if !ok {
+						continue
 					}
+					updaterName, updater = k, u
 
-					format, hash, err := modData.Write()
+					checks, err := updater.CheckUpdate([]*core.Mod{&modData}, pack)
 					if err != nil {
-						fmt.Println(err)
-						os.Exit(1)
+						cmdshared.ExitWithError(err)
 					}
-					err = index.RefreshFileWithHash(modPath, format, hash, true)
-					if err != nil {
-						fmt.Println(err)
-						os.Exit(1)
+					if len(checks) != 1 {
+						fmt.Println("Invalid update check response")
+						cmdshared.Exit(cmdshared.ExitGeneric)
 					}
-				} else {
+					check = checks[0]
+					break
+				}
+				if updater == nil {
+					// TODO: use file name instead of Name when len(Name) == 0 in all places?
+					fmt.Println("A supported update system for \"" + modData.Name + "\" cannot be found.")
+					cmdshared.Exit(cmdshared.ExitGeneric)
+				}
+				if check.Error != nil {
+					fmt.Println(check.Error)
+					cmdshared.Exit(cmdshared.ExitGeneric)
+				}
+				if !check.UpdateAvailable {
 					fmt.Printf("\"%s\" is already up to date!\n", modData.Name)
 					return
 				}
+			}
 
-				break
+			if dryRun {
+				printDryRunUpdates([]dryRunUpdate{{modData.Name, check, updaterName}})
+				report.Modified = append(report.Modified, updateChange(&index, &modData, check, true))
+				writeUpdateReport(report)
+				cmdshared.Exit(cmdshared.ExitUpdatesAvailable)
 			}
-			if !updaterFound {
-				// TODO: use file name instead of Name when len(Name) == 0 in all places?
-				fmt.Println("A supported update system for \"" + modData.Name + "\" cannot be found.")
-				os.Exit(1)
+
+			fmt.Printf("Update available: %s\n", check.UpdateString)
+
+			err = updater.DoUpdate([]*core.Mod{&modData}, []interface{}{check.CachedState})
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+
+			format, hash, err := modData.Write()
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			err = index.RefreshFileWithHash(modPath, format, hash, true)
+			if err != nil {
+				cmdshared.ExitWithError(err)
 			}
+			report.Modified = append(report.Modified, updateChange(&index, &modData, check, false))
 		}
 
 		err = index.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.UpdateIndexHash()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		locked, err := index.UpdateLock()
+		if err != nil {
+			fmt.Printf("Error updating %s: %v\n", core.LockFile, err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		if locked {
+			fmt.Printf("Updated %s\n", core.LockFile)
 		}
 		if viper.GetBool("update.all") {
+			summary.print(false)
+			if len(summary.failures) > 0 {
+				writeUpdateReport(report)
+				cmdshared.Exit(cmdshared.ExitGeneric)
+			}
 			fmt.Println("Files updated!")
 		} else {
 			fmt.Printf("\"%s\" updated!\n", singleUpdatedName)
@@ -218,9 +305,163 @@ var UpdateCmd = &cobra.Command{
 	},
 }
 
+// groupModsByUpdater groups mods by the update systems in updaters that they have update data for, optionally only
+// including the given source. Mods without update data for any of the update systems are also returned.
+func groupModsByUpdater(mods []*core.Mod, source string, updaters map[string]core.Updater) (map[string][]*core.Mod, []*core.Mod) {
+	filesWithUpdater := make(map[string][]*core.Mod)
+	var withoutUpdater []*core.Mod
+	for _, modData := range mods {
+		updaterFound := false
+		for k := range modData.Update {
+			if source != "" && k != source {
+				continue
+			}
+			if _, ok := updaters[k]; !ok {
+				continue
+			}
+			updaterFound = true
+			filesWithUpdater[k] = append(filesWithUpdater[k], modData)
+		}
+		if !updaterFound {
+			withoutUpdater = append(withoutUpdater, modData)
+		}
+	}
+	return filesWithUpdater, withoutUpdater
+}
+
+// skipPinned prints a message and returns true if the given mod is pinned, so it should not be updated
+func skipPinned(mod *core.Mod) bool {
+	if !mod.Pin {
+		return false
+	}
+	fmt.Printf("%s: skipped (pinned)\n", mod.Name)
+	return true
+}
+
+// getRequestedVersion returns the version ID specified by the user, and the name of the update system it is for
+func getRequestedVersion() (string, string) {
+	versionID := viper.GetString("update.version-id")
+	fileID := viper.GetString("update.file-id")
+	if versionID != "" && fileID != "" {
+		fmt.Println("Only one of --version-id and --file-id can be specified")
+		cmdshared.Exit(cmdshared.ExitUsage)
+	}
+	if versionID != "" {
+		return versionID, "modrinth"
+	}
+	if fileID != "" {
+		return fileID, "curseforge"
+	}
+	return "", ""
+}
+
+// doUpdates applies updates to the given files, returning an error for each file that failed to update. If updating
+// them all at once fails, they are updated one at a time so that one failing file doesn't prevent the others from
+// being updated.
+func doUpdates(updater core.Updater, mods []*core.Mod, cachedState []interface{}) []error {
+	errs := make([]error, len(mods))
+	err := updater.DoUpdate(mods, cachedState)
+	if err == nil {
+		return errs
+	}
+	if len(mods) == 1 {
+		errs[0] = err
+		return errs
+	}
+	for i, mod := range mods {
+		errs[i] = updater.DoUpdate([]*core.Mod{mod}, []interface{}{cachedState[i]})
+	}
+	return errs
+}
+
+type updateFailure struct {
+	Name string
+	Err  error
+}
+
+// updateSummary counts the results of updating all files, so that failures don't stop other files from being updated
+type updateSummary struct {
+	updated   int
+	unchanged int
+	failures  []updateFailure
+}
+
+func (s *updateSummary) fail(name string, err error) {
+	s.failures = append(s.failures, updateFailure{name, err})
+}
+
+// print prints the number of updated, unchanged and failed files, and the reason each file failed
+func (s *updateSummary) print(dryRun bool) {
+	if dryRun {
+		fmt.Printf("%d updates available, %d unchanged, %d failed\n", s.updated, s.unchanged, len(s.failures))
+	} else {
+		fmt.Printf("%d updated, %d unchanged, %d failed\n", s.updated, s.unchanged, len(s.failures))
+	}
+	if len(s.failures) == 0 {
+		return
+	}
+	slices.SortFunc(s.failures, func(a, b updateFailure) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	fmt.Println("Failed files:")
+	for _, f := range s.failures {
+		fmt.Printf("%s: %v\n", f.Name, f.Err)
+	}
+}
+
+// updateChange creates a report entry for an update to a mod; the new hash is only known once the update is done
+func updateChange(index *core.Index, mod *core.Mod, check core.UpdateCheck, dryRun bool) cmdshared.FileChange {
+	change := cmdshared.NewFileChange(index, mod)
+	change.OldVersion, change.NewVersion = check.CurrentVersion, check.NewVersion
+	if dryRun {
+		change.HashFormat, change.Hash = "", ""
+	}
+	return change
+}
+
+// writeUpdateReport writes the report given by --report, exiting if it can't be written
+func writeUpdateReport(report cmdshared.ChangeReport) {
+	if err := cmdshared.WriteReport("update.report", report); err != nil {
+		cmdshared.ExitWithError(err)
+	}
+}
+
+type dryRunUpdate struct {
+	Name   string
+	Check  core.UpdateCheck
+	Source string
+}
+
+// printDryRunUpdates prints a table of the updates that would be applied
+func printDryRunUpdates(updates []dryRunUpdate) {
+	slices.SortFunc(updates, func(a, b dryRunUpdate) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	fmt.Println("Updates found (dry run, no files were changed):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Name\tCurrent version\tAvailable version\tSource")
+	for _, u := range updates {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.Name, u.Check.CurrentVersion, u.Check.NewVersion, u.Source)
+	}
+	_ = w.Flush()
+}
+
 func init() {
 	rootCmd.AddCommand(UpdateCmd)
+	cmdshared.AddHooks(UpdateCmd, "update")
 
 	UpdateCmd.Flags().BoolP("all", "a", false, "Update all external files")
 	_ = viper.BindPFlag("update.all", UpdateCmd.Flags().Lookup("all"))
+	UpdateCmd.Flags().String("source", "", "Only update files from this source (e.g. modrinth, curseforge or github) when using --all")
+	_ = viper.BindPFlag("update.source", UpdateCmd.Flags().Lookup("source"))
+	UpdateCmd.Flags().Bool("dry-run", false, "List available updates without changing any files, exiting with code 6 if any are found")
+	_ = viper.BindPFlag("update.dry-run", UpdateCmd.Flags().Lookup("dry-run"))
+	UpdateCmd.Flags().String("version-id", "", "Update a Modrinth file to the version with this ID, rather than the latest version")
+	_ = viper.BindPFlag("update.version-id", UpdateCmd.Flags().Lookup("version-id"))
+	UpdateCmd.Flags().String("channel", "", "Move Modrinth files to this channel (release, beta or alpha), updating them to the latest version of this type or a more stable one")
+	_ = viper.BindPFlag("update.channel", UpdateCmd.Flags().Lookup("channel"))
+	UpdateCmd.Flags().String("file-id", "", "Update a CurseForge file to the file with this ID, rather than the latest file")
+	_ = viper.BindPFlag("update.file-id", UpdateCmd.Flags().Lookup("file-id"))
+	UpdateCmd.Flags().String("report", "", "Write a JSON summary of the updated files to this file (or stdout, if \"-\", with other output sent to stderr), even with --dry-run")
+	_ = viper.BindPFlag("update.report", UpdateCmd.Flags().Lookup("report"))
 }
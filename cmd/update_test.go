@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+// failingTestUpdater fails to update files with the name "broken", setting the file name of the others
+type failingTestUpdater struct{}
+
+func (failingTestUpdater) ParseUpdate(data map[string]interface{}) (interface{}, error) {
+	return data, nil
+}
+
+func (failingTestUpdater) CheckUpdate(mods []*core.Mod, _ core.Pack) ([]core.UpdateCheck, error) {
+	return make([]core.UpdateCheck, len(mods)), nil
+}
+
+func (failingTestUpdater) DoUpdate(mods []*core.Mod, cachedState []interface{}) error {
+	for i, mod := range mods {
+		if mod.Name == "broken" {
+			return errors.New("no compatible version")
+		}
+		mod.FileName = cachedState[i].(string)
+	}
+	return nil
+}
+
+func TestDoUpdatesContinuesPastFailures(t *testing.T) {
+	mods := []*core.Mod{{Name: "a"}, {Name: "broken"}, {Name: "c"}}
+	errs := doUpdates(failingTestUpdater{}, mods, []interface{}{"a-2.jar", "broken-2.jar", "c-2.jar"})
+
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Fatalf("Expected only the broken file to fail, got %v", errs)
+	}
+	if mods[0].FileName != "a-2.jar" || mods[2].FileName != "c-2.jar" {
+		t.Errorf("Expected the other files to be updated, got %s and %s", mods[0].FileName, mods[2].FileName)
+	}
+	if mods[1].FileName != "" {
+		t.Errorf("Expected the broken file not to be updated, got %s", mods[1].FileName)
+	}
+}
+
+// writeUpdateTestPack creates a pack containing a file for each of the given names, updated by outdatedTestUpdater, and
+// uses it as the pack file
+func writeUpdateTestPack(t *testing.T, names ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"pack.toml": "name = \"test\"\npack-format = \"packwiz:1.1.0\"\n[index]\nfile = \"index.toml\"\nhash-format = \"sha256\"\nhash = \"\"\n",
+	}
+	index := "hash-format = \"sha256\"\n"
+	for _, name := range names {
+		index += "[[files]]\nfile = \"mods/" + name + ".pw.toml\"\nhash = \"\"\nmetafile = true\n"
+		files["mods/"+name+".pw.toml"] = "name = \"" + name + "\"\nfilename = \"" + name + ".jar\"\n[download]\n" +
+			"url = \"https://example.com/" + name + ".jar\"\nhash-format = \"sha1\"\nhash = \"abc\"\n[update.test]\n"
+	}
+	files["index.toml"] = index
+	for name, contents := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	core.Updaters["test"] = outdatedTestUpdater{}
+	t.Cleanup(func() { delete(core.Updaters, "test") })
+
+	// Other tests set pack-file in viper, which takes precedence over the flag
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", filepath.Join(dir, "pack.toml"))
+	t.Cleanup(func() { viper.Set("pack-file", oldPackFile) })
+}
+
+func TestUpdateDryRunExitCode(t *testing.T) {
+	t.Cleanup(func() {
+		_ = UpdateCmd.Flags().Set("all", "false")
+		_ = UpdateCmd.Flags().Set("dry-run", "false")
+	})
+
+	writeUpdateTestPack(t, "current")
+	if code := executeForExitCode(t, "update", "--all", "--dry-run"); code != cmdshared.ExitOK {
+		t.Errorf("Expected exit code %d when no updates are found, got %d", cmdshared.ExitOK, code)
+	}
+	writeUpdateTestPack(t, "current", "old")
+	if code := executeForExitCode(t, "update", "--all", "--dry-run"); code != cmdshared.ExitUpdatesAvailable {
+		t.Errorf("Expected exit code %d when updates are found, got %d", cmdshared.ExitUpdatesAvailable, code)
+	}
+	writeUpdateTestPack(t, "broken")
+	if code := executeForExitCode(t, "update", "--all", "--dry-run"); code != cmdshared.ExitGeneric {
+		t.Errorf("Expected exit code %d when checking for updates fails, got %d", cmdshared.ExitGeneric, code)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the modpack for common mistakes before publishing it",
+	Long: `Check the modpack for common mistakes before publishing it, without modifying any files.
+This reports files in the index that are missing on disk, files on disk that aren't in the index, metadata files with an
+invalid side or without a download hash, empty or stale hashes in the index, a stale index hash in pack.toml, and jar
+files that declare the same mod ID. Downloaded files are checked for mod IDs if they are in the download cache; use
+--download to download the files that aren't.
+Exits with a non-zero code if any errors are found, or any warnings with --strict.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		problems := index.Validate(pack)
+		conflicts, skipped, err := index.FindModIDConflicts(cmd.Context(), viper.GetBool("validate.download"))
+		if err != nil {
+			fmt.Printf("Failed to check for conflicting mod IDs: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		for _, conflict := range conflicts {
+			problems = append(problems, core.ValidationProblem{
+				Severity: core.SeverityWarning,
+				Path:     conflict.Paths[0],
+				Message:  fmt.Sprintf("mod ID %q is also declared by %s; the mod loader may refuse to start", conflict.ModID, strings.Join(conflict.Paths[1:], ", ")),
+			})
+		}
+		slices.SortStableFunc(problems, func(a, b core.ValidationProblem) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+		if skipped > 0 {
+			fmt.Printf("%d files aren't in the download cache, so weren't checked for conflicting mod IDs; use --download to check them\n", skipped)
+		}
+		errorCount, warningCount := 0, 0
+		for _, problem := range problems {
+			fmt.Println(problem)
+			if problem.Severity == core.SeverityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+		if len(problems) == 0 {
+			fmt.Println("No problems found!")
+			return
+		}
+		fmt.Printf("Found %d errors and %d warnings\n", errorCount, warningCount)
+		if errorCount > 0 || viper.GetBool("validate.strict") {
+			cmdshared.Exit(cmdshared.ExitGeneric)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().Bool("strict", false, "Exit with a non-zero code if any warnings are found")
+	_ = viper.BindPFlag("validate.strict", validateCmd.Flags().Lookup("strict"))
+	validateCmd.Flags().Bool("download", false, "Download files that aren't in the download cache to check them for conflicting mod IDs")
+	_ = viper.BindPFlag("validate.download", validateCmd.Flags().Lookup("download"))
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the hashes of all files in the modpack match their stored hashes",
+	Long: `Check that the hashes of all files in the modpack match their stored hashes.
+This checks the index against pack.toml, and each file (including metadata files) against the index.
+With --download, files referenced by metadata files are also downloaded (or read from the cache) and checked.
+Exits with a non-zero code if any mismatches are found.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		var mismatches []core.HashMismatch
+		if mismatch := pack.VerifyIndexHash(); mismatch != nil {
+			mismatches = append(mismatches, *mismatch)
+		}
+		mismatches = append(mismatches, index.Verify()...)
+
+		if viper.GetBool("verify.download") {
+			mods, err := index.LoadAllMods()
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			mismatches = append(mismatches, verifyDownloads(mods, &index)...)
+		}
+
+		if len(mismatches) > 0 {
+			fmt.Println("Hash mismatches found:")
+			for _, mismatch := range mismatches {
+				fmt.Println(mismatch)
+			}
+			cmdshared.Exit(cmdshared.ExitHashMismatch)
+		}
+		fmt.Println("All files match their hashes!")
+	},
+}
+
+// verifyDownloads downloads the files referenced by the given mods, and returns any that don't match their stored hash
+func verifyDownloads(mods []*core.Mod, index *core.Index) []core.HashMismatch {
+	session, err := core.CreateDownloadSession(mods, []string{})
+	if err != nil {
+		fmt.Printf("Error retrieving external files: %v\n", err)
+		cmdshared.Exit(cmdshared.ExitCode(err))
+	}
+	if err := cmdshared.ListManualDownloads(session); err != nil {
+		cmdshared.ExitWithError(err)
+	}
+
+	var mismatches []core.HashMismatch
+	for dl := range session.StartDownloads() {
+		path := dl.Mod.GetDestFilePath()
+		if relPath, err := index.RelIndexPath(path); err == nil {
+			path = relPath
+		}
+		mismatch := core.HashMismatch{
+			Path:     path,
+			Format:   dl.Mod.Download.HashFormat,
+			Expected: dl.Mod.Download.Hash,
+		}
+		if dl.Error != nil {
+			mismatch.Error = dl.Error
+			mismatches = append(mismatches, mismatch)
+			continue
+		}
+		for _, warning := range dl.Warnings {
+			fmt.Printf("Warning for %s: %v\n", dl.Mod.Name, warning)
+		}
+
+		// Rehash the file rather than trusting the cache index, so corrupted cache entries are found
+		mismatch.Actual, mismatch.Error = core.HashReader(dl.File, dl.Mod.Download.HashFormat)
+		_ = dl.File.Close()
+		if mismatch.Error != nil || !strings.EqualFold(mismatch.Actual, mismatch.Expected) {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+
+	err = session.SaveIndex()
+	if err != nil {
+		fmt.Printf("Error saving cache index: %v\n", err)
+		cmdshared.Exit(cmdshared.ExitCode(err))
+	}
+	return mismatches
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().Bool("download", false, "Also download (or read from the cache) the files referenced by metadata files, and check their hashes")
+	_ = viper.BindPFlag("verify.download", verifyCmd.Flags().Lookup("download"))
+}
@@ -0,0 +1,90 @@
+package cmdshared
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrAlreadyAdded is returned when adding a project from a list that is already in the pack, which isn't a failure
+var ErrAlreadyAdded = errors.New("already in the pack")
+
+// ListEntry is a project to add, read from a file given with --from-file
+type ListEntry struct {
+	// Line is the line number of the entry in the file, for error messages
+	Line    int
+	Project string
+	// Version is the version to add, or empty to add the latest version
+	Version string
+}
+
+func (e ListEntry) String() string {
+	if e.Version != "" {
+		return e.Project + " " + e.Version
+	}
+	return e.Project
+}
+
+// ReadProjectList reads a list of projects to add from a file: one slug, ID or URL per line, optionally followed by a
+// version to add instead of the latest one. Text after a # is ignored, as are blank lines.
+func ReadProjectList(path string) ([]ListEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []ListEntry
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			entries = append(entries, ListEntry{Line: line, Project: fields[0]})
+		case 2:
+			entries = append(entries, ListEntry{Line: line, Project: fields[0], Version: fields[1]})
+		default:
+			return nil, fmt.Errorf("%s:%d: expected a project and an optional version, got %q", path, line, strings.TrimSpace(text))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddFromList adds every entry of a project list with add, continuing past entries that fail, then prints a summary.
+// It returns the number of entries that couldn't be added.
+func AddFromList(entries []ListEntry, add func(entry ListEntry) error) int {
+	var added, skipped int
+	var failed []string
+	for i, entry := range entries {
+		fmt.Printf("[%d/%d] Adding %s...\n", i+1, len(entries), entry)
+		err := add(entry)
+		if errors.Is(err, ErrAlreadyAdded) {
+			fmt.Printf("Skipping %s: %v\n", entry.Project, err)
+			skipped++
+		} else if err != nil {
+			fmt.Printf("Failed to add %s: %v\n", entry.Project, err)
+			failed = append(failed, fmt.Sprintf("line %d: %s (%v)", entry.Line, entry, err))
+		} else {
+			added++
+		}
+	}
+
+	fmt.Printf("\nAdded %d, skipped %d (already in the pack), failed %d\n", added, skipped, len(failed))
+	if len(failed) > 0 {
+		fmt.Println("Failed to add:")
+		for _, f := range failed {
+			fmt.Println("  " + f)
+		}
+	}
+	return len(failed)
+}
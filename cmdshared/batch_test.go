@@ -0,0 +1,62 @@
+package cmdshared
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadProjectList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mods.txt")
+	contents := "# Performance\nsodium\n\nlithium mc1.20.1-0.11.2 # pinned\n  https://modrinth.com/mod/iris  \n"
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadProjectList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ListEntry{
+		{Line: 2, Project: "sodium"},
+		{Line: 4, Project: "lithium", Version: "mc1.20.1-0.11.2"},
+		{Line: 5, Project: "https://modrinth.com/mod/iris"},
+	}
+	if !slices.Equal(entries, expected) {
+		t.Errorf("Expected entries %v, got %v", expected, entries)
+	}
+
+	if err := os.WriteFile(path, []byte("sodium 1.0 extra\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadProjectList(path); err == nil {
+		t.Error("Expected an error for a line with too many fields")
+	}
+}
+
+func TestAddFromList(t *testing.T) {
+	entries := []ListEntry{
+		{Line: 1, Project: "a"},
+		{Line: 2, Project: "b"},
+		{Line: 3, Project: "c"},
+		{Line: 4, Project: "d"},
+	}
+	var attempted []string
+	failed := AddFromList(entries, func(entry ListEntry) error {
+		attempted = append(attempted, entry.Project)
+		switch entry.Project {
+		case "b":
+			return errors.New("not found")
+		case "c":
+			return ErrAlreadyAdded
+		}
+		return nil
+	})
+	if failed != 1 {
+		t.Errorf("Expected 1 failure, got %d", failed)
+	}
+	if !slices.Equal(attempted, []string{"a", "b", "c", "d"}) {
+		t.Errorf("Expected all entries to be attempted after a failure, got %v", attempted)
+	}
+}
@@ -2,32 +2,83 @@ package cmdshared
 
 import (
 	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/0byte-coding/packwiz/core"
 	"io"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 )
 
-func ListManualDownloads(session core.DownloadSession) {
+// ListManualDownloads lists the files in the session that must be downloaded manually, returning an error if there are any
+func ListManualDownloads(session core.DownloadSession) error {
 	manualDownloads := session.GetManualDownloads()
-	if len(manualDownloads) > 0 {
-		fmt.Printf("Found %v manual downloads; these mods are unable to be downloaded by packwiz (due to API limitations) and must be manually downloaded:\n",
-			len(manualDownloads))
-		for _, dl := range manualDownloads {
-			fmt.Printf("%s (%s) from %s\n", dl.Name, dl.FileName, dl.URL)
-		}
-		cacheDir, err := core.GetPackwizCache()
-		if err != nil {
-			fmt.Printf("Error locating cache folder: %v", err)
-			os.Exit(1)
-		}
+	if len(manualDownloads) == 0 {
+		return nil
+	}
+	fmt.Printf("Found %v manual downloads; these mods are unable to be downloaded by packwiz (due to API limitations) and must be manually downloaded:\n",
+		len(manualDownloads))
+	for _, dl := range manualDownloads {
+		fmt.Printf("%s (%s) from %s\n", dl.Name, dl.FileName, dl.URL)
+	}
+	cacheDir, err := core.GetPackwizCache()
+	if err != nil {
+		return fmt.Errorf("error locating cache folder: %w", err)
+	}
+	return fmt.Errorf("%d files must be downloaded manually; once you have done so, place them in %s and re-run this command",
+		len(manualDownloads), filepath.Join(cacheDir, core.DownloadCacheImportFolder))
+}
+
+// DownloadModpack downloads the modpack at the given URL into a temporary folder so it can be imported, returning the
+// path of the file. The folder is removed when the command finishes, even if the download fails. Only zip files are
+// accepted, so that error pages aren't passed to the importers.
+func DownloadModpack(ctx context.Context, fileURL string) (string, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	tempDir, err := os.MkdirTemp("", "packwiz-import")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	AddCleanup(func() { _ = os.RemoveAll(tempDir) })
+
+	// Keep the file name, as some importers use it to detect the format
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		name = "modpack"
+	}
+	resp, err := core.GetWithProgress(ctx, core.DownloadHTTPClient, fileURL, "application/zip", NewProgressBar(name))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
 
-		fmt.Printf("Once you have done so, place these files in %s and re-run this command.\n",
-			filepath.Join(cacheDir, core.DownloadCacheImportFolder))
-		os.Exit(1)
+	body := bufio.NewReader(resp.Body)
+	magic, err := body.Peek(4)
+	if err != nil || !isZipHeader(magic) {
+		return "", fmt.Errorf("%s is not a zip file", fileURL)
 	}
+	destPath := filepath.Join(tempDir, name)
+	f, err := os.Create(destPath)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, body)
+	if err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return destPath, f.Close()
+}
+
+// isZipHeader returns true if the given data starts with the signature of a zip file (including an empty one)
+func isZipHeader(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06"))
 }
 
 func AddToZip(dl core.CompletedDownload, exp *zip.Writer, dir string, index *core.Index) bool {
@@ -64,11 +115,46 @@ func AddToZip(dl core.CompletedDownload, exp *zip.Writer, dir string, index *cor
 	return true
 }
 
-// AddNonMetafileOverrides saves all non-metadata files into an overrides folder in the zip
-func AddNonMetafileOverrides(index *core.Index, exp *zip.Writer) {
+// AddNonMetafileOverrides saves the non-metadata files included by the rules into an overrides folder in the zip
+func AddNonMetafileOverrides(index *core.Index, exp *zip.Writer, rules core.OverrideRules) {
+	AddNonMetafileFiles(index, exp, "overrides", rules)
+}
+
+// AddNonMetafileFiles saves the non-metadata files included by the rules into the given folder in the zip
+func AddNonMetafileFiles(index *core.Index, exp *zip.Writer, dir string, rules core.OverrideRules) {
+	addNonMetafileFiles(index, exp, func(p string) (string, bool) {
+		return dir, rules.Includes(p)
+	})
+}
+
+// AddNonMetafileSideOverrides saves the non-metadata files into the overrides folders of a Modrinth pack: files
+// included for both sides go in overrides, and files only included for one side go in client-overrides or
+// server-overrides
+func AddNonMetafileSideOverrides(index *core.Index, exp *zip.Writer, clientRules core.OverrideRules, serverRules core.OverrideRules) {
+	addNonMetafileFiles(index, exp, func(p string) (string, bool) {
+		client, server := clientRules.Includes(p), serverRules.Includes(p)
+		switch {
+		case client && server:
+			return "overrides", true
+		case client:
+			return "client-overrides", true
+		case server:
+			return "server-overrides", true
+		}
+		return "", false
+	})
+}
+
+// addNonMetafileFiles saves non-metadata files into the zip, in the folder returned by getDir for each file; files
+// are left out if getDir returns false
+func addNonMetafileFiles(index *core.Index, exp *zip.Writer, getDir func(p string) (string, bool)) {
 	for p, v := range index.Files {
 		if !v.IsMetaFile() {
-			file, err := exp.Create(path.Join("overrides", p))
+			dir, ok := getDir(p)
+			if !ok {
+				continue
+			}
+			file, err := exp.Create(path.Join(dir, p))
 			if err != nil {
 				fmt.Printf("Error creating file: %s\n", err.Error())
 				// TODO: exit(1)?
@@ -0,0 +1,71 @@
+package cmdshared
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadModpack(t *testing.T) {
+	var packZip bytes.Buffer
+	zw := zip.NewWriter(&packZip)
+	if _, err := zw.Create("manifest.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pack.zip":
+			_, _ = w.Write(packZip.Bytes())
+		case "/page.zip":
+			_, _ = w.Write([]byte("<html>Not a modpack</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"/pack.zip", false},
+		{"/page.zip", true},
+		{"/missing.zip", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("TMPDIR", tempDir)
+			t.Cleanup(RunCleanups)
+
+			downloaded, err := DownloadModpack(context.Background(), srv.URL+tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error downloading %s", tt.path)
+				}
+			} else if err != nil {
+				t.Errorf("Failed to download %s: %v", tt.path, err)
+			} else {
+				if filepath.Base(downloaded) != filepath.Base(tt.path) {
+					t.Errorf("Expected the file name to be kept, got %s", downloaded)
+				}
+				if data, err := os.ReadFile(downloaded); err != nil || !bytes.Equal(data, packZip.Bytes()) {
+					t.Errorf("Expected the downloaded file to contain the modpack (%v)", err)
+				}
+			}
+
+			RunCleanups()
+			if entries, err := os.ReadDir(tempDir); err != nil || len(entries) > 0 {
+				t.Errorf("Expected temporary files to be removed, found %v (%v)", entries, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cmdshared
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// Exit codes used by packwiz, so scripts can tell why a command failed
+const (
+	ExitOK = 0
+	// ExitGeneric is used for failures that don't have a more specific code
+	ExitGeneric = 1
+	// ExitUsage is used when a command is given invalid arguments or flags
+	ExitUsage = 2
+	// ExitNetwork is used when a request fails, is rate limited, or can't be made in offline mode
+	ExitNetwork = 3
+	// ExitNotFound is used when a file, project or version can't be found
+	ExitNotFound = 4
+	// ExitHashMismatch is used when a file doesn't match its expected hash or signature
+	ExitHashMismatch = 5
+	// ExitUpdatesAvailable is used when checking for updates without applying them (such as update --dry-run) finds
+	// updates, so scripts can tell this apart from the check failing
+	ExitUpdatesAvailable = 6
+)
+
+// ExitCodesHelp describes the exit codes, for the help of the root command
+const ExitCodesHelp = `Exit codes:
+  0  success
+  1  other errors
+  2  invalid arguments or flags
+  3  network errors, rate limiting, or network access needed in offline mode
+  4  a file, project or version couldn't be found
+  5  a file doesn't match its expected hash or signature
+  6  updates are available (update --dry-run, list --outdated)`
+
+// ExitFunc is called by Exit; it is replaced in tests, which can't let packwiz exit
+var ExitFunc = os.Exit
+
+// Exit exits packwiz with the given code, after running the cleanups added with AddCleanup. Commands must exit
+// through this (rather than calling os.Exit), so that exiting can be intercepted in tests.
+func Exit(code int) {
+	RunCleanups()
+	ExitFunc(code)
+}
+
+// cleanups are run when the command finishes, or before packwiz exits
+var cleanups []func()
+
+// AddCleanup adds a function (such as removing a temporary file) that is run when the command finishes, whether it
+// returns or exits through Exit
+func AddCleanup(cleanup func()) {
+	cleanups = append(cleanups, cleanup)
+}
+
+// RunCleanups runs the cleanups added with AddCleanup, most recently added first, and removes them
+func RunCleanups() {
+	for len(cleanups) > 0 {
+		cleanup := cleanups[len(cleanups)-1]
+		cleanups = cleanups[:len(cleanups)-1]
+		cleanup()
+	}
+}
+
+// exitCodeError is an error that packwiz exits with a specific code for
+type exitCodeError struct {
+	err  error
+	code int
+}
+
+func (e exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// WithExitCode wraps an error so that ExitCode returns the given code for it, for failures that can't be told apart by
+// the errors they are caused by (such as invalid arguments)
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return exitCodeError{err, code}
+}
+
+// ExitCode returns the exit code for an error, based on the kind of failure it was caused by
+func ExitCode(err error) int {
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var codeErr exitCodeError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &codeErr):
+		return codeErr.code
+	case errors.Is(err, core.ErrHashMismatch), errors.Is(err, core.ErrInvalidSignature):
+		return ExitHashMismatch
+	case errors.Is(err, core.ErrOffline), errors.Is(err, core.ErrRateLimited),
+		errors.As(err, &urlErr), errors.As(err, &opErr):
+		return ExitNetwork
+	case errors.Is(err, fs.ErrNotExist):
+		return ExitNotFound
+	default:
+		return ExitGeneric
+	}
+}
+
+// ExitWithError prints an error and exits with the code for it
+func ExitWithError(err error) {
+	fmt.Println(err)
+	Exit(ExitCode(err))
+}
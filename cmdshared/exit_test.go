@@ -0,0 +1,61 @@
+package cmdshared
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func TestExitCode(t *testing.T) {
+	_, notExist := os.Open("does-not-exist")
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitOK},
+		{errors.New("something went wrong"), ExitGeneric},
+		{fmt.Errorf("failed to download: %w", core.ErrOffline), ExitNetwork},
+		{&url.Error{Op: "Get", URL: "https://example.com", Err: fmt.Errorf("%w after 3 retries", core.ErrRateLimited)}, ExitNetwork},
+		{fmt.Errorf("failed to read pack: %w", notExist), ExitNotFound},
+		{fmt.Errorf("download failed: %w", core.ErrHashMismatch), ExitHashMismatch},
+		{core.ErrInvalidSignature, ExitHashMismatch},
+		{WithExitCode(errors.New("unknown loader"), ExitUsage), ExitUsage},
+		{fmt.Errorf("failed to migrate: %w", WithExitCode(core.ErrOffline, ExitNotFound)), ExitNotFound},
+	}
+	for _, test := range tests {
+		if code := ExitCode(test.err); code != test.code {
+			t.Errorf("Expected exit code %d for %v, got %d", test.code, test.err, code)
+		}
+	}
+}
+
+func TestExitRunsCleanups(t *testing.T) {
+	oldExit := ExitFunc
+	var exitCode int
+	ExitFunc = func(code int) {
+		exitCode = code
+	}
+	t.Cleanup(func() {
+		ExitFunc = oldExit
+	})
+
+	var order []int
+	AddCleanup(func() { order = append(order, 1) })
+	AddCleanup(func() { order = append(order, 2) })
+	Exit(ExitNetwork)
+	if exitCode != ExitNetwork {
+		t.Errorf("Expected exit code %d, got %d", ExitNetwork, exitCode)
+	}
+	if fmt.Sprint(order) != "[2 1]" {
+		t.Errorf("Expected cleanups to run most recent first, got %v", order)
+	}
+	// Cleanups only run once
+	RunCleanups()
+	if len(order) != 2 {
+		t.Errorf("Expected cleanups to be removed after running, got %v", order)
+	}
+}
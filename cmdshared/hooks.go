@@ -0,0 +1,154 @@
+package cmdshared
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// HookOperations are the operations that hooks can be run before (pre-<operation>) and after (post-<operation>)
+var HookOperations = []string{"add", "refresh", "remove", "update"}
+
+// HookNames returns the names of all the hooks that can be configured
+func HookNames() []string {
+	var names []string
+	for _, operation := range HookOperations {
+		names = append(names, "pre-"+operation, "post-"+operation)
+	}
+	return names
+}
+
+// hookCommand returns the shell command configured for the named hook, or an empty string if there isn't one
+func hookCommand(name string) string {
+	return strings.TrimSpace(viper.GetString("hooks." + name))
+}
+
+// RunHook runs the shell command configured for the named hook (e.g. pre-refresh) in the pack root, if there is one,
+// with the given environment variables added to its environment
+func RunHook(name string, env map[string]string) error {
+	command := hookCommand(name)
+	if command == "" {
+		return nil
+	}
+	// Hook output is sent to stderr, so it doesn't get mixed up with output for scripts, such as reports
+	fmt.Fprintf(os.Stderr, "Running %s hook...\n", name)
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+	c.Dir = filepath.Dir(viper.GetString("pack-file"))
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(), "PACKWIZ_HOOK="+name)
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		c.Env = append(c.Env, k+"="+env[k])
+	}
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
+
+// hookSnapshot records the files in the index and the names of metadata files before an operation, so the files it
+// changed can be passed to post- hooks
+type hookSnapshot struct {
+	index core.Index
+	mods  map[string]*core.Mod
+}
+
+func takeHookSnapshot() (hookSnapshot, error) {
+	pack, err := core.LoadPack()
+	if err != nil {
+		return hookSnapshot{}, err
+	}
+	index, err := pack.LoadIndex()
+	if err != nil {
+		return hookSnapshot{}, err
+	}
+	mods, err := snapshotMods()
+	if err != nil {
+		return hookSnapshot{}, err
+	}
+	return hookSnapshot{index, mods}, nil
+}
+
+// changedFilesEnv returns the environment variables describing the files changed between two snapshots
+func changedFilesEnv(before hookSnapshot, after hookSnapshot) map[string]string {
+	var paths, names []string
+	for _, change := range core.DiffIndex(before.index, after.index) {
+		paths = append(paths, change.Path)
+		if mod, ok := after.mods[change.Path]; ok {
+			names = append(names, mod.Name)
+		} else if mod, ok := before.mods[change.Path]; ok {
+			names = append(names, mod.Name)
+		}
+	}
+	return map[string]string{
+		"PACKWIZ_CHANGED_FILES": strings.Join(paths, "\n"),
+		"PACKWIZ_MOD_NAMES":     strings.Join(names, "\n"),
+	}
+}
+
+// AddHooks runs the pre- and post- hooks of an operation before and after a command. The command is aborted if the
+// pre- hook fails; if the post- hook fails a warning is shown. Hooks are given the arguments of the command in
+// PACKWIZ_ARGS, and post- hooks are given the paths of the changed files (relative to the pack root) and the names of
+// the changed metadata files in PACKWIZ_CHANGED_FILES and PACKWIZ_MOD_NAMES, separated by newlines.
+func AddHooks(cmd *cobra.Command, operation string) {
+	preRun, postRun := cmd.PreRunE, cmd.PostRunE
+	var before *hookSnapshot
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		// The command's own PreRunE runs first, as it may redirect output for --report
+		if preRun != nil {
+			if err := preRun(cmd, args); err != nil {
+				return err
+			}
+		}
+		err := RunHook("pre-"+operation, map[string]string{"PACKWIZ_ARGS": strings.Join(args, " ")})
+		if err != nil {
+			return fmt.Errorf("%w; the %s was aborted", err, operation)
+		}
+		if hookCommand("post-"+operation) != "" {
+			snapshot, err := takeHookSnapshot()
+			if err != nil {
+				fmt.Printf("Warning: failed to read the pack for the post-%s hook: %v\n", operation, err)
+			} else {
+				before = &snapshot
+			}
+		}
+		return nil
+	}
+	cmd.PostRunE = func(cmd *cobra.Command, args []string) error {
+		if postRun != nil {
+			if err := postRun(cmd, args); err != nil {
+				return err
+			}
+		}
+		if hookCommand("post-"+operation) == "" {
+			return nil
+		}
+		env := map[string]string{"PACKWIZ_ARGS": strings.Join(args, " ")}
+		if before != nil {
+			after, err := takeHookSnapshot()
+			if err != nil {
+				fmt.Printf("Warning: failed to read the pack for the post-%s hook: %v\n", operation, err)
+			} else {
+				maps.Copy(env, changedFilesEnv(*before, after))
+			}
+		}
+		if err := RunHook("post-"+operation, env); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package cmdshared
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// setTestHook configures a hook for the duration of a test, with the pack root in a temporary folder
+func setTestHook(t *testing.T, name string, command string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in tests use sh")
+	}
+	dir := t.TempDir()
+	viper.Set("pack-file", filepath.Join(dir, "pack.toml"))
+	viper.Set("hooks."+name, command)
+	t.Cleanup(func() {
+		viper.Set("pack-file", "pack.toml")
+		viper.Set("hooks."+name, "")
+	})
+	return dir
+}
+
+func TestRunHook(t *testing.T) {
+	dir := setTestHook(t, "post-add", `printf '%s|%s' "$PACKWIZ_HOOK" "$PACKWIZ_MOD_NAMES" > hook.txt`)
+	if err := RunHook("post-add", map[string]string{"PACKWIZ_MOD_NAMES": "Sodium"}); err != nil {
+		t.Fatalf("Expected hook to succeed, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "hook.txt"))
+	if err != nil {
+		t.Fatalf("Expected hook to run in the pack root: %v", err)
+	}
+	if string(data) != "post-add|Sodium" {
+		t.Errorf("Unexpected hook environment %q", data)
+	}
+
+	if err := RunHook("pre-add", nil); err != nil {
+		t.Errorf("Expected unconfigured hook to be skipped, got %v", err)
+	}
+	viper.Set("hooks.post-add", "exit 3")
+	if err := RunHook("post-add", nil); err == nil {
+		t.Error("Expected failing hook to return an error")
+	}
+}
+
+func TestPreHookAbortsCommand(t *testing.T) {
+	setTestHook(t, "pre-refresh", "exit 1")
+
+	ran := false
+	cmd := &cobra.Command{
+		Use: "refresh",
+		Run: func(cmd *cobra.Command, args []string) {
+			ran = true
+		},
+	}
+	cmd.SetArgs([]string{})
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	AddHooks(cmd, "refresh")
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "the refresh was aborted") {
+		t.Errorf("Expected command to return an error saying it was aborted, got %v", err)
+	}
+	if code := ExitCode(err); code != ExitGeneric {
+		t.Errorf("Expected exit code %d, got %d", ExitGeneric, code)
+	}
+	if ran {
+		t.Error("Expected command not to run after the pre- hook failed")
+	}
+}
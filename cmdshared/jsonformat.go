@@ -0,0 +1,65 @@
+package cmdshared
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// JSON output formats, chosen with --json-format
+const (
+	JSONFormatPretty  = "pretty"
+	JSONFormatCompact = "compact"
+)
+
+// jsonFormat returns the format set with --json-format, or the default for JSON written to out: pretty for a terminal
+// and compact otherwise, so piped output is small. out is nil for files given by path, which are pretty by default.
+func jsonFormat(out *os.File) string {
+	if format := viper.GetString("json-format"); format != "" {
+		return format
+	}
+	if out == nil || isTerminal(out) {
+		return JSONFormatPretty
+	}
+	return JSONFormatCompact
+}
+
+// checkJSONFormat returns an error if the format set with --json-format isn't known
+func checkJSONFormat() error {
+	switch format := viper.GetString("json-format"); format {
+	case "", JSONFormatPretty, JSONFormatCompact:
+		return nil
+	default:
+		return fmt.Errorf("invalid JSON format %q, must be %s or %s", format, JSONFormatPretty, JSONFormatCompact)
+	}
+}
+
+// MarshalJSON encodes v as JSON in the given format, followed by a newline
+func MarshalJSON(v interface{}, format string) ([]byte, error) {
+	var data []byte
+	var err error
+	switch format {
+	case JSONFormatPretty:
+		data, err = json.MarshalIndent(v, "", "\t")
+	case JSONFormatCompact:
+		data, err = json.Marshal(v)
+	default:
+		return nil, fmt.Errorf("invalid JSON format %q, must be %s or %s", format, JSONFormatPretty, JSONFormatCompact)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteJSON writes v to out as JSON, in the format chosen with --json-format
+func WriteJSON(out *os.File, v interface{}) error {
+	data, err := MarshalJSON(v, jsonFormat(out))
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
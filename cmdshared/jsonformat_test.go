@@ -0,0 +1,50 @@
+package cmdshared
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	v := map[string][]int{"a": {1, 2}}
+	data, err := MarshalJSON(v, JSONFormatPretty)
+	if err != nil || string(data) != "{\n\t\"a\": [\n\t\t1,\n\t\t2\n\t]\n}\n" {
+		t.Errorf("Unexpected pretty JSON %q (%v)", data, err)
+	}
+	data, err = MarshalJSON(v, JSONFormatCompact)
+	if err != nil || string(data) != "{\"a\":[1,2]}\n" {
+		t.Errorf("Unexpected compact JSON %q (%v)", data, err)
+	}
+	if _, err := MarshalJSON(v, "yaml"); err == nil {
+		t.Error("Expected unknown format to be rejected")
+	}
+}
+
+func TestJSONFormatDefault(t *testing.T) {
+	t.Cleanup(func() {
+		viper.Set("json-format", "")
+	})
+	out, err := os.Create(filepath.Join(t.TempDir(), "out.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	if format := jsonFormat(out); format != JSONFormatCompact {
+		t.Errorf("Expected compact JSON when output isn't a terminal, got %s", format)
+	}
+	if format := jsonFormat(nil); format != JSONFormatPretty {
+		t.Errorf("Expected pretty JSON for files written by path, got %s", format)
+	}
+	viper.Set("json-format", JSONFormatPretty)
+	if format := jsonFormat(out); format != JSONFormatPretty {
+		t.Errorf("Expected --json-format to override the default, got %s", format)
+	}
+	viper.Set("json-format", "yaml")
+	if err := checkJSONFormat(); err == nil {
+		t.Error("Expected unknown format to be rejected")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/0byte-coding/packwiz/core"
-	"os"
 	"sort"
 	"time"
 )
@@ -23,14 +22,14 @@ type McVersionManifest struct {
 	} `json:"versions"`
 }
 
-func (m McVersionManifest) CheckValid(version string) {
+// CheckValid returns an error if the given version isn't a Minecraft version listed in the manifest
+func (m McVersionManifest) CheckValid(version string) error {
 	for _, v := range m.Versions {
 		if v.ID == version {
-			return
+			return nil
 		}
 	}
-	fmt.Println("Given version is not a valid Minecraft version!")
-	os.Exit(1)
+	return WithExitCode(fmt.Errorf("%s is not a valid Minecraft version", version), ExitUsage)
 }
 
 func GetValidMCVersions() (McVersionManifest, error) {
@@ -50,3 +49,21 @@ func GetValidMCVersions() (McVersionManifest, error) {
 	})
 	return out, nil
 }
+
+// OverrideGameVersion returns a copy of the pack that finds files for the given Minecraft version (from --game-version)
+// instead of the pack's versions, warning that the added files may not work with the pack. If version is empty, the
+// pack is returned unchanged.
+func OverrideGameVersion(pack core.Pack, version string) core.Pack {
+	if version == "" {
+		return pack
+	}
+	packVersion, err := pack.GetMCVersion()
+	if err != nil {
+		packVersion = "(none)"
+	}
+	if version != packVersion {
+		fmt.Printf("WARNING: adding a file for Minecraft %s, which doesn't match the pack's Minecraft version %s!\n", version, packVersion)
+		fmt.Printf("WARNING: the file may not work in the pack; packwiz update will look for files for %s again, unless it is pinned\n", packVersion)
+	}
+	return pack.WithGameVersion(version)
+}
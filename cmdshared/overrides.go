@@ -0,0 +1,22 @@
+package cmdshared
+
+import (
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// AddOverrideFlags adds --override-include and --override-exclude flags to an export command, bound to keys under the
+// given prefix, which add to the patterns in [export.overrides] in pack.toml
+func AddOverrideFlags(cmd *cobra.Command, prefix string) {
+	cmd.Flags().StringSlice("override-include", nil, "Only include files (other than metadata files) matching these patterns, relative to the pack root, in addition to the include patterns in [export.overrides] in pack.toml")
+	_ = viper.BindPFlag(prefix+".override-include", cmd.Flags().Lookup("override-include"))
+	cmd.Flags().StringSlice("override-exclude", nil, "Leave out files (other than metadata files) matching these patterns, relative to the pack root, in addition to the exclude patterns in [export.overrides] in pack.toml")
+	_ = viper.BindPFlag(prefix+".override-exclude", cmd.Flags().Lookup("override-exclude"))
+}
+
+// GetOverrideRules returns the rules selecting the files exported for a side, from pack.toml and the flags added by
+// AddOverrideFlags with the given prefix
+func GetOverrideRules(pack core.Pack, side string, prefix string) (core.OverrideRules, error) {
+	return pack.GetOverrideRules(side, viper.GetStringSlice(prefix+".override-include"), viper.GetStringSlice(prefix+".override-exclude"))
+}
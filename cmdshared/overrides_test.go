@@ -0,0 +1,90 @@
+package cmdshared
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/BurntSushi/toml"
+)
+
+func TestAddNonMetafileOverridesRules(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"config/common.toml", "config/client-ui.toml", "config/server/world.toml", "options.txt"}
+	index := "hash-format = \"sha256\"\n\n[[files]]\nfile = \"mods/mod.pw.toml\"\nhash = \"00\"\nmetafile = true\n"
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+		index += "\n[[files]]\nfile = \"" + f + "\"\nhash = \"00\"\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.toml"), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	in, err := core.LoadIndex(filepath.Join(dir, "index.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pack core.Pack
+	_, err = toml.Decode(`[export.overrides]
+client-exclude = ["config/server/"]
+server-exclude = ["options.txt", "config/client-*.toml"]
+`, &pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientRules, err := pack.GetOverrideRules(core.ClientSide, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverRules, err := pack.GetOverrideRules(core.ServerSide, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zipFiles := func(add func(exp *zip.Writer)) []string {
+		t.Helper()
+		var buf bytes.Buffer
+		exp := zip.NewWriter(&buf)
+		add(exp)
+		if err := exp.Close(); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	names := zipFiles(func(exp *zip.Writer) { AddNonMetafileOverrides(&in, exp, serverRules) })
+	expected := []string{"overrides/config/common.toml", "overrides/config/server/world.toml"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Expected server export to contain %v, got %v", expected, names)
+	}
+
+	names = zipFiles(func(exp *zip.Writer) { AddNonMetafileSideOverrides(&in, exp, clientRules, serverRules) })
+	expected = []string{"client-overrides/config/client-ui.toml", "client-overrides/options.txt", "overrides/config/common.toml", "server-overrides/config/server/world.toml"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Expected files to be split between the side overrides, got %v", names)
+	}
+	for _, name := range names {
+		if strings.Contains(name, "mod.pw.toml") {
+			t.Errorf("Expected metadata files to be left out, got %s", name)
+		}
+	}
+}
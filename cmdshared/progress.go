@@ -0,0 +1,89 @@
+package cmdshared
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+const (
+	progressBarWidth = 30
+	// progressDelay is the time a download must take before a progress bar is shown, to avoid flickering for small files
+	progressDelay = 200 * time.Millisecond
+	// progressInterval is the minimum time between updates of a progress bar
+	progressInterval = 100 * time.Millisecond
+)
+
+// isTerminal returns true if the file is a terminal rather than a pipe or regular file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// NewProgressBar returns a ProgressFunc that renders a progress bar for the named download to stderr, which is cleared
+// once the download has finished. If stderr isn't a terminal or --quiet is set, nil is returned so nothing is shown.
+func NewProgressBar(name string) core.ProgressFunc {
+	if viper.GetBool("quiet") || !isTerminal(os.Stderr) {
+		return nil
+	}
+	return newProgressBar(os.Stderr, name, time.Now)
+}
+
+// DownloadProgressBar returns a progress bar for a file downloaded by a download session, for use with
+// StartDownloadsContext
+func DownloadProgressBar(mod *core.Mod) core.ProgressFunc {
+	return NewProgressBar(mod.FileName)
+}
+
+func newProgressBar(w io.Writer, name string, now func() time.Time) core.ProgressFunc {
+	start := now()
+	var lastRender time.Time
+	rendered := false
+	return func(done int64, total int64) {
+		if total == done {
+			if rendered {
+				_, _ = fmt.Fprint(w, "\r\033[K")
+			}
+			return
+		}
+		t := now()
+		if t.Sub(start) < progressDelay || t.Sub(lastRender) < progressInterval {
+			return
+		}
+		lastRender = t
+		rendered = true
+		_, _ = fmt.Fprint(w, "\r\033[K"+formatProgress(name, done, total))
+	}
+}
+
+// formatProgress formats a progress bar line, or only the downloaded size if the total size isn't known
+func formatProgress(name string, done int64, total int64) string {
+	if total <= 0 {
+		return fmt.Sprintf("%s %s", name, FormatSize(done))
+	}
+	filled := min(int(done*progressBarWidth/total), progressBarWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	if filled > 0 && filled < progressBarWidth {
+		bar = bar[:filled-1] + ">" + bar[filled:]
+	}
+	return fmt.Sprintf("%s [%s] %3d%% %s/%s", name, bar, done*100/total, FormatSize(done), FormatSize(total))
+}
+
+// FormatSize formats a number of bytes using binary units
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
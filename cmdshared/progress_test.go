@@ -0,0 +1,46 @@
+package cmdshared
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatProgress(t *testing.T) {
+	tests := []struct {
+		done, total int64
+		expected    string
+	}{
+		{512, -1, "a.jar 512 B"},
+		{3 * 1024 * 1024, -1, "a.jar 3.0 MiB"},
+		{0, 2048, "a.jar [                              ]   0% 0 B/2.0 KiB"},
+		{1024, 2048, "a.jar [==============>               ]  50% 1.0 KiB/2.0 KiB"},
+		{4096, 2048, "a.jar [==============================] 200% 4.0 KiB/2.0 KiB"},
+	}
+	for _, tt := range tests {
+		if actual := formatProgress("a.jar", tt.done, tt.total); actual != tt.expected {
+			t.Errorf("formatProgress(%d, %d) = %q, expected %q", tt.done, tt.total, actual, tt.expected)
+		}
+	}
+}
+
+func TestProgressBarClearsWhenDone(t *testing.T) {
+	var out strings.Builder
+	now := time.Unix(0, 0)
+	progress := newProgressBar(&out, "a.jar", func() time.Time { return now })
+
+	// Updates before the delay aren't shown, so small files don't flicker
+	progress(10, 100)
+	if out.Len() != 0 {
+		t.Errorf("Expected no output before the delay, got %q", out.String())
+	}
+	now = now.Add(time.Second)
+	progress(50, -1)
+	if !strings.HasSuffix(out.String(), "a.jar 50 B") {
+		t.Errorf("Expected progress to be shown, got %q", out.String())
+	}
+	progress(100, 100)
+	if !strings.HasSuffix(out.String(), "\r\033[K") {
+		t.Errorf("Expected the progress bar to be cleared, got %q", out.String())
+	}
+}
@@ -2,27 +2,66 @@ package cmdshared
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"github.com/spf13/viper"
+	"io"
 	"os"
 	"strings"
+
+	"github.com/spf13/viper"
 )
 
-func PromptYesNo(prompt string) bool {
+// ErrCannotPrompt is returned when a command needs to ask the user something, but stdin isn't a terminal and --yes
+// isn't set, so waiting for an answer could block forever
+var ErrCannotPrompt = errors.New("input is required, but stdin isn't a terminal; use --yes to accept the default options")
+
+// promptInput is shared between prompts, so input buffered when reading one answer isn't lost
+var promptInput = bufio.NewReader(os.Stdin)
+
+// stdinIsTerminal is replaced in tests
+var stdinIsTerminal = func() bool {
+	return isTerminal(os.Stdin)
+}
+
+// CanPrompt returns ErrCannotPrompt if the user can't be asked for input. Commands showing their own menus must check
+// this first, unless --yes is set (in which case the default option should be chosen without showing the menu).
+func CanPrompt() error {
+	if !stdinIsTerminal() {
+		return ErrCannotPrompt
+	}
+	return nil
+}
+
+// PromptValue prints the prompt and reads a line of input, returning def if the input is empty or --yes is set. If the
+// user can't be prompted, ErrCannotPrompt is returned.
+func PromptValue(prompt string, def string) (string, error) {
 	fmt.Print(prompt)
 	if viper.GetBool("non-interactive") {
-		fmt.Println("Y (non-interactive mode)")
-		return true
+		fmt.Printf("%s (non-interactive mode)\n", def)
+		return def, nil
 	}
-	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
-	if err != nil {
-		fmt.Printf("Failed to prompt user: %v\n", err)
-		os.Exit(1)
+	if err := CanPrompt(); err != nil {
+		fmt.Println()
+		return "", err
+	}
+	value, err := promptInput.ReadString('\n')
+	if err != nil && (err != io.EOF || len(value) == 0) {
+		return "", fmt.Errorf("failed to read input: %w", err)
 	}
+	// Trims both CR and LF
+	value = strings.TrimSpace(value)
+	if len(value) > 0 {
+		return value, nil
+	}
+	return def, nil
+}
 
-	ansNormal := strings.ToLower(strings.TrimSpace(answer))
-	if len(ansNormal) > 0 && ansNormal[0] == 'n' {
-		return false
+// PromptYesNo asks the user a question, returning true unless they answer no. If --yes is set, true is returned
+// without waiting for input.
+func PromptYesNo(prompt string) (bool, error) {
+	answer, err := PromptValue(prompt, "Y")
+	if err != nil {
+		return false, err
 	}
-	return true
+	return !strings.HasPrefix(strings.ToLower(answer), "n"), nil
 }
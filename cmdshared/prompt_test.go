@@ -0,0 +1,58 @@
+package cmdshared
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setPromptInput replaces stdin for prompts with the given input, for the duration of a test
+func setPromptInput(t *testing.T, input string, terminal bool) {
+	t.Helper()
+	oldInput, oldIsTerminal := promptInput, stdinIsTerminal
+	promptInput = bufio.NewReader(strings.NewReader(input))
+	stdinIsTerminal = func() bool { return terminal }
+	t.Cleanup(func() {
+		promptInput, stdinIsTerminal = oldInput, oldIsTerminal
+		viper.Set("non-interactive", false)
+	})
+	// Prompts are printed to stdout; discard them
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	t.Cleanup(func() {
+		_ = os.Stdout.Close()
+		os.Stdout = stdout
+	})
+}
+
+func TestReadPromptValue(t *testing.T) {
+	setPromptInput(t, "first\n\nlast", true)
+	for _, expected := range []string{"first", "default", "last"} {
+		value, err := PromptValue("Value: ", "default")
+		if err != nil || value != expected {
+			t.Errorf("Expected %q, got %q (%v)", expected, value, err)
+		}
+	}
+	if _, err := PromptValue("Value: ", "default"); err == nil {
+		t.Error("Expected an error once input has ended")
+	}
+}
+
+func TestReadPromptValueNonInteractive(t *testing.T) {
+	setPromptInput(t, "", false)
+	if _, err := PromptValue("Value: ", "default"); !errors.Is(err, ErrCannotPrompt) {
+		t.Errorf("Expected ErrCannotPrompt when stdin isn't a terminal, got %v", err)
+	}
+
+	viper.Set("non-interactive", true)
+	if value, err := PromptValue("Value: ", "default"); err != nil || value != "default" {
+		t.Errorf("Expected the default with --yes, got %q (%v)", value, err)
+	}
+	if yes, err := PromptYesNo("Continue? [Y/n] "); err != nil || !yes {
+		t.Errorf("Expected yes with --yes, got %v (%v)", yes, err)
+	}
+}
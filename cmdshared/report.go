@@ -0,0 +1,195 @@
+package cmdshared
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// reportStdout is the original stdout, so that reports can be written to it after other output is redirected to stderr
+var reportStdout = os.Stdout
+
+// ChangeReport is a machine-readable summary of the metadata files changed by a command, written with --report
+type ChangeReport struct {
+	// DryRun is true if the changes were not actually made
+	DryRun   bool         `json:"dry-run,omitempty"`
+	Added    []FileChange `json:"added"`
+	Modified []FileChange `json:"modified"`
+	Removed  []FileChange `json:"removed"`
+}
+
+// FileChange describes a single changed metadata file in a ChangeReport
+type FileChange struct {
+	// Path is the path of the metadata file (or other file, when comparing indexes), relative to the pack root
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	OldVersion string `json:"old-version,omitempty"`
+	NewVersion string `json:"new-version,omitempty"`
+	// HashFormat and Hash are the hash of the file downloaded by the metadata file, after the change
+	HashFormat string `json:"hash-format,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+	// OldHashFormat and OldHash are the hash before the change, when it is known
+	OldHashFormat string `json:"old-hash-format,omitempty"`
+	OldHash       string `json:"old-hash,omitempty"`
+}
+
+// NewFileChange creates a FileChange for the given mod, including its current hash
+func NewFileChange(index *core.Index, mod *core.Mod) FileChange {
+	path, err := index.RelIndexPath(mod.GetFilePath())
+	if err != nil {
+		path = mod.GetFilePath()
+	}
+	return FileChange{
+		Path:       path,
+		Name:       mod.Name,
+		HashFormat: mod.Download.HashFormat,
+		Hash:       mod.Download.Hash,
+	}
+}
+
+// Write writes the report as JSON to the given file, or to stdout if the path is "-"
+func (r ChangeReport) Write(path string) error {
+	// Use empty lists rather than null, so consumers don't have to check for both
+	for _, list := range []*[]FileChange{&r.Added, &r.Modified, &r.Removed} {
+		if *list == nil {
+			*list = []FileChange{}
+		}
+		slices.SortFunc(*list, func(a, b FileChange) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+	}
+	if path == "-" {
+		return WriteJSON(reportStdout, r)
+	}
+	data, err := MarshalJSON(r, jsonFormat(nil))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// WriteReport writes the report to the path given by the --report flag bound to the given key, if it was set
+func WriteReport(key string, report ChangeReport) error {
+	path := viper.GetString(key)
+	if path == "" {
+		return nil
+	}
+	if err := report.Write(path); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// RedirectOutputForReport sends all other output to stderr if the report given by the --report flag bound to the given
+// key is written to stdout, so that it can be parsed. It returns an error if the report couldn't be written in the
+// format chosen with --json-format, which should be checked before the command makes any changes.
+func RedirectOutputForReport(key string) error {
+	if viper.GetString(key) == "" {
+		return nil
+	}
+	if err := checkJSONFormat(); err != nil {
+		return err
+	}
+	if viper.GetString(key) == "-" {
+		os.Stdout = os.Stderr
+	}
+	return nil
+}
+
+// snapshotMods loads all the metadata files in the pack, keyed by their path relative to the pack root
+func snapshotMods() (map[string]*core.Mod, error) {
+	pack, err := core.LoadPack()
+	if err != nil {
+		return nil, err
+	}
+	index, err := pack.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	mods, err := index.LoadAllMods()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]*core.Mod, len(mods))
+	for _, mod := range mods {
+		path, err := index.RelIndexPath(mod.GetFilePath())
+		if err != nil {
+			return nil, err
+		}
+		snapshot[path] = mod
+	}
+	return snapshot, nil
+}
+
+// diffMods returns a report of the metadata files that were added, changed or removed between two snapshots.
+// As metadata files don't store versions in a common format, file names are used as versions.
+func diffMods(before map[string]*core.Mod, after map[string]*core.Mod) ChangeReport {
+	var report ChangeReport
+	for path, mod := range after {
+		change := FileChange{
+			Path:       path,
+			Name:       mod.Name,
+			NewVersion: mod.FileName,
+			HashFormat: mod.Download.HashFormat,
+			Hash:       mod.Download.Hash,
+		}
+		oldMod, ok := before[path]
+		if !ok {
+			report.Added = append(report.Added, change)
+			continue
+		}
+		if oldMod.FileName != mod.FileName || oldMod.Download.URL != mod.Download.URL ||
+			oldMod.Download.HashFormat != mod.Download.HashFormat || oldMod.Download.Hash != mod.Download.Hash {
+			change.OldVersion = oldMod.FileName
+			report.Modified = append(report.Modified, change)
+		}
+	}
+	for path, mod := range before {
+		if _, ok := after[path]; !ok {
+			report.Removed = append(report.Removed, FileChange{
+				Path:       path,
+				Name:       mod.Name,
+				OldVersion: mod.FileName,
+			})
+		}
+	}
+	return report
+}
+
+// AddReportFlag adds a --report flag to a command that adds files to the pack, bound to the given key, which
+// writes a report of the metadata files added or changed by the command (including dependencies)
+func AddReportFlag(cmd *cobra.Command, key string) {
+	cmd.Flags().String("report", "", "Write a JSON summary of the changed files to this file (or stdout, if \"-\", with other output sent to stderr)")
+	_ = viper.BindPFlag(key, cmd.Flags().Lookup("report"))
+
+	var before map[string]*core.Mod
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := RedirectOutputForReport(key); err != nil {
+			return err
+		}
+		if viper.GetString(key) == "" {
+			return nil
+		}
+		var err error
+		before, err = snapshotMods()
+		if err != nil {
+			return fmt.Errorf("failed to read modpack for report: %w", err)
+		}
+		return nil
+	}
+	cmd.PostRunE = func(cmd *cobra.Command, args []string) error {
+		if before == nil {
+			return nil
+		}
+		after, err := snapshotMods()
+		if err != nil {
+			return fmt.Errorf("failed to read modpack for report: %w", err)
+		}
+		return WriteReport(key, diffMods(before, after))
+	}
+}
@@ -0,0 +1,75 @@
+package cmdshared
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func reportTestMod(fileName string, hash string) *core.Mod {
+	return &core.Mod{
+		Name:     "Test",
+		FileName: fileName,
+		Download: core.ModDownload{URL: "https://example.com/" + fileName, HashFormat: "sha1", Hash: hash},
+	}
+}
+
+func TestChangeReport(t *testing.T) {
+	before := map[string]*core.Mod{
+		"mods/kept.pw.toml":    reportTestMod("kept-1.0.jar", "aaa"),
+		"mods/updated.pw.toml": reportTestMod("updated-1.0.jar", "bbb"),
+		"mods/removed.pw.toml": reportTestMod("removed-1.0.jar", "ccc"),
+	}
+	after := map[string]*core.Mod{
+		"mods/kept.pw.toml":    reportTestMod("kept-1.0.jar", "aaa"),
+		"mods/updated.pw.toml": reportTestMod("updated-1.1.jar", "ddd"),
+		"mods/added.pw.toml":   reportTestMod("added-1.0.jar", "eee"),
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := diffMods(before, after).Write(path); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report ChangeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+
+	if len(report.Added) != 1 || report.Added[0] != (FileChange{
+		Path: "mods/added.pw.toml", Name: "Test", NewVersion: "added-1.0.jar", HashFormat: "sha1", Hash: "eee",
+	}) {
+		t.Errorf("Unexpected added files: %v", report.Added)
+	}
+	if len(report.Modified) != 1 || report.Modified[0] != (FileChange{
+		Path: "mods/updated.pw.toml", Name: "Test", OldVersion: "updated-1.0.jar", NewVersion: "updated-1.1.jar", HashFormat: "sha1", Hash: "ddd",
+	}) {
+		t.Errorf("Unexpected modified files: %v", report.Modified)
+	}
+	if len(report.Removed) != 1 || report.Removed[0] != (FileChange{
+		Path: "mods/removed.pw.toml", Name: "Test", OldVersion: "removed-1.0.jar",
+	}) {
+		t.Errorf("Unexpected removed files: %v", report.Removed)
+	}
+}
+
+func TestEmptyChangeReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := (ChangeReport{DryRun: true}).Write(path); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\n\t\"dry-run\": true,\n\t\"added\": [],\n\t\"modified\": [],\n\t\"removed\": []\n}\n"
+	if string(data) != expected {
+		t.Errorf("Expected empty lists in report, got %s", data)
+	}
+}
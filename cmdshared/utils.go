@@ -14,3 +14,12 @@ func GetRawForgeVersion(version string) string {
 	}
 	return wantedVersion
 }
+
+// GetRawLoaderVersion strips the Minecraft version prefix from a loader version, for loaders that use the
+// mcVersion-loaderVersion format (Forge, and NeoForge for 1.20.1 only - newer NeoForge versions can contain a "-beta" suffix)
+func GetRawLoaderVersion(loader string, mcVersion string, version string) string {
+	if loader == "forge" || (loader == "neoforge" && mcVersion == "1.20.1") {
+		return GetRawForgeVersion(version)
+	}
+	return version
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic writes a file by calling write with a temporary file in the same directory, which is then renamed
+// over the destination. If packwiz is interrupted or write returns an error, the existing file is left intact.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := f.Name()
+	cleanup := func(err error) error {
+		_ = f.Close()
+		_ = os.Remove(tempPath)
+		return err
+	}
+
+	// Keep the permissions of the existing file; temporary files are only readable by the current user
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := f.Chmod(mode); err != nil {
+		return cleanup(err)
+	}
+
+	w := bufio.NewWriter(f)
+	if err := write(w); err != nil {
+		return cleanup(err)
+	}
+	if err := w.Flush(); err != nil {
+		return cleanup(err)
+	}
+	if err := f.Sync(); err != nil {
+		return cleanup(err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	if err := renameFile(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
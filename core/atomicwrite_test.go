@@ -0,0 +1,90 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// assertFileIntact checks that a file has the given contents, and that no temporary files were left next to it
+func assertFileIntact(t *testing.T, path string, contents string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != contents {
+		t.Errorf("Expected %s to be left intact, got %q", filepath.Base(path), data)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".tmp" {
+			t.Errorf("Expected temporary file %s to be removed", entry.Name())
+		}
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pack.toml")
+	if err := os.WriteFile(path, []byte("original"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	errWrite := errors.New("write failed")
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return errWrite
+	})
+	if !errors.Is(err, errWrite) {
+		t.Errorf("Expected the write error to be returned, got %v", err)
+	}
+	assertFileIntact(t, path, "original")
+
+	err = writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write([]byte("replaced"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	assertFileIntact(t, path, "replaced")
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected file permissions to be kept, got %v (%v)", info.Mode(), err)
+	}
+}
+
+func TestWriteFailuresKeepFiles(t *testing.T) {
+	dir := t.TempDir()
+	packPath := filepath.Join(dir, "pack.toml")
+	const packContents = "name = \"test\"\n"
+	if err := os.WriteFile(packPath, []byte(packContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", packPath)
+	t.Cleanup(func() { viper.Set("pack-file", oldPackFile) })
+
+	// Channels can't be encoded, so encoding fails after some of the file has been written
+	pack := Pack{Name: "broken", Options: map[string]interface{}{"broken": make(chan int)}}
+	if err := pack.Write(); err == nil {
+		t.Error("Expected writing the pack to fail")
+	}
+	assertFileIntact(t, packPath, packContents)
+
+	modPath := filepath.Join(dir, "mods", "a.pw.toml")
+	mod := Mod{Name: "A", Update: map[string]map[string]interface{}{"broken": {"value": make(chan int)}}}
+	mod.SetMetaPath(modPath)
+	if _, _, err := mod.Write(); err == nil {
+		t.Error("Expected writing the metadata file to fail")
+	}
+	if _, err := os.Stat(modPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no metadata file to be created, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+// Package blake3 implements the BLAKE3 hash function with the default 32 byte output, as specified in
+// https://github.com/BLAKE3-team/BLAKE3-specs. Only unkeyed hashing is supported.
+package blake3
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// Size is the size of a BLAKE3 hash in bytes
+const Size = 32
+
+const (
+	blockLen = 64
+	chunkLen = 1024
+
+	flagChunkStart = 1 << 0
+	flagChunkEnd   = 1 << 1
+	flagParent     = 1 << 2
+	flagRoot       = 1 << 3
+)
+
+var iv = [8]uint32{
+	0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A, 0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19,
+}
+
+var msgPermutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+func g(state *[16]uint32, a, b, c, d int, mx, my uint32) {
+	state[a] = state[a] + state[b] + mx
+	state[d] = bits.RotateLeft32(state[d]^state[a], -16)
+	state[c] = state[c] + state[d]
+	state[b] = bits.RotateLeft32(state[b]^state[c], -12)
+	state[a] = state[a] + state[b] + my
+	state[d] = bits.RotateLeft32(state[d]^state[a], -8)
+	state[c] = state[c] + state[d]
+	state[b] = bits.RotateLeft32(state[b]^state[c], -7)
+}
+
+func round(state *[16]uint32, m *[16]uint32) {
+	// Mix the columns
+	g(state, 0, 4, 8, 12, m[0], m[1])
+	g(state, 1, 5, 9, 13, m[2], m[3])
+	g(state, 2, 6, 10, 14, m[4], m[5])
+	g(state, 3, 7, 11, 15, m[6], m[7])
+	// Mix the diagonals
+	g(state, 0, 5, 10, 15, m[8], m[9])
+	g(state, 1, 6, 11, 12, m[10], m[11])
+	g(state, 2, 7, 8, 13, m[12], m[13])
+	g(state, 3, 4, 9, 14, m[14], m[15])
+}
+
+func permute(m *[16]uint32) {
+	var permuted [16]uint32
+	for i, j := range msgPermutation {
+		permuted[i] = m[j]
+	}
+	*m = permuted
+}
+
+func compress(cv *[8]uint32, block *[16]uint32, counter uint64, blockLen uint32, flags uint32) [16]uint32 {
+	state := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		iv[0], iv[1], iv[2], iv[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	m := *block
+	for i := 0; i < 7; i++ {
+		round(&state, &m)
+		if i < 6 {
+			permute(&m)
+		}
+	}
+	for i := 0; i < 8; i++ {
+		state[i] ^= state[i+8]
+		state[i+8] ^= cv[i]
+	}
+	return state
+}
+
+func wordsFromBytes(b []byte) (words [16]uint32) {
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	return
+}
+
+func firstEight(state [16]uint32) (cv [8]uint32) {
+	copy(cv[:], state[:8])
+	return
+}
+
+// output is the state needed to produce either the chaining value of a node, or the root hash
+type output struct {
+	inputCV  [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+func (o output) chainingValue() [8]uint32 {
+	return firstEight(compress(&o.inputCV, &o.block, o.counter, o.blockLen, o.flags))
+}
+
+func (o output) rootHash() []byte {
+	// The root hash is the first block of output, with a counter of zero
+	words := compress(&o.inputCV, &o.block, 0, o.blockLen, o.flags|flagRoot)
+	out := make([]byte, Size)
+	for i := 0; i < Size/4; i++ {
+		binary.LittleEndian.PutUint32(out[i*4:], words[i])
+	}
+	return out
+}
+
+func parentOutput(left [8]uint32, right [8]uint32) output {
+	var block [16]uint32
+	copy(block[:8], left[:])
+	copy(block[8:], right[:])
+	return output{inputCV: iv, block: block, blockLen: blockLen, flags: flagParent}
+}
+
+type chunkState struct {
+	cv               [8]uint32
+	chunkCounter     uint64
+	block            [blockLen]byte
+	blockLen         int
+	blocksCompressed int
+}
+
+func newChunkState(chunkCounter uint64) chunkState {
+	return chunkState{cv: iv, chunkCounter: chunkCounter}
+}
+
+func (c *chunkState) len() int {
+	return blockLen*c.blocksCompressed + c.blockLen
+}
+
+func (c *chunkState) startFlag() uint32 {
+	if c.blocksCompressed == 0 {
+		return flagChunkStart
+	}
+	return 0
+}
+
+func (c *chunkState) update(input []byte) {
+	for len(input) > 0 {
+		// If the block buffer is full, compress it; the last block is kept for the output, as it has different flags
+		if c.blockLen == blockLen {
+			words := wordsFromBytes(c.block[:])
+			c.cv = firstEight(compress(&c.cv, &words, c.chunkCounter, blockLen, c.startFlag()))
+			c.blocksCompressed++
+			c.block = [blockLen]byte{}
+			c.blockLen = 0
+		}
+		n := copy(c.block[c.blockLen:], input)
+		c.blockLen += n
+		input = input[n:]
+	}
+}
+
+func (c *chunkState) output() output {
+	return output{
+		inputCV:  c.cv,
+		block:    wordsFromBytes(c.block[:]),
+		counter:  c.chunkCounter,
+		blockLen: uint32(c.blockLen),
+		flags:    c.startFlag() | flagChunkEnd,
+	}
+}
+
+// digest is an incremental BLAKE3 hasher
+type digest struct {
+	chunk chunkState
+	// cvStack holds the chaining values of completed subtrees; 54 levels is enough for 2^64 bytes of input
+	cvStack [54][8]uint32
+	cvLen   int
+}
+
+// New returns a new hash.Hash computing the BLAKE3 hash of its input
+func New() hash.Hash {
+	d := &digest{}
+	d.Reset()
+	return d
+}
+
+func (d *digest) addChunkChainingValue(cv [8]uint32, totalChunks uint64) {
+	// Merge completed subtrees: each trailing zero bit of the chunk count means a subtree is complete
+	for totalChunks&1 == 0 {
+		d.cvLen--
+		cv = parentOutput(d.cvStack[d.cvLen], cv).chainingValue()
+		totalChunks >>= 1
+	}
+	d.cvStack[d.cvLen] = cv
+	d.cvLen++
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// If the chunk is full, finish it; the last chunk is kept for the output, as it may be the root
+		if d.chunk.len() == chunkLen {
+			cv := d.chunk.output().chainingValue()
+			totalChunks := d.chunk.chunkCounter + 1
+			d.addChunkChainingValue(cv, totalChunks)
+			d.chunk = newChunkState(totalChunks)
+		}
+		take := min(chunkLen-d.chunk.len(), len(p))
+		d.chunk.update(p[:take])
+		p = p[take:]
+	}
+	return n, nil
+}
+
+func (d *digest) Sum(b []byte) []byte {
+	out := d.chunk.output()
+	for i := d.cvLen - 1; i >= 0; i-- {
+		out = parentOutput(d.cvStack[i], out.chainingValue())
+	}
+	return append(b, out.rootHash()...)
+}
+
+func (d *digest) Reset() {
+	d.chunk = newChunkState(0)
+	d.cvLen = 0
+}
+
+func (d *digest) Size() int {
+	return Size
+}
+
+func (d *digest) BlockSize() int {
+	return blockLen
+}
@@ -0,0 +1,59 @@
+package blake3
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// testInput returns the input used by the official BLAKE3 test vectors: a repeating sequence of the bytes 0 to 250
+func testInput(n int) []byte {
+	in := make([]byte, n)
+	for i := range in {
+		in[i] = byte(i % 251)
+	}
+	return in
+}
+
+func TestVectors(t *testing.T) {
+	// From https://github.com/BLAKE3-team/BLAKE3/blob/master/test_vectors/test_vectors.json
+	vectors := map[int]string{
+		0:      "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262",
+		1:      "2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213",
+		1023:   "10108970eeda3eb932baac1428c7a2163b0e924c9a9e25b35bba72b28f70bd11",
+		1024:   "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7",
+		1025:   "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444",
+		2048:   "e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a",
+		102400: "bc3e3d41a1146b069abffad3c0d44860cf664390afce4d9661f7902e7943e085",
+	}
+	for n, expected := range vectors {
+		h := New()
+		_, _ = h.Write(testInput(n))
+		if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+			t.Errorf("Expected hash of %d bytes to be %s, got %s", n, expected, actual)
+		}
+	}
+}
+
+func TestIncrementalWrites(t *testing.T) {
+	in := testInput(10000)
+	whole := New()
+	_, _ = whole.Write(in)
+	expected := whole.Sum(nil)
+
+	h := New()
+	for len(in) > 0 {
+		n := min(37, len(in))
+		_, _ = h.Write(in[:n])
+		in = in[n:]
+		// Sum shouldn't change the state of the hash
+		h.Sum(nil)
+	}
+	if actual := h.Sum(nil); string(actual) != string(expected) {
+		t.Errorf("Expected incremental writes to give %x, got %x", expected, actual)
+	}
+
+	h.Reset()
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262" {
+		t.Errorf("Expected reset hash to be the hash of no input, got %s", actual)
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// IndexDuplicate describes entries in the index file that have the same path (and alias), which were merged into one
+type IndexDuplicate struct {
+	Path  string
+	Alias string
+	// Count is the number of entries that were merged
+	Count int
+	// Kept is the hash of the entry that was kept
+	Kept string
+	// Ambiguous is true if no entry matched the hash of the file on disk, so the first entry was kept
+	Ambiguous bool
+}
+
+// MergeDuplicates reads the index file again, merging entries with the same path and alias (e.g. left by a bad
+// merge), which are otherwise silently overwritten when the index is loaded. The entry whose hash matches the file on
+// disk is kept; if none match, the first entry is kept. The files of the index are replaced with the merged entries,
+// which must then be written with Write.
+func (in *Index) MergeDuplicates() ([]IndexDuplicate, error) {
+	data, err := os.ReadFile(in.indexFile)
+	if err != nil {
+		return nil, err
+	}
+	var rep indexTomlRepresentation
+	if _, err := toml.Decode(string(data), &rep); err != nil {
+		return nil, err
+	}
+
+	type entryKey struct {
+		file  string
+		alias string
+	}
+	key := func(entry indexFile) entryKey {
+		alias := path.Clean(entry.Alias)
+		if alias == "." {
+			alias = ""
+		}
+		return entryKey{path.Clean(entry.File), alias}
+	}
+	groups := make(map[entryKey][]indexFile)
+	var order []entryKey
+	for _, entry := range rep.Files {
+		k := key(entry)
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], entry)
+	}
+
+	var duplicates []IndexDuplicate
+	merged := make(indexFilesTomlRepresentation, 0, len(order))
+	for _, k := range order {
+		entries := groups[k]
+		if len(entries) == 1 {
+			merged = append(merged, entries[0])
+			continue
+		}
+		kept, ambiguous := entries[0], true
+		for _, entry := range entries {
+			format := entry.HashFormat
+			if format == "" {
+				format = in.HashFormat
+			}
+			hash, err := hashFile(in.ResolveIndexPath(k.file), format)
+			if err == nil && strings.EqualFold(hash, entry.Hash) {
+				kept, ambiguous = entry, false
+				break
+			}
+		}
+		merged = append(merged, kept)
+		duplicates = append(duplicates, IndexDuplicate{
+			Path:      k.file,
+			Alias:     k.alias,
+			Count:     len(entries),
+			Kept:      kept.Hash,
+			Ambiguous: ambiguous,
+		})
+	}
+
+	in.Files = merged.toMemoryRep()
+	return duplicates, nil
+}
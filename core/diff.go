@@ -0,0 +1,83 @@
+package core
+
+import (
+	"slices"
+	"strings"
+)
+
+// The types of change in an IndexChange
+const (
+	FileAdded   = "added"
+	FileRemoved = "removed"
+	FileUpdated = "updated"
+)
+
+// IndexChange describes a file that differs between two versions of an index
+type IndexChange struct {
+	// Path is the path of the file, relative to the pack root
+	Path     string
+	Type     string
+	MetaFile bool
+	// The hashes of the file in each version of the index; the old hash is empty for added files, and the new hash
+	// is empty for removed files
+	OldHashFormat string
+	OldHash       string
+	NewHashFormat string
+	NewHash       string
+}
+
+// indexEntryHash returns the hash format and hash of a file in the index
+func indexEntryHash(holder IndexPathHolder, defaultFormat string) (string, string) {
+	var entry indexFile
+	if file, ok := holder.(*indexFile); ok {
+		entry = *file
+	} else if file, ok := holder.(*indexFileMultipleAlias); ok {
+		// Aliased entries point to the same file, so they have the same hash
+		aliases := make([]string, 0, len(*file))
+		for alias := range *file {
+			aliases = append(aliases, alias)
+		}
+		slices.Sort(aliases)
+		entry = (*file)[aliases[0]]
+	} else {
+		panic("Unknown type in IndexFiles")
+	}
+	if entry.HashFormat == "" {
+		return defaultFormat, entry.Hash
+	}
+	return entry.HashFormat, entry.Hash
+}
+
+// DiffIndex returns the files that were added, removed or changed between two versions of an index, sorted by path.
+// Files whose hash is in a different format in each version are treated as changed.
+func DiffIndex(oldIndex Index, newIndex Index) []IndexChange {
+	var changes []IndexChange
+	for p, newHolder := range newIndex.Files {
+		change := IndexChange{Path: p, MetaFile: newHolder.IsMetaFile()}
+		change.NewHashFormat, change.NewHash = indexEntryHash(newHolder, newIndex.HashFormat)
+		oldHolder, ok := oldIndex.Files[p]
+		if !ok {
+			change.Type = FileAdded
+			changes = append(changes, change)
+			continue
+		}
+		change.OldHashFormat, change.OldHash = indexEntryHash(oldHolder, oldIndex.HashFormat)
+		if change.OldHashFormat != change.NewHashFormat || !strings.EqualFold(change.OldHash, change.NewHash) {
+			change.Type = FileUpdated
+			changes = append(changes, change)
+		}
+	}
+	for p, oldHolder := range oldIndex.Files {
+		if _, ok := newIndex.Files[p]; ok {
+			continue
+		}
+		change := IndexChange{Path: p, Type: FileRemoved, MetaFile: oldHolder.IsMetaFile()}
+		change.OldHashFormat, change.OldHash = indexEntryHash(oldHolder, oldIndex.HashFormat)
+		changes = append(changes, change)
+	}
+
+	slices.SortFunc(changes, func(a, b IndexChange) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return changes
+}
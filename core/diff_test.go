@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestDiffIndex(t *testing.T) {
+	oldIndex, err := ParseIndex([]byte(`hash-format = "sha256"
+
+[[files]]
+file = "config/same.txt"
+hash = "aaa"
+
+[[files]]
+file = "config/changed.txt"
+hash = "bbb"
+
+[[files]]
+file = "mods/removed.pw.toml"
+hash = "ccc"
+metafile = true
+`), "old/index.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newIndex, err := ParseIndex([]byte(`hash-format = "sha256"
+
+[[files]]
+file = "config/same.txt"
+hash = "AAA"
+
+[[files]]
+file = "config/changed.txt"
+hash = "ddd"
+
+[[files]]
+file = "mods/added.pw.toml"
+hash = "eee"
+metafile = true
+`), "new/index.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := DiffIndex(oldIndex, newIndex)
+	expected := []IndexChange{
+		{Path: "config/changed.txt", Type: FileUpdated, OldHashFormat: "sha256", OldHash: "bbb", NewHashFormat: "sha256", NewHash: "ddd"},
+		{Path: "mods/added.pw.toml", Type: FileAdded, MetaFile: true, NewHashFormat: "sha256", NewHash: "eee"},
+		{Path: "mods/removed.pw.toml", Type: FileRemoved, MetaFile: true, OldHashFormat: "sha256", OldHash: "ccc"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %v", len(expected), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Expected change %v, got %v", expected[i], changes[i])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,20 +10,34 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"slices"
+
+	"github.com/spf13/viper"
 )
 
-const UserAgent = "packwiz/packwiz"
+// ErrOffline is returned instead of making a network request when offline mode is enabled
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+// ErrHashMismatch is matched (with errors.Is) by errors returned when a downloaded file doesn't match its expected hash
+var ErrHashMismatch = errors.New("hash mismatch")
+
+// IsOffline returns true if network access is disabled, so files can only be read from the download cache
+func IsOffline() bool {
+	return viper.GetBool("offline")
+}
 
 func GetWithUA(url string, contentType string) (resp *http.Response, err error) {
+	if IsOffline() {
+		return nil, fmt.Errorf("failed to request %s: %w", url, ErrOffline)
+	}
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", UserAgent)
 	req.Header.Set("Accept", contentType)
-	return http.DefaultClient.Do(req)
+	return defaultHTTPClient.Do(req)
 }
 
 const DownloadCacheImportFolder = "import"
@@ -30,6 +45,12 @@ const DownloadCacheImportFolder = "import"
 type DownloadSession interface {
 	GetManualDownloads() []ManualDownload
 	StartDownloads() chan CompletedDownload
+	// StartDownloadsContext is like StartDownloads, but stops downloading when ctx is done and reports the progress of
+	// each file that is downloaded to the ProgressFunc returned by progress, which may be nil or return nil
+	StartDownloadsContext(ctx context.Context, progress func(mod *Mod) ProgressFunc) chan CompletedDownload
+	// StartDownloadsParallel is like StartDownloadsContext, but downloads up to jobs files at a time; progress is
+	// called from several goroutines
+	StartDownloadsParallel(ctx context.Context, progress func(mod *Mod) ProgressFunc, jobs int) chan CompletedDownload
 	SaveIndex() error
 }
 
@@ -43,6 +64,11 @@ type CompletedDownload struct {
 	Error error
 	// Warnings indicates messages to show to the user regarding this file (download was successful, but had a problem)
 	Warnings []error
+	// FromCache is true if the file was already in the cache, so it didn't need to be downloaded
+	FromCache bool
+	// Manual is set if packwiz can't download the file (e.g. as its author has disabled third-party downloads), so it
+	// was downloaded manually; the file may not be allowed to be redistributed
+	Manual *ManualDownload
 }
 
 type downloadSessionInternal struct {
@@ -52,12 +78,15 @@ type downloadSessionInternal struct {
 	manualDownloads      []ManualDownload
 	downloadTasks        []downloadTask
 	foundManualDownloads []CompletedDownload
+	// mu guards cacheIndex, which is shared by the workers downloading files
+	mu sync.Mutex
 }
 
 type downloadTask struct {
 	metaDownloaderData MetaDownloaderData
 	mod                *Mod
 	url                string
+	mirrors            []string
 	hashFormat         string
 	hash               string
 }
@@ -67,75 +96,139 @@ func (d *downloadSessionInternal) GetManualDownloads() []ManualDownload {
 }
 
 func (d *downloadSessionInternal) StartDownloads() chan CompletedDownload {
+	return d.StartDownloadsContext(context.Background(), nil)
+}
+
+func (d *downloadSessionInternal) StartDownloadsContext(ctx context.Context, progress func(mod *Mod) ProgressFunc) chan CompletedDownload {
+	return d.StartDownloadsParallel(ctx, progress, 1)
+}
+
+func (d *downloadSessionInternal) StartDownloadsParallel(ctx context.Context, progress func(mod *Mod) ProgressFunc, jobs int) chan CompletedDownload {
+	// Tasks for the same file are run by the same worker, one after another, so the file is only downloaded once
+	var groups [][]*downloadTask
+	groupIndex := make(map[string]int)
+	for i := range d.downloadTasks {
+		task := &d.downloadTasks[i]
+		key := task.hashFormat + ":" + strings.ToLower(task.hash)
+		if g, ok := groupIndex[key]; ok {
+			groups[g] = append(groups[g], task)
+		} else {
+			groupIndex[key] = len(groups)
+			groups = append(groups, []*downloadTask{task})
+		}
+	}
+
 	downloads := make(chan CompletedDownload)
+	pending := make(chan []*downloadTask)
 	go func() {
 		for _, found := range d.foundManualDownloads {
 			downloads <- found
 		}
-		for _, task := range d.downloadTasks {
-			warnings := make([]error, 0)
-
-			// Get handle for mod
-			cacheHandle := d.cacheIndex.GetHandleFromHash(task.hashFormat, task.hash)
-			if cacheHandle != nil {
-				download, err := reuseExistingFile(cacheHandle, d.hashesToObtain, task.mod)
-				if err != nil {
-					// Remove handle and try again
-					cacheHandle.Remove()
-					cacheHandle = nil
-					warnings = append(warnings, fmt.Errorf("redownloading cached file: %w", err))
-				} else {
-					downloads <- download
-					continue
-				}
-			}
-
-			download, err := downloadNewFile(&task, d.cacheFolder, d.hashesToObtain, &d.cacheIndex)
-			if err != nil {
-				downloads <- CompletedDownload{
-					Error: err,
-					Mod:   task.mod,
+		var wg sync.WaitGroup
+		for range min(max(jobs, 1), max(len(groups), 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for group := range pending {
+					for _, task := range group {
+						downloads <- d.runTask(ctx, task, progress)
+					}
 				}
-			} else {
-				download.Warnings = warnings
-				downloads <- download
-			}
+			}()
+		}
+		for _, group := range groups {
+			pending <- group
 		}
+		close(pending)
+		wg.Wait()
 		close(downloads)
 	}()
 	return downloads
 }
 
+// runTask gets a file from the cache, or downloads it if it isn't in the cache or the cached file is corrupted
+func (d *downloadSessionInternal) runTask(ctx context.Context, task *downloadTask, progress func(mod *Mod) ProgressFunc) CompletedDownload {
+	if err := ctx.Err(); err != nil {
+		return CompletedDownload{
+			Error: err,
+			Mod:   task.mod,
+		}
+	}
+	warnings := make([]error, 0)
+
+	// Get handle for mod
+	d.mu.Lock()
+	cacheHandle := d.cacheIndex.GetHandleFromHash(task.hashFormat, task.hash)
+	d.mu.Unlock()
+	if cacheHandle != nil {
+		download, err := reuseExistingFile(cacheHandle, d.hashesToObtain, task.mod, &d.mu)
+		if err != nil {
+			// Remove handle and try again
+			d.mu.Lock()
+			cacheHandle.Remove()
+			d.mu.Unlock()
+			warnings = append(warnings, fmt.Errorf("redownloading cached file: %w", err))
+		} else {
+			download.FromCache = true
+			return download
+		}
+	}
+
+	var taskProgress ProgressFunc
+	if progress != nil {
+		taskProgress = progress(task.mod)
+	}
+	download, err := downloadNewFile(ctx, task, d.cacheFolder, d.hashesToObtain, &d.cacheIndex, &d.mu, taskProgress)
+	if err != nil {
+		return CompletedDownload{
+			Error: err,
+			Mod:   task.mod,
+		}
+	}
+	download.Warnings = warnings
+	return download
+}
+
 func (d *downloadSessionInternal) SaveIndex() error {
-	data, err := json.Marshal(d.cacheIndex)
+	return d.cacheIndex.save()
+}
+
+// save writes the cache index to the cache folder
+func (c *CacheIndex) save() error {
+	data, err := json.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("failed to serialise index: %w", err)
 	}
-	err = os.WriteFile(filepath.Join(d.cacheFolder, "index.json"), data, 0644)
+	err = os.WriteFile(filepath.Join(c.cachePath, "index.json"), data, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write index: %w", err)
 	}
 	return nil
 }
 
-func reuseExistingFile(cacheHandle *CacheIndexHandle, hashesToObtain []string, mod *Mod) (CompletedDownload, error) {
+// reuseExistingFile opens a file from the cache, validating it against the stored hashes and calculating any missing
+// hashes; the cache index is only updated while holding lock
+func reuseExistingFile(cacheHandle *CacheIndexHandle, hashesToObtain []string, mod *Mod, lock sync.Locker) (CompletedDownload, error) {
 	// Already stored; try using it!
 	file, err := cacheHandle.Open()
 	if err == nil {
+		// Always read the file, to validate it against the stored hashes so that corrupted files aren't used
 		remainingHashes := cacheHandle.GetRemainingHashes(hashesToObtain)
 		var warnings []error
+		err = teeHashes(remainingHashes, cacheHandle.Hashes, io.Discard, file)
+		if err != nil {
+			_ = file.Close()
+			return CompletedDownload{}, fmt.Errorf("failed to read hashes of file %s from cache: %w", cacheHandle.Path(), err)
+		}
+		_, err = file.Seek(0, 0)
+		if err != nil {
+			_ = file.Close()
+			return CompletedDownload{}, fmt.Errorf("failed to seek file %s in cache: %w", cacheHandle.Path(), err)
+		}
 		if len(remainingHashes) > 0 {
-			err = teeHashes(remainingHashes, cacheHandle.Hashes, io.Discard, file)
-			if err != nil {
-				_ = file.Close()
-				return CompletedDownload{}, fmt.Errorf("failed to read hashes of file %s from cache: %w", cacheHandle.Path(), err)
-			}
-			_, err := file.Seek(0, 0)
-			if err != nil {
-				_ = file.Close()
-				return CompletedDownload{}, fmt.Errorf("failed to seek file %s in cache: %w", cacheHandle.Path(), err)
-			}
+			lock.Lock()
 			warnings = cacheHandle.UpdateIndex()
+			lock.Unlock()
 		}
 
 		return CompletedDownload{
@@ -149,44 +242,57 @@ func reuseExistingFile(cacheHandle *CacheIndexHandle, hashesToObtain []string, m
 	}
 }
 
-func downloadNewFile(task *downloadTask, cacheFolder string, hashesToObtain []string, index *CacheIndex) (CompletedDownload, error) {
-	// Create temp file to download to
-	tempFile, err := os.CreateTemp(filepath.Join(cacheFolder, "temp"), "download-tmp")
+func downloadNewFile(ctx context.Context, task *downloadTask, cacheFolder string, hashesToObtain []string, index *CacheIndex, lock sync.Locker, progress ProgressFunc) (CompletedDownload, error) {
+	if IsOffline() {
+		return CompletedDownload{}, fmt.Errorf("%s isn't in the download cache: %w", task.mod.Name, ErrOffline)
+	}
+
+	// Create temp file to download to; files downloaded from a URL may already be partially downloaded
+	tempFile, err := openPartialDownload(cacheFolder, task)
 	if err != nil {
 		return CompletedDownload{}, fmt.Errorf("failed to create temporary file for download: %w", err)
 	}
 
 	hashesToObtain, hashes := getHashListsForDownload(hashesToObtain, task.hashFormat, task.hash)
-	if len(hashesToObtain) > 0 {
-		var data io.ReadCloser
-		if task.url != "" {
-			resp, err := GetWithUA(task.url, "application/octet-stream")
-			if err != nil {
-				return CompletedDownload{}, fmt.Errorf("failed to download %s: %w", task.url, err)
-			}
-			if resp.StatusCode != 200 {
-				_ = resp.Body.Close()
-				return CompletedDownload{}, fmt.Errorf("failed to download %s: invalid status code %v", task.url, resp.StatusCode)
-			}
-			data = resp.Body
-		} else {
-			data, err = task.metaDownloaderData.DownloadFile()
-			if err != nil {
-				return CompletedDownload{}, err
+	if task.url != "" {
+		err = downloadURLResumable(ctx, task.url, hashesToObtain, hashes, tempFile, progress)
+		// Try each mirror in order, until one provides a file with the expected hash; mirrors serve the same file, so
+		// data downloaded from another mirror can be resumed
+		for _, mirror := range task.mirrors {
+			if err == nil {
+				break
 			}
+			// Sent to stderr, so it doesn't get mixed up with output for scripts
+			_, _ = fmt.Fprintf(os.Stderr, "Download of %s failed, trying mirror %s: %v\n", task.mod.Name, mirror, err)
+			err = downloadURLResumable(ctx, mirror, hashesToObtain, hashes, tempFile, progress)
+		}
+		if err != nil {
+			closePartialDownload(tempFile)
+			return CompletedDownload{}, err
+		}
+	} else {
+		data, err := task.metaDownloaderData.DownloadFile()
+		if err != nil {
+			closePartialDownload(tempFile)
+			return CompletedDownload{}, err
 		}
 
+		// Metadata downloaders don't report the size of the file
+		data = newProgressReader(data, -1, progress)
 		err = teeHashes(hashesToObtain, hashes, tempFile, data)
 		_ = data.Close()
 		if err != nil {
+			closePartialDownload(tempFile)
 			return CompletedDownload{}, fmt.Errorf("failed to download: %w", err)
 		}
 	}
 
 	// Create handle with calculated hashes
+	lock.Lock()
 	cacheHandle, alreadyExists := index.NewHandleFromHashes(hashes)
 	// Update index stored hashes
 	warnings := cacheHandle.UpdateIndex()
+	lock.Unlock()
 
 	var file *os.File
 	if alreadyExists {
@@ -194,6 +300,7 @@ func downloadNewFile(task *downloadTask, cacheFolder string, hashesToObtain []st
 		if err != nil {
 			return CompletedDownload{}, fmt.Errorf("failed to close temporary file %s: %w", tempFile.Name(), err)
 		}
+		_ = os.Remove(tempFile.Name())
 		file, err = cacheHandle.Open()
 		if err != nil {
 			return CompletedDownload{}, fmt.Errorf("failed to read file %s from cache: %w", cacheHandle.Path(), err)
@@ -215,6 +322,15 @@ func downloadNewFile(task *downloadTask, cacheFolder string, hashesToObtain []st
 	}, nil
 }
 
+// resetFile truncates a file and seeks to the start, so it can be written again
+func resetFile(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
 func selectPreferredHash(hashes map[string]string) (currHashFormat string, currHash string) {
 	for _, hashFormat := range preferredHashList {
 		if hash, ok := hashes[hashFormat]; ok {
@@ -278,9 +394,7 @@ func teeHashes(hashesToObtain []string, hashes map[string]string,
 
 	// Check if the hash of the downloaded file matches the expected hash
 	if strings.ToLower(calculatedHash) != strings.ToLower(validateHash) {
-		return fmt.Errorf(
-			"%s hash of downloaded file does not match with expected hash!\n download hash: %s\n expected hash: %s\n",
-			validateHashFormat, calculatedHash, validateHash)
+		return &hashMismatchError{format: validateHashFormat, calculated: calculatedHash, expected: validateHash}
 	}
 
 	for hashFormat, v := range hashers {
@@ -290,6 +404,22 @@ func teeHashes(hashesToObtain []string, hashes map[string]string,
 	return nil
 }
 
+// hashMismatchError is returned when a downloaded file doesn't match its expected hash
+type hashMismatchError struct {
+	format     string
+	calculated string
+	expected   string
+}
+
+func (e *hashMismatchError) Is(target error) bool {
+	return target == ErrHashMismatch
+}
+
+func (e *hashMismatchError) Error() string {
+	return fmt.Sprintf("%s hash of downloaded file does not match with expected hash!\n download hash: %s\n expected hash: %s\n",
+		e.format, e.calculated, e.expected)
+}
+
 const cacheHashFormat = "sha256"
 
 type CacheIndex struct {
@@ -584,33 +714,34 @@ func removeEmpty(hashList []string) ([]string, []int) {
 	return hashList[:i], indices
 }
 
-func CreateDownloadSession(mods []*Mod, hashesToObtain []string) (DownloadSession, error) {
-	// Load cache index
+// loadCacheIndex reads the index of the download cache, creating the cache folders and moving files from the import
+// folder into the cache
+func loadCacheIndex() (CacheIndex, error) {
 	cacheIndex := CacheIndex{Version: 1, Hashes: make(map[string][]string)}
 	cachePath, err := GetPackwizCache()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load cache: %w", err)
+		return CacheIndex{}, fmt.Errorf("failed to load cache: %w", err)
 	}
 	err = os.MkdirAll(cachePath, 0755)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		return CacheIndex{}, fmt.Errorf("failed to create cache directory: %w", err)
 	}
 	err = os.MkdirAll(filepath.Join(cachePath, "temp"), 0755)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cache temp directory: %w", err)
+		return CacheIndex{}, fmt.Errorf("failed to create cache temp directory: %w", err)
 	}
 	cacheIndexData, err := os.ReadFile(filepath.Join(cachePath, "index.json"))
 	if err != nil {
 		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("failed to read cache index file: %w", err)
+			return CacheIndex{}, fmt.Errorf("failed to read cache index file: %w", err)
 		}
 	} else {
 		err = json.Unmarshal(cacheIndexData, &cacheIndex)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read cache index file: %w", err)
+			return CacheIndex{}, fmt.Errorf("failed to read cache index file: %w", err)
 		}
 		if cacheIndex.Version > 1 {
-			return nil, fmt.Errorf("cache index is too new (version %v)", cacheIndex.Version)
+			return CacheIndex{}, fmt.Errorf("cache index is too new (version %v)", cacheIndex.Version)
 		}
 	}
 
@@ -637,13 +768,22 @@ func CreateDownloadSession(mods []*Mod, hashesToObtain []string) (DownloadSessio
 	// Create import folder
 	err = os.MkdirAll(filepath.Join(cachePath, DownloadCacheImportFolder), 0755)
 	if err != nil {
-		return nil, fmt.Errorf("error creating cache import folder: %w", err)
+		return CacheIndex{}, fmt.Errorf("error creating cache import folder: %w", err)
 	}
 	// Move import files
 	err = cacheIndex.MoveImportFiles()
 	if err != nil {
-		return nil, fmt.Errorf("error updating cache import folder: %w", err)
+		return CacheIndex{}, fmt.Errorf("error updating cache import folder: %w", err)
 	}
+	return cacheIndex, nil
+}
+
+func CreateDownloadSession(mods []*Mod, hashesToObtain []string) (DownloadSession, error) {
+	cacheIndex, err := loadCacheIndex()
+	if err != nil {
+		return nil, err
+	}
+	cachePath := cacheIndex.cachePath
 
 	// Create session
 	downloadSession := downloadSessionInternal{
@@ -660,6 +800,7 @@ func CreateDownloadSession(mods []*Mod, hashesToObtain []string) (DownloadSessio
 			downloadSession.downloadTasks = append(downloadSession.downloadTasks, downloadTask{
 				mod:        mod,
 				url:        mod.Download.URL,
+				mirrors:    mod.Download.Mirrors,
 				hashFormat: mod.Download.HashFormat,
 				hash:       mod.Download.Hash,
 			})
@@ -672,6 +813,17 @@ func CreateDownloadSession(mods []*Mod, hashesToObtain []string) (DownloadSessio
 	}
 
 	for dlID, mods := range pendingMetadata {
+		if IsOffline() {
+			// Metadata can't be retrieved, so these files can only be read from the cache
+			for _, v := range mods {
+				downloadSession.downloadTasks = append(downloadSession.downloadTasks, downloadTask{
+					mod:        v,
+					hashFormat: v.Download.HashFormat,
+					hash:       v.Download.Hash,
+				})
+			}
+			continue
+		}
 		downloader, ok := MetaDownloaders[dlID]
 		if !ok {
 			return nil, fmt.Errorf("unknown download mode %s for %s", mods[0].Download.Mode, mods[0].Name)
@@ -693,9 +845,11 @@ func CreateDownloadSession(mods []*Mod, hashesToObtain []string) (DownloadSessio
 						return nil, fmt.Errorf("failed to open manual download %s: %w", v.Name, err)
 					}
 					downloadSession.foundManualDownloads = append(downloadSession.foundManualDownloads, CompletedDownload{
-						File:   file,
-						Mod:    v,
-						Hashes: handle.Hashes,
+						File:      file,
+						Mod:       v,
+						Hashes:    handle.Hashes,
+						FromCache: true,
+						Manual:    &manualDownload,
 					})
 				} else {
 					downloadSession.manualDownloads = append(downloadSession.manualDownloads, manualDownload)
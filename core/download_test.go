@@ -0,0 +1,440 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestDownloadURLValidatesHash(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good":
+			_, _ = w.Write([]byte("hello"))
+		case "/tampered":
+			_, _ = w.Write([]byte("tampered"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	// sha256 of "hello"
+	const hash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	for _, path := range []string{"/missing", "/tampered"} {
+		_, hashes := getHashListsForDownload(nil, "sha256", hash)
+		f := createPartialTestFile(t, "")
+		err := downloadURLResumable(context.Background(), srv.URL+path, nil, hashes, f, nil)
+		if err == nil {
+			t.Errorf("Expected download of %s to fail", path)
+		} else if path == "/tampered" && !errors.Is(err, ErrHashMismatch) {
+			t.Errorf("Expected a hash mismatch for %s, got %v", path, err)
+		}
+	}
+
+	hashesToObtain, hashes := getHashListsForDownload(nil, "sha256", hash)
+	f := createPartialTestFile(t, "")
+	if err := downloadURLResumable(context.Background(), srv.URL+"/good", hashesToObtain, hashes, f, nil); err != nil {
+		t.Fatalf("Expected download to succeed, got %v", err)
+	}
+	if data := readPartialTestFile(t, f); data != "hello" {
+		t.Errorf("Expected downloaded contents to be written, got %q", data)
+	}
+}
+
+// createPartialTestFile creates a temporary file containing the start of a download
+func createPartialTestFile(t *testing.T, data string) *os.File {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), "partial"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func readPartialTestFile(t *testing.T, f *os.File) string {
+	t.Helper()
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+const resumeTestContent = "hello, resumable world"
+
+// resumeTestServer serves resumeTestContent, supporting range requests if ranges is set. If truncate is set, the
+// connection is closed halfway through the response. Requested ranges are recorded.
+type resumeTestServer struct {
+	ranges   bool
+	truncate bool
+	requests []string
+}
+
+func (s *resumeTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests = append(s.requests, r.Header.Get("Range"))
+	if s.truncate {
+		if s.ranges {
+			w.Header().Set("Accept-Ranges", "bytes")
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(resumeTestContent)))
+		_, _ = w.Write([]byte(resumeTestContent[:len(resumeTestContent)/2]))
+		return
+	}
+	if s.ranges {
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(resumeTestContent))
+		return
+	}
+	_, _ = w.Write([]byte(resumeTestContent))
+}
+
+func resumeTestHashes() ([]string, map[string]string) {
+	sum := sha256.Sum256([]byte(resumeTestContent))
+	return getHashListsForDownload(nil, "sha256", hex.EncodeToString(sum[:]))
+}
+
+func TestDownloadResume(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranges   bool
+		partial  string
+		requests []string
+	}{
+		{"range-capable server", true, "hello, ", []string{"bytes=7-"}},
+		{"range-incapable server", false, "hello, ", []string{"bytes=7-"}},
+		{"corrupt partial file", true, "HELLO, ", []string{"bytes=7-", ""}},
+		{"complete partial file", true, resumeTestContent, []string{"bytes=22-", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &resumeTestServer{ranges: tt.ranges}
+			srv := httptest.NewServer(server)
+			defer srv.Close()
+
+			f := createPartialTestFile(t, tt.partial)
+			hashesToObtain, hashes := resumeTestHashes()
+			if err := downloadURLResumable(context.Background(), srv.URL, hashesToObtain, hashes, f, nil); err != nil {
+				t.Fatalf("Expected download to succeed, got %v", err)
+			}
+			if data := readPartialTestFile(t, f); data != resumeTestContent {
+				t.Errorf("Expected %q to be downloaded, got %q", resumeTestContent, data)
+			}
+			if !slices.Equal(server.requests, tt.requests) {
+				t.Errorf("Expected requested ranges %q, got %q", tt.requests, server.requests)
+			}
+		})
+	}
+}
+
+func TestDownloadInterrupted(t *testing.T) {
+	for _, ranges := range []bool{true, false} {
+		server := &resumeTestServer{ranges: ranges, truncate: true}
+		srv := httptest.NewServer(server)
+
+		f := createPartialTestFile(t, "")
+		hashesToObtain, hashes := resumeTestHashes()
+		if err := downloadURLResumable(context.Background(), srv.URL, hashesToObtain, hashes, f, nil); err == nil {
+			t.Fatal("Expected interrupted download to fail")
+		}
+		srv.Close()
+
+		// Data is only kept if the download can be resumed later
+		expected := ""
+		if ranges {
+			expected = resumeTestContent[:len(resumeTestContent)/2]
+		}
+		if data := readPartialTestFile(t, f); data != expected {
+			t.Errorf("Expected partial file to contain %q when ranges are supported=%v, got %q", expected, ranges, data)
+		}
+	}
+}
+
+func TestDownloadSessionResumes(t *testing.T) {
+	server := &resumeTestServer{ranges: true, truncate: true}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	cacheDir := t.TempDir()
+	viper.Set("cache.directory", cacheDir)
+	t.Cleanup(func() {
+		viper.Set("cache.directory", "")
+	})
+	_, hashes := resumeTestHashes()
+	mod := &Mod{Name: "Resumable", Download: ModDownload{
+		URL:        srv.URL + "/resumable.jar",
+		HashFormat: "sha256",
+		Hash:       hashes["sha256"],
+	}}
+
+	if _, err := downloadTestFile(t, mod); err == nil {
+		t.Fatal("Expected interrupted download to fail")
+	}
+	partial := filepath.Join(cacheDir, "temp", "partial-sha256-"+hashes["sha256"])
+	if _, err := os.Stat(partial); err != nil {
+		t.Fatalf("Expected partial file to be kept: %v", err)
+	}
+
+	server.truncate = false
+	if data, err := downloadTestFile(t, mod); err != nil || data != resumeTestContent {
+		t.Fatalf("Expected download to be resumed, got %q (%v)", data, err)
+	}
+	if server.requests[len(server.requests)-1] != "bytes=11-" {
+		t.Errorf("Expected the rest of the file to be requested, got %q", server.requests)
+	}
+	if _, err := os.Stat(partial); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected partial file to be moved to the cache, got %v", err)
+	}
+}
+
+// downloadTestFile downloads a single mod using a new download session, returning the contents of the file
+func downloadTestFile(t *testing.T, mod *Mod) (string, error) {
+	t.Helper()
+	session, err := CreateDownloadSession([]*Mod{mod}, []string{})
+	if err != nil {
+		t.Fatalf("Failed to create download session: %v", err)
+	}
+	var data []byte
+	for dl := range session.StartDownloads() {
+		if dl.Error != nil {
+			err = dl.Error
+			continue
+		}
+		data, err = io.ReadAll(dl.File)
+		_ = dl.File.Close()
+	}
+	if err := session.SaveIndex(); err != nil {
+		t.Fatalf("Failed to save cache index: %v", err)
+	}
+	return string(data), err
+}
+
+func TestDownloadCache(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	viper.Set("cache.directory", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("cache.directory", "")
+		viper.Set("offline", false)
+	})
+	mod := &Mod{Name: "Hello", Download: ModDownload{
+		URL:        srv.URL + "/hello.jar",
+		HashFormat: "sha256",
+		// sha256 of "hello"
+		Hash: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}}
+
+	if data, err := downloadTestFile(t, mod); err != nil || data != "hello" {
+		t.Fatalf("Expected file to be downloaded, got %q (%v)", data, err)
+	}
+
+	viper.Set("offline", true)
+	if data, err := downloadTestFile(t, mod); err != nil || data != "hello" {
+		t.Fatalf("Expected file to be read from the cache, got %q (%v)", data, err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %v", requests)
+	}
+
+	// Corrupt the cache entry; it can't be fetched again in offline mode
+	session, err := CreateDownloadSession(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle := session.(*downloadSessionInternal).cacheIndex.GetHandleFromHash(mod.Download.HashFormat, mod.Download.Hash)
+	if handle == nil {
+		t.Fatal("Expected file to be in the cache index")
+	}
+	if err := os.WriteFile(handle.Path(), []byte("corrupted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloadTestFile(t, mod); !errors.Is(err, ErrOffline) {
+		t.Errorf("Expected corrupted cache entry to be rejected in offline mode, got %v", err)
+	}
+
+	viper.Set("offline", false)
+	if data, err := downloadTestFile(t, mod); err != nil || data != "hello" {
+		t.Fatalf("Expected corrupted file to be downloaded again, got %q (%v)", data, err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %v", requests)
+	}
+}
+
+func TestDownloadBlake3ObtainsOtherHashes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	viper.Set("cache.directory", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("cache.directory", "")
+	})
+	if err := ValidateHashFormat("blake3"); err != nil {
+		t.Fatalf("Expected blake3 to be a valid hash format, got %v", err)
+	}
+	mod := &Mod{Name: "Hello", Download: ModDownload{
+		URL:        srv.URL + "/hello.jar",
+		HashFormat: "blake3",
+		// blake3 of "hello"
+		Hash: "ea8f163db38682925e4491c5e58d4bb3506ef8c14eb78a86e908c5624a67200f",
+	}}
+
+	// Exporting to Modrinth requires sha1 and sha512, which must be computed from the downloaded file
+	session, err := CreateDownloadSession([]*Mod{mod}, []string{"sha1", "sha512"})
+	if err != nil {
+		t.Fatalf("Failed to create download session: %v", err)
+	}
+	for dl := range session.StartDownloads() {
+		if dl.Error != nil {
+			t.Fatalf("Expected download to succeed, got %v", dl.Error)
+		}
+		_ = dl.File.Close()
+		expected := map[string]string{
+			"sha1":   "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+			"sha512": "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043",
+		}
+		for format, hash := range expected {
+			if dl.Hashes[format] != hash {
+				t.Errorf("Expected %s hash %s, got %s", format, hash, dl.Hashes[format])
+			}
+		}
+	}
+	if err := session.SaveIndex(); err != nil {
+		t.Fatalf("Failed to save cache index: %v", err)
+	}
+
+	// Downloading again uses the cache, which must still be able to look the file up by its blake3 hash
+	if data, err := downloadTestFile(t, mod); err != nil || data != "hello" {
+		t.Fatalf("Expected file to be read from the cache, got %q (%v)", data, err)
+	}
+}
+
+func TestDownloadParallel(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		_, _ = w.Write([]byte(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".jar")))
+	}))
+	defer srv.Close()
+
+	viper.Set("cache.directory", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("cache.directory", "")
+	})
+	var mods []*Mod
+	for _, content := range []string{"a", "b", "c", "d", "e", "a"} {
+		hash := sha256.Sum256([]byte(content))
+		mods = append(mods, &Mod{Name: content, Download: ModDownload{
+			URL:        srv.URL + "/" + content + ".jar",
+			HashFormat: "sha256",
+			Hash:       hex.EncodeToString(hash[:]),
+		}})
+	}
+
+	fetch := func() (cached int) {
+		session, err := CreateDownloadSession(mods, []string{})
+		if err != nil {
+			t.Fatalf("Failed to create download session: %v", err)
+		}
+		count := 0
+		for dl := range session.StartDownloadsParallel(context.Background(), nil, 3) {
+			count++
+			if dl.Error != nil {
+				t.Fatalf("Expected download of %s to succeed, got %v", dl.Mod.Name, dl.Error)
+			}
+			data, err := io.ReadAll(dl.File)
+			_ = dl.File.Close()
+			if err != nil || string(data) != dl.Mod.Name {
+				t.Errorf("Expected %q, got %q (%v)", dl.Mod.Name, data, err)
+			}
+			if dl.FromCache {
+				cached++
+			}
+		}
+		if count != len(mods) {
+			t.Errorf("Expected %d downloads, got %d", len(mods), count)
+		}
+		if err := session.SaveIndex(); err != nil {
+			t.Fatalf("Failed to save cache index: %v", err)
+		}
+		return cached
+	}
+
+	// The duplicate file is only downloaded once, then read from the cache
+	if cached := fetch(); cached != 1 {
+		t.Errorf("Expected 1 file to be read from the cache, got %d", cached)
+	}
+	if requests["/a.jar"] != 1 || len(requests) != 5 {
+		t.Errorf("Expected each file to be requested once, got %v", requests)
+	}
+	if cached := fetch(); cached != len(mods) {
+		t.Errorf("Expected all files to be read from the cache, got %d", cached)
+	}
+}
+
+func TestDownloadMirrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mirror/hello.jar" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	viper.Set("cache.directory", t.TempDir())
+	t.Cleanup(func() { viper.Set("cache.directory", "") })
+	mod := &Mod{Name: "Hello", Download: ModDownload{
+		URL:        srv.URL + "/hello.jar",
+		Mirrors:    []string{srv.URL + "/missing/hello.jar", srv.URL + "/mirror/hello.jar"},
+		HashFormat: "sha256",
+		// sha256 of "hello"
+		Hash: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}}
+
+	// Messages about failed downloads must not be written to stdout, which is parsed by scripts
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = stdoutW
+	data, err := downloadTestFile(t, mod)
+	os.Stdout = oldStdout
+	_ = stdoutW.Close()
+	stdout, _ := io.ReadAll(stdoutR)
+
+	if err != nil || data != "hello" {
+		t.Fatalf("Expected the file to be downloaded from the second mirror, got %q (%v)", data, err)
+	}
+	if len(stdout) > 0 {
+		t.Errorf("Expected nothing to be written to stdout, got %q", stdout)
+	}
+}
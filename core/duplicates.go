@@ -0,0 +1,129 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// duplicateKeys returns identifiers for the project or file that a mod installs; mods sharing any of these are
+// considered duplicates of each other
+func duplicateKeys(mod *Mod) []string {
+	var keys []string
+	if mod.FileName != "" {
+		keys = append(keys, "filename:"+strings.ToLower(mod.FileName))
+	}
+	if mod.Download.Hash != "" {
+		keys = append(keys, "hash:"+mod.Download.HashFormat+":"+strings.ToLower(mod.Download.Hash))
+	}
+	if data, ok := mod.Update["modrinth"]; ok {
+		if id, ok := data["mod-id"]; ok {
+			keys = append(keys, fmt.Sprintf("modrinth:%v", id))
+		}
+	}
+	if data, ok := mod.Update["curseforge"]; ok {
+		if id, ok := data["project-id"]; ok {
+			keys = append(keys, fmt.Sprintf("curseforge:%v", id))
+		}
+	}
+	return keys
+}
+
+// sidesOverlap returns true if mods on the two given sides would be installed together
+func sidesOverlap(a, b string) bool {
+	return a == b || a == EmptySide || a == UniversalSide || b == EmptySide || b == UniversalSide
+}
+
+// duplicateInfo is what duplicate detection needs to know about a metadata file; it is stored in the refresh cache so
+// that unchanged metadata files don't need to be read again
+type duplicateInfo struct {
+	Side string   `json:"side,omitempty"`
+	Keys []string `json:"keys"`
+}
+
+// FindDuplicateMods finds metadata files in the index that install the same project or file, returning groups of
+// metadata file paths (relative to the index). Metadata files that can't be read are skipped.
+//
+// Metadata files only store the project ID of the source they were added from, so a mod added from both CurseForge and
+// Modrinth is only found if both files have the same file name (or the same hash, in the same format).
+func (in Index) FindDuplicateMods() [][]string {
+	return in.findDuplicateMods(nil)
+}
+
+// findDuplicateMods is FindDuplicateMods, reusing the information stored in the given refresh cache (if not nil) for
+// metadata files that haven't changed, and storing it for the rest
+func (in Index) findDuplicateMods(cache *refreshCache) [][]string {
+	pathsByKey := make(map[string][]string)
+	sides := make(map[string]string)
+	for p, file := range in.Files {
+		if !file.IsMetaFile() {
+			continue
+		}
+		info, ok := cache.getDuplicateInfo(p)
+		if !ok {
+			modData, err := LoadMod(in.ResolveIndexPath(p))
+			if err != nil {
+				continue
+			}
+			info = duplicateInfo{modData.Side, duplicateKeys(&modData)}
+			cache.setDuplicateInfo(p, info)
+		}
+		sides[p] = info.Side
+		for _, key := range info.Keys {
+			pathsByKey[key] = append(pathsByKey[key], p)
+		}
+	}
+
+	var groups [][]string
+	seenGroups := make(map[string]bool)
+	for _, paths := range pathsByKey {
+		if len(paths) < 2 {
+			continue
+		}
+		overlapping := false
+		for i := range paths {
+			for j := i + 1; j < len(paths); j++ {
+				if sidesOverlap(sides[paths[i]], sides[paths[j]]) {
+					overlapping = true
+				}
+			}
+		}
+		if !overlapping {
+			continue
+		}
+
+		paths = slices.Clone(paths)
+		slices.Sort(paths)
+		paths = slices.Compact(paths)
+		if len(paths) < 2 {
+			continue
+		}
+		groupKey := strings.Join(paths, "\x00")
+		if !seenGroups[groupKey] {
+			seenGroups[groupKey] = true
+			groups = append(groups, paths)
+		}
+	}
+	slices.SortFunc(groups, func(a, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+	return groups
+}
+
+// checkDuplicateMods warns about duplicate mods in the index, returning an error instead if strict is true. The
+// refresh cache is used for metadata files that haven't changed, if it isn't nil.
+func (in Index) checkDuplicateMods(cache *refreshCache, strict bool) error {
+	groups := in.findDuplicateMods(cache)
+	if len(groups) == 0 {
+		return nil
+	}
+	var msg strings.Builder
+	for _, group := range groups {
+		msg.WriteString("\n\t" + strings.Join(group, ", "))
+	}
+	if strict {
+		return fmt.Errorf("duplicate files found in the pack:%s", msg.String())
+	}
+	fmt.Printf("Warning: the following metadata files install the same file or project, which may be a duplicate:%s\n", msg.String())
+	return nil
+}
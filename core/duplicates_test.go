@@ -0,0 +1,148 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// writeDuplicateTestPack creates a pack containing the given metadata files (keyed by name, in the mods folder),
+// returning the path to its index file
+func writeDuplicateTestPack(t *testing.T, mods map[string]string) string {
+	t.Helper()
+	for _, name := range []string{"curseforge", "modrinth"} {
+		if _, ok := Updaters[name]; !ok {
+			Updaters[name] = goldenTestUpdater{}
+			t.Cleanup(func() { delete(Updaters, name) })
+		}
+	}
+	indexFile := createTestPack(t, 0)
+	for name, contents := range mods {
+		p := filepath.Join(filepath.Dir(indexFile), "mods", name+MetaExtension)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return indexFile
+}
+
+// duplicateTestMod returns the contents of a metadata file; extra is appended, for update sections
+func duplicateTestMod(fileName string, side string, hash string, extra string) string {
+	return fmt.Sprintf("name = %q\nfilename = %q\nside = %q\n\n[download]\nurl = \"https://example.com/%s\"\nhash-format = \"sha1\"\nhash = %q\n%s",
+		fileName, fileName, side, fileName, hash, extra)
+}
+
+func refreshDuplicateTestPack(t *testing.T, indexFile string) (Index, error) {
+	t.Helper()
+	index, err := LoadIndex(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return index, index.Refresh()
+}
+
+func TestFindDuplicateMods(t *testing.T) {
+	tests := []struct {
+		name     string
+		mods     map[string]string
+		expected [][]string
+	}{
+		{"file name", map[string]string{
+			"a": duplicateTestMod("Sodium.jar", "both", "aaaa", ""),
+			"b": duplicateTestMod("sodium.jar", "both", "bbbb", ""),
+		}, [][]string{{"mods/a.pw.toml", "mods/b.pw.toml"}}},
+		{"hash", map[string]string{
+			"a": duplicateTestMod("a.jar", "both", "abcd", ""),
+			"b": duplicateTestMod("b.jar", "client", "ABCD", ""),
+		}, [][]string{{"mods/a.pw.toml", "mods/b.pw.toml"}}},
+		{"modrinth project", map[string]string{
+			"a": duplicateTestMod("a.jar", "both", "aaaa", "\n[update.modrinth]\nmod-id = \"AANobbMI\"\nversion = \"1\"\n"),
+			"b": duplicateTestMod("b.jar", "both", "bbbb", "\n[update.modrinth]\nmod-id = \"AANobbMI\"\nversion = \"2\"\n"),
+		}, [][]string{{"mods/a.pw.toml", "mods/b.pw.toml"}}},
+		{"curseforge project", map[string]string{
+			"a": duplicateTestMod("a.jar", "both", "aaaa", "\n[update.curseforge]\nproject-id = 394468\nfile-id = 1\n"),
+			"b": duplicateTestMod("b.jar", "", "bbbb", "\n[update.curseforge]\nproject-id = 394468\nfile-id = 2\n"),
+		}, [][]string{{"mods/a.pw.toml", "mods/b.pw.toml"}}},
+		{"different sides", map[string]string{
+			"a": duplicateTestMod("a.jar", "client", "aaaa", ""),
+			"b": duplicateTestMod("a.jar", "server", "aaaa", ""),
+		}, nil},
+		{"different projects", map[string]string{
+			"a": duplicateTestMod("a.jar", "both", "aaaa", "\n[update.modrinth]\nmod-id = \"AANobbMI\"\nversion = \"1\"\n"),
+			"b": duplicateTestMod("b.jar", "both", "bbbb", "\n[update.curseforge]\nproject-id = 394468\nfile-id = 2\n"),
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := refreshDuplicateTestPack(t, writeDuplicateTestPack(t, tt.mods))
+			if err != nil {
+				t.Fatal(err)
+			}
+			groups := index.FindDuplicateMods()
+			if !slices.EqualFunc(groups, tt.expected, slices.Equal) {
+				t.Errorf("Expected duplicates %v, got %v", tt.expected, groups)
+			}
+		})
+	}
+}
+
+func TestRefreshStrictDuplicates(t *testing.T) {
+	indexFile := writeDuplicateTestPack(t, map[string]string{
+		"a": duplicateTestMod("a.jar", "both", "aaaa", ""),
+		"b": duplicateTestMod("a.jar", "both", "bbbb", ""),
+	})
+	t.Cleanup(func() { viper.Set("refresh.strict", false) })
+
+	if _, err := refreshDuplicateTestPack(t, indexFile); err != nil {
+		t.Errorf("Expected duplicates to only be a warning, got %v", err)
+	}
+	viper.Set("refresh.strict", true)
+	_, err := refreshDuplicateTestPack(t, indexFile)
+	if err == nil || !strings.Contains(err.Error(), "mods/a.pw.toml, mods/b.pw.toml") {
+		t.Errorf("Expected duplicates to be an error with --strict, got %v", err)
+	}
+}
+
+func TestDuplicatesUseRefreshCache(t *testing.T) {
+	indexFile := writeDuplicateTestPack(t, map[string]string{
+		"a": duplicateTestMod("a.jar", "both", "aaaa", ""),
+		"b": duplicateTestMod("b.jar", "both", "bbbb", ""),
+	})
+	viper.Set("refresh.incremental", true)
+	t.Cleanup(func() {
+		viper.Set("refresh.incremental", false)
+		viper.Set("refresh.strict", false)
+	})
+	index, err := refreshDuplicateTestPack(t, indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Give the unchanged metadata files the same key in the cache, which should be used instead of reading them again
+	cache := index.loadRefreshCache(false)
+	cache.Files = cache.previous
+	for _, p := range []string{"mods/a.pw.toml", "mods/b.pw.toml"} {
+		entry := cache.Files[p]
+		if entry.Duplicates == nil {
+			t.Fatalf("Expected duplicate information for %s to be cached", p)
+		}
+		entry.Duplicates.Keys = append(entry.Duplicates.Keys, "cached")
+		cache.Files[p] = entry
+	}
+	if err := index.saveRefreshCache(cache); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("refresh.strict", true)
+	if _, err := refreshDuplicateTestPack(t, indexFile); err == nil {
+		t.Error("Expected the cached duplicate information to be used")
+	}
+}
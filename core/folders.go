@@ -0,0 +1,67 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/viper"
+)
+
+// Project types that can be given a folder in the folders field of pack.toml
+const (
+	ProjectTypeMod          = "mod"
+	ProjectTypeResourcePack = "resourcepack"
+	ProjectTypeShaderPack   = "shaderpack"
+	ProjectTypeDatapack     = "datapack"
+	ProjectTypePlugin       = "plugin"
+	ProjectTypeWorld        = "world"
+)
+
+// ProjectTypes lists all the project types that can be given a folder
+var ProjectTypes = []string{ProjectTypeMod, ProjectTypeResourcePack, ProjectTypeShaderPack, ProjectTypeDatapack,
+	ProjectTypePlugin, ProjectTypeWorld}
+
+// DefaultProjectTypeSide returns the side that files of a project type are added with when no side is given: resource
+// packs and shader packs are only used by the client, so they aren't sent to servers
+func DefaultProjectTypeSide(projectType string) string {
+	if projectType == ProjectTypeResourcePack || projectType == ProjectTypeShaderPack {
+		return ClientSide
+	}
+	return EmptySide
+}
+
+// validateProjectTypeFolder checks that a folder configured for a project type is a known type and stays within the pack
+func validateProjectTypeFolder(projectType string, folder string) error {
+	if !slices.Contains(ProjectTypes, projectType) {
+		return fmt.Errorf("unknown project type %s in folders, must be one of %v", projectType, ProjectTypes)
+	}
+	if !filepath.IsLocal(filepath.FromSlash(folder)) {
+		return fmt.Errorf("folder %s for project type %s must be a relative path within the pack", folder, projectType)
+	}
+	return nil
+}
+
+// ValidateProjectTypeFolders checks that all the folders configured for project types are within the pack
+func ValidateProjectTypeFolders() error {
+	for projectType, folder := range viper.GetStringMapString("folders") {
+		if err := validateProjectTypeFolder(projectType, folder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProjectTypeFolder returns the folder that new metadata files of the given project type are added to (unless
+// the meta-folder option is set), relative to meta-folder-base: the folder configured for the project type in the
+// folders field of pack.toml, or defaultFolder if there isn't one
+func GetProjectTypeFolder(projectType string, defaultFolder string) (string, error) {
+	folder, ok := viper.GetStringMapString("folders")[projectType]
+	if !ok || folder == "" {
+		return defaultFolder, nil
+	}
+	if err := validateProjectTypeFolder(projectType, folder); err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(folder), nil
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestGetProjectTypeFolder(t *testing.T) {
+	indexFile := createTestPack(t, 0)
+	err := os.WriteFile(filepath.Join(filepath.Dir(indexFile), "pack.toml"), []byte(`name = "Test"
+pack-format = "packwiz:1.1.0"
+
+[index]
+file = "index.toml"
+hash-format = "sha256"
+
+[folders]
+mod = "client/mods"
+resourcepack = "../outside"
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { viper.Set("folders", nil) })
+
+	pack, err := LoadPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pack.Folders[ProjectTypeMod] != "client/mods" {
+		t.Errorf("Expected folders to be read from pack.toml, got %v", pack.Folders)
+	}
+
+	folder, err := GetProjectTypeFolder(ProjectTypeMod, "mods")
+	if err != nil || folder != filepath.FromSlash("client/mods") {
+		t.Errorf("Expected configured mod folder, got %q (%v)", folder, err)
+	}
+	folder, err = GetProjectTypeFolder(ProjectTypeShaderPack, "shaderpacks")
+	if err != nil || folder != "shaderpacks" {
+		t.Errorf("Expected default shaderpack folder, got %q (%v)", folder, err)
+	}
+	if _, err := GetProjectTypeFolder(ProjectTypeResourcePack, "resourcepacks"); err == nil {
+		t.Error("Expected a folder outside the pack to be rejected")
+	}
+	if err := ValidateProjectTypeFolders(); err == nil {
+		t.Error("Expected validation to reject a folder outside the pack")
+	}
+
+	for _, folders := range []map[string]string{{"mod": "/abs/mods"}, {"mods": "mods"}} {
+		viper.Set("folders", folders)
+		if err := ValidateProjectTypeFolders(); err == nil {
+			t.Errorf("Expected validation of %v to fail", folders)
+		}
+	}
+	viper.Set("folders", map[string]string{"mod": "mods", "world": "saves/worlds"})
+	if err := ValidateProjectTypeFolders(); err != nil {
+		t.Errorf("Expected valid folders to pass validation, got %v", err)
+	}
+}
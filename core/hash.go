@@ -8,12 +8,37 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"github.com/0byte-coding/packwiz/core/blake3"
 	"github.com/0byte-coding/packwiz/curseforge/murmur2"
 	"hash"
 	"strconv"
 	"strings"
+
+	"github.com/spf13/viper"
 )
 
+// DefaultHashFormat is the hash format used for new files when no other format is configured
+const DefaultHashFormat = "sha256"
+
+// GetDefaultHashFormat returns the hash format configured for new files, or DefaultHashFormat if none is configured
+func GetDefaultHashFormat() string {
+	format := strings.ToLower(viper.GetString("default-hash-format"))
+	if ValidateHashFormat(format) != nil {
+		return DefaultHashFormat
+	}
+	return format
+}
+
+// ValidateHashFormat returns an error if the given hash format can't be used to store the hashes of files
+func ValidateHashFormat(format string) error {
+	// length-bytes is only used internally, as it can't be used to validate the contents of files
+	if strings.ToLower(format) == "length-bytes" {
+		return fmt.Errorf("hash implementation %s can't be used to store hashes", format)
+	}
+	_, err := GetHashImpl(format)
+	return err
+}
+
 // GetHashImpl gets an implementation of hash.Hash for the given hash type string
 func GetHashImpl(hashType string) (HashStringer, error) {
 	switch strings.ToLower(hashType) {
@@ -23,6 +48,8 @@ func GetHashImpl(hashType string) (HashStringer, error) {
 		return hexStringer{sha256.New()}, nil
 	case "sha512":
 		return hexStringer{sha512.New()}, nil
+	case "blake3":
+		return hexStringer{blake3.New()}, nil
 	case "md5":
 		return hexStringer{md5.New()}, nil
 	case "murmur2": // TODO: change to something indicating that this is the CF variant
@@ -39,6 +66,7 @@ var preferredHashList = []string{
 	"sha1",
 	"sha256",
 	"sha512",
+	"blake3",
 }
 
 type HashStringer interface {
@@ -1,13 +1,16 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -33,9 +36,19 @@ type indexTomlRepresentation struct {
 
 // LoadIndex attempts to load the index file from a path
 func LoadIndex(indexFile string) (Index, error) {
+	data, err := os.ReadFile(indexFile)
+	if err != nil {
+		return Index{}, err
+	}
+	return ParseIndex(data, indexFile)
+}
+
+// ParseIndex parses the contents of an index file, which is treated as being at the given path
+// (e.g. when reading an old version of the index from version control)
+func ParseIndex(data []byte, indexFile string) (Index, error) {
 	// Decode as indexTomlRepresentation then convert to Index
 	var rep indexTomlRepresentation
-	if _, err := toml.DecodeFile(indexFile, &rep); err != nil {
+	if _, err := toml.Decode(string(data), &rep); err != nil {
 		return Index{}, err
 	}
 	if len(rep.HashFormat) == 0 {
@@ -60,6 +73,43 @@ func (in *Index) RemoveFile(path string) error {
 	return nil
 }
 
+// MoveFile moves a file in the pack to a new path, and moves its entry in the index to match; its hash and other
+// index data are kept. Files that aren't in the index can't be moved, and existing files aren't overwritten.
+func (in *Index) MoveFile(oldPath string, newPath string) error {
+	oldRelPath, err := in.RelIndexPath(oldPath)
+	if err != nil {
+		return err
+	}
+	newRelPath, err := in.RelIndexPath(newPath)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsLocal(filepath.FromSlash(newRelPath)) {
+		return fmt.Errorf("%s is outside the pack", newPath)
+	}
+	if _, found := in.Files[oldRelPath]; !found {
+		return fmt.Errorf("%s is not in the index", oldRelPath)
+	}
+	if _, found := in.Files[newRelPath]; found {
+		return fmt.Errorf("%s is already in the index", newRelPath)
+	}
+	if _, err := os.Lstat(newPath); err == nil {
+		return fmt.Errorf("%s already exists", newRelPath)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(newPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+	in.Files.moveEntry(oldRelPath, newRelPath)
+	return nil
+}
+
 func (in *Index) updateFileHashGiven(path, format, hash string, markAsMetaFile bool) error {
 	// Remove format if equal to index hash format
 	if in.HashFormat == format {
@@ -75,43 +125,66 @@ func (in *Index) updateFileHashGiven(path, format, hash string, markAsMetaFile b
 	return nil
 }
 
-// updateFile calculates the hash for a given path and updates it in the index
-func (in *Index) updateFile(path string) error {
-	var hashString string
-	if viper.GetBool("no-internal-hashes") {
-		hashString = ""
-	} else {
-		f, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-
-		// Hash usage strategy (may change):
-		// Just use SHA256, overwrite existing hash regardless of what it is
-		// May update later to continue using the same hash that was already being used
-		h, err := GetHashImpl("sha256")
-		if err != nil {
-			_ = f.Close()
-			return err
-		}
-		if _, err := io.Copy(h, f); err != nil {
-			_ = f.Close()
-			return err
-		}
-		err = f.Close()
-		if err != nil {
-			return err
-		}
-		hashString = h.HashToString(h.Sum(nil))
-	}
-
+// updateFileHash updates the hash of a file in the index, given a hash in the index hash format
+func (in *Index) updateFileHash(path string, hashString string) error {
 	markAsMetaFile := false
 	// If the file has an extension of pw.toml, set markAsMetaFile to true
 	if strings.HasSuffix(filepath.Base(path), MetaExtension) {
 		markAsMetaFile = true
 	}
 
-	return in.updateFileHashGiven(path, "sha256", hashString, markAsMetaFile)
+	return in.updateFileHashGiven(path, in.HashFormat, hashString, markAsMetaFile)
+}
+
+// RefreshWorkers returns the number of files hashed in parallel when refreshing the index
+func RefreshWorkers() int {
+	workers := viper.GetInt("refresh.jobs")
+	if workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// hashFiles calculates the hashes of the given files in the index hash format, using a pool of workers. The hashes
+// are returned in the same order as the files, along with the errors for any files that couldn't be hashed.
+// If a refresh cache is given, files that haven't changed since the last refresh aren't hashed again.
+func (in *Index) hashFiles(paths []string, cache *refreshCache, progress *mpb.Bar) ([]string, error) {
+	hashes := make([]string, len(paths))
+	if viper.GetBool("no-internal-hashes") {
+		progress.IncrBy(len(paths))
+		return hashes, nil
+	}
+
+	// Hash usage strategy (may change):
+	// Just use the index hash format, overwrite existing hash regardless of what it is
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(RefreshWorkers(), max(len(paths), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				start := time.Now()
+				if cache != nil {
+					var relPath string
+					relPath, errs[i] = in.RelIndexPath(paths[i])
+					if errs[i] == nil {
+						hashes[i], errs[i] = cache.hashFile(relPath, paths[i], in.HashFormat)
+					}
+				} else {
+					hashes[i], errs[i] = hashFile(paths[i], in.HashFormat)
+				}
+				progress.Increment(time.Since(start))
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return hashes, errors.Join(errs...)
 }
 
 // ResolveIndexPath turns a path from the index into a file path on disk
@@ -145,6 +218,15 @@ var ignoreDefaults = []string{
 	// Exclude exported Modrinth packs
 	"*.mrpack",
 
+	// Exclude the incremental refresh cache
+	"/" + RefreshCacheFile,
+
+	// Exclude the lock file, which is only used when developing the pack
+	"/" + LockFile,
+
+	// Exclude temporary files left behind if packwiz is interrupted while writing a file
+	".*.tmp",
+
 	// Exclude packwiz binaries, if the user puts them in their pack folder
 	"packwiz.exe",
 	"packwiz", // Note: also excludes packwiz/ as a directory - you can negate this pattern if you want a directory called packwiz
@@ -164,17 +246,16 @@ func readGitignore(path string) (*gitignore.GitIgnore, bool) {
 	return gitignore.CompileIgnoreLines(lines...), true
 }
 
-// Refresh updates the hashes of all the files in the index, and adds new files to the index
-func (in *Index) Refresh() error {
-	// TODO: If needed, multithreaded hashing
-	// for i := 0; i < runtime.NumCPU(); i++ {}
-
+// listFiles returns the paths of the files in the pack that should be in the index: all files in the pack root
+// except the pack, index, index signature and refresh cache files, and files ignored by .packwizignore
+func (in Index) listFiles() ([]string, error) {
 	// Is case-sensitivity a problem?
 	pathPF, _ := filepath.Abs(viper.GetString("pack-file"))
 	pathIndex, _ := filepath.Abs(in.indexFile)
 
 	pathIgnore, _ := filepath.Abs(filepath.Join(in.packRoot, ".packwizignore"))
 	ignore, ignoreExists := readGitignore(pathIgnore)
+	pathRefreshCache, _ := filepath.Abs(filepath.Join(in.packRoot, RefreshCacheFile))
 
 	var fileList []string
 	err := filepath.WalkDir(in.packRoot, func(path string, info os.DirEntry, err error) error {
@@ -198,7 +279,7 @@ func (in *Index) Refresh() error {
 		}
 		// Exit if the files are the same as the pack/index files
 		absPath, _ := filepath.Abs(path)
-		if absPath == pathPF || absPath == pathIndex {
+		if absPath == pathPF || absPath == pathIndex || absPath == pathIndex+SignatureExtension || absPath == pathRefreshCache {
 			return nil
 		}
 		if ignoreExists {
@@ -213,6 +294,12 @@ func (in *Index) Refresh() error {
 		fileList = append(fileList, path)
 		return nil
 	})
+	return fileList, err
+}
+
+// Refresh updates the hashes of all the files in the index, and adds new files to the index
+func (in *Index) Refresh() error {
+	fileList, err := in.listFiles()
 	if err != nil {
 		return err
 	}
@@ -234,19 +321,26 @@ func (in *Index) Refresh() error {
 		),
 	)
 
-	for _, v := range fileList {
-		start := time.Now()
+	// The refresh cache only speeds up hashing, so it isn't used when hashes aren't stored
+	var cache *refreshCache
+	if viper.GetBool("refresh.incremental") && !viper.GetBool("no-internal-hashes") {
+		cache = in.loadRefreshCache(viper.GetBool("refresh.force"))
+	}
+	hashes, err := in.hashFiles(fileList, cache, progress)
+	// Close bar
+	progress.SetTotal(int64(len(fileList)), true) // If len = 0, we have to manually set complete to true
+	progressContainer.Wait()
+	if err != nil {
+		return err
+	}
 
-		err := in.updateFile(v)
+	// The index isn't safe for concurrent use, so it is updated once all files are hashed
+	for i, v := range fileList {
+		err := in.updateFileHash(v, hashes[i])
 		if err != nil {
 			return err
 		}
-
-		progress.Increment(time.Since(start))
 	}
-	// Close bar
-	progress.SetTotal(int64(len(fileList)), true) // If len = 0, we have to manually set complete to true
-	progressContainer.Wait()
 
 	// Check all the files exist, remove them if they don't
 	for p, file := range in.Files {
@@ -255,7 +349,15 @@ func (in *Index) Refresh() error {
 		}
 	}
 
-	return nil
+	// Duplicates are checked before the cache is saved, so the information read from metadata files is stored in it
+	duplicatesErr := in.checkDuplicateMods(cache, viper.GetBool("refresh.strict"))
+	if cache != nil {
+		err = in.saveRefreshCache(cache)
+		if err != nil {
+			return err
+		}
+	}
+	return duplicatesErr
 }
 
 // Write saves the index file
@@ -267,20 +369,9 @@ func (in Index) Write() error {
 	}
 
 	// TODO: calculate and provide hash while writing?
-	f, err := os.Create(in.indexFile)
-	if err != nil {
-		return err
-	}
-
-	enc := toml.NewEncoder(f)
-	// Disable indentation
-	enc.Indent = ""
-	err = enc.Encode(rep)
-	if err != nil {
-		_ = f.Close()
-		return err
-	}
-	return f.Close()
+	return writeFileAtomic(in.indexFile, func(w io.Writer) error {
+		return encodeToml(w, rep)
+	})
 }
 
 // RefreshFileWithHash updates a file in the index, given a file hash and whether it should be marked as metafile or not
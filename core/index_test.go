@@ -0,0 +1,141 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// createTestPack creates a pack with the given number of files, returning the path to its index file
+func createTestPack(tb testing.TB, files int) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pack.toml"), []byte{}, 0644); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.toml"), []byte("hash-format = \"sha256\"\n"), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	for i := 0; i < files; i++ {
+		p := filepath.Join(dir, "mods", fmt.Sprintf("mod-%03d%s", i, MetaExtension))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		contents := fmt.Sprintf("name = \"Mod %d\"\nfilename = \"mod-%d.jar\"\n", i, i)
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", filepath.Join(dir, "pack.toml"))
+	tb.Cleanup(func() { viper.Set("pack-file", oldPackFile) })
+	return filepath.Join(dir, "index.toml")
+}
+
+// refreshTestPack refreshes the index using the given number of workers, returning the contents of the written index
+func refreshTestPack(tb testing.TB, indexFile string, jobs int) string {
+	tb.Helper()
+	viper.Set("refresh.jobs", jobs)
+	tb.Cleanup(func() { viper.Set("refresh.jobs", 0) })
+
+	index, err := LoadIndex(indexFile)
+	if err != nil {
+		tb.Fatalf("Failed to load index: %v", err)
+	}
+	if err := index.Refresh(); err != nil {
+		tb.Fatalf("Failed to refresh index: %v", err)
+	}
+	if err := index.Write(); err != nil {
+		tb.Fatalf("Failed to write index: %v", err)
+	}
+	data, err := os.ReadFile(indexFile)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRefreshParallelMatchesSerial(t *testing.T) {
+	indexFile := createTestPack(t, 50)
+	serial := refreshTestPack(t, indexFile, 1)
+	if err := os.WriteFile(indexFile, []byte("hash-format = \"sha256\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parallel := refreshTestPack(t, indexFile, 8)
+	if serial != parallel {
+		t.Errorf("Expected parallel refresh to write the same index as a serial refresh\nserial:\n%s\nparallel:\n%s", serial, parallel)
+	}
+}
+
+func TestRefreshHashErrors(t *testing.T) {
+	indexFile := createTestPack(t, 5)
+	index, err := LoadIndex(indexFile)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	index.HashFormat = "unknown"
+	if err := index.Refresh(); err == nil {
+		t.Error("Expected hashing errors to be returned")
+	}
+}
+
+func BenchmarkRefresh(b *testing.B) {
+	indexFile := createTestPack(b, 500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		refreshTestPack(b, indexFile, 0)
+	}
+}
+
+func TestIncrementalRefresh(t *testing.T) {
+	indexFile := createTestPack(t, 3)
+	packRoot := filepath.Dir(indexFile)
+	viper.Set("refresh.incremental", true)
+	t.Cleanup(func() {
+		viper.Set("refresh.incremental", false)
+		viper.Set("refresh.force", false)
+	})
+
+	full := refreshTestPack(t, indexFile, 0)
+	gitignore, err := os.ReadFile(filepath.Join(packRoot, ".gitignore"))
+	if err != nil || string(gitignore) != "/"+RefreshCacheFile+"\n" {
+		t.Errorf("Expected the refresh cache to be git-ignored, got %q (%v)", gitignore, err)
+	}
+
+	// Replace a cached hash, which should be used instead of hashing the unchanged file
+	cachePath := filepath.Join(packRoot, RefreshCacheFile)
+	index, err := LoadIndex(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := index.loadRefreshCache(false)
+	entry, ok := cache.previous["mods/mod-000"+MetaExtension]
+	if !ok {
+		t.Fatalf("Expected file to be in the refresh cache, got %v", cache.previous)
+	}
+	entry.Hash = "cached"
+	cache.Files = cache.previous
+	cache.Files["mods/mod-000"+MetaExtension] = entry
+	if err := index.saveRefreshCache(cache); err != nil {
+		t.Fatal(err)
+	}
+	if incremental := refreshTestPack(t, indexFile, 0); !strings.Contains(incremental, `hash = "cached"`) {
+		t.Errorf("Expected the cached hash to be used, got:\n%s", incremental)
+	}
+
+	viper.Set("refresh.force", true)
+	if forced := refreshTestPack(t, indexFile, 0); forced != full {
+		t.Errorf("Expected a forced refresh to hash all files\nexpected:\n%s\ngot:\n%s", full, forced)
+	}
+	if strings.Contains(full, RefreshCacheFile) {
+		t.Errorf("Expected the refresh cache not to be in the index, got:\n%s", full)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Errorf("Expected the refresh cache to be written: %v", err)
+	}
+}
@@ -14,6 +14,7 @@ type IndexPathHolder interface {
 	updateHash(hash string, format string)
 	markFound()
 	markMetaFile()
+	setPath(path string)
 	markedFound() bool
 	IsMetaFile() bool
 }
@@ -43,6 +44,10 @@ func (i *indexFile) markMetaFile() {
 	i.MetaFile = true
 }
 
+func (i *indexFile) setPath(path string) {
+	i.File = path
+}
+
 func (i *indexFile) markedFound() bool {
 	return i.fileFound
 }
@@ -75,6 +80,13 @@ func (i *indexFileMultipleAlias) markMetaFile() {
 	}
 }
 
+func (i *indexFileMultipleAlias) setPath(path string) {
+	for k, v := range *i {
+		v.setPath(path)
+		(*i)[k] = v // Can't mutate map value in place
+	}
+}
+
 func (i *indexFileMultipleAlias) markedFound() bool {
 	for _, v := range *i {
 		return v.markedFound()
@@ -120,6 +132,17 @@ func (f *IndexFiles) updateFileEntry(path string, format string, hash string, ma
 	}
 }
 
+// moveEntry moves the entry of a file (and all its aliased variants) to a new path, keeping its hash and flags
+func (f *IndexFiles) moveEntry(oldPath string, newPath string) {
+	file, found := (*f)[oldPath]
+	if !found {
+		return
+	}
+	file.setPath(newPath)
+	delete(*f, oldPath)
+	(*f)[newPath] = file
+}
+
 type indexFilesTomlRepresentation []indexFile
 
 // toMemoryRep converts the TOML representation of IndexFiles to that used in memory
@@ -18,6 +18,21 @@ type Updater interface {
 	DoUpdate([]*Mod, []interface{}) error
 }
 
+// VersionUpdater is implemented by updaters that can also update a mod to a specific version, rather than the latest one
+type VersionUpdater interface {
+	Updater
+	// CheckUpdateToVersion checks the version of a mod with the given ID (as used by the update system), returning
+	// an UpdateCheck that can be passed to DoUpdate. Compatibility problems with the pack are warned about, not returned.
+	CheckUpdateToVersion(*Mod, string, Pack) (UpdateCheck, error)
+}
+
+// ProjectPageUpdater is implemented by updaters that can link to the web page of the project a mod was installed from
+type ProjectPageUpdater interface {
+	Updater
+	// ProjectPage returns the URL of the project page for a mod handled by this updater
+	ProjectPage(*Mod) (string, error)
+}
+
 // UpdateCheck represents the data returned from CheckUpdate for each mod
 type UpdateCheck struct {
 	// UpdateAvailable is true if an update is available for this mod
@@ -25,6 +40,10 @@ type UpdateCheck struct {
 	// UpdateString is a string that details the update in some way to the user. Usually this will be in the form of
 	// a version change (1.0.0 -> 1.0.1), or a file name change (thanos-skin-1.0.0.jar -> thanos-skin-1.0.1.jar).
 	UpdateString string
+	// CurrentVersion and NewVersion describe the installed and available versions to the user (e.g. file names or
+	// release tags), when they need to be shown separately
+	CurrentVersion string
+	NewVersion     string
 	// CachedState can be used to preserve per-mod state between CheckUpdate and DoUpdate (e.g. file metadata)
 	CachedState interface{}
 	// Error stores an error for this specific mod
@@ -48,6 +67,38 @@ type MetaDownloaderData interface {
 	DownloadFile() (io.ReadCloser, error)
 }
 
+// PackImporters stores the importers that can be used to create a pack from an existing modpack, keyed by the source name.
+var PackImporters = make(map[string]PackImporter)
+
+// PackImporter reads existing modpacks (e.g. a modpack zip or manifest) so that they can be imported into a pack
+type PackImporter interface {
+	// ReadPack reads the modpack at the given path. If the file isn't a modpack in a format supported by this
+	// importer, false is returned.
+	ReadPack(path string) (ImportedPack, bool, error)
+}
+
+// ImportedPack is a modpack read by a PackImporter
+type ImportedPack interface {
+	Name() string
+	PackAuthor() string
+	PackVersion() string
+	// Versions returns the versions of Minecraft and the mod loader used by the modpack, in the format of Pack.Versions
+	Versions() map[string]string
+	// Import adds the mods and other files of the modpack to the given index
+	Import(*Index) error
+}
+
+// FileMatchers stores the sources that can identify local files (e.g. by their hash), so that they can be replaced by
+// metadata files, keyed by the source name.
+var FileMatchers = make(map[string]FileMatcher)
+
+// FileMatcher finds the projects that local files were downloaded from
+type FileMatcher interface {
+	// MatchFiles creates metadata files in the index for the files at the given paths that are found on the source.
+	// The names of the projects that were matched are returned, keyed by the path of the file.
+	MatchFiles(paths []string, pack Pack, index *Index) (map[string]string, error)
+}
+
 type ManualDownload struct {
 	Name     string
 	FileName string
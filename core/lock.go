@@ -0,0 +1,202 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// LockFile is the name of the file in the pack root that records the exact versions resolved for each metadata file
+const LockFile = "packwiz.lock"
+
+// lockVersion is the version of the lock file format
+const lockVersion = 1
+
+// Lock records the file and update source data of every metadata file in a pack, so that they can be restored to
+// exactly those versions later
+type Lock struct {
+	Version int          `toml:"lock-version"`
+	Files   []LockedFile `toml:"files"`
+}
+
+// LockedFile records the resolved version of a single metadata file
+type LockedFile struct {
+	// Path is the path of the metadata file, relative to the pack root
+	Path     string                            `toml:"path"`
+	Name     string                            `toml:"name"`
+	FileName string                            `toml:"filename"`
+	Side     string                            `toml:"side,omitempty"`
+	Download ModDownload                       `toml:"download"`
+	Update   map[string]map[string]interface{} `toml:"update,omitempty"`
+}
+
+func newLockedFile(path string, mod *Mod) LockedFile {
+	update := mod.Update
+	if len(update) == 0 {
+		// An empty update table and a missing one are the same
+		update = nil
+	}
+	return LockedFile{
+		Path:     path,
+		Name:     mod.Name,
+		FileName: mod.FileName,
+		Side:     mod.Side,
+		Download: mod.Download,
+		Update:   update,
+	}
+}
+
+// LockPath returns the path of the lock file of the pack
+func (in Index) LockPath() string {
+	return filepath.Join(in.packRoot, LockFile)
+}
+
+// NewLock records the current versions of all the metadata files in the index
+func (in Index) NewLock() (Lock, error) {
+	mods, err := in.LoadAllMods()
+	if err != nil {
+		return Lock{}, err
+	}
+	lock := Lock{Version: lockVersion, Files: make([]LockedFile, 0, len(mods))}
+	for _, mod := range mods {
+		path, err := in.RelIndexPath(mod.GetFilePath())
+		if err != nil {
+			return Lock{}, err
+		}
+		lock.Files = append(lock.Files, newLockedFile(path, mod))
+	}
+	slices.SortFunc(lock.Files, func(a, b LockedFile) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return lock, nil
+}
+
+// LoadLock reads the lock file of the pack
+func (in Index) LoadLock() (Lock, error) {
+	var lock Lock
+	if _, err := toml.DecodeFile(in.LockPath(), &lock); err != nil {
+		return Lock{}, err
+	}
+	if lock.Version != lockVersion {
+		return Lock{}, fmt.Errorf("unsupported %s version %d, it may have been created by a newer version of packwiz", LockFile, lock.Version)
+	}
+	return lock, nil
+}
+
+// WriteLock saves the lock file of the pack
+func (in Index) WriteLock(lock Lock) error {
+	return writeFileAtomic(in.LockPath(), func(w io.Writer) error {
+		if _, err := io.WriteString(w, "# Generated by packwiz lock; records the exact version of every metadata file\n\n"); err != nil {
+			return err
+		}
+		return encodeToml(w, lock)
+	})
+}
+
+// UpdateLock rewrites the lock file with the current versions of all metadata files, if the pack has a lock file
+func (in Index) UpdateLock() (bool, error) {
+	if _, err := os.Stat(in.LockPath()); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	lock, err := in.NewLock()
+	if err != nil {
+		return false, err
+	}
+	return true, in.WriteLock(lock)
+}
+
+// equal returns true if both locked files record the same version
+func (f LockedFile) equal(other LockedFile) bool {
+	return reflect.DeepEqual(f, other)
+}
+
+// LockDifference describes a metadata file that doesn't match the lock file
+type LockDifference struct {
+	Path string
+	// Problem is "changed", "missing" (locked but not in the pack) or "unlocked" (in the pack but not locked)
+	Problem string
+}
+
+// Compare returns the metadata files in the index that don't match the lock file
+func (l Lock) Compare(in Index) ([]LockDifference, error) {
+	current, err := in.NewLock()
+	if err != nil {
+		return nil, err
+	}
+	currentFiles := make(map[string]LockedFile, len(current.Files))
+	for _, f := range current.Files {
+		currentFiles[f.Path] = f
+	}
+
+	var diffs []LockDifference
+	for _, f := range l.Files {
+		c, ok := currentFiles[f.Path]
+		if !ok {
+			diffs = append(diffs, LockDifference{f.Path, "missing"})
+			continue
+		}
+		delete(currentFiles, f.Path)
+		if !f.equal(c) {
+			diffs = append(diffs, LockDifference{f.Path, "changed"})
+		}
+	}
+	for path := range currentFiles {
+		diffs = append(diffs, LockDifference{path, "unlocked"})
+	}
+	slices.SortFunc(diffs, func(a, b LockDifference) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return diffs, nil
+}
+
+// Restore writes the metadata files recorded in the lock file at their locked versions, updating the index. Other
+// fields of existing metadata files (such as tags and pins) are kept, and metadata files that aren't locked are left
+// as they are. The paths of the restored files are returned.
+func (l Lock) Restore(in *Index) ([]string, error) {
+	var restored []string
+	for _, f := range l.Files {
+		// Paths are checked, as the lock file may come from somewhere else
+		if !filepath.IsLocal(filepath.FromSlash(f.Path)) {
+			return restored, fmt.Errorf("invalid path %s in %s", f.Path, LockFile)
+		}
+		path := in.ResolveIndexPath(f.Path)
+
+		var mod Mod
+		if _, err := os.Stat(path); err == nil {
+			mod, err = LoadMod(path)
+			if err != nil {
+				return restored, err
+			}
+			if f.equal(newLockedFile(f.Path, &mod)) {
+				continue
+			}
+		} else if errors.Is(err, os.ErrNotExist) {
+			mod.SetMetaPath(path)
+		} else {
+			return restored, err
+		}
+		mod.Name = f.Name
+		mod.FileName = f.FileName
+		mod.Side = f.Side
+		mod.Download = f.Download
+		mod.Update = f.Update
+
+		format, hash, err := mod.Write()
+		if err != nil {
+			return restored, err
+		}
+		err = in.RefreshFileWithHash(path, format, hash, true)
+		if err != nil {
+			return restored, err
+		}
+		restored = append(restored, f.Path)
+	}
+	return restored, nil
+}
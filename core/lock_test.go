@@ -0,0 +1,111 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLockRestore(t *testing.T) {
+	indexFile := createTestPack(t, 2)
+	dir := filepath.Dir(indexFile)
+	index, err := LoadIndex(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if locked, err := index.UpdateLock(); err != nil || locked {
+		t.Fatalf("Expected no lock file to be updated before one is created, got %v, %v", locked, err)
+	}
+
+	lock, err := index.NewLock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.WriteLock(lock); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(index.LockPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err = index.LoadLock()
+	if err != nil {
+		t.Fatalf("Failed to load lock file: %v", err)
+	}
+	if diffs, err := lock.Compare(index); err != nil || len(diffs) > 0 {
+		t.Fatalf("Expected no differences after locking, got %v, %v", diffs, err)
+	}
+
+	// Change one file and remove the other
+	changedPath := filepath.Join(dir, "mods", "mod-000"+MetaExtension)
+	mod, err := LoadMod(changedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod.FileName = "mod-0-updated.jar"
+	mod.Tags = []string{"kept"}
+	if _, _, err := mod.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "mods", "mod-001"+MetaExtension)); err != nil {
+		t.Fatal(err)
+	}
+	// Reload the index, as files found by a previous refresh aren't removed
+	index, err = LoadIndex(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := lock.Compare(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []LockDifference{{"mods/mod-000.pw.toml", "changed"}, {"mods/mod-001.pw.toml", "missing"}}
+	if !slices.Equal(diffs, expected) {
+		t.Errorf("Expected differences %v, got %v", expected, diffs)
+	}
+
+	restored, err := lock.Restore(&index)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if !slices.Equal(restored, []string{"mods/mod-000.pw.toml", "mods/mod-001.pw.toml"}) {
+		t.Errorf("Unexpected restored files %v", restored)
+	}
+	if diffs, err := lock.Compare(index); err != nil || len(diffs) > 0 {
+		t.Errorf("Expected no differences after restoring, got %v, %v", diffs, err)
+	}
+	mod, err = LoadMod(changedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.FileName != "mod-0.jar" || !slices.Equal(mod.Tags, []string{"kept"}) {
+		t.Errorf("Expected the locked file name and the tags to be kept, got %q and %v", mod.FileName, mod.Tags)
+	}
+	if mismatches := index.Verify(); len(mismatches) > 0 {
+		t.Errorf("Expected index hashes to be updated, got %v", mismatches)
+	}
+
+	// The lock file is rewritten identically when nothing changed
+	if locked, err := index.UpdateLock(); err != nil || !locked {
+		t.Fatalf("Expected the lock file to be updated, got %v, %v", locked, err)
+	}
+	rewritten, err := os.ReadFile(index.LockPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rewritten) != string(written) {
+		t.Errorf("Expected the lock file to be unchanged, got:\n%s\nexpected:\n%s", rewritten, written)
+	}
+
+	lock.Files = append(lock.Files, LockedFile{Path: "../outside.pw.toml", Name: "Outside"})
+	if _, err := lock.Restore(&index); err == nil {
+		t.Error("Expected an error for a path outside the pack")
+	}
+}
@@ -2,10 +2,12 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -24,6 +26,13 @@ type Mod struct {
 	updateData map[string]interface{}
 
 	Option *ModOption `toml:"option,omitempty"`
+
+	// Dependencies lists the project IDs of the required dependencies of this mod, as used by its update source
+	Dependencies []string `toml:"dependencies,omitempty"`
+	// AddedAsDependency is true if this mod was added automatically as a dependency of another mod
+	AddedAsDependency bool `toml:"added-as-dependency,omitempty"`
+	// Tags are freeform labels, which can be used to export only some of the files in a pack
+	Tags []string `toml:"tags,omitempty"`
 }
 
 const (
@@ -38,6 +47,10 @@ type ModDownload struct {
 	Hash       string `toml:"hash"`
 	// Mode defaults to modeURL (i.e. use URL when omitted or empty)
 	Mode string `toml:"mode,omitempty"`
+	// FileSize is the size of the file in bytes, if known
+	FileSize int64 `toml:"filesize,omitzero"`
+	// Mirrors are alternative URLs for the file, tried in order if downloading from URL fails
+	Mirrors []string `toml:"mirrors,omitempty"`
 }
 
 // ModOption specifies optional metadata for this mod file
@@ -55,10 +68,27 @@ const (
 	EmptySide     = ""
 )
 
+// ValidateSide checks that side is one of the possible values of Side
+func ValidateSide(side string) error {
+	if !slices.Contains([]string{EmptySide, ClientSide, ServerSide, UniversalSide}, side) {
+		return fmt.Errorf("invalid side %s, must be one of client, server or both", side)
+	}
+	return nil
+}
+
 // LoadMod attempts to load a mod file from a path
 func LoadMod(modFile string) (Mod, error) {
+	data, err := os.ReadFile(modFile)
+	if err != nil {
+		return Mod{}, err
+	}
+	return ParseMod(data, modFile)
+}
+
+// ParseMod parses the contents of a metadata file, which is treated as being at the given path
+func ParseMod(data []byte, modFile string) (Mod, error) {
 	var mod Mod
-	if _, err := toml.DecodeFile(modFile, &mod); err != nil {
+	if _, err := toml.Decode(string(data), &mod); err != nil {
 		return Mod{}, err
 	}
 	mod.updateData = make(map[string]interface{})
@@ -87,35 +117,21 @@ func (m *Mod) SetMetaPath(metaFile string) string {
 
 // Write saves the mod file, returning a hash format and the value of the hash of the saved file
 func (m Mod) Write() (string, string, error) {
-	f, err := os.Create(m.metaFile)
-	if err != nil {
-		// Attempt to create the containing directory
-		err2 := os.MkdirAll(filepath.Dir(m.metaFile), os.ModePerm)
-		if err2 == nil {
-			f, err = os.Create(m.metaFile)
-		}
-		if err != nil {
-			return "sha256", "", err
-		}
-	}
-
-	h, err := GetHashImpl("sha256")
+	hashFormat := GetDefaultHashFormat()
+	h, err := GetHashImpl(hashFormat)
 	if err != nil {
-		_ = f.Close()
 		return "", "", err
 	}
-	w := io.MultiWriter(h, f)
-
-	enc := toml.NewEncoder(w)
-	// Disable indentation
-	enc.Indent = ""
-	err = enc.Encode(m)
-	hashString := h.HashToString(h.Sum(nil))
+	// Create the containing directory, if it doesn't exist
+	err = os.MkdirAll(filepath.Dir(m.metaFile), os.ModePerm)
 	if err != nil {
-		_ = f.Close()
-		return "sha256", hashString, err
+		return hashFormat, "", err
 	}
-	return "sha256", hashString, f.Close()
+
+	err = writeFileAtomic(m.metaFile, func(w io.Writer) error {
+		return encodeToml(io.MultiWriter(h, w), m)
+	})
+	return hashFormat, h.HashToString(h.Sum(nil)), err
 }
 
 // GetParsedUpdateData can be used to retrieve updater-specific information after parsing a mod file
@@ -124,6 +140,25 @@ func (m Mod) GetParsedUpdateData(updaterName string) (interface{}, bool) {
 	return upd, ok
 }
 
+// GetProjectPage returns the URL of the web page of the project this mod was installed from, using the first of its
+// update sources that can link to one
+func (m *Mod) GetProjectPage() (string, error) {
+	sources := make([]string, 0, len(m.Update))
+	for source := range m.Update {
+		sources = append(sources, source)
+	}
+	slices.Sort(sources)
+	for _, source := range sources {
+		if updater, ok := Updaters[source].(ProjectPageUpdater); ok {
+			return updater.ProjectPage(m)
+		}
+	}
+	if len(sources) == 0 {
+		return "", fmt.Errorf("%s was added from a URL, so it has no project page", m.Name)
+	}
+	return "", fmt.Errorf("the update source of %s (%s) has no project page", m.Name, strings.Join(sources, ", "))
+}
+
 // GetFilePath is a clumsy hack that I made because Mod already stores it's path anyway
 func (m Mod) GetFilePath() string {
 	return m.metaFile
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SetField sets a field of the metadata file, given by its dotted key as written in the TOML file (e.g. side,
+// download.url or option.optional), to a value given as a string. The value is converted to the type of the field:
+// lists are given as comma separated values, and fields of update data keep the type of their current value. Unknown
+// keys and invalid values are rejected, leaving the mod unchanged. Returns true if the value of the field changed.
+func (m *Mod) SetField(key string, value string) (bool, error) {
+	updated := *m
+	var err error
+	if source, field, ok := strings.Cut(strings.TrimPrefix(key, "update."), "."); ok && strings.HasPrefix(key, "update.") {
+		err = updated.setUpdateField(source, field, value)
+	} else {
+		err = setStructField(reflect.ValueOf(&updated).Elem(), key, strings.Split(key, "."), value)
+	}
+	if err == nil {
+		err = updated.validateField(key)
+	}
+	if err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(*m, updated) {
+		return false, nil
+	}
+	*m = updated
+	return true, nil
+}
+
+// setStructField sets the field of a struct at the given path of TOML keys
+func setStructField(v reflect.Value, key string, path []string, value string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if !field.IsExported() || name != path[0] {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			// Copy the value, so that the original mod isn't changed
+			elem := reflect.New(fv.Type().Elem())
+			if !fv.IsNil() {
+				elem.Elem().Set(fv.Elem())
+			}
+			fv.Set(elem)
+			fv = elem.Elem()
+		}
+		if fv.Kind() == reflect.Map {
+			return fmt.Errorf("fields of %s are set with keys like %s.<source>.<key>", path[0], path[0])
+		}
+		if fv.Kind() == reflect.Struct {
+			if len(path) == 1 {
+				return fmt.Errorf("%s is a table; set one of its keys instead (%s)", key, strings.Join(structKeys(fv.Type()), ", "))
+			}
+			return setStructField(fv, key, path[1:], value)
+		}
+		if len(path) > 1 {
+			return fmt.Errorf("unknown key %s: %s is not a table", key, strings.TrimSuffix(key, "."+strings.Join(path[1:], ".")))
+		}
+		return setValue(fv, key, value)
+	}
+	return fmt.Errorf("unknown key %s, must be one of %s", key, strings.Join(structKeys(t), ", "))
+}
+
+// structKeys returns the TOML keys of the fields of a struct
+func structKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ","); t.Field(i).IsExported() && name != "" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// setValue converts a value given as a string to the type of a field, and sets it
+func setValue(v reflect.Value, key string, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %s for %s, must be true or false", value, key)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %s for %s, must be an integer", value, key)
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("%s can't be set with this command", key)
+		}
+		var values []string
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+		v.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("%s can't be set with this command", key)
+	}
+	return nil
+}
+
+// setUpdateField sets a field of the update data of the given source, keeping the type of its current value
+func (m *Mod) setUpdateField(source string, field string, value string) error {
+	updater, ok := Updaters[source]
+	if !ok {
+		return fmt.Errorf("unknown update source %s", source)
+	}
+	if strings.Contains(field, ".") {
+		return fmt.Errorf("unknown key update.%s.%s: %s is not a table", source, field, field)
+	}
+	var parsed interface{} = value
+	current := m.Update[source][field]
+	switch current.(type) {
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %s for update.%s.%s, must be true or false", value, source, field)
+		}
+		parsed = b
+	case int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %s for update.%s.%s, must be an integer", value, source, field)
+		}
+		parsed = n
+	case nil:
+		// New fields are converted to the first type that fits
+		if b, err := strconv.ParseBool(value); err == nil {
+			parsed = b
+		} else if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			parsed = n
+		}
+	}
+
+	// Copy the update data, so that the original mod isn't changed
+	m.Update = maps.Clone(m.Update)
+	if m.Update == nil {
+		m.Update = make(map[string]map[string]interface{})
+	}
+	m.Update[source] = maps.Clone(m.Update[source])
+	if m.Update[source] == nil {
+		m.Update[source] = make(map[string]interface{})
+	}
+	m.Update[source][field] = parsed
+
+	updateData, err := updater.ParseUpdate(m.Update[source])
+	if err != nil {
+		return fmt.Errorf("invalid value %s for update.%s.%s: %w", value, source, field, err)
+	}
+	m.updateData = maps.Clone(m.updateData)
+	if m.updateData == nil {
+		m.updateData = make(map[string]interface{})
+	}
+	m.updateData[source] = updateData
+	return nil
+}
+
+// validateField checks the value of a field after it has been set
+func (m *Mod) validateField(key string) error {
+	switch key {
+	case "name":
+		if strings.TrimSpace(m.Name) == "" {
+			return fmt.Errorf("%s must not be empty", key)
+		}
+	case "filename":
+		if strings.TrimSpace(m.FileName) == "" {
+			return fmt.Errorf("%s must not be empty", key)
+		}
+	case "side":
+		return ValidateSide(m.Side)
+	case "download.hash-format":
+		return ValidateHashFormat(m.Download.HashFormat)
+	case "download.mode":
+		if !slices.Contains([]string{"", ModeURL, ModeCF}, m.Download.Mode) {
+			return fmt.Errorf("invalid download mode %s, must be %s or %s", m.Download.Mode, ModeURL, ModeCF)
+		}
+	case "tags":
+		// Tags are kept sorted, as by AddTag
+		slices.Sort(m.Tags)
+		m.Tags = slices.Compact(m.Tags)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ModIDConflict describes files in the pack that declare the same mod ID, so the mod loader would refuse to start
+type ModIDConflict struct {
+	ModID string
+	// Paths are the paths of the metadata files (or other jar files in the pack) that declare the mod ID, relative to
+	// the pack root
+	Paths []string
+}
+
+// ReadJarModIDs returns the mod IDs declared by a jar file, in its fabric.mod.json, quilt.mod.json or (Neo)Forge
+// mods.toml, including IDs it provides for other mods. Jars nested inside the jar aren't read.
+func ReadJarModIDs(r io.ReaderAt, size int64) ([]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, f := range zr.File {
+		var fileIDs []string
+		switch f.Name {
+		case "fabric.mod.json":
+			var data struct {
+				ID       string   `json:"id"`
+				Provides []string `json:"provides"`
+			}
+			err = decodeZipFile(f, func(r io.Reader) error { return json.NewDecoder(r).Decode(&data) })
+			fileIDs = append([]string{data.ID}, data.Provides...)
+		case "quilt.mod.json":
+			var data struct {
+				Loader struct {
+					ID string `json:"id"`
+					// Provides entries are either IDs or objects with an id field
+					Provides []json.RawMessage `json:"provides"`
+				} `json:"quilt_loader"`
+			}
+			err = decodeZipFile(f, func(r io.Reader) error { return json.NewDecoder(r).Decode(&data) })
+			fileIDs = append(fileIDs, data.Loader.ID)
+			for _, raw := range data.Loader.Provides {
+				var provided struct {
+					ID string `json:"id"`
+				}
+				if json.Unmarshal(raw, &provided.ID) != nil {
+					_ = json.Unmarshal(raw, &provided)
+				}
+				fileIDs = append(fileIDs, provided.ID)
+			}
+		case "META-INF/mods.toml", "META-INF/neoforge.mods.toml":
+			var data struct {
+				Mods []struct {
+					ModID string `toml:"modId"`
+				} `toml:"mods"`
+			}
+			err = decodeZipFile(f, func(r io.Reader) error {
+				_, err := toml.NewDecoder(r).Decode(&data)
+				return err
+			})
+			for _, mod := range data.Mods {
+				fileIDs = append(fileIDs, mod.ModID)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		ids = append(ids, fileIDs...)
+	}
+	ids = slices.DeleteFunc(ids, func(id string) bool { return id == "" })
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+func decodeZipFile(f *zip.File, decode func(r io.Reader) error) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return decode(r)
+}
+
+// readFileModIDs returns the mod IDs declared by a jar file on disk
+func readFileModIDs(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return ReadJarModIDs(f, info.Size())
+}
+
+// isJarFile returns true if a file name is that of a jar that may be a mod
+func isJarFile(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".jar")
+}
+
+// FindModIDConflicts finds jar files in the pack that declare the same mod ID and would be installed together. Files
+// downloaded by metadata files are read from the download cache; files that aren't in the cache are downloaded if
+// download is set, and skipped otherwise. The number of skipped files is returned.
+func (in Index) FindModIDConflicts(ctx context.Context, download bool) ([]ModIDConflict, int, error) {
+	mods, err := in.LoadAllMods()
+	if err != nil {
+		return nil, 0, err
+	}
+	type jarFile struct {
+		path string
+		side string
+	}
+	idFiles := make(map[string][]jarFile)
+	addIDs := func(path string, side string, ids []string) {
+		for _, id := range ids {
+			idFiles[id] = append(idFiles[id], jarFile{path, side})
+		}
+	}
+	relPath := func(mod *Mod) string {
+		p, err := in.RelIndexPath(mod.GetFilePath())
+		if err != nil {
+			return mod.GetFilePath()
+		}
+		return p
+	}
+
+	// Jar files stored directly in the pack
+	for p, holder := range in.Files {
+		if holder.IsMetaFile() || !isJarFile(p) {
+			continue
+		}
+		ids, err := readFileModIDs(in.ResolveIndexPath(p))
+		if err != nil {
+			continue
+		}
+		addIDs(p, EmptySide, ids)
+	}
+
+	cacheIndex, err := loadCacheIndex()
+	if err != nil {
+		return nil, 0, err
+	}
+	// Importing files may have changed the cache index
+	if err := cacheIndex.save(); err != nil {
+		return nil, 0, err
+	}
+	var uncached []*Mod
+	for _, mod := range mods {
+		if !isJarFile(mod.FileName) {
+			continue
+		}
+		handle := cacheIndex.GetHandleFromHash(mod.Download.HashFormat, mod.Download.Hash)
+		if handle == nil {
+			uncached = append(uncached, mod)
+			continue
+		}
+		ids, err := readFileModIDs(handle.Path())
+		if err != nil {
+			continue
+		}
+		addIDs(relPath(mod), mod.Side, ids)
+	}
+
+	skipped := 0
+	if !download {
+		skipped = len(uncached)
+	} else if len(uncached) > 0 {
+		session, err := CreateDownloadSession(uncached, []string{})
+		if err != nil {
+			return nil, 0, err
+		}
+		skipped = len(session.GetManualDownloads())
+		for dl := range session.StartDownloadsContext(ctx, nil) {
+			if dl.Error != nil {
+				skipped++
+				continue
+			}
+			ids, err := readFileModIDs(dl.File.Name())
+			_ = dl.File.Close()
+			if err != nil {
+				continue
+			}
+			addIDs(relPath(dl.Mod), dl.Mod.Side, ids)
+		}
+		if err := session.SaveIndex(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var conflicts []ModIDConflict
+	for id, files := range idFiles {
+		overlapping := false
+		for i := range files {
+			for j := i + 1; j < len(files); j++ {
+				if files[i].path != files[j].path && sidesOverlap(files[i].side, files[j].side) {
+					overlapping = true
+				}
+			}
+		}
+		if !overlapping {
+			continue
+		}
+		conflict := ModIDConflict{ModID: id}
+		for _, f := range files {
+			conflict.Paths = append(conflict.Paths, f.path)
+		}
+		slices.Sort(conflict.Paths)
+		conflict.Paths = slices.Compact(conflict.Paths)
+		conflicts = append(conflicts, conflict)
+	}
+	slices.SortFunc(conflicts, func(a, b ModIDConflict) int {
+		return strings.Compare(a.ModID, b.ModID)
+	})
+	return conflicts, skipped, nil
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// createTestJar returns a jar containing the given files
+func createTestJar(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadJarModIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{"fabric", map[string]string{
+			"fabric.mod.json": `{"schemaVersion": 1, "id": "examplemod", "provides": ["example"]}`,
+		}, []string{"example", "examplemod"}},
+		{"quilt", map[string]string{
+			"quilt.mod.json": `{"quilt_loader": {"id": "quiltmod", "provides": ["a", {"id": "b", "version": "1.0"}]}}`,
+		}, []string{"a", "b", "quiltmod"}},
+		{"forge", map[string]string{
+			"META-INF/mods.toml": "modLoader = \"javafml\"\n[[mods]]\nmodId = \"forgemod\"\n[[mods]]\nmodId = \"forgemod2\"\n",
+		}, []string{"forgemod", "forgemod2"}},
+		{"multiloader", map[string]string{
+			"fabric.mod.json":             `{"id": "examplemod"}`,
+			"META-INF/neoforge.mods.toml": "[[mods]]\nmodId = \"examplemod\"\n",
+		}, []string{"examplemod"}},
+		{"not a mod", map[string]string{"assets/a.txt": "a"}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jar := createTestJar(t, test.files)
+			ids, err := ReadJarModIDs(bytes.NewReader(jar), int64(len(jar)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(ids, test.expected) {
+				t.Errorf("Expected mod IDs %v, got %v", test.expected, ids)
+			}
+		})
+	}
+}
+
+func TestFindModIDConflicts(t *testing.T) {
+	viper.Set("cache.directory", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("cache.directory", "")
+	})
+	jars := map[string][]byte{
+		"/forge.jar":  createTestJar(t, map[string]string{"META-INF/mods.toml": "[[mods]]\nmodId = \"examplemod\"\n"}),
+		"/client.jar": createTestJar(t, map[string]string{"fabric.mod.json": `{"id": "sidemod"}`}),
+		"/server.jar": createTestJar(t, map[string]string{"fabric.mod.json": `{"id": "sidemod"}`}),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(jars[r.URL.Path])
+	}))
+	defer srv.Close()
+
+	indexFile := createTestPack(t, 0)
+	dir := filepath.Dir(indexFile)
+	if err := os.MkdirAll(filepath.Join(dir, "mods"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeMod := func(name string, side string) {
+		hash := sha256.Sum256(jars["/"+name+".jar"])
+		contents := "name = \"" + name + "\"\nfilename = \"" + name + ".jar\"\nside = \"" + side + "\"\n[download]\nurl = \"" +
+			srv.URL + "/" + name + ".jar\"\nhash-format = \"sha256\"\nhash = \"" + hex.EncodeToString(hash[:]) + "\"\n"
+		if err := os.WriteFile(filepath.Join(dir, "mods", name+".pw.toml"), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeMod("forge", UniversalSide)
+	writeMod("client", ClientSide)
+	writeMod("server", ServerSide)
+	rawJar := createTestJar(t, map[string]string{"fabric.mod.json": `{"id": "examplemod"}`})
+	if err := os.WriteFile(filepath.Join(dir, "mods", "fabric.jar"), rawJar, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := LoadIndex(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts, skipped, err := index.FindModIDConflicts(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 || skipped != 3 {
+		t.Errorf("Expected 3 files to be skipped without downloading, got %v conflicts and %v skipped", conflicts, skipped)
+	}
+
+	expected := []ModIDConflict{{ModID: "examplemod", Paths: []string{"mods/fabric.jar", "mods/forge.pw.toml"}}}
+	conflicts, skipped, err = index.FindModIDConflicts(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(conflicts, expected) || skipped != 0 {
+		t.Errorf("Expected conflicts %v, got %v (%v skipped)", expected, conflicts, skipped)
+	}
+
+	// Downloaded files are now read from the cache
+	conflicts, skipped, err = index.FindModIDConflicts(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(conflicts, expected) || skipped != 0 {
+		t.Errorf("Expected conflicts %v from the cache, got %v (%v skipped)", expected, conflicts, skipped)
+	}
+}
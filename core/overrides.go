@@ -0,0 +1,99 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// OverridesExportKey is the table in the export section of pack.toml ([export.overrides]) that selects the files that
+// are included when exporting the pack
+const OverridesExportKey = "overrides"
+
+// overrideRulesConfig is the [export.overrides] table in pack.toml. Patterns use the same syntax as .packwizignore and
+// are matched against paths relative to the pack root; the include and exclude lists apply to both sides.
+type overrideRulesConfig struct {
+	Include       []string `mapstructure:"include"`
+	Exclude       []string `mapstructure:"exclude"`
+	ClientInclude []string `mapstructure:"client-include"`
+	ClientExclude []string `mapstructure:"client-exclude"`
+	ServerInclude []string `mapstructure:"server-include"`
+	ServerExclude []string `mapstructure:"server-exclude"`
+}
+
+// OverrideRules selects which files that aren't metadata files (such as configs) are included when exporting the pack
+// for a side. The zero value includes every file inside the pack root.
+type OverrideRules struct {
+	include *gitignore.GitIgnore
+	exclude *gitignore.GitIgnore
+}
+
+// GetOverrideRules returns the rules for exporting the pack for a side, from [export.overrides] in pack.toml and the
+// given extra patterns. For the client and server sides, the rules for that side apply as well as those for both
+// sides; for UniversalSide, only the rules for both sides apply.
+func (pack Pack) GetOverrideRules(side string, include []string, exclude []string) (OverrideRules, error) {
+	var config overrideRulesConfig
+	if raw, ok := pack.Export[OverridesExportKey]; ok {
+		if err := mapstructure.Decode(raw, &config); err != nil {
+			return OverrideRules{}, fmt.Errorf("failed to parse [export.%s] in pack.toml: %w", OverridesExportKey, err)
+		}
+	}
+	include = slices.Concat(config.Include, include)
+	exclude = slices.Concat(config.Exclude, exclude)
+	switch side {
+	case ClientSide:
+		include = slices.Concat(include, config.ClientInclude)
+		exclude = slices.Concat(exclude, config.ClientExclude)
+	case ServerSide:
+		include = slices.Concat(include, config.ServerInclude)
+		exclude = slices.Concat(exclude, config.ServerExclude)
+	}
+	for _, pattern := range slices.Concat(include, exclude) {
+		if err := validateOverridePattern(pattern); err != nil {
+			return OverrideRules{}, err
+		}
+	}
+
+	var rules OverrideRules
+	if len(include) > 0 {
+		rules.include = gitignore.CompileIgnoreLines(include...)
+	}
+	if len(exclude) > 0 {
+		rules.exclude = gitignore.CompileIgnoreLines(exclude...)
+	}
+	return rules, nil
+}
+
+// validateOverridePattern checks that a pattern only matches paths inside the pack root
+func validateOverridePattern(pattern string) error {
+	// As in .packwizignore, a leading slash anchors the pattern to the pack root
+	p := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(pattern), "!"), "/")
+	if p == "" {
+		return fmt.Errorf("invalid override pattern %q: patterns must not be empty", pattern)
+	}
+	if filepath.VolumeName(p) != "" {
+		return fmt.Errorf("invalid override pattern %q: patterns must be relative to the pack root", pattern)
+	}
+	for _, part := range strings.Split(strings.ReplaceAll(p, "\\", "/"), "/") {
+		if part == ".." {
+			return fmt.Errorf("invalid override pattern %q: patterns must not refer to files outside the pack", pattern)
+		}
+	}
+	return nil
+}
+
+// Includes returns true if the file at the given index path (relative to the pack root, in forward slash format) is
+// included by the rules. Files outside the pack root are never included.
+func (r OverrideRules) Includes(p string) bool {
+	if !filepath.IsLocal(filepath.FromSlash(p)) {
+		return false
+	}
+	if r.include != nil && !r.include.MatchesPath(p) {
+		return false
+	}
+	return r.exclude == nil || !r.exclude.MatchesPath(p)
+}
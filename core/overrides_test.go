@@ -0,0 +1,68 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestOverrideRules(t *testing.T) {
+	var pack Pack
+	_, err := toml.Decode(`[export.overrides]
+exclude = ["*.bak"]
+client-exclude = ["config/server/"]
+server-exclude = ["options.txt", "config/client-*.toml"]
+server-include = ["config/", "/options.txt", "server.properties"]
+`, &pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path   string
+		client bool
+		server bool
+		both   bool
+	}{
+		{"config/common.toml", true, true, true},
+		{"config/client-ui.toml", true, false, true},
+		{"config/server/world.toml", false, true, true},
+		{"config/common.toml.bak", false, false, false},
+		{"options.txt", true, false, true},
+		{"server.properties", true, true, true},
+		{"resourcepacks/pack.zip", true, false, true},
+		{"../outside.txt", false, false, false},
+	}
+	for _, side := range []string{ClientSide, ServerSide, UniversalSide} {
+		rules, err := pack.GetOverrideRules(side, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to get %s rules: %v", side, err)
+		}
+		for _, tt := range tests {
+			expected := map[string]bool{ClientSide: tt.client, ServerSide: tt.server, UniversalSide: tt.both}[side]
+			if rules.Includes(tt.path) != expected {
+				t.Errorf("Expected %s to be included for %s: %v", tt.path, side, expected)
+			}
+		}
+	}
+
+	// Patterns from flags are added to those in pack.toml
+	rules, err := pack.GetOverrideRules(ClientSide, nil, []string{"resourcepacks/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Includes("resourcepacks/pack.zip") || !rules.Includes("config/common.toml") {
+		t.Error("Expected the extra exclude pattern to be applied")
+	}
+
+	// The zero value includes everything inside the pack
+	if !(OverrideRules{}).Includes("config/common.toml") || (OverrideRules{}).Includes("../outside.txt") {
+		t.Error("Expected the zero value to include only files inside the pack")
+	}
+
+	for _, pattern := range []string{"../secrets", "config/../../x", "!../x", ""} {
+		if _, err := pack.GetOverrideRules(ClientSide, []string{pattern}, nil); err == nil {
+			t.Errorf("Expected pattern %q to be rejected", pattern)
+		}
+	}
+}
@@ -30,6 +30,18 @@ type Pack struct {
 	Versions map[string]string                 `toml:"versions"`
 	Export   map[string]map[string]interface{} `toml:"export"`
 	Options  map[string]interface{}            `toml:"options"`
+	// Folders maps project types (mod, resourcepack, shaderpack, datapack, plugin or world) to the folder, in forward
+	// slash format, that new metadata files of that type are added to. Like the default folders for each type (mods,
+	// resourcepacks, etc.), which are used for types that aren't listed, it is resolved from meta-folder-base (the pack
+	// root by default) and must be within it.
+	Folders map[string]string `toml:"folders,omitempty"`
+
+	// gameVersionOverride replaces the Minecraft version when finding files to add; see WithGameVersion
+	gameVersionOverride string
+	// templates stores the values that were expanded from environment variables, keyed by their path
+	templates map[string]packTemplate
+	// loadedFormat is the pack-format field as it was in pack.toml, before the pack was migrated
+	loadedFormat string
 }
 
 const CurrentPackFormat = "packwiz:1.1.0"
@@ -45,6 +57,27 @@ func mustParseConstraint(s string) *semver.Constraints {
 	return c
 }
 
+// userOnlyOptions are the settings that can't be set in the options of pack.toml, only in the user config: hooks run
+// shell commands, so packs must not be able to set them
+var userOnlyOptions = []string{"hooks"}
+
+// packViperOptions returns the options of a pack that are read into viper, leaving out userOnlyOptions (whether they
+// are given as a table, or as a dotted key such as "hooks.post-refresh")
+func packViperOptions(options map[string]interface{}) map[string]interface{} {
+	filtered := make(map[string]interface{}, len(options))
+	for k, v := range options {
+		key := strings.ToLower(k)
+		if slices.ContainsFunc(userOnlyOptions, func(name string) bool {
+			return key == name || strings.HasPrefix(key, name+".")
+		}) {
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: ignoring options.%s in pack.toml; it can only be set in your user config\n", k)
+			continue
+		}
+		filtered[k] = v
+	}
+	return filtered
+}
+
 // LoadPack loads the modpack metadata to a Pack struct
 func LoadPack() (Pack, error) {
 	var modpack Pack
@@ -52,34 +85,40 @@ func LoadPack() (Pack, error) {
 		return Pack{}, err
 	}
 
+	modpack = expandPackEnv(modpack)
+
 	// Check pack-format
+	modpack.loadedFormat = modpack.PackFormat
 	if len(modpack.PackFormat) == 0 {
-		fmt.Println("Modpack manifest has no pack-format field; assuming packwiz:1.1.0")
-		modpack.PackFormat = "packwiz:1.1.0"
+		fmt.Printf("Modpack manifest has no pack-format field; assuming %s\n", CurrentPackFormat)
+		modpack.PackFormat = CurrentPackFormat
 	}
-	// Auto-migrate versions
-	if modpack.PackFormat == "packwiz:1.0.0" {
-		fmt.Println("Automatically migrating pack to packwiz:1.1.0 format...")
-		modpack.PackFormat = "packwiz:1.1.0"
-	}
-	if !strings.HasPrefix(modpack.PackFormat, "packwiz:") {
-		return Pack{}, errors.New("pack-format field does not indicate a valid packwiz pack")
-	}
-	ver, err := semver.StrictNewVersion(strings.TrimPrefix(modpack.PackFormat, "packwiz:"))
+	status, err := CheckPackFormat(modpack.PackFormat)
 	if err != nil {
-		return Pack{}, fmt.Errorf("pack-format field is not valid semver: %w", err)
-	}
-	if !PackFormatConstraintAccepted.Check(ver) {
-		return Pack{}, errors.New("the modpack is incompatible with this version of packwiz; please update")
+		return Pack{}, err
 	}
-	if !PackFormatConstraintSuggestUpgrade.Check(ver) {
-		fmt.Println("Modpack has a newer feature number than is supported by this version of packwiz. Update to the latest version of packwiz for new features and bugfixes!")
+	switch status {
+	case PackFormatOutdated:
+		// Auto-migrate versions; packwiz utils migrate-format writes the migrated pack
+		fmt.Printf("Automatically migrating pack to %s format...\n", CurrentPackFormat)
+		if _, err := modpack.MigrateFormat(); err != nil {
+			return Pack{}, err
+		}
+	case PackFormatNewer:
+		fmt.Println("Modpack has a newer feature number than is supported by this version of packwiz, so it can't be modified. Update to the latest version of packwiz for new features and bugfixes!")
 	}
-	// TODO: suggest migration if necessary (primarily for 2.0.0)
 
 	// Read options into viper
 	if modpack.Options != nil {
-		err := viper.MergeConfigMap(modpack.Options)
+		err := viper.MergeConfigMap(packViperOptions(modpack.Options))
+		if err != nil {
+			return Pack{}, err
+		}
+	}
+
+	// Read project type folders into viper, so they are used by add commands
+	if modpack.Folders != nil {
+		err := viper.MergeConfigMap(map[string]interface{}{"folders": modpack.Folders})
 		if err != nil {
 			return Pack{}, err
 		}
@@ -103,7 +142,7 @@ func (pack Pack) LoadIndex() (Index, error) {
 // UpdateIndexHash recalculates the hash of the index file of this modpack
 func (pack *Pack) UpdateIndexHash() error {
 	if viper.GetBool("no-internal-hashes") {
-		pack.Index.HashFormat = "sha256"
+		pack.Index.HashFormat = GetDefaultHashFormat()
 		pack.Index.Hash = ""
 		return nil
 	}
@@ -117,9 +156,9 @@ func (pack *Pack) UpdateIndexHash() error {
 	}
 
 	// Hash usage strategy (may change):
-	// Just use SHA256, overwrite existing hash regardless of what it is
-	// May update later to continue using the same hash that was already being used
-	h, err := GetHashImpl("sha256")
+	// Just use the default hash format, overwrite existing hash regardless of what it is
+	hashFormat := GetDefaultHashFormat()
+	h, err := GetHashImpl(hashFormat)
 	if err != nil {
 		_ = f.Close()
 		return err
@@ -130,31 +169,35 @@ func (pack *Pack) UpdateIndexHash() error {
 	}
 	hashString := h.HashToString(h.Sum(nil))
 
-	pack.Index.HashFormat = "sha256"
+	pack.Index.HashFormat = hashFormat
 	pack.Index.Hash = hashString
 	return f.Close()
 }
 
-// Write saves the pack file
+// Write saves the pack file. Packs with a newer format than CurrentPackFormat aren't written, as fields that this version
+// of packwiz doesn't know about could be lost.
 func (pack Pack) Write() error {
-	f, err := os.Create(viper.GetString("pack-file"))
-	if err != nil {
+	if err := pack.checkWritable(); err != nil {
 		return err
 	}
+	return writeFileAtomic(viper.GetString("pack-file"), func(w io.Writer) error {
+		// Keep environment variable references, rather than writing the values they were expanded to
+		return encodeToml(w, unexpandPackEnv(pack))
+	})
+}
 
-	enc := toml.NewEncoder(f)
-	// Disable indentation
-	enc.Indent = ""
-	err = enc.Encode(pack)
-	if err != nil {
-		_ = f.Close()
-		return err
-	}
-	return f.Close()
+// WithGameVersion returns a copy of the pack that finds files for the given Minecraft version instead of the pack's
+// Minecraft version and acceptable versions. The override isn't written to pack.toml.
+func (pack Pack) WithGameVersion(version string) Pack {
+	pack.gameVersionOverride = version
+	return pack
 }
 
 // GetMCVersion gets the version of Minecraft this pack uses, if it has been correctly specified
 func (pack Pack) GetMCVersion() (string, error) {
+	if pack.gameVersionOverride != "" {
+		return pack.gameVersionOverride, nil
+	}
 	mcVersion, ok := pack.Versions["minecraft"]
 	if !ok {
 		return "", errors.New("no minecraft version specified in modpack")
@@ -164,6 +207,9 @@ func (pack Pack) GetMCVersion() (string, error) {
 
 // GetSupportedMCVersions gets the versions of Minecraft this pack allows in downloaded mods, ordered by preference (highest = most desirable)
 func (pack Pack) GetSupportedMCVersions() ([]string, error) {
+	if pack.gameVersionOverride != "" {
+		return []string{pack.gameVersionOverride}, nil
+	}
 	mcVersion, ok := pack.Versions["minecraft"]
 	if !ok {
 		return nil, errors.New("no minecraft version specified in modpack")
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestPackWithGameVersion(t *testing.T) {
+	pack := Pack{Name: "Test", Versions: map[string]string{"minecraft": "1.20.4"}}
+	override := pack.WithGameVersion("1.20.1")
+
+	if v, err := override.GetMCVersion(); err != nil || v != "1.20.1" {
+		t.Errorf("Expected overridden version 1.20.1, got %s (%v)", v, err)
+	}
+	if v, err := override.GetSupportedMCVersions(); err != nil || !slices.Equal(v, []string{"1.20.1"}) {
+		t.Errorf("Expected only the overridden version to be supported, got %v (%v)", v, err)
+	}
+	if v, _ := pack.GetMCVersion(); v != "1.20.4" {
+		t.Errorf("Expected the original pack to be unchanged, got %s", v)
+	}
+
+	// The override is only used to find files, and is never written to pack.toml
+	var buf bytes.Buffer
+	if err := encodeToml(&buf, override); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "1.20.1") || !strings.Contains(buf.String(), `minecraft = "1.20.4"`) {
+		t.Errorf("Expected the pack's own version to be written, got:\n%s", buf.String())
+	}
+}
+
+// TestLoadPackIgnoresHooks verifies that pack.toml can't set hooks, which run shell commands on the user's computer
+func TestLoadPackIgnoresHooks(t *testing.T) {
+	packPath := filepath.Join(t.TempDir(), "pack.toml")
+	contents := `name = "Test"
+pack-format = "packwiz:1.1.0"
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+[options]
+"hooks.pre-refresh" = "touch pwned"
+no-internal-hashes = true
+[options.hooks]
+post-refresh = "touch pwned"
+[options.HOOKS]
+post-update = "touch pwned"
+`
+	if err := os.WriteFile(packPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", packPath)
+	t.Cleanup(func() {
+		viper.Set("pack-file", oldPackFile)
+		viper.Set("no-internal-hashes", nil)
+	})
+
+	pack, err := LoadPack()
+	if err != nil {
+		t.Fatalf("Failed to load pack: %v", err)
+	}
+	for _, hook := range []string{"pre-refresh", "post-refresh", "post-update"} {
+		if command := viper.GetString("hooks." + hook); command != "" {
+			t.Errorf("Expected pack.toml not to set the %s hook, got %q", hook, command)
+		}
+	}
+	if !viper.GetBool("no-internal-hashes") {
+		t.Error("Expected other options to be read from pack.toml")
+	}
+	if _, ok := pack.Options["hooks"]; !ok {
+		t.Error("Expected the options of the pack to be kept as they are, so they are written back unchanged")
+	}
+}
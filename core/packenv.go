@@ -0,0 +1,122 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// packTemplate records a string value of pack.toml that contained environment variables, so the template (rather than
+// its expanded value) is written back to pack.toml
+type packTemplate struct {
+	raw      string
+	expanded string
+}
+
+// expandPackEnv expands ${VAR} and $VAR references to environment variables in the string values of the pack (other
+// than options, which configure packwiz itself); $$ is a literal $. Variables that aren't set expand to an empty string, with a warning. The templates of the expanded
+// values are stored in the pack, so that Write doesn't replace them with their expanded values.
+func expandPackEnv(pack Pack) Pack {
+	templates := make(map[string]packTemplate)
+	warned := make(map[string]bool)
+	expanded := mapPackStrings(pack, func(path string, value string) string {
+		if !strings.Contains(value, "$") {
+			return value
+		}
+		result := os.Expand(value, func(name string) string {
+			if name == "$" {
+				return "$"
+			}
+			v, ok := os.LookupEnv(name)
+			if !ok && !warned[name] {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: environment variable %s used in pack.toml is not set; it will be empty\n", name)
+				warned[name] = true
+			}
+			return v
+		})
+		templates[path] = packTemplate{raw: value, expanded: result}
+		return result
+	})
+	if len(templates) > 0 {
+		expanded.templates = templates
+	}
+	return expanded
+}
+
+// unexpandPackEnv returns a copy of the pack with values that were expanded from templates, and haven't been changed
+// since, replaced by their templates
+func unexpandPackEnv(pack Pack) Pack {
+	if len(pack.templates) == 0 {
+		return pack
+	}
+	return mapPackStrings(pack, func(path string, value string) string {
+		if t, ok := pack.templates[path]; ok && t.expanded == value {
+			return t.raw
+		}
+		return value
+	})
+}
+
+// mapPackStrings returns a copy of the pack with fn applied to each string value that can be set by the user (not
+// including the pack format, index hash or options); path is the TOML key of the value, with tables separated by dots.
+// Maps are copied, so the original pack isn't modified.
+func mapPackStrings(pack Pack, fn func(path string, value string) string) Pack {
+	pack.Name = fn("name", pack.Name)
+	pack.Author = fn("author", pack.Author)
+	pack.Version = fn("version", pack.Version)
+	pack.Description = fn("description", pack.Description)
+	pack.Index.File = fn("index.file", pack.Index.File)
+	pack.Versions = mapStringMap("versions", pack.Versions, fn)
+	pack.Folders = mapStringMap("folders", pack.Folders, fn)
+	if pack.Export != nil {
+		export := make(map[string]map[string]interface{}, len(pack.Export))
+		for k, v := range pack.Export {
+			export[k], _ = mapValueStrings("export."+k, v, fn).(map[string]interface{})
+		}
+		pack.Export = export
+	}
+	return pack
+}
+
+func mapStringMap(path string, m map[string]string, fn func(path string, value string) string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = fn(path+"."+k, v)
+	}
+	return out
+}
+
+// mapValueStrings applies fn to the strings in a value decoded from TOML, copying any tables and arrays
+func mapValueStrings(path string, value interface{}, fn func(path string, value string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(path, v)
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = mapValueStrings(path+"."+k, item, fn)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = mapValueStrings(path+"."+strconv.Itoa(i), item, fn)
+		}
+		return out
+	case []map[string]interface{}:
+		out := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			out[i], _ = mapValueStrings(path+"."+strconv.Itoa(i), item, fn).(map[string]interface{})
+		}
+		return out
+	default:
+		return value
+	}
+}
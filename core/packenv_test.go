@@ -0,0 +1,90 @@
+package core
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestPackEnvExpansion(t *testing.T) {
+	t.Setenv("PACKWIZ_TEST_HOST", "staging.example.com")
+	packFile := filepath.Join(t.TempDir(), "pack.toml")
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", packFile)
+	t.Cleanup(func() { viper.Set("pack-file", oldPackFile) })
+	data := `name = "Test"
+version = "${PACKWIZ_TEST_VERSION_UNSET}"
+description = "Costs $$5"
+pack-format = "packwiz:1.1.0"
+
+[index]
+file = "index.toml"
+hash-format = "sha256"
+hash = ""
+
+[export.example]
+url = "https://${PACKWIZ_TEST_HOST}/pack"
+
+[versions]
+minecraft = "1.20.1"
+
+[options]
+mods-folder = "${PACKWIZ_TEST_HOST}"
+`
+	if err := os.WriteFile(packFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Warnings must not be written to stdout, which is parsed by scripts
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = stdoutW
+	pack, err := LoadPack()
+	os.Stdout = oldStdout
+	_ = stdoutW.Close()
+	stdout, _ := io.ReadAll(stdoutR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stdout) > 0 {
+		t.Errorf("Expected nothing to be written to stdout, got %q", stdout)
+	}
+	if url := pack.Export["example"]["url"]; url != "https://staging.example.com/pack" {
+		t.Errorf("Expected the export URL to be expanded, got %v", url)
+	}
+	if pack.Version != "" {
+		t.Errorf("Expected an unset variable to expand to an empty string, got %q", pack.Version)
+	}
+	if pack.Description != "Costs $5" {
+		t.Errorf("Expected $$ to be a literal $, got %q", pack.Description)
+	}
+	if folder := pack.Options["mods-folder"]; folder != "${PACKWIZ_TEST_HOST}" {
+		t.Errorf("Expected options not to be expanded, got %v", folder)
+	}
+
+	// Unchanged values are written as their templates, changed values as they are
+	pack.Name = "Renamed"
+	pack.Description = "Free"
+	if err := pack.Write(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(packFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`name = "Renamed"`, `version = "${PACKWIZ_TEST_VERSION_UNSET}"`, `description = "Free"`, `url = "https://${PACKWIZ_TEST_HOST}/pack"`} {
+		if !strings.Contains(string(written), expected) {
+			t.Errorf("Expected written pack.toml to contain %s, got:\n%s", expected, written)
+		}
+	}
+	if pack.Export["example"]["url"] != "https://staging.example.com/pack" {
+		t.Error("Expected writing the pack not to change the loaded values")
+	}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ErrPackFormatTooNew is returned when a pack uses a format from a newer version of packwiz: packs with a newer major
+// version can't be read, and packs with a newer feature number can be read but not written, as fields that this
+// version doesn't know about could be lost
+var ErrPackFormatTooNew = errors.New("the modpack uses a newer format than this version of packwiz supports; please update packwiz")
+
+// PackFormatStatus describes how the format of a pack compares to CurrentPackFormat
+type PackFormatStatus int
+
+const (
+	// PackFormatCurrent is a format that this version of packwiz reads and writes
+	PackFormatCurrent PackFormatStatus = iota
+	// PackFormatOutdated is an older format, which is migrated to CurrentPackFormat when the pack is loaded
+	PackFormatOutdated
+	// PackFormatNewer is a format with a newer feature number, which can be read but not written
+	PackFormatNewer
+)
+
+// ParsePackFormat parses the value of the pack-format field of pack.toml (e.g. packwiz:1.1.0), returning its version
+func ParsePackFormat(format string) (*semver.Version, error) {
+	if !strings.HasPrefix(format, "packwiz:") {
+		return nil, errors.New("pack-format field does not indicate a valid packwiz pack")
+	}
+	ver, err := semver.StrictNewVersion(strings.TrimPrefix(format, "packwiz:"))
+	if err != nil {
+		return nil, fmt.Errorf("pack-format field is not valid semver: %w", err)
+	}
+	return ver, nil
+}
+
+// CheckPackFormat checks whether this version of packwiz can read a pack with the given format, returning an error
+// wrapping ErrPackFormatTooNew if the format is from a newer major version
+func CheckPackFormat(format string) (PackFormatStatus, error) {
+	ver, err := ParsePackFormat(format)
+	if err != nil {
+		return PackFormatCurrent, err
+	}
+	current, err := ParsePackFormat(CurrentPackFormat)
+	if err != nil {
+		return PackFormatCurrent, err
+	}
+	if !PackFormatConstraintAccepted.Check(ver) {
+		if ver.GreaterThan(current) {
+			return PackFormatCurrent, fmt.Errorf("%w (pack-format is %s, but the newest format supported is %s)", ErrPackFormatTooNew, format, CurrentPackFormat)
+		}
+		return PackFormatCurrent, fmt.Errorf("pack-format %s is too old to be read by this version of packwiz", format)
+	}
+	if !PackFormatConstraintSuggestUpgrade.Check(ver) {
+		if ver.GreaterThan(current) {
+			return PackFormatNewer, nil
+		}
+		return PackFormatOutdated, nil
+	}
+	return PackFormatCurrent, nil
+}
+
+// packFormatMigrations are the changes made to packs when upgrading from formats older than each version, in order
+var packFormatMigrations = []struct {
+	version string
+	migrate func(pack *Pack)
+}{
+	{"1.1.0", func(pack *Pack) {
+		// The mods-folder option was renamed to meta-folder
+		if folder, ok := pack.Options["mods-folder"]; ok {
+			if _, exists := pack.Options["meta-folder"]; !exists {
+				pack.Options["meta-folder"] = folder
+			}
+			delete(pack.Options, "mods-folder")
+		}
+	}},
+}
+
+// MigrateFormat upgrades a pack with an older format to CurrentPackFormat, adjusting any fields that have changed.
+// Returns true if the pack was changed; packs with a format that can't be written are rejected.
+func (pack *Pack) MigrateFormat() (bool, error) {
+	if pack.PackFormat == "" {
+		pack.PackFormat = CurrentPackFormat
+		return true, nil
+	}
+	if err := pack.checkWritable(); err != nil {
+		return false, err
+	}
+	if status, _ := CheckPackFormat(pack.PackFormat); status == PackFormatCurrent {
+		return false, nil
+	}
+	ver, err := ParsePackFormat(pack.PackFormat)
+	if err != nil {
+		return false, err
+	}
+	for _, migration := range packFormatMigrations {
+		if ver.LessThan(semver.MustParse(migration.version)) {
+			migration.migrate(pack)
+		}
+	}
+	pack.PackFormat = CurrentPackFormat
+	return true, nil
+}
+
+// LoadedFormat returns the value of the pack-format field when the pack was loaded, before it was migrated
+func (pack Pack) LoadedFormat() string {
+	return pack.loadedFormat
+}
+
+// checkWritable returns an error if the pack has a format that this version of packwiz can't write
+func (pack Pack) checkWritable() error {
+	if pack.PackFormat == "" {
+		return nil
+	}
+	status, err := CheckPackFormat(pack.PackFormat)
+	if err != nil {
+		return err
+	}
+	if status == PackFormatNewer {
+		return fmt.Errorf("%w (pack-format is %s, but this version of packwiz writes %s)", ErrPackFormatTooNew, pack.PackFormat, CurrentPackFormat)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCheckPackFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		status   PackFormatStatus
+		tooNew   bool
+		expected bool // whether the format can be read
+	}{
+		{"packwiz:1.0.0", PackFormatOutdated, false, true},
+		{CurrentPackFormat, PackFormatCurrent, false, true},
+		{"packwiz:1.1.3", PackFormatCurrent, false, true},
+		{"packwiz:1.2.0", PackFormatNewer, false, true},
+		{"packwiz:2.0.0", PackFormatCurrent, true, false},
+		{"packwiz:0.9.0", PackFormatCurrent, false, false},
+		{"packwiz:1.1", PackFormatCurrent, false, false},
+		{"other:1.1.0", PackFormatCurrent, false, false},
+	}
+	for _, test := range tests {
+		status, err := CheckPackFormat(test.format)
+		if (err == nil) != test.expected {
+			t.Errorf("%s: expected readable %v, got error %v", test.format, test.expected, err)
+		}
+		if errors.Is(err, ErrPackFormatTooNew) != test.tooNew {
+			t.Errorf("%s: expected too new %v, got error %v", test.format, test.tooNew, err)
+		}
+		if err == nil && status != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.format, test.status, status)
+		}
+	}
+}
+
+// loadFormatTestPack writes a pack.toml with the given format and extra contents, and loads it
+func loadFormatTestPack(t *testing.T, format string, extra string) (string, Pack, error) {
+	t.Helper()
+	packPath := filepath.Join(t.TempDir(), "pack.toml")
+	contents := "name = \"Test\"\n"
+	if format != "" {
+		contents += "pack-format = \"" + format + "\"\n"
+	}
+	contents += "[index]\nfile = \"index.toml\"\nhash-format = \"sha256\"\nhash = \"\"\n" + extra
+	if err := os.WriteFile(packPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", packPath)
+	t.Cleanup(func() { viper.Set("pack-file", oldPackFile) })
+	pack, err := LoadPack()
+	return packPath, pack, err
+}
+
+func TestLoadPackFormat(t *testing.T) {
+	// Past formats are migrated when loaded, adjusting changed fields
+	_, pack, err := loadFormatTestPack(t, "packwiz:1.0.0", "[options]\nmods-folder = \"custom-mods\"\n")
+	if err != nil {
+		t.Fatalf("Failed to load a pack with a past format: %v", err)
+	}
+	if pack.PackFormat != CurrentPackFormat || pack.LoadedFormat() != "packwiz:1.0.0" {
+		t.Errorf("Expected the pack to be migrated from packwiz:1.0.0, got %s (loaded %s)", pack.PackFormat, pack.LoadedFormat())
+	}
+	if _, ok := pack.Options["mods-folder"]; ok || pack.Options["meta-folder"] != "custom-mods" {
+		t.Errorf("Expected mods-folder to be renamed to meta-folder, got %v", pack.Options)
+	}
+	if changed, err := pack.MigrateFormat(); err != nil || changed {
+		t.Errorf("Expected a migrated pack not to change again, got %v (%v)", changed, err)
+	}
+
+	// The current format is loaded as it is
+	packPath, pack, err := loadFormatTestPack(t, CurrentPackFormat, "")
+	if err != nil {
+		t.Fatalf("Failed to load a pack with the current format: %v", err)
+	}
+	if pack.PackFormat != CurrentPackFormat || pack.LoadedFormat() != CurrentPackFormat {
+		t.Errorf("Expected the current format to be kept, got %s", pack.PackFormat)
+	}
+	if err := pack.Write(); err != nil {
+		t.Errorf("Failed to write a pack with the current format: %v", err)
+	}
+
+	// Future feature numbers can be read, but not written
+	packPath, pack, err = loadFormatTestPack(t, "packwiz:1.2.0", "")
+	if err != nil {
+		t.Fatalf("Failed to load a pack with a newer feature number: %v", err)
+	}
+	if err := pack.Write(); !errors.Is(err, ErrPackFormatTooNew) {
+		t.Errorf("Expected writing a pack with a newer format to fail, got %v", err)
+	}
+	if data, err := os.ReadFile(packPath); err != nil || !strings.Contains(string(data), "packwiz:1.2.0") {
+		t.Errorf("Expected pack.toml to be unchanged, got:\n%s", data)
+	}
+	if _, err := pack.MigrateFormat(); !errors.Is(err, ErrPackFormatTooNew) {
+		t.Errorf("Expected migrating a pack with a newer format to fail, got %v", err)
+	}
+
+	// Future major versions can't be read
+	if _, _, err := loadFormatTestPack(t, "packwiz:2.0.0", ""); !errors.Is(err, ErrPackFormatTooNew) {
+		t.Errorf("Expected loading a pack with a future format to fail, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ProgressFunc is called as a download progresses, with the number of bytes downloaded so far and the total size of the
+// download, which is -1 if the server didn't report it. Once the download has finished, it is called with total equal
+// to done.
+type ProgressFunc func(done int64, total int64)
+
+// progressReader wraps a reader, reporting the number of bytes read from it to a ProgressFunc
+type progressReader struct {
+	io.ReadCloser
+	progress ProgressFunc
+	done     int64
+	total    int64
+}
+
+// newProgressReader returns a reader that reports progress as data is read from r; if progress is nil, r is returned
+func newProgressReader(r io.ReadCloser, total int64, progress ProgressFunc) io.ReadCloser {
+	if progress == nil {
+		return r
+	}
+	return &progressReader{ReadCloser: r, progress: progress, total: total}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.done += int64(n)
+	if err == io.EOF {
+		r.progress(r.done, r.done)
+	} else if n > 0 {
+		r.progress(r.done, r.total)
+	}
+	return n, err
+}
+
+// GetWithProgress requests the given URL with the given client (or a client that only sets the User-Agent, if nil), returning an error if
+// the response status isn't 200 OK. Reading the response body reports progress to progress, which may be nil.
+// The request is cancelled when ctx is done; the caller must close the response body.
+func GetWithProgress(ctx context.Context, client *http.Client, url string, contentType string, progress ProgressFunc) (*http.Response, error) {
+	if IsOffline() {
+		return nil, fmt.Errorf("failed to request %s: %w", url, ErrOffline)
+	}
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", contentType)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response status: %v", resp.Status)
+	}
+	resp.Body = newProgressReader(resp.Body, resp.ContentLength, progress)
+	return resp, nil
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetWithProgress(t *testing.T) {
+	body := strings.Repeat("a", 100000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// Flushing before writing the body makes the server omit Content-Length
+			w.(http.Flusher).Flush()
+		} else {
+			w.Header().Set("Content-Length", "100000")
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	for path, expectedTotal := range map[string]int64{"/sized": 100000, "/chunked": -1} {
+		var calls [][2]int64
+		resp, err := GetWithProgress(context.Background(), nil, srv.URL+path, "application/octet-stream", func(done int64, total int64) {
+			calls = append(calls, [2]int64{done, total})
+		})
+		if err != nil {
+			t.Fatalf("Request to %s failed: %v", path, err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil || string(data) != body {
+			t.Fatalf("Failed to read body of %s: %v", path, err)
+		}
+
+		if len(calls) < 2 {
+			t.Fatalf("Expected progress to be reported for %s, got %v", path, calls)
+		}
+		for _, call := range calls[:len(calls)-1] {
+			if call[1] != expectedTotal {
+				t.Errorf("Expected total %d for %s, got %v", expectedTotal, path, call)
+			}
+		}
+		if last := calls[len(calls)-1]; last != [2]int64{100000, 100000} {
+			t.Errorf("Expected final progress for %s to be complete, got %v", path, last)
+		}
+	}
+}
+
+func TestGetWithProgressCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetWithProgress(ctx, nil, srv.URL, "application/octet-stream", nil); err == nil {
+		t.Error("Expected a cancelled request to fail")
+	}
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+type projectPageTestUpdater struct {
+	Updater
+}
+
+func (projectPageTestUpdater) ProjectPage(mod *Mod) (string, error) {
+	return "https://example.com/" + mod.Name, nil
+}
+
+func TestGetProjectPage(t *testing.T) {
+	Updaters["projectpagetest"] = projectPageTestUpdater{}
+	Updaters["nopagetest"] = nil
+	defer delete(Updaters, "projectpagetest")
+	defer delete(Updaters, "nopagetest")
+
+	mod := Mod{Name: "Test", Update: map[string]map[string]interface{}{"nopagetest": nil, "projectpagetest": nil}}
+	url, err := mod.GetProjectPage()
+	if err != nil {
+		t.Fatalf("Failed to get project page: %v", err)
+	}
+	if url != "https://example.com/Test" {
+		t.Errorf("Unexpected project page %q", url)
+	}
+
+	mod.Update = map[string]map[string]interface{}{"nopagetest": nil}
+	if _, err := mod.GetProjectPage(); err == nil || !strings.Contains(err.Error(), "nopagetest") {
+		t.Errorf("Expected an error naming the update source, got %v", err)
+	}
+
+	mod.Update = nil
+	if _, err := mod.GetProjectPage(); err == nil || !strings.Contains(err.Error(), "URL") {
+		t.Errorf("Expected an error for a mod added from a URL, got %v", err)
+	}
+}
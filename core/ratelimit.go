@@ -0,0 +1,402 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultMaxRetries is the default number of times a rate limited request is retried
+const DefaultMaxRetries = 100
+
+// DefaultBackoffBase is the default wait time before the first retry, when the API doesn't specify one
+const DefaultBackoffBase = 100 * time.Millisecond
+
+// DefaultThrottleThreshold is the default number of remaining requests below which requests are slowed down
+const DefaultThrottleThreshold = 20
+
+// DefaultServerErrorRetries is the default number of times an idempotent request is retried after a server error
+const DefaultServerErrorRetries = 3
+
+// ErrRateLimited is returned when a request is still rate limited after the maximum number of retries
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// WaitTimeParser extracts the time to wait before retrying from the body of a rate limited response.
+// It should return 0 if the body doesn't specify a wait time.
+type WaitTimeParser func(body string) time.Duration
+
+// RateLimitTransport wraps an http.RoundTripper and adds retry logic for rate limit errors
+type RateLimitTransport struct {
+	Transport  http.RoundTripper
+	MaxRetries int
+	// BaseBackoff is the wait time for the first retry when the API doesn't specify one, doubling for each
+	// subsequent retry; defaults to DefaultBackoffBase
+	BaseBackoff time.Duration
+	// APIName is the name of the API being requested, used in messages shown to the user
+	APIName string
+	// ParseWaitTime is used to read the wait time from rate limit responses; defaults to ExtractWaitTime
+	ParseWaitTime WaitTimeParser
+	// Jitter randomizes exponential backoff wait times within [waitTime/2, waitTime], so that concurrent requests
+	// don't all retry at the same instant
+	Jitter bool
+	// JitterRand is the random source used for jitter; if nil, the global source is used. Set to a seeded source
+	// for reproducible wait times.
+	JitterRand *rand.Rand
+
+	// Logger is used to report retries to the user; defaults to fmt.Printf
+	Logger func(format string, args ...any)
+	// Limiter, if set, limits the rate at which requests are sent to avoid being rate limited in the first place.
+	// It is shared between all goroutines using this transport.
+	Limiter *rate.Limiter
+	// ServerErrorRetries is the number of times an idempotent request is retried with exponential backoff when the
+	// server responds with a transient 5xx error, counted separately from rate limit retries. Zero disables these
+	// retries; non-idempotent requests are never retried.
+	ServerErrorRetries int
+	// ThrottleThreshold, if greater than zero, slows down requests once the X-Ratelimit-Remaining header of a response
+	// drops below it, spreading the remaining requests over the time until the limit resets (given in seconds by the
+	// X-Ratelimit-Reset header) so that requests aren't rate limited in the first place
+	ThrottleThreshold int
+
+	jitterLock sync.Mutex
+
+	throttleLock  sync.Mutex
+	throttleDelay time.Duration
+	nextRequest   time.Time
+}
+
+// RoundTrip implements the http.RoundTripper interface with rate limit retry logic
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if IsOffline() {
+		return nil, ErrOffline
+	}
+	if t.Transport == nil {
+		t.Transport = BaseTransport{}
+	}
+	if t.MaxRetries == 0 {
+		t.MaxRetries = 5
+	}
+	baseBackoff := t.BaseBackoff
+	if baseBackoff == 0 {
+		baseBackoff = DefaultBackoffBase
+	}
+	parseWaitTime := t.ParseWaitTime
+	if parseWaitTime == nil {
+		parseWaitTime = ExtractWaitTime
+	}
+	logger := t.Logger
+	if logger == nil {
+		logger = func(format string, args ...any) {
+			fmt.Printf(format, args...)
+		}
+	}
+	apiName := t.APIName
+	if apiName == "" {
+		apiName = "API"
+	}
+
+	var resp *http.Response
+	var err error
+
+	for attempt := 0; attempt <= t.MaxRetries; attempt++ {
+		resp, err = t.roundTripServerErrors(req, attempt > 0, baseBackoff, logger, apiName)
+		if err != nil {
+			return resp, err
+		}
+
+		// If we got a 429 (Too Many Requests), handle retry
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if !canResendBody(req) {
+				logger("Rate limited by %s, and the request can't be retried as its body can't be sent again\n", apiName)
+				return resp, nil
+			}
+
+			// Read the response body to extract wait time
+			bodyBytes, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if readErr != nil {
+				return resp, fmt.Errorf("failed to read rate limit response: %w", readErr)
+			}
+
+			// Try to extract wait time from error message
+			waitTime := parseWaitTime(string(bodyBytes))
+
+			haveWaitTime := waitTime != 0
+
+			// If we couldn't parse it, try Retry-After header
+			if !haveWaitTime {
+				waitTime, haveWaitTime = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			}
+
+			// Default to exponential backoff if we couldn't determine wait time
+			if !haveWaitTime {
+				waitTime = t.jitter(baseBackoff * time.Duration(1<<uint(attempt)))
+			}
+
+			// Add a small buffer to the wait time (10% + 50ms)
+			waitTime = waitTime + (waitTime / 10) + (50 * time.Millisecond)
+
+			if attempt < t.MaxRetries {
+				logger("Rate limited by %s, waiting %v before retry (attempt %d/%d)...\n",
+					apiName, waitTime, attempt+1, t.MaxRetries)
+				select {
+				case <-time.After(waitTime):
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				}
+				continue
+			}
+
+			// Max retries exceeded, return the error response
+			details := fmt.Sprintf("last wait time %v", waitTime)
+			if remaining := extractRemaining(string(bodyBytes)); remaining != "" {
+				details = remaining + " requests remaining, " + details
+			}
+			return resp, fmt.Errorf("%w after %d retries (%s) - %s is heavily rate limiting requests. Please try again later or contact the API provider if this persists", ErrRateLimited, t.MaxRetries, details, apiName)
+		}
+
+		// Success or non-rate-limit error
+		return resp, nil
+	}
+
+	return resp, err
+}
+
+// roundTripServerErrors sends a request, retrying it if it is idempotent and the server responds with a transient
+// error, until ServerErrorRetries is exceeded. resend is true if the request has already been sent, so its body must
+// be reset.
+func (t *RateLimitTransport) roundTripServerErrors(req *http.Request, resend bool, baseBackoff time.Duration, logger func(format string, args ...any), apiName string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.waitThrottle(req.Context()); err != nil {
+			return nil, err
+		}
+		if t.Limiter != nil {
+			if err := t.Limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		// Clone the request for retries (required because the body can only be read once)
+		reqClone, err := cloneRequest(req, resend || attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.Transport.RoundTrip(reqClone)
+		if err == nil {
+			t.inspectRateLimitHeaders(resp)
+		}
+		if err != nil || attempt >= t.ServerErrorRetries || !isRetryableServerError(resp.StatusCode) || !isIdempotent(req) {
+			return resp, err
+		}
+
+		// Drain the body so that the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		waitTime, haveWaitTime := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !haveWaitTime {
+			waitTime = t.jitter(baseBackoff * time.Duration(1<<uint(attempt)))
+		}
+		logger("%s returned %s, waiting %v before retry (attempt %d/%d)...\n",
+			apiName, resp.Status, waitTime, attempt+1, t.ServerErrorRetries)
+		select {
+		case <-time.After(waitTime):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// inspectRateLimitHeaders reads the remaining request count and reset time from a response, setting the delay between
+// subsequent requests if fewer than ThrottleThreshold requests remain
+func (t *RateLimitTransport) inspectRateLimitHeaders(resp *http.Response) {
+	if t.ThrottleThreshold <= 0 {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("X-Ratelimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseFloat(resp.Header.Get("X-Ratelimit-Reset"), 64)
+	if err != nil {
+		return
+	}
+
+	var delay time.Duration
+	if remaining < t.ThrottleThreshold {
+		delay = time.Duration(reset*float64(time.Second)) / time.Duration(max(remaining, 0)+1)
+	}
+	t.throttleLock.Lock()
+	defer t.throttleLock.Unlock()
+	t.throttleDelay = delay
+	if next := time.Now().Add(delay); next.After(t.nextRequest) {
+		t.nextRequest = next
+	}
+}
+
+// waitThrottle waits until the next request can be sent without exceeding the rate limit reported by the API, as
+// determined by inspectRateLimitHeaders
+func (t *RateLimitTransport) waitThrottle(ctx context.Context) error {
+	if t.ThrottleThreshold <= 0 {
+		return nil
+	}
+	t.throttleLock.Lock()
+	now := time.Now()
+	waitTime := t.nextRequest.Sub(now)
+	// Concurrent requests are spaced out by the delay, rather than all being sent at once
+	if t.nextRequest.Before(now) {
+		t.nextRequest = now
+	}
+	t.nextRequest = t.nextRequest.Add(t.throttleDelay)
+	t.throttleLock.Unlock()
+
+	if waitTime <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(waitTime):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// canResendBody returns true if a request can be sent again: either it has no body, or GetBody can provide a new copy
+// of it
+func canResendBody(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// cloneRequest clones a request to be sent; when it is being sent again, its body is replaced with a new copy from
+// GetBody, as the body of the original request has already been read
+func cloneRequest(req *http.Request, resend bool) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if resend && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, errors.New("the request body can't be sent again")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to reset the request body: %w", err)
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// isRetryableServerError returns true if the status code is a server error that may succeed if retried
+func isRetryableServerError(statusCode int) bool {
+	switch statusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isIdempotent returns true if the request can safely be sent again. Only methods without a request body are
+// considered, as the body of a request can't be sent twice.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number of seconds or an HTTP date.
+// Dates in the past result in a wait time of zero. The boolean result is false if the value couldn't be parsed.
+func parseRetryAfter(retryAfter string, now time.Time) (time.Duration, bool) {
+	if retryAfter == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(retryAfter, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// jitter returns a random duration within [waitTime/2, waitTime] if Jitter is enabled
+func (t *RateLimitTransport) jitter(waitTime time.Duration) time.Duration {
+	if !t.Jitter || waitTime < 2 {
+		return waitTime
+	}
+	half := waitTime / 2
+	var offset int64
+	if t.JitterRand != nil {
+		// rand.Rand is not safe for concurrent use
+		t.jitterLock.Lock()
+		offset = t.JitterRand.Int64N(int64(waitTime-half) + 1)
+		t.jitterLock.Unlock()
+	} else {
+		offset = rand.Int64N(int64(waitTime-half) + 1)
+	}
+	return half + time.Duration(offset)
+}
+
+var waitTimePatterns = []struct {
+	pattern *regexp.Regexp
+	unit    time.Duration
+}{
+	{regexp.MustCompile(`Please wait (\d+) milliseconds?`), time.Millisecond},
+	{regexp.MustCompile(`Please wait (\d+) seconds?`), time.Second},
+}
+
+// ExtractWaitTime attempts to extract the wait time from a "Please wait X milliseconds/seconds" rate limit message,
+// as used by Modrinth
+func ExtractWaitTime(body string) time.Duration {
+	for _, p := range waitTimePatterns {
+		matches := p.pattern.FindStringSubmatch(body)
+		if len(matches) >= 2 {
+			if value, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
+				return time.Duration(value) * p.unit
+			}
+		}
+	}
+
+	return 0
+}
+
+var remainingPattern = regexp.MustCompile(`(\d+/\d+) remaining`)
+
+// extractRemaining attempts to extract the remaining request count (e.g. "0/300") from a rate limit message
+func extractRemaining(body string) string {
+	matches := remainingPattern.FindStringSubmatch(body)
+	if len(matches) >= 2 {
+		return matches[1]
+	}
+	return ""
+}
+
+// NewRateLimitHTTPClient creates a new HTTP client with rate limit retry logic for the given API.
+// If requestsPerMinute is greater than zero, requests are limited to that rate before being sent.
+// If parseWaitTime is nil, ExtractWaitTime is used to read wait times from rate limit responses.
+func NewRateLimitHTTPClient(apiName string, maxRetries int, requestsPerMinute int, parseWaitTime WaitTimeParser) *http.Client {
+	var limiter *rate.Limiter
+	if requestsPerMinute > 0 {
+		// Allow a second's worth of requests to be sent at once
+		limiter = rate.NewLimiter(rate.Limit(float64(requestsPerMinute)/60), max(requestsPerMinute/60, 1))
+	}
+	return &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:     &UserAgentTransport{Transport: BaseTransport{}},
+			MaxRetries:    maxRetries,
+			APIName:       apiName,
+			ParseWaitTime: parseWaitTime,
+			Jitter:        true,
+			Limiter:       limiter,
+		},
+	}
+}
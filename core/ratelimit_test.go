@@ -0,0 +1,641 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// TestRateLimitCustomWaitTimeParser verifies that a provider supplied parser is used to read the wait time
+func TestRateLimitCustomWaitTimeParser(t *testing.T) {
+	var attemptCount atomic.Int32
+	var parsedBody atomic.Value
+
+	// Create a test server that returns 429 with a non-Modrinth message once, then succeeds
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := attemptCount.Add(1)
+		if attempt == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"Too many requests, retry in 150ms"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:  http.DefaultTransport,
+			MaxRetries: 5,
+			APIName:    "Test API",
+			ParseWaitTime: func(body string) time.Duration {
+				parsedBody.Store(body)
+				if strings.Contains(body, "retry in 150ms") {
+					return 150 * time.Millisecond
+				}
+				return 0
+			},
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	duration := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status OK, got %d", resp.StatusCode)
+	}
+
+	if body, _ := parsedBody.Load().(string); !strings.Contains(body, "retry in 150ms") {
+		t.Errorf("Expected parser to receive the rate limit body, got %q", body)
+	}
+
+	// Should have waited at least the 150ms returned by the parser (exponential backoff would be 100ms)
+	if duration < 150*time.Millisecond {
+		t.Errorf("Expected at least 150ms duration, got %v", duration)
+	}
+
+	t.Logf("Test completed successfully with %d attempts in %v", attemptCount.Load(), duration)
+}
+
+// TestRateLimitJitterConcurrentRequests verifies that concurrent requests don't all back off for the same time
+func TestRateLimitJitterConcurrentRequests(t *testing.T) {
+	const requestCount = 20
+
+	var lock sync.Mutex
+	firstAttempt := make(map[string]time.Time)
+	retryGaps := make(map[string]time.Duration)
+
+	// Create a test server that rate limits the first attempt of each request without specifying a wait time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		lock.Lock()
+		first, seen := firstAttempt[id]
+		if !seen {
+			firstAttempt[id] = time.Now()
+		} else {
+			retryGaps[id] = time.Since(first)
+		}
+		lock.Unlock()
+		if !seen {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"rate_limit"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:  http.DefaultTransport,
+			MaxRetries: 5,
+			Jitter:     true,
+			JitterRand: rand.New(rand.NewPCG(1, 2)),
+		},
+	}
+
+	errChan := make(chan error, requestCount)
+	for i := 0; i < requestCount; i++ {
+		go func(id int) {
+			resp, err := client.Get(fmt.Sprintf("%s?id=%d", server.URL, id))
+			if err != nil {
+				errChan <- fmt.Errorf("request %d failed: %w", id, err)
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				errChan <- fmt.Errorf("request %d got status %d", id, resp.StatusCode)
+				return
+			}
+			errChan <- nil
+		}(i)
+	}
+
+	for i := 0; i < requestCount; i++ {
+		if err := <-errChan; err != nil {
+			t.Errorf("Concurrent request failed: %v", err)
+		}
+	}
+
+	if len(retryGaps) != requestCount {
+		t.Fatalf("Expected %d retried requests, got %d", requestCount, len(retryGaps))
+	}
+
+	minGap, maxGap := time.Duration(1<<62), time.Duration(0)
+	for _, gap := range retryGaps {
+		minGap = min(minGap, gap)
+		maxGap = max(maxGap, gap)
+	}
+
+	// Backoff for the first retry is jittered within [50ms, 100ms], so the gaps should be spread out
+	if maxGap-minGap < 10*time.Millisecond {
+		t.Errorf("Expected jittered wait times to differ, got gaps between %v and %v", minGap, maxGap)
+	}
+
+	t.Logf("Test completed successfully, retry gaps ranged from %v to %v", minGap, maxGap)
+}
+
+// TestRateLimitJitterBounds verifies that backoff is unchanged without jitter and stays within bounds with it
+func TestRateLimitJitterBounds(t *testing.T) {
+	transport := &RateLimitTransport{}
+	for i := 0; i < 10; i++ {
+		if waitTime := transport.jitter(100 * time.Millisecond); waitTime != 100*time.Millisecond {
+			t.Fatalf("Expected 100ms without jitter, got %v", waitTime)
+		}
+	}
+
+	transport = &RateLimitTransport{Jitter: true, JitterRand: rand.New(rand.NewPCG(1, 2))}
+	for i := 0; i < 10; i++ {
+		if waitTime := transport.jitter(100 * time.Millisecond); waitTime < 50*time.Millisecond || waitTime > 100*time.Millisecond {
+			t.Fatalf("Expected jittered wait time within [50ms, 100ms], got %v", waitTime)
+		}
+	}
+}
+
+// TestRateLimitParseRetryAfter verifies parsing of both forms of the Retry-After header
+func TestRateLimitParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, time.October, 21, 7, 28, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		retryAfter string
+		expected   time.Duration
+		ok         bool
+	}{
+		{"Seconds", "1.5", 1500 * time.Millisecond, true},
+		{"Future date", "Wed, 21 Oct 2025 07:28:30 GMT", 30 * time.Second, true},
+		{"Past date is clamped to zero", "Wed, 21 Oct 2025 07:27:00 GMT", 0, true},
+		{"Empty", "", 0, false},
+		{"Invalid", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := parseRetryAfter(tt.retryAfter, now)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("Expected %v (%v), got %v (%v)", tt.expected, tt.ok, result, ok)
+			}
+		})
+	}
+}
+
+// TestRateLimitRetryAfterDate verifies that HTTP date Retry-After headers are respected
+func TestRateLimitRetryAfterDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		retryAfter  func() string
+		minDuration time.Duration
+		maxDuration time.Duration
+	}{
+		{
+			// HTTP dates only have second precision, so a date 2 seconds ahead waits at least 1 second
+			name:        "Future date",
+			retryAfter:  func() string { return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat) },
+			minDuration: time.Second,
+			maxDuration: 3 * time.Second,
+		},
+		{
+			// A date in the past should retry straight away rather than falling back to exponential backoff
+			name:        "Past date",
+			retryAfter:  func() string { return time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat) },
+			minDuration: 0,
+			maxDuration: 100 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attemptCount atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := attemptCount.Add(1)
+				if attempt == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter())
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(`{"error":"rate_limit"}`))
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"success": true}`))
+			}))
+			defer server.Close()
+
+			client := &http.Client{
+				Transport: &RateLimitTransport{
+					Transport:  http.DefaultTransport,
+					MaxRetries: 5,
+				},
+			}
+
+			start := time.Now()
+			resp, err := client.Get(server.URL)
+			duration := time.Since(start)
+
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected status OK, got %d", resp.StatusCode)
+			}
+
+			if duration < tt.minDuration || duration > tt.maxDuration {
+				t.Errorf("Expected duration between %v and %v, got %v", tt.minDuration, tt.maxDuration, duration)
+			}
+
+			t.Logf("Test completed successfully, waited %v as specified by Retry-After header", duration)
+		})
+	}
+}
+
+// TestRateLimitContextCancellation verifies that cancelling the request context interrupts the backoff
+func TestRateLimitContextCancellation(t *testing.T) {
+	var attemptCount atomic.Int32
+
+	// Create a test server that always asks for a long wait
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"rate_limit","description":"Please wait 10 seconds"}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:  http.DefaultTransport,
+			MaxRetries: 5,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	duration := time.Since(start)
+
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected error after cancellation, got nil")
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+
+	if duration > time.Second {
+		t.Errorf("Expected request to return promptly after cancellation, took %v", duration)
+	}
+
+	if attemptCount.Load() != 1 {
+		t.Errorf("Expected 1 attempt before cancellation, got %d", attemptCount.Load())
+	}
+
+	t.Logf("Test completed successfully, cancelled after %v", duration)
+}
+
+// TestRateLimitLimiter verifies that requests are proactively limited when a limiter is set
+func TestRateLimitLimiter(t *testing.T) {
+	var attemptCount atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount.Add(1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	// 20 requests per second, with no burst
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:  http.DefaultTransport,
+			MaxRetries: 5,
+			Limiter:    rate.NewLimiter(rate.Limit(20), 1),
+		},
+	}
+
+	// Make 6 concurrent requests, sharing the limiter
+	start := time.Now()
+	errChan := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		go func(id int) {
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				errChan <- fmt.Errorf("request %d failed: %w", id, err)
+				return
+			}
+			resp.Body.Close()
+			errChan <- nil
+		}(i)
+	}
+	for i := 0; i < 6; i++ {
+		if err := <-errChan; err != nil {
+			t.Errorf("Concurrent request failed: %v", err)
+		}
+	}
+	duration := time.Since(start)
+
+	// The first request is sent immediately, the other 5 are spaced out by 50ms
+	if duration < 250*time.Millisecond {
+		t.Errorf("Expected at least 250ms for limited requests, got %v", duration)
+	}
+
+	if attemptCount.Load() != 6 {
+		t.Errorf("Expected 6 attempts, got %d", attemptCount.Load())
+	}
+
+	t.Logf("Test completed successfully, %d requests took %v", attemptCount.Load(), duration)
+}
+
+// TestRateLimitLogger verifies that retries are reported through the configured logger
+func TestRateLimitLogger(t *testing.T) {
+	var attemptCount atomic.Int32
+
+	// Create a test server that returns 429 for the first 2 attempts, then succeeds
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := attemptCount.Add(1)
+		if attempt <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"rate_limit","description":"Please wait 10 milliseconds"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	var messages []string
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:  http.DefaultTransport,
+			MaxRetries: 5,
+			APIName:    "Test API",
+			Logger: func(format string, args ...any) {
+				messages = append(messages, fmt.Sprintf(format, args...))
+			},
+		},
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 logged retries, got %d: %v", len(messages), messages)
+	}
+
+	for i, message := range messages {
+		if !strings.Contains(message, "Rate limited by Test API") {
+			t.Errorf("Expected message to name the API, got: %q", message)
+		}
+		if expected := fmt.Sprintf("(attempt %d/5)", i+1); !strings.Contains(message, expected) {
+			t.Errorf("Expected message to contain %q, got: %q", expected, message)
+		}
+	}
+
+	t.Logf("Test completed successfully, logged %d retries", len(messages))
+}
+
+// TestRateLimitRemainingInError verifies that the remaining request count is reported when retries are exhausted
+func TestRateLimitRemainingInError(t *testing.T) {
+	// Create a test server that always returns 429
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"rate_limit","description":"You are being rate-limited. Please wait 10 milliseconds. 0/300 remaining."}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:  http.DefaultTransport,
+			MaxRetries: 2,
+		},
+	}
+
+	resp, err := client.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected error after max retries, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "0/300 requests remaining") {
+		t.Errorf("Expected error to contain remaining count, got: %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "last wait time 61ms") {
+		t.Errorf("Expected error to contain last wait time, got: %v", err)
+	}
+
+	t.Logf("Test completed successfully, got error: %v", err)
+}
+
+// TestRateLimitServerErrorRetry verifies that idempotent requests are retried after transient server errors
+func TestRateLimitServerErrorRetry(t *testing.T) {
+	var attemptCount atomic.Int32
+
+	// Create a test server that returns 503 for the first 2 attempts, then succeeds
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := attemptCount.Add(1)
+		if attempt <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	var messages []string
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:          http.DefaultTransport,
+			MaxRetries:         5,
+			BaseBackoff:        10 * time.Millisecond,
+			APIName:            "Test API",
+			ServerErrorRetries: 3,
+			Jitter:             true,
+			Logger: func(format string, args ...any) {
+				messages = append(messages, fmt.Sprintf(format, args...))
+			},
+		},
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status OK, got %d", resp.StatusCode)
+	}
+	if attemptCount.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attemptCount.Load())
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 logged retries, got %d: %v", len(messages), messages)
+	}
+	for i, message := range messages {
+		if !strings.Contains(message, "Test API returned 503") {
+			t.Errorf("Expected message to name the API and status, got: %q", message)
+		}
+		if expected := fmt.Sprintf("(attempt %d/3)", i+1); !strings.Contains(message, expected) {
+			t.Errorf("Expected message to contain %q, got: %q", expected, message)
+		}
+	}
+}
+
+// TestRateLimitServerErrorRetryLimit verifies that the server error response is returned once retries are exhausted,
+// and that non-idempotent requests and disabled retries don't retry at all
+func TestRateLimitServerErrorRetryLimit(t *testing.T) {
+	var attemptCount atomic.Int32
+
+	// Create a test server that always returns 502
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		retries  int
+		attempts int32
+	}{
+		{"GET", http.MethodGet, 2, 3},
+		{"POST", http.MethodPost, 2, 1},
+		{"disabled", http.MethodGet, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attemptCount.Store(0)
+			client := &http.Client{
+				Transport: &RateLimitTransport{
+					Transport:          http.DefaultTransport,
+					BaseBackoff:        time.Millisecond,
+					ServerErrorRetries: tt.retries,
+					Logger:             func(format string, args ...any) {},
+				},
+			}
+
+			req, err := http.NewRequest(tt.method, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusBadGateway {
+				t.Errorf("Expected status Bad Gateway, got %d", resp.StatusCode)
+			}
+			if attemptCount.Load() != tt.attempts {
+				t.Errorf("Expected %d attempts, got %d", tt.attempts, attemptCount.Load())
+			}
+		})
+	}
+}
+
+// bodyRecordingTransport records the body of each request, returning 429 for the first request and 200 afterwards.
+// Unlike http.Transport, it never rewinds request bodies itself.
+type bodyRecordingTransport struct {
+	bodies []string
+}
+
+func (t *bodyRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+	}
+	t.bodies = append(t.bodies, string(body))
+	status, message := http.StatusOK, ""
+	if len(t.bodies) == 1 {
+		status, message = http.StatusTooManyRequests, "Please wait 1 milliseconds"
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(message)),
+		Request:    req,
+	}, nil
+}
+
+// TestRateLimitRetryWithBody verifies that a rate limited request with a body is retried with the full body, and that
+// requests whose body can't be sent again aren't retried
+func TestRateLimitRetryWithBody(t *testing.T) {
+	payload := `{"fingerprints":[1234,5678]}`
+	recorder := &bodyRecordingTransport{}
+	client := &http.Client{
+		Transport: &RateLimitTransport{
+			Transport:  recorder,
+			MaxRetries: 5,
+			Logger:     func(format string, args ...any) {},
+		},
+	}
+
+	resp, err := client.Post("http://example.com/v1/fingerprints", "application/json", bytes.NewBufferString(payload))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status OK, got %d", resp.StatusCode)
+	}
+	if len(recorder.bodies) != 2 || recorder.bodies[0] != payload || recorder.bodies[1] != payload {
+		t.Errorf("Expected the full body to be sent on each attempt, got %q", recorder.bodies)
+	}
+
+	// Bodies without GetBody can't be rewound, so the 429 is returned rather than retried
+	recorder.bodies = nil
+	req, err := http.NewRequest(http.MethodPost, "http://example.com/v1/fingerprints", io.MultiReader(strings.NewReader(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected status Too Many Requests, got %d", resp.StatusCode)
+	}
+	if len(recorder.bodies) != 1 {
+		t.Errorf("Expected 1 attempt, got %d", len(recorder.bodies))
+	}
+}
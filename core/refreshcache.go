@@ -0,0 +1,167 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RefreshCacheFile is the name of the file in the pack root that stores the hashes of files from the last
+// incremental refresh, so that unchanged files don't need to be hashed again
+const RefreshCacheFile = ".packwiz-cache"
+
+// refreshCache stores the modification time, size and hash of each file in the pack, keyed by index path
+type refreshCache struct {
+	Version int                          `json:"version"`
+	Files   map[string]refreshCacheEntry `json:"files"`
+
+	// previous holds the entries read from the cache file, which are only used if the file hasn't changed since
+	previous map[string]refreshCacheEntry
+	lock     sync.Mutex
+}
+
+type refreshCacheEntry struct {
+	// ModTime is the modification time of the file, in nanoseconds since the Unix epoch
+	ModTime    int64  `json:"mtime"`
+	Size       int64  `json:"size"`
+	HashFormat string `json:"hash-format"`
+	Hash       string `json:"hash"`
+	// Duplicates is stored for metadata files, once they have been checked for duplicates
+	Duplicates *duplicateInfo `json:"duplicates,omitempty"`
+}
+
+// loadRefreshCache reads the refresh cache of the pack; if force is set or the cache can't be read, an empty cache is
+// returned so that all files are hashed again
+func (in Index) loadRefreshCache(force bool) *refreshCache {
+	cache := &refreshCache{Version: 1, Files: make(map[string]refreshCacheEntry)}
+	if force {
+		return cache
+	}
+	data, err := os.ReadFile(filepath.Join(in.packRoot, RefreshCacheFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Warning: failed to read %s, all files will be hashed: %v\n", RefreshCacheFile, err)
+		}
+		return cache
+	}
+	var previous refreshCache
+	err = json.Unmarshal(data, &previous)
+	if err != nil || previous.Version != 1 {
+		fmt.Printf("Warning: %s is invalid, all files will be hashed\n", RefreshCacheFile)
+		return cache
+	}
+	cache.previous = previous.Files
+	return cache
+}
+
+// hashFile returns the hash of a file, reusing the previous hash if the file's modification time and size haven't
+// changed. The entry for the file is stored in the cache, so it is safe to call from multiple goroutines.
+func (c *refreshCache) hashFile(relPath string, path string, hashFormat string) (string, error) {
+	// Stat before hashing, so a change while hashing is picked up by the next refresh
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	entry := refreshCacheEntry{
+		ModTime:    info.ModTime().UnixNano(),
+		Size:       info.Size(),
+		HashFormat: hashFormat,
+	}
+	if prev, ok := c.previous[relPath]; ok && prev.ModTime == entry.ModTime && prev.Size == entry.Size &&
+		prev.HashFormat == entry.HashFormat && prev.Hash != "" {
+		entry.Hash = prev.Hash
+		entry.Duplicates = prev.Duplicates
+	} else {
+		entry.Hash, err = hashFile(path, hashFormat)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	c.lock.Lock()
+	c.Files[relPath] = entry
+	c.lock.Unlock()
+	return entry.Hash, nil
+}
+
+// getDuplicateInfo returns the stored duplicate information for the metadata file at the given index path, if the file
+// hasn't changed since it was stored. The cache may be nil.
+func (c *refreshCache) getDuplicateInfo(relPath string) (duplicateInfo, bool) {
+	if c == nil {
+		return duplicateInfo{}, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.Files[relPath]
+	if !ok || entry.Duplicates == nil {
+		return duplicateInfo{}, false
+	}
+	return *entry.Duplicates, true
+}
+
+// setDuplicateInfo stores the duplicate information for the metadata file at the given index path, if it was hashed
+// in this refresh. The cache may be nil.
+func (c *refreshCache) setDuplicateInfo(relPath string, info duplicateInfo) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if entry, ok := c.Files[relPath]; ok {
+		entry.Duplicates = &info
+		c.Files[relPath] = entry
+	}
+}
+
+// saveRefreshCache writes the refresh cache to the pack root, adding it to the pack's .gitignore when it is created
+func (in Index) saveRefreshCache(cache *refreshCache) error {
+	cachePath := filepath.Join(in.packRoot, RefreshCacheFile)
+	_, err := os.Stat(cachePath)
+	if os.IsNotExist(err) {
+		err = ignoreRefreshCache(filepath.Join(in.packRoot, ".gitignore"))
+		if err != nil {
+			return fmt.Errorf("failed to add %s to .gitignore: %w", RefreshCacheFile, err)
+		}
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to serialise %s: %w", RefreshCacheFile, err)
+	}
+	err = os.WriteFile(cachePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", RefreshCacheFile, err)
+	}
+	return nil
+}
+
+// ignoreRefreshCache adds the refresh cache to the given .gitignore file, unless it is already listed
+func ignoreRefreshCache(gitignorePath string) error {
+	data, err := os.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if slices.Contains(lines, RefreshCacheFile) || slices.Contains(lines, "/"+RefreshCacheFile) {
+		return nil
+	}
+
+	f, err := os.OpenFile(gitignorePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	entry := "/" + RefreshCacheFile + "\n"
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		entry = "\n" + entry
+	}
+	_, err = f.WriteString(entry)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package core
+
+import "os"
+
+// renameFile renames a file, replacing newPath if it exists
+func renameFile(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
@@ -0,0 +1,23 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// renameFile renames a file, replacing newPath if it exists. On Windows, replacing a file fails while another process
+// (such as an antivirus scanner or a file indexer) has it open, so the rename is retried for a short time.
+func renameFile(oldPath, newPath string) error {
+	var err error
+	for i := 0; i < 10; i++ {
+		err = os.Rename(oldPath, newPath)
+		if err == nil || !(errors.Is(err, windows.ERROR_ACCESS_DENIED) || errors.Is(err, windows.ERROR_SHARING_VIOLATION)) {
+			return err
+		}
+		time.Sleep(time.Duration(i+1) * 20 * time.Millisecond)
+	}
+	return err
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// errRestartDownload is returned when a partial download can't be resumed, so it must be downloaded again from the start
+var errRestartDownload = errors.New("partial download can't be resumed")
+
+// openPartialDownload opens the file that a download is written to before it is moved to the cache. Files downloaded
+// from a URL are kept in the cache temp folder under a name derived from their expected hash, so that an interrupted
+// download can be resumed by a later session.
+func openPartialDownload(cacheFolder string, task *downloadTask) (*os.File, error) {
+	tempFolder := filepath.Join(cacheFolder, "temp")
+	if task.url == "" || !isSafePartialName(task.hashFormat) || !isSafePartialName(task.hash) {
+		return os.CreateTemp(tempFolder, "download-tmp")
+	}
+	name := "partial-" + strings.ToLower(task.hashFormat) + "-" + strings.ToLower(task.hash)
+	return os.OpenFile(filepath.Join(tempFolder, name), os.O_RDWR|os.O_CREATE, 0644)
+}
+
+// isSafePartialName returns true if s only contains characters that can be safely used in a file name
+func isSafePartialName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// closePartialDownload closes a file that failed to download, removing it if nothing that can be resumed was
+// downloaded or it isn't named so that it can be resumed
+func closePartialDownload(f *os.File) {
+	info, err := f.Stat()
+	_ = f.Close()
+	if err != nil || info.Size() == 0 || !strings.HasPrefix(filepath.Base(f.Name()), "partial-") {
+		_ = os.Remove(f.Name())
+	}
+}
+
+// downloadURLResumable downloads a file to f, calculating the hashes in hashesToObtain and validating it against
+// hashes. If f already contains the start of the file and the server supports range requests, only the rest of the
+// file is downloaded; if the data can't be resumed or the resumed file doesn't match the expected hash, the file is
+// downloaded again from the start. When a download fails, f is left containing the data downloaded so far if the
+// server supports range requests, otherwise it is emptied.
+func downloadURLResumable(ctx context.Context, url string, hashesToObtain []string, hashes map[string]string, f *os.File, progress ProgressFunc) error {
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		err = downloadFromOffset(ctx, url, hashesToObtain, hashes, f, offset, progress)
+		if !errors.Is(err, errRestartDownload) {
+			return err
+		}
+		if err := resetFile(f); err != nil {
+			return err
+		}
+	}
+	return downloadFromOffset(ctx, url, hashesToObtain, hashes, f, 0, progress)
+}
+
+// downloadFromOffset downloads the part of a file after the first offset bytes (which must already be in f) and
+// appends it to f, validating the hash of the whole file
+func downloadFromOffset(ctx context.Context, url string, hashesToObtain []string, hashes map[string]string, f *os.File, offset int64, progress ProgressFunc) error {
+	resp, err := getFromOffset(ctx, url, offset, progress)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if offset > 0 && resp.StatusCode == http.StatusOK {
+		// The server ignored the range, so the whole file is being sent
+		if err := resetFile(f); err != nil {
+			return err
+		}
+		offset = 0
+	}
+
+	// Existing data is read to calculate the hashes of the whole file, but only new data is written
+	src := io.MultiReader(io.NewSectionReader(f, 0, offset), resp.Body)
+	err = teeHashes(hashesToObtain, hashes, &skipWriter{w: f, skip: offset}, src)
+	if err != nil {
+		var mismatch *hashMismatchError
+		if errors.As(err, &mismatch) && offset > 0 {
+			// The existing data may be corrupt
+			return fmt.Errorf("%w: %w", errRestartDownload, err)
+		}
+		if errors.As(err, &mismatch) || !supportsRanges(resp) {
+			_ = resetFile(f)
+		}
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	return nil
+}
+
+// getFromOffset requests a file like GetWithProgress, asking for the bytes after offset if offset is greater than zero.
+// The response has status 206 Partial Content only if the server returned the requested range, or 200 OK if it
+// returned the whole file; errRestartDownload is returned if the range can't be resumed.
+func getFromOffset(ctx context.Context, url string, offset int64, progress ProgressFunc) (*http.Response, error) {
+	if offset == 0 {
+		return GetWithProgress(ctx, nil, url, "application/octet-stream", progress)
+	}
+	if IsOffline() {
+		return nil, fmt.Errorf("failed to request %s: %w", url, ErrOffline)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	resp, err := defaultHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		resp.Body = newProgressReader(resp.Body, resp.ContentLength, progress)
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), "bytes "+strconv.FormatInt(offset, 10)+"-") {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("%w: unexpected Content-Range %q", errRestartDownload, resp.Header.Get("Content-Range"))
+		}
+		total := int64(-1)
+		if resp.ContentLength >= 0 {
+			total = offset + resp.ContentLength
+		}
+		if progress != nil {
+			resp.Body = &progressReader{ReadCloser: resp.Body, progress: progress, done: offset, total: total}
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		_ = resp.Body.Close()
+		return nil, errRestartDownload
+	default:
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response status: %v", resp.Status)
+	}
+	return resp, nil
+}
+
+// supportsRanges returns true if the server that sent the response allows downloads to be resumed
+func supportsRanges(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusPartialContent ||
+		strings.Contains(strings.ToLower(resp.Header.Get("Accept-Ranges")), "bytes")
+}
+
+// skipWriter discards the first skip bytes written to it, and writes the rest to w
+type skipWriter struct {
+	w    io.Writer
+	skip int64
+}
+
+func (s *skipWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if s.skip > 0 {
+		skipped := min(s.skip, int64(len(p)))
+		s.skip -= skipped
+		p = p[skipped:]
+	}
+	if len(p) > 0 {
+		if _, err := s.w.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
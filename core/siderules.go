@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// SideRulesOption is the pack option that stores the side rules of a pack
+const SideRulesOption = "side-rules"
+
+// SideRule sets the side of metadata files matching a pattern, when they don't have a side set explicitly
+type SideRule struct {
+	// Pattern is a glob pattern (e.g. "*-client") matched against the name of the metadata file (without the
+	// .pw.toml extension) and the file name of the file it downloads
+	Pattern string `toml:"pattern" mapstructure:"pattern"`
+	Side    string `toml:"side" mapstructure:"side"`
+}
+
+// Validate checks that the pattern and side of a rule are valid
+func (r SideRule) Validate() error {
+	if _, err := path.Match(r.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %s: %w", r.Pattern, err)
+	}
+	if r.Side != ClientSide && r.Side != ServerSide && r.Side != UniversalSide {
+		return fmt.Errorf("invalid side %s, must be one of client, server or both", r.Side)
+	}
+	return nil
+}
+
+// Matches returns true if the rule's pattern matches the given metadata file
+func (r SideRule) Matches(mod *Mod) bool {
+	metaName := strings.TrimSuffix(path.Base(strings.ReplaceAll(mod.GetFilePath(), "\\", "/")), MetaExtension)
+	for _, name := range []string{metaName, mod.FileName} {
+		if matched, _ := path.Match(r.Pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSideRules returns the side rules stored in the pack's options
+func (pack Pack) GetSideRules() ([]SideRule, error) {
+	var rules []SideRule
+	raw, ok := pack.Options[SideRulesOption]
+	if !ok {
+		return rules, nil
+	}
+	if err := mapstructure.Decode(raw, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse %s option: %w", SideRulesOption, err)
+	}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("failed to parse %s option: %w", SideRulesOption, err)
+		}
+	}
+	return rules, nil
+}
+
+// SetSideRules stores the given side rules in the pack's options, removing the option if there are none
+func (pack *Pack) SetSideRules(rules []SideRule) {
+	if len(rules) == 0 {
+		delete(pack.Options, SideRulesOption)
+		return
+	}
+	if pack.Options == nil {
+		pack.Options = make(map[string]interface{})
+	}
+	pack.Options[SideRulesOption] = rules
+}
+
+// ApplySideRules sets the side of metadata files without an explicit side using the first matching rule, writing
+// them and updating their hashes in the index. The number of updated files is returned.
+func (in *Index) ApplySideRules(rules []SideRule) (int, error) {
+	if len(rules) == 0 {
+		return 0, nil
+	}
+	mods, err := in.LoadAllMods()
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, mod := range mods {
+		if mod.Side != EmptySide {
+			continue
+		}
+		for _, rule := range rules {
+			if !rule.Matches(mod) {
+				continue
+			}
+			mod.Side = rule.Side
+			format, hash, err := mod.Write()
+			if err != nil {
+				return updated, err
+			}
+			err = in.RefreshFileWithHash(mod.GetFilePath(), format, hash, true)
+			if err != nil {
+				return updated, err
+			}
+			updated++
+			break
+		}
+	}
+	return updated, nil
+}
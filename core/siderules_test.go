@@ -0,0 +1,100 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestGetSideRules(t *testing.T) {
+	var pack Pack
+	_, err := toml.Decode(`[options]
+[[options.side-rules]]
+pattern = "*-client"
+side = "client"
+
+[[options.side-rules]]
+pattern = "*-server*"
+side = "server"
+`, &pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := pack.GetSideRules()
+	if err != nil {
+		t.Fatalf("Failed to get side rules: %v", err)
+	}
+	if len(rules) != 2 || rules[0] != (SideRule{"*-client", ClientSide}) || rules[1] != (SideRule{"*-server*", ServerSide}) {
+		t.Errorf("Unexpected side rules: %v", rules)
+	}
+
+	pack.SetSideRules(nil)
+	if _, ok := pack.Options[SideRulesOption]; ok {
+		t.Error("Expected side rules option to be removed")
+	}
+
+	pack.Options[SideRulesOption] = []map[string]interface{}{{"pattern": "*", "side": "nowhere"}}
+	if _, err := pack.GetSideRules(); err == nil {
+		t.Error("Expected an error for an invalid side")
+	}
+}
+
+func TestApplySideRules(t *testing.T) {
+	indexFile := createTestPack(t, 0)
+	dir := filepath.Dir(indexFile)
+	mods := map[string]string{
+		"mods/map-client.pw.toml": "name = \"Map\"\nfilename = \"map.jar\"\n",
+		"mods/perf.pw.toml":       "name = \"Perf\"\nfilename = \"perf-server-1.0.jar\"\n",
+		"mods/explicit.pw.toml":   "name = \"Explicit\"\nfilename = \"explicit-client.jar\"\nside = \"both\"\n",
+		"mods/other.pw.toml":      "name = \"Other\"\nfilename = \"other.jar\"\n",
+	}
+	for name, contents := range mods {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	index, err := LoadIndex(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := index.ApplySideRules([]SideRule{
+		{Pattern: "*-client", Side: ClientSide},
+		{Pattern: "*-client*", Side: ServerSide},
+		{Pattern: "*-server-*.jar", Side: ServerSide},
+	})
+	if err != nil {
+		t.Fatalf("Failed to apply side rules: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("Expected 2 files to be updated, got %d", updated)
+	}
+
+	expected := map[string]string{
+		"mods/map-client.pw.toml": ClientSide,
+		"mods/perf.pw.toml":       ServerSide,
+		"mods/explicit.pw.toml":   UniversalSide,
+		"mods/other.pw.toml":      EmptySide,
+	}
+	for name, side := range expected {
+		mod, err := LoadMod(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mod.Side != side {
+			t.Errorf("Expected %s to have side %q, got %q", name, side, mod.Side)
+		}
+	}
+	if mismatches := index.Verify(); len(mismatches) > 0 {
+		t.Errorf("Expected index hashes to be updated, got %v", mismatches)
+	}
+}
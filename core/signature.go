@@ -0,0 +1,140 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SignatureExtension is appended to the path of the index file to get the path of its detached signature
+const SignatureExtension = ".sig"
+
+// ErrInvalidSignature is returned when the signature of the index doesn't match the index and public key
+var ErrInvalidSignature = errors.New("signature does not match the index file")
+
+// SignaturePath returns the path of the detached signature of the index file
+func (in Index) SignaturePath() string {
+	return in.indexFile + SignatureExtension
+}
+
+// LoadPrivateKey reads an ed25519 private key from a PEM file in PKCS #8 format, as created by
+// openssl genpkey -algorithm ed25519
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// LoadPublicKey reads an ed25519 public key from a PEM file in PKIX format, as created by openssl pkey -pubout
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsePublicKey(data, path)
+}
+
+// ParsePublicKey reads an ed25519 public key in PEM format, as accepted by LoadPublicKey
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	return parsePublicKey(data, "public key")
+}
+
+func parsePublicKey(data []byte, name string) (ed25519.PublicKey, error) {
+	der, err := decodePEM(data, "PUBLIC KEY", name)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", name, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an ed25519 key", name)
+	}
+	return edKey, nil
+}
+
+// readPEM returns the contents of the first PEM block of the given type in a file
+func readPEM(path string, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodePEM(data, blockType, path)
+}
+
+// decodePEM returns the contents of the first PEM block of the given type in data, read from the named file
+func decodePEM(data []byte, blockType string, name string) ([]byte, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no %s found in %s", blockType, name)
+		}
+		if block.Type == blockType {
+			return block.Bytes, nil
+		}
+	}
+}
+
+// Sign writes a detached signature of the index file, as written to disk, to SignaturePath. ed25519 signatures are
+// deterministic, so signing the same index with the same key always produces the same signature.
+func (in Index) Sign(key ed25519.PrivateKey) error {
+	data, err := os.ReadFile(in.indexFile)
+	if err != nil {
+		return err
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, data))
+	return writeFileAtomic(in.SignaturePath(), func(w io.Writer) error {
+		_, err := io.WriteString(w, signature+"\n")
+		return err
+	})
+}
+
+// VerifySignature checks the detached signature of the index file against the given public key, returning
+// ErrInvalidSignature if it doesn't match
+func (in Index) VerifySignature(key ed25519.PublicKey) error {
+	data, err := os.ReadFile(in.indexFile)
+	if err != nil {
+		return err
+	}
+	sigData, err := os.ReadFile(in.SignaturePath())
+	if err != nil {
+		return err
+	}
+	valid, err := VerifyDetachedSignature(key, data, sigData)
+	if err != nil {
+		return fmt.Errorf("failed to read signature %s: %w", in.SignaturePath(), err)
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyDetachedSignature checks a detached signature in the format written by Index.Sign (a base64 encoded ed25519
+// signature) against data, returning an error if the signature can't be decoded
+func VerifyDetachedSignature(key ed25519.PublicKey, data []byte, sigData []byte) (bool, error) {
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil {
+		return false, err
+	}
+	return ed25519.Verify(key, data, signature), nil
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestKeys creates an ed25519 key pair, written as PEM files like those created by openssl
+func writeTestKeys(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPath, pubPath := filepath.Join(dir, "key.pem"), filepath.Join(dir, "key.pub.pem")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return privPath, pubPath
+}
+
+func TestIndexSignature(t *testing.T) {
+	dir := t.TempDir()
+	index := Index{indexFile: filepath.Join(dir, "index.toml"), packRoot: dir}
+	if err := os.WriteFile(index.indexFile, []byte("hash-format = \"sha256\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	privPath, pubPath := writeTestKeys(t, t.TempDir())
+	priv, err := LoadPrivateKey(privPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := LoadPublicKey(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := index.VerifySignature(pub); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing signature to be reported, got %v", err)
+	}
+
+	if err := index.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.ReadFile(index.SignaturePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.VerifySignature(pub); err != nil {
+		t.Errorf("Expected the signature to be valid, got %v", err)
+	}
+
+	// Signing is deterministic
+	if err := index.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile(index.SignaturePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("Expected signing the same index twice to produce the same signature, got %q and %q", first, second)
+	}
+
+	// Any change to the index invalidates the signature
+	if err := os.WriteFile(index.indexFile, []byte("hash-format = \"sha512\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.VerifySignature(pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a modified index to fail verification, got %v", err)
+	}
+
+	// The signature isn't added to the index
+	files, err := index.listFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected the index and signature to be excluded from the index, got %v", files)
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// NormalizeTag trims whitespace from a tag, returning an error if it is empty
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) == 0 {
+		return "", errors.New("tags must not be empty")
+	}
+	return tag, nil
+}
+
+// HasTag returns true if the mod has the given tag
+func (m *Mod) HasTag(tag string) bool {
+	return slices.Contains(m.Tags, tag)
+}
+
+// AddTag adds a tag to the mod, returning false if it already had the tag
+func (m *Mod) AddTag(tag string) bool {
+	if m.HasTag(tag) {
+		return false
+	}
+	m.Tags = append(m.Tags, tag)
+	slices.Sort(m.Tags)
+	return true
+}
+
+// RemoveTag removes a tag from the mod, returning false if it didn't have the tag
+func (m *Mod) RemoveTag(tag string) bool {
+	i := slices.Index(m.Tags, tag)
+	if i < 0 {
+		return false
+	}
+	m.Tags = slices.Delete(m.Tags, i, i+1)
+	if len(m.Tags) == 0 {
+		m.Tags = nil
+	}
+	return true
+}
+
+// TagFilter selects mods by their tags, for exporting a subset of a pack
+type TagFilter struct {
+	// Include lists tags of which mods must have at least one, if it isn't empty
+	Include []string
+	// Exclude lists tags of which mods must have none; this takes precedence over Include
+	Exclude []string
+}
+
+// Matches returns true if the mod is selected by the filter
+func (f TagFilter) Matches(mod *Mod) bool {
+	if slices.ContainsFunc(f.Exclude, mod.HasTag) {
+		return false
+	}
+	return len(f.Include) == 0 || slices.ContainsFunc(f.Include, mod.HasTag)
+}
+
+// Filter returns the mods selected by the filter
+func (f TagFilter) Filter(mods []*Mod) []*Mod {
+	if len(f.Include) == 0 && len(f.Exclude) == 0 {
+		return mods
+	}
+	filtered := make([]*Mod, 0, len(mods))
+	for _, mod := range mods {
+		if f.Matches(mod) {
+			filtered = append(filtered, mod)
+		}
+	}
+	return filtered
+}
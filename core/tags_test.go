@@ -0,0 +1,55 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestModTags(t *testing.T) {
+	mod := &Mod{Name: "A"}
+	if !mod.AddTag("lite") || !mod.AddTag("full") || mod.AddTag("lite") {
+		t.Error("Expected each tag to be added once")
+	}
+	if !slices.Equal(mod.Tags, []string{"full", "lite"}) {
+		t.Errorf("Expected sorted tags, got %v", mod.Tags)
+	}
+	if !mod.RemoveTag("full") || mod.RemoveTag("full") || !mod.RemoveTag("lite") {
+		t.Error("Expected each tag to be removed once")
+	}
+	if mod.Tags != nil {
+		t.Errorf("Expected no tags, got %v", mod.Tags)
+	}
+	if _, err := NormalizeTag("  "); err == nil {
+		t.Error("Expected an empty tag to be rejected")
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	mods := []*Mod{
+		{Name: "Untagged"},
+		{Name: "Lite", Tags: []string{"lite"}},
+		{Name: "Full", Tags: []string{"full"}},
+		{Name: "Lite Client", Tags: []string{"client-only", "lite"}},
+	}
+	tests := []struct {
+		filter   TagFilter
+		expected []string
+	}{
+		{TagFilter{}, []string{"Untagged", "Lite", "Full", "Lite Client"}},
+		{TagFilter{Include: []string{"lite"}}, []string{"Lite", "Lite Client"}},
+		{TagFilter{Include: []string{"lite", "full"}}, []string{"Lite", "Full", "Lite Client"}},
+		{TagFilter{Exclude: []string{"full"}}, []string{"Untagged", "Lite", "Lite Client"}},
+		// Excluded tags take precedence over included tags
+		{TagFilter{Include: []string{"lite"}, Exclude: []string{"client-only"}}, []string{"Lite"}},
+		{TagFilter{Include: []string{"lite"}, Exclude: []string{"lite"}}, nil},
+	}
+	for _, tt := range tests {
+		var names []string
+		for _, mod := range tt.filter.Filter(mods) {
+			names = append(names, mod.Name)
+		}
+		if !slices.Equal(names, tt.expected) {
+			t.Errorf("Expected filter %+v to select %v, got %v", tt.filter, tt.expected, names)
+		}
+	}
+}
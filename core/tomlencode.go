@@ -0,0 +1,19 @@
+package core
+
+import (
+	"io"
+
+	"github.com/BurntSushi/toml"
+)
+
+// encodeToml writes v as TOML in the canonical format used for pack, index and metadata files, so that rewriting a
+// file that hasn't changed produces exactly the same bytes, and changing one value only changes that line.
+// Struct fields are written in the order they are declared in, and map keys (e.g. update sources, versions and
+// options) in sorted order; tables are written after plain values, and nothing is indented.
+// Comments and formatting that aren't in the canonical format are not preserved.
+func encodeToml(w io.Writer, v interface{}) error {
+	enc := toml.NewEncoder(w)
+	// Disable indentation
+	enc.Indent = ""
+	return enc.Encode(v)
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// goldenTestUpdater parses update data without validating it, so that metadata files can be loaded without the real
+// updaters
+type goldenTestUpdater struct {
+	Updater
+}
+
+func (goldenTestUpdater) ParseUpdate(data map[string]interface{}) (interface{}, error) {
+	return data, nil
+}
+
+// copyGoldenFile copies a file from testdata/golden to dir, returning its original contents and the path of the copy
+func copyGoldenFile(t *testing.T, dir string, name string) ([]byte, string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "golden", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return data, path
+}
+
+func assertGoldenOutput(t *testing.T, name string, expected []byte, path string) {
+	t.Helper()
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("Rewriting %s changed it:\n--- expected\n%s\n--- actual\n%s", name, expected, actual)
+	}
+}
+
+func TestWriteIsByteStable(t *testing.T) {
+	for _, name := range []string{"curseforge", "modrinth"} {
+		if _, ok := Updaters[name]; !ok {
+			Updaters[name] = goldenTestUpdater{}
+			defer delete(Updaters, name)
+		}
+	}
+	dir := t.TempDir()
+	oldPackFile := viper.GetString("pack-file")
+	oldHashFormat := viper.GetString("default-hash-format")
+	t.Cleanup(func() {
+		viper.Set("pack-file", oldPackFile)
+		viper.Set("default-hash-format", oldHashFormat)
+	})
+
+	t.Run("pack", func(t *testing.T) {
+		expected, path := copyGoldenFile(t, dir, "pack.toml")
+		viper.Set("pack-file", path)
+		// Writing twice checks that the output doesn't depend on the order maps are iterated in
+		for range 2 {
+			pack, err := LoadPack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := pack.Write(); err != nil {
+				t.Fatal(err)
+			}
+			assertGoldenOutput(t, "pack.toml", expected, path)
+		}
+	})
+
+	t.Run("index", func(t *testing.T) {
+		expected, path := copyGoldenFile(t, dir, "index.toml")
+		for range 2 {
+			index, err := LoadIndex(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := index.Write(); err != nil {
+				t.Fatal(err)
+			}
+			assertGoldenOutput(t, "index.toml", expected, path)
+		}
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		expected, path := copyGoldenFile(t, dir, "golden.pw.toml")
+		viper.Set("default-hash-format", "sha256")
+		for range 2 {
+			mod, err := LoadMod(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := mod.Write(); err != nil {
+				t.Fatal(err)
+			}
+			assertGoldenOutput(t, "golden.pw.toml", expected, path)
+		}
+
+		// Changing one value only changes that line
+		mod, err := LoadMod(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mod.Update["modrinth"]["version"] = "CCCCCCCC"
+		if _, _, err := mod.Write(); err != nil {
+			t.Fatal(err)
+		}
+		assertGoldenOutput(t, "golden.pw.toml", bytes.Replace(expected, []byte(`"BBBBBBBB"`+"\n"), []byte(`"CCCCCCCC"`+"\n"), 1), path)
+	})
+}
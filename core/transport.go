@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultHTTPTimeout is the default time to wait for the response to an HTTP request, or for more of its body
+const DefaultHTTPTimeout = 30 * time.Second
+
+// ErrHTTPTimeout is returned when a server doesn't respond to a request (or stops sending its body) within the timeout
+// set by SetHTTPTimeout
+var ErrHTTPTimeout = errors.New("request timed out")
+
+// NewBaseTransport returns a transport for HTTP requests that uses the proxy given by the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables. If caCertFile isn't empty, the PEM encoded certificates in it are trusted as well as
+// the system roots, e.g. for proxies that intercept TLS connections with an internal CA.
+func NewBaseTransport(caCertFile string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if caCertFile == "" {
+		return transport, nil
+	}
+
+	data, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// The system roots aren't available on some platforms, so only the custom CA is trusted
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("failed to read CA certificate: " + caCertFile + " doesn't contain any PEM encoded certificates")
+	}
+	transport.TLSClientConfig.RootCAs = pool
+	return transport, nil
+}
+
+var baseTransport atomic.Pointer[http.Transport]
+
+func init() {
+	transport, _ := NewBaseTransport("")
+	baseTransport.Store(transport)
+}
+
+// SetBaseTransport replaces the transport used by all HTTP clients to send requests
+func SetBaseTransport(transport *http.Transport) {
+	baseTransport.Store(transport)
+}
+
+var httpTimeout atomic.Int64
+
+func init() {
+	httpTimeout.Store(int64(DefaultHTTPTimeout))
+}
+
+// SetHTTPTimeout sets the time to wait for the response to each HTTP request sent by BaseTransport, or for more of its
+// body; zero disables the timeout. As it applies to each attempt, rather than the whole request, it doesn't limit the
+// time spent waiting to retry rate limited requests, or the total time taken by a slow but steady download.
+func SetHTTPTimeout(timeout time.Duration) {
+	httpTimeout.Store(int64(max(timeout, 0)))
+}
+
+// BaseTransport sends requests with the transport set by SetBaseTransport, so clients created before the proxy and CA
+// settings have been read still use them
+type BaseTransport struct{}
+
+func (BaseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := time.Duration(httpTimeout.Load())
+	if timeout == 0 {
+		return baseTransport.Load().RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timeoutErr := fmt.Errorf("%w: no response from %s within %v", ErrHTTPTimeout, req.URL.Host, timeout)
+	timer := time.AfterFunc(timeout, func() { cancel(timeoutErr) })
+	resp, err := baseTransport.Load().RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		timer.Stop()
+		if context.Cause(ctx) == timeoutErr {
+			err = timeoutErr
+		}
+		cancel(nil)
+		return nil, err
+	}
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, ctx: ctx, cancel: cancel, timer: timer, timeout: timeout, timeoutErr: timeoutErr}
+	return resp, nil
+}
+
+// timeoutBody restarts the timeout of a request each time data is read from its body, and releases its context when
+// it is closed
+type timeoutBody struct {
+	io.ReadCloser
+	ctx        context.Context
+	cancel     context.CancelCauseFunc
+	timer      *time.Timer
+	timeout    time.Duration
+	timeoutErr error
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && context.Cause(b.ctx) == b.timeoutErr {
+		return n, b.timeoutErr
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	b.timer.Stop()
+	err := b.ReadCloser.Close()
+	b.cancel(nil)
+	return err
+}
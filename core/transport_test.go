@@ -0,0 +1,122 @@
+package core
+
+import (
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewBaseTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport, err := NewBaseTransport("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.ValueOf(transport.Proxy).Pointer() != reflect.ValueOf(http.ProxyFromEnvironment).Pointer() {
+		t.Error("Expected the proxy to be read from the environment")
+	}
+	if _, err := (&http.Client{Transport: transport}).Get(server.URL); err == nil {
+		t.Error("Expected the test server's certificate not to be trusted without a custom CA")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	transport, err = NewBaseTransport(caFile)
+	if err != nil {
+		t.Fatalf("Failed to load CA certificate: %v", err)
+	}
+	if reflect.ValueOf(transport.Proxy).Pointer() != reflect.ValueOf(http.ProxyFromEnvironment).Pointer() {
+		t.Error("Expected the proxy to be read from the environment with a custom CA")
+	}
+
+	// Clients created before the base transport is set use it too
+	client := NewRateLimitHTTPClient("Test API", 1, 0, nil)
+	SetBaseTransport(transport)
+	defer func() {
+		transport, _ := NewBaseTransport("")
+		SetBaseTransport(transport)
+	}()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the custom CA to be trusted: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBaseTransport(caFile); err == nil {
+		t.Error("Expected an error for a file without certificates")
+	}
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		case "/stall":
+			_, _ = w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	SetHTTPTimeout(100 * time.Millisecond)
+	t.Cleanup(func() { SetHTTPTimeout(DefaultHTTPTimeout) })
+	client := &http.Client{Transport: BaseTransport{}}
+
+	resp, err := client.Get(server.URL + "/fast")
+	if err != nil {
+		t.Fatalf("Expected a fast request to succeed, got %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil || string(body) != "ok" {
+		t.Errorf("Expected body ok, got %q (%v)", body, err)
+	}
+
+	start := time.Now()
+	_, err = client.Get(server.URL + "/slow")
+	if !errors.Is(err, ErrHTTPTimeout) {
+		t.Errorf("Expected a timeout error for a slow response, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the request to time out quickly, took %v", elapsed)
+	}
+
+	resp, err = client.Get(server.URL + "/stall")
+	if err != nil {
+		t.Fatalf("Expected the response headers to arrive, got %v", err)
+	}
+	_, err = io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !errors.Is(err, ErrHTTPTimeout) {
+		t.Errorf("Expected a timeout error for a stalled body, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Version is the version of packwiz, which can be set when building with
+// -ldflags="-X 'github.com/0byte-coding/packwiz/core.Version=1.2.3'". If it isn't set, the module version from the
+// build info is used.
+var Version = ""
+
+// GetVersion returns the version of packwiz, or "dev" if it isn't known
+func GetVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return strings.TrimPrefix(info.Main.Version, "v")
+	}
+	return "dev"
+}
+
+// GetUserAgent returns the User-Agent sent with all HTTP requests: packwiz/<version>, followed by the contact details
+// configured in the user-agent.contact setting, if any
+func GetUserAgent() string {
+	userAgent := "packwiz/" + GetVersion()
+	if contact := strings.TrimSpace(viper.GetString("user-agent.contact")); contact != "" {
+		userAgent += " (" + contact + ")"
+	}
+	return userAgent
+}
+
+// UserAgentTransport wraps an http.RoundTripper, setting the User-Agent header of all requests to GetUserAgent
+type UserAgentTransport struct {
+	Transport http.RoundTripper
+}
+
+func (t *UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = BaseTransport{}
+	}
+	// RoundTrippers must not modify the request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", GetUserAgent())
+	return transport.RoundTrip(req)
+}
+
+// defaultHTTPClient is used for requests that aren't made to an API with its own client, such as downloading files
+var defaultHTTPClient = &http.Client{Transport: &UserAgentTransport{}}
+
+// DownloadHTTPClient is used to download files from servers that don't have an API client of their own, retrying
+// rate limited requests. The settings module replaces it with a client using the configured retry settings.
+var DownloadHTTPClient = NewRateLimitHTTPClient("download server", DefaultMaxRetries, 0, nil)
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestUserAgent(t *testing.T) {
+	var userAgents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+	}))
+	defer srv.Close()
+
+	viper.Set("user-agent.contact", "admin@example.com")
+	t.Cleanup(func() { viper.Set("user-agent.contact", "") })
+	expected := "packwiz/" + GetVersion() + " (admin@example.com)"
+
+	// API clients, which also set a User-Agent of their own
+	client := NewRateLimitHTTPClient("Test API", 0, 0, nil)
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "other")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if req.Header.Get("User-Agent") != "other" {
+		t.Error("Expected the original request not to be modified")
+	}
+
+	// Downloads
+	resp, err = GetWithProgress(context.Background(), nil, srv.URL, "*/*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if len(userAgents) != 2 || userAgents[0] != expected || userAgents[1] != expected {
+		t.Errorf("Expected all requests to have User-Agent %q, got %q", expected, userAgents)
+	}
+
+	viper.Set("user-agent.contact", "")
+	if userAgent := GetUserAgent(); userAgent != "packwiz/"+GetVersion() {
+		t.Errorf("Expected no contact details in the User-Agent, got %q", userAgent)
+	}
+}
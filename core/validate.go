@@ -0,0 +1,138 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Severities of the problems found when validating a pack
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationProblem describes a problem found when validating a pack
+type ValidationProblem struct {
+	Severity string
+	// Path is the path of the file with the problem, relative to the pack root
+	Path    string
+	Message string
+}
+
+func (p ValidationProblem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Path, p.Message)
+}
+
+// Validate checks the pack and its index for common mistakes, without modifying any files, returning the problems
+// found sorted by path
+func (in Index) Validate(pack Pack) []ValidationProblem {
+	var problems []ValidationProblem
+	report := func(severity string, path string, format string, args ...interface{}) {
+		problems = append(problems, ValidationProblem{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	noInternalHashes := viper.GetBool("no-internal-hashes")
+	packFile := viper.GetString("pack-file")
+	if relPath, err := in.RelIndexPath(packFile); err == nil {
+		packFile = relPath
+	}
+
+	if pack.Index.Hash == "" {
+		if !noInternalHashes {
+			report(SeverityWarning, packFile, "no hash is stored for the index")
+		}
+	} else if mismatch := pack.VerifyIndexHash(); mismatch != nil {
+		if mismatch.Error != nil {
+			report(SeverityError, mismatch.Path, "failed to hash the index: %v", mismatch.Error)
+		} else {
+			report(SeverityError, packFile, "the stored index hash is stale; run packwiz refresh")
+		}
+	}
+
+	for p, holder := range in.Files {
+		if _, err := os.Stat(in.ResolveIndexPath(p)); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				report(SeverityError, p, "referenced by the index but missing on disk")
+			} else {
+				report(SeverityError, p, "failed to read file: %v", err)
+			}
+			continue
+		}
+		if !noInternalHashes && hasEmptyHash(holder) {
+			report(SeverityWarning, p, "no hash is stored in the index; run packwiz refresh")
+		}
+		if holder.IsMetaFile() {
+			problems = append(problems, validateMetaFile(p, in.ResolveIndexPath(p))...)
+		}
+	}
+	for _, mismatch := range in.Verify() {
+		// Missing files have already been reported
+		if mismatch.Error == nil {
+			report(SeverityError, mismatch.Path, "file doesn't match its hash in the index; run packwiz refresh")
+		} else if _, err := os.Stat(in.ResolveIndexPath(mismatch.Path)); err == nil {
+			report(SeverityError, mismatch.Path, "failed to hash file: %v", mismatch.Error)
+		}
+	}
+
+	files, err := in.listFiles()
+	if err != nil {
+		report(SeverityError, ".", "failed to list files: %v", err)
+	}
+	for _, file := range files {
+		relPath, err := in.RelIndexPath(file)
+		if err != nil {
+			report(SeverityError, file, "%v", err)
+			continue
+		}
+		if _, ok := in.Files[relPath]; !ok {
+			report(SeverityWarning, relPath, "not in the index; run packwiz refresh to add it, or add it to .packwizignore")
+		}
+	}
+
+	slices.SortStableFunc(problems, func(a, b ValidationProblem) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return problems
+}
+
+// hasEmptyHash returns true if any index entry for a path doesn't have a hash
+func hasEmptyHash(holder IndexPathHolder) bool {
+	if file, ok := holder.(*indexFile); ok {
+		return file.Hash == ""
+	} else if file, ok := holder.(*indexFileMultipleAlias); ok {
+		for _, alias := range *file {
+			if alias.Hash == "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateMetaFile checks that a metadata file can be read, and has a valid side and download hash
+func validateMetaFile(relPath string, path string) []ValidationProblem {
+	var problems []ValidationProblem
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, ValidationProblem{Severity: SeverityError, Path: relPath, Message: fmt.Sprintf(format, args...)})
+	}
+
+	mod, err := LoadMod(path)
+	if err != nil {
+		report("failed to read metadata file: %v", err)
+		return problems
+	}
+	if !slices.Contains([]string{EmptySide, ClientSide, ServerSide, UniversalSide}, mod.Side) {
+		report("invalid side %q, must be one of client, server or both", mod.Side)
+	}
+	if mod.Download.Hash == "" {
+		report("no download hash is set")
+	}
+	if err := ValidateHashFormat(mod.Download.HashFormat); err != nil {
+		report("invalid download hash format: %v", err)
+	}
+	return problems
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	indexFile := createTestPack(t, 0)
+	dir := filepath.Dir(indexFile)
+	mods := map[string]string{
+		"mods/a.pw.toml": "name = \"A\"\nfilename = \"a.jar\"\nside = \"both\"\n[download]\nurl = \"https://example.com/a.jar\"\nhash-format = \"sha256\"\nhash = \"abc\"\n",
+		"mods/b.pw.toml": "name = \"B\"\nfilename = \"b.jar\"\n[download]\nurl = \"https://example.com/b.jar\"\nhash-format = \"sha256\"\nhash = \"bbb\"\n",
+		"mods/c.pw.toml": "name = \"C\"\nfilename = \"c.jar\"\n[download]\nurl = \"https://example.com/c.jar\"\nhash-format = \"sha256\"\nhash = \"ccc\"\n",
+	}
+	writeFiles := func(files map[string]string) {
+		for name, contents := range files {
+			p := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeFiles(mods)
+
+	index, err := LoadIndex(indexFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Write(); err != nil {
+		t.Fatal(err)
+	}
+	pack := Pack{Index: struct {
+		File       string `toml:"file"`
+		HashFormat string `toml:"hash-format"`
+		Hash       string `toml:"hash,omitempty"`
+	}{File: "index.toml"}}
+	if err := pack.UpdateIndexHash(); err != nil {
+		t.Fatal(err)
+	}
+	if problems := index.Validate(pack); len(problems) > 0 {
+		t.Fatalf("Expected no problems for a refreshed pack, got %v", problems)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "mods", "a.pw.toml")); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(map[string]string{
+		"mods/b.pw.toml":  "name = \"B\"\nfilename = \"b.jar\"\nside = \"everywhere\"\n[download]\nurl = \"https://example.com/b.jar\"\nhash-format = \"sha256\"\nhash = \"\"\n",
+		"config/new.toml": "a = 1",
+	})
+	pack.Index.Hash = "0000"
+
+	expected := []ValidationProblem{
+		{SeverityWarning, "config/new.toml", "not in the index; run packwiz refresh to add it, or add it to .packwizignore"},
+		{SeverityError, "mods/a.pw.toml", "referenced by the index but missing on disk"},
+		{SeverityError, "mods/b.pw.toml", "invalid side \"everywhere\", must be one of client, server or both"},
+		{SeverityError, "mods/b.pw.toml", "no download hash is set"},
+		{SeverityError, "mods/b.pw.toml", "file doesn't match its hash in the index; run packwiz refresh"},
+		{SeverityError, "pack.toml", "the stored index hash is stale; run packwiz refresh"},
+	}
+	problems := index.Validate(pack)
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), problems)
+	}
+	for i, problem := range problems {
+		if problem != expected[i] {
+			t.Errorf("Expected problem %v, got %v", expected[i], problem)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// HashMismatch describes a file whose contents don't match the hash stored for it
+type HashMismatch struct {
+	// Path is the path of the file, relative to the pack root
+	Path     string
+	Format   string
+	Expected string
+	// Actual is empty when the file could not be read, in which case Error is set
+	Actual string
+	Error  error
+}
+
+func (m HashMismatch) String() string {
+	if m.Error != nil {
+		return fmt.Sprintf("%s: %v", m.Path, m.Error)
+	}
+	return fmt.Sprintf("%s: expected %s hash %s, got %s", m.Path, m.Format, m.Expected, m.Actual)
+}
+
+// HashReader calculates the hash of the contents of a reader using the given hash format
+func HashReader(r io.Reader, hashFormat string) (string, error) {
+	h, err := GetHashImpl(hashFormat)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return h.HashToString(h.Sum(nil)), nil
+}
+
+// hashFile calculates the hash of the file at the given path using the given hash format
+func hashFile(path string, hashFormat string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	hash, err := HashReader(f, hashFormat)
+	if err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return hash, f.Close()
+}
+
+// verifyHash checks that the file at the given path matches the given hash, returning nil if it does
+func verifyHash(relPath string, path string, hashFormat string, hash string) *HashMismatch {
+	actual, err := hashFile(path, hashFormat)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = errors.New("file not found")
+		}
+		return &HashMismatch{Path: relPath, Format: hashFormat, Expected: hash, Error: err}
+	}
+	// Hashes are stored in lowercase, but may have been edited by hand
+	if !strings.EqualFold(actual, hash) {
+		return &HashMismatch{Path: relPath, Format: hashFormat, Expected: hash, Actual: actual}
+	}
+	return nil
+}
+
+// VerifyIndexHash checks that the index file matches the hash stored in the pack, returning nil if it does
+// (or if the pack doesn't store a hash for the index)
+func (pack Pack) VerifyIndexHash() *HashMismatch {
+	if pack.Index.Hash == "" {
+		return nil
+	}
+	indexFile := filepath.Join(filepath.Dir(viper.GetString("pack-file")), filepath.FromSlash(pack.Index.File))
+	return verifyHash(pack.Index.File, indexFile, pack.Index.HashFormat, pack.Index.Hash)
+}
+
+// Verify recalculates the hashes of all files in the index, returning any that don't match the stored hashes,
+// sorted by path. Files without a stored hash are skipped.
+func (in Index) Verify() []HashMismatch {
+	var mismatches []HashMismatch
+	for p, holder := range in.Files {
+		var entries []indexFile
+		if file, ok := holder.(*indexFile); ok {
+			entries = append(entries, *file)
+		} else if file, ok := holder.(*indexFileMultipleAlias); ok {
+			for _, alias := range *file {
+				entries = append(entries, alias)
+			}
+		} else {
+			panic("Unknown type in IndexFiles")
+		}
+
+		// Aliased entries point to the same file, so only report each distinct hash once
+		checked := make(map[string]bool)
+		for _, entry := range entries {
+			if entry.Hash == "" {
+				continue
+			}
+			hashFormat := entry.HashFormat
+			if hashFormat == "" {
+				hashFormat = in.HashFormat
+			}
+			if checked[hashFormat+":"+entry.Hash] {
+				continue
+			}
+			checked[hashFormat+":"+entry.Hash] = true
+
+			if mismatch := verifyHash(p, in.ResolveIndexPath(p), hashFormat, entry.Hash); mismatch != nil {
+				mismatches = append(mismatches, *mismatch)
+			}
+		}
+	}
+
+	slices.SortFunc(mismatches, func(a, b HashMismatch) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return mismatches
+}
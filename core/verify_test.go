@@ -0,0 +1,59 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexVerify(t *testing.T) {
+	dir := t.TempDir()
+	// sha256 of "hello"
+	index := `hash-format = "sha256"
+
+[[files]]
+file = "config/good.txt"
+hash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+[[files]]
+file = "config/bad.txt"
+hash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+[[files]]
+file = "config/missing.txt"
+hash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+[[files]]
+file = "config/unhashed.txt"
+`
+	files := map[string]string{
+		"index.toml":          index,
+		"config/good.txt":     "hello",
+		"config/bad.txt":      "tampered",
+		"config/unhashed.txt": "anything",
+	}
+	for name, contents := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	in, err := LoadIndex(filepath.Join(dir, "index.toml"))
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+	mismatches := in.Verify()
+	if len(mismatches) != 2 {
+		t.Fatalf("Expected 2 mismatches, got %v", mismatches)
+	}
+	if mismatches[0].Path != "config/bad.txt" || mismatches[0].Error != nil || mismatches[0].Actual == "" {
+		t.Errorf("Expected a hash mismatch for config/bad.txt, got %v", mismatches[0])
+	}
+	if mismatches[1].Path != "config/missing.txt" || mismatches[1].Error == nil {
+		t.Errorf("Expected an error for config/missing.txt, got %v", mismatches[1])
+	}
+}
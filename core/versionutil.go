@@ -161,24 +161,29 @@ func FetchNeoForge() func(mcVersion string) ([]string, string, error) {
 }
 
 func FetchMavenWithNeoForgeStyleVersions(url string, friendlyName string) func(mcVersion string) ([]string, string, error) {
-	return FetchMavenVersionFiltered(url, friendlyName, func(neoforgeVersion string, mcVersion string) bool {
-		// Minecraft versions are in the form of 1.a.b
-		// Neoforge versions are in the form of a.b.x
-		// Eg, for minecraft 1.20.6, neoforge version 20.6.2 and 20.6.83-beta would both be valid versions
-		// for minecraft 1.20.2, neoforge version 20.2.23-beta
-		// for minecraft 1.21, neoforge version 21.0.143 would be valid
-		var mcSplit = strings.Split(mcVersion, ".")
-		if len(mcSplit) < 2 {
-			// This does not appear to be a minecraft version that's formatted in a way that matches neoforge
-			return false
-		}
-		var mcMajor = mcSplit[1]
-		var mcMinor = "0"
-		if len(mcSplit) > 2 {
-			mcMinor = mcSplit[2]
-		}
-		return strings.HasPrefix(neoforgeVersion, mcMajor+"."+mcMinor)
-	})
+	return FetchMavenVersionFiltered(url, friendlyName, isNeoForgeVersionForMC)
+}
+
+// isNeoForgeVersionForMC returns true if the given NeoForge version (in the format used since 1.20.2) is for the given
+// Minecraft version
+func isNeoForgeVersionForMC(neoforgeVersion string, mcVersion string) bool {
+	// Minecraft versions are in the form of 1.a.b
+	// Neoforge versions are in the form of a.b.x
+	// Eg, for minecraft 1.20.6, neoforge version 20.6.2 and 20.6.83-beta would both be valid versions
+	// for minecraft 1.20.2, neoforge version 20.2.23-beta
+	// for minecraft 1.21, neoforge version 21.0.143 would be valid
+	var mcSplit = strings.Split(mcVersion, ".")
+	if len(mcSplit) < 2 {
+		// This does not appear to be a minecraft version that's formatted in a way that matches neoforge
+		return false
+	}
+	var mcMajor = mcSplit[1]
+	var mcMinor = "0"
+	if len(mcSplit) > 2 {
+		mcMinor = mcSplit[2]
+	}
+	// Include the trailing dot, so that 1.21.1 doesn't match versions for 1.21.10
+	return strings.HasPrefix(neoforgeVersion, mcMajor+"."+mcMinor+".")
 }
 
 func ComponentToFriendlyName(component string) string {
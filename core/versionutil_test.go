@@ -0,0 +1,25 @@
+package core
+
+import "testing"
+
+func TestIsNeoForgeVersionForMC(t *testing.T) {
+	tests := []struct {
+		neoforgeVersion string
+		mcVersion       string
+		expected        bool
+	}{
+		{"20.2.23-beta", "1.20.2", true},
+		{"20.6.83-beta", "1.20.6", true},
+		{"21.0.143", "1.21", true},
+		{"21.1.5", "1.21.1", true},
+		{"21.10.5", "1.21.1", false},
+		{"21.1.5", "1.21.10", false},
+		{"20.4.80-beta", "1.20.2", false},
+		{"21.0.143", "invalid", false},
+	}
+	for _, test := range tests {
+		if actual := isNeoForgeVersionForMC(test.neoforgeVersion, test.mcVersion); actual != test.expected {
+			t.Errorf("isNeoForgeVersionForMC(%q, %q) = %v, expected %v", test.neoforgeVersion, test.mcVersion, actual, test.expected)
+		}
+	}
+}
@@ -29,6 +29,8 @@ func init() {
 	cmd.Add(curseforgeCmd)
 	core.Updaters["curseforge"] = cfUpdater{}
 	core.MetaDownloaders["curseforge"] = cfDownloader{}
+	core.PackImporters["curseforge"] = cfPackImporter{}
+	core.FileMatchers["curseforge"] = cfFileMatcher{}
 }
 
 var snapshotVersionRegex = regexp.MustCompile(`(?:Snapshot )?(\d+)w0?(0|[1-9]\d*)([a-z])`)
@@ -121,9 +123,10 @@ func getCurseforgeVersions(mcVersions []string) []string {
 }
 
 var urlRegexes = [...]*regexp.Regexp{
-	regexp.MustCompile(`^https?://(?P<game>minecraft)\.curseforge\.com/projects/(?P<slug>[^/]+)(?:/(?:files|download)/(?P<fileID>\d+))?`),
-	regexp.MustCompile(`^https?://(?:www\.|beta\.|legacy\.)?curseforge\.com/(?P<game>[^/]+)/(?P<category>[^/]+)/(?P<slug>[^/]+)(?:/(?:files|download)/(?P<fileID>\d+))?`),
-	regexp.MustCompile(`^(?P<slug>[a-z][\da-z\-_]{0,127})$`),
+	regexp.MustCompile(`^(?:https?://)?(?P<game>minecraft)\.curseforge\.com/projects/(?P<slug>[^/?#]+)(?:/(?:files|download)/(?P<fileID>\d+))?`),
+	regexp.MustCompile(`^(?:https?://)?(?:www\.|beta\.|legacy\.)?curseforge\.com/(?P<game>[^/]+)/(?P<category>[^/]+)/(?P<slug>[^/?#]+)(?:/(?:files|download)/(?P<fileID>\d+))?`),
+	// Slugs may start with a digit; callers treat a number on its own as a project ID instead
+	regexp.MustCompile(`^(?P<slug>[\da-z][\da-z\-_]{0,127})$`),
 }
 
 func parseSlugOrUrl(url string) (game string, category string, slug string, fileID uint32, err error) {
@@ -152,31 +155,66 @@ func parseSlugOrUrl(url string) (game string, category string, slug string, file
 	return
 }
 
-var defaultFolders = map[uint32]map[uint32]string{
+type projectTypeFolder struct {
+	projectType string
+	folder      string
+}
+
+var defaultFolders = map[uint32]map[uint32]projectTypeFolder{
 	432: { // Minecraft
-		5:  "plugins", // Bukkit Plugins
-		12: "resourcepacks",
-		6:  "mods",
-		17: "saves",
+		5:    {core.ProjectTypePlugin, "plugins"}, // Bukkit Plugins
+		12:   {core.ProjectTypeResourcePack, "resourcepacks"},
+		6:    {core.ProjectTypeMod, "mods"},
+		17:   {core.ProjectTypeWorld, "saves"},
+		6552: {core.ProjectTypeShaderPack, "shaderpacks"},
+		6945: {core.ProjectTypeDatapack, "datapacks"},
 	},
 }
 
-func getPathForFile(gameID uint32, classID uint32, categoryID uint32, slug string) string {
+func getPathForFile(gameID uint32, classID uint32, categoryID uint32, slug string) (string, error) {
 	metaFolder := viper.GetString("meta-folder")
 	if metaFolder == "" {
 		if m, ok := defaultFolders[gameID]; ok {
-			if folder, ok := m[classID]; ok {
-				return filepath.Join(viper.GetString("meta-folder-base"), folder, slug+core.MetaExtension)
-			} else if folder, ok := m[categoryID]; ok {
-				return filepath.Join(viper.GetString("meta-folder-base"), folder, slug+core.MetaExtension)
+			typeFolder, ok := m[classID]
+			if !ok {
+				typeFolder, ok = m[categoryID]
+			}
+			if ok {
+				folder, err := core.GetProjectTypeFolder(typeFolder.projectType, typeFolder.folder)
+				if err != nil {
+					return "", err
+				}
+				return filepath.Join(viper.GetString("meta-folder-base"), folder, slug+core.MetaExtension), nil
 			}
 		}
 		metaFolder = "."
 	}
-	return filepath.Join(viper.GetString("meta-folder-base"), metaFolder, slug+core.MetaExtension)
+	return filepath.Join(viper.GetString("meta-folder-base"), metaFolder, slug+core.MetaExtension), nil
 }
 
-func createModFile(modInfo modInfo, fileInfo modFileInfo, index *core.Index, optionalDisabled bool) error {
+// getRequiredDependencyIDs returns the project IDs of the required dependencies of a file, so that they can be stored in
+// its metadata file
+func getRequiredDependencyIDs(fileInfo modFileInfo, pack core.Pack) []string {
+	isQuilt := slices.Contains(pack.GetCompatibleLoaders(), "quilt")
+	mcVersion, _ := pack.GetMCVersion()
+	var ids []uint32
+	for _, dep := range fileInfo.Dependencies {
+		if dep.Type == dependencyTypeRequired {
+			ids = append(ids, mapDepOverride(dep.ModID, isQuilt, mcVersion))
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return idStrings
+}
+
+// createModFile creates the metadata file for a CurseForge file; dependencies are the project IDs of its required
+// dependencies, which may be nil if they aren't known
+func createModFile(modInfo modInfo, fileInfo modFileInfo, index *core.Index, optionalDisabled bool, dependencies []string, addedAsDependency bool) error {
 	updateMap := make(map[string]map[string]interface{})
 	var err error
 
@@ -198,19 +236,34 @@ func createModFile(modInfo modInfo, fileInfo modFileInfo, index *core.Index, opt
 		}
 	}
 
+	side := core.UniversalSide
+	if folder, ok := defaultFolders[modInfo.GameID][modInfo.ClassID]; ok && core.DefaultProjectTypeSide(folder.projectType) != core.EmptySide {
+		side = core.DefaultProjectTypeSide(folder.projectType)
+	}
+	if sideFlag != "" && !addedAsDependency {
+		side = sideFlag
+	}
+
 	modMeta := core.Mod{
 		Name:     modInfo.Name,
 		FileName: fileInfo.FileName,
-		Side:     core.UniversalSide,
+		Side:     side,
 		Download: core.ModDownload{
 			HashFormat: hashFormat,
 			Hash:       hash,
 			Mode:       core.ModeCF,
 		},
-		Option: optional,
-		Update: updateMap,
+		Pin:               pinFlag && !addedAsDependency,
+		Option:            optional,
+		Dependencies:      dependencies,
+		AddedAsDependency: addedAsDependency,
+		Update:            updateMap,
 	}
-	path := modMeta.SetMetaPath(getPathForFile(modInfo.GameID, modInfo.ClassID, modInfo.PrimaryCategoryID, modInfo.Slug))
+	metaPath, err := getPathForFile(modInfo.GameID, modInfo.ClassID, modInfo.PrimaryCategoryID, modInfo.Slug)
+	if err != nil {
+		return err
+	}
+	path := modMeta.SetMetaPath(metaPath)
 
 	// If the file already exists, this will overwrite it!!!
 	// TODO: Should this be improved?
@@ -221,6 +274,9 @@ func createModFile(modInfo modInfo, fileInfo modFileInfo, index *core.Index, opt
 	if err != nil {
 		return err
 	}
+	if modInfo.distributionDisabled() {
+		fmt.Printf("Warning: the author of %s has disabled third-party downloads, so it must be downloaded manually when exporting the pack, and it can't be included in Modrinth packs\n", modInfo.Name)
+	}
 
 	return index.RefreshFileWithHash(path, format, hash, true)
 }
@@ -376,6 +432,15 @@ func (u cfUpdater) ParseUpdate(updateUnparsed map[string]interface{}) (interface
 	return updateData, err
 }
 
+func (u cfUpdater) ProjectPage(mod *core.Mod) (string, error) {
+	rawData, ok := mod.GetParsedUpdateData("curseforge")
+	if !ok {
+		return "", errors.New("failed to parse update metadata")
+	}
+	data := rawData.(cfUpdateData)
+	return "https://www.curseforge.com/projects/" + strconv.FormatUint(uint64(data.ProjectID), 10), nil
+}
+
 type cachedStateStore struct {
 	modInfo
 	fileID   uint32
@@ -426,15 +491,21 @@ func (u cfUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 		project := projectRaw.(cfUpdateData)
 
 		fileID, fileInfoData, fileName := findLatestFile(modInfos[i], mcVersions, packLoaders)
-		if fileID != project.FileID && fileID != 0 {
+		if fileID == 0 {
+			results[i] = core.UpdateCheck{Error: errors.New("no valid versions found")}
+			continue
+		}
+		if fileID != project.FileID {
 			// Update (or downgrade, if changing to an older version) available!
 			results[i] = core.UpdateCheck{
 				UpdateAvailable: true,
 				UpdateString:    v.FileName + " -> " + fileName,
+				CurrentVersion:  v.FileName,
+				NewVersion:      fileName,
 				CachedState:     cachedStateStore{modInfos[i], fileID, fileInfoData},
 			}
 		} else {
-			// Could not find a file, too old, or up to date: no update available
+			// Up to date: no update available
 			results[i] = core.UpdateCheck{UpdateAvailable: false}
 			continue
 		}
@@ -442,6 +513,54 @@ func (u cfUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 	return results, nil
 }
 
+func (u cfUpdater) CheckUpdateToVersion(mod *core.Mod, versionID string, pack core.Pack) (core.UpdateCheck, error) {
+	projectRaw, ok := mod.GetParsedUpdateData("curseforge")
+	if !ok {
+		return core.UpdateCheck{}, errors.New("failed to parse update metadata")
+	}
+	project := projectRaw.(cfUpdateData)
+
+	fileID, err := strconv.ParseUint(versionID, 10, 32)
+	if err != nil {
+		return core.UpdateCheck{}, fmt.Errorf("invalid file ID %s: %w", versionID, err)
+	}
+	if uint32(fileID) == project.FileID {
+		return core.UpdateCheck{UpdateAvailable: false}, nil
+	}
+
+	modInfoData, err := cfDefaultClient.getModInfo(project.ProjectID)
+	if err != nil {
+		return core.UpdateCheck{}, fmt.Errorf("failed to get project info: %w", err)
+	}
+	fileInfoData, err := cfDefaultClient.getFileInfo(project.ProjectID, uint32(fileID))
+	if err != nil {
+		return core.UpdateCheck{}, fmt.Errorf("failed to get file %d: %w", fileID, err)
+	}
+	if fileInfoData.ModID != project.ProjectID {
+		return core.UpdateCheck{}, fmt.Errorf("file %d is not a file of %s", fileID, mod.Name)
+	}
+
+	mcVersions, err := pack.GetSupportedMCVersions()
+	if err != nil {
+		return core.UpdateCheck{}, err
+	}
+	if core.HighestSliceIndex(mcVersions, fileInfoData.GameVersions) < 0 {
+		fmt.Printf("Warning: file %s of %s doesn't support any of the pack's Minecraft versions (%s)\n",
+			fileInfoData.FileName, mod.Name, strings.Join(mcVersions, ", "))
+	}
+	if _, loaderValid := filterFileInfoLoaderIndex(pack.GetCompatibleLoaders(), fileInfoData); !loaderValid {
+		fmt.Printf("Warning: file %s of %s doesn't support any of the pack's loaders\n", fileInfoData.FileName, mod.Name)
+	}
+
+	return core.UpdateCheck{
+		UpdateAvailable: true,
+		UpdateString:    mod.FileName + " -> " + fileInfoData.FileName,
+		CurrentVersion:  mod.FileName,
+		NewVersion:      fileInfoData.FileName,
+		CachedState:     cachedStateStore{modInfoData, fileInfoData.ID, &fileInfoData},
+	}, nil
+}
+
 func (u cfUpdater) DoUpdate(mods []*core.Mod, cachedState []interface{}) error {
 	// "Do" isn't really that accurate, more like "Apply", because all the work is done in CheckUpdate!
 	for i, v := range mods {
@@ -0,0 +1,85 @@
+package curseforge
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+func TestNoDistributionIsManualDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/mods/files":
+			// Files of projects with third-party downloads disabled have no download URL
+			_, _ = w.Write([]byte(`{"data": [
+				{"id": 10, "modId": 1, "fileName": "allowed.jar", "downloadUrl": "https://edge.forgecdn.net/files/0/10/allowed.jar"},
+				{"id": 20, "modId": 2, "fileName": "restricted.jar", "downloadUrl": null}
+			]}`))
+		case "/v1/mods":
+			_, _ = w.Write([]byte(`{"data": [
+				{"id": 2, "name": "Restricted", "allowModDistribution": false, "links": {"websiteUrl": "https://www.curseforge.com/minecraft/mc-mods/restricted"}}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	oldURL := cfApiURL
+	cfApiURL = srv.URL
+	viper.Set("cache.directory", t.TempDir())
+	t.Cleanup(func() {
+		cfApiURL = oldURL
+		viper.Set("cache.directory", "")
+	})
+
+	dir := t.TempDir()
+	newMod := func(name string, projectID int, fileID int) *core.Mod {
+		t.Helper()
+		path := filepath.Join(dir, name+core.MetaExtension)
+		contents := fmt.Sprintf("name = %q\nfilename = \"%s.jar\"\n[download]\nmode = \"metadata:curseforge\"\nhash-format = \"sha1\"\nhash = \"%x\"\n"+
+			"[update.curseforge]\nproject-id = %d\nfile-id = %d\n", name, name, name, projectID, fileID)
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mod, err := core.LoadMod(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &mod
+	}
+	mods := []*core.Mod{newMod("allowed", 1, 10), newMod("restricted", 2, 20)}
+
+	session, err := core.CreateDownloadSession(mods, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := core.ManualDownload{
+		Name:     "Restricted",
+		FileName: "restricted.jar",
+		URL:      "https://www.curseforge.com/minecraft/mc-mods/restricted/files/20",
+	}
+	manual := session.GetManualDownloads()
+	if len(manual) != 1 || manual[0] != expected {
+		t.Errorf("Expected only the restricted file to be a manual download, got %v", manual)
+	}
+}
+
+func TestDistributionDisabled(t *testing.T) {
+	allowed, disallowed := true, false
+	if (modInfo{}).distributionDisabled() {
+		t.Error("Expected distribution to be allowed when the API doesn't say")
+	}
+	if (modInfo{AllowModDistribution: &allowed}).distributionDisabled() {
+		t.Error("Expected distribution to be allowed")
+	}
+	if !(modInfo{AllowModDistribution: &disallowed}).distributionDisabled() {
+		t.Error("Expected distribution to be disabled")
+	}
+}
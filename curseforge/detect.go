@@ -2,138 +2,104 @@ package curseforge
 
 import (
 	"fmt"
-	"github.com/aviddiviner/go-murmur"
-	"github.com/0byte-coding/packwiz/core"
-	"github.com/spf13/cobra"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-)
 
-// TODO: make all of this less bad and hardcoded
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/0byte-coding/packwiz/curseforge/murmur2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
 
 // detectCmd represents the detect command
 var detectCmd = &cobra.Command{
-	Use:   "detect",
-	Short: "Detect .jar files in the mods folder (experimental)",
-	Args:  cobra.NoArgs,
+	Use:   "detect [folder]",
+	Short: "Replace .jar files in the mods folder with metadata files for the matching CurseForge projects",
+	Long: `Replace .jar files in the mods folder (or the given folder) with metadata files for the matching CurseForge projects.
+The CurseForge fingerprint of each file is looked up; when a file exactly matches a file on CurseForge, a metadata file
+for it is created and the file is removed. Files that can't be matched are listed and left alone.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Loading modpack...")
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
-		// Walk files in the mods folder
-		var hashes []uint32
-		modPaths := make(map[uint32]string)
-		err = filepath.Walk("mods", func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if info.IsDir() {
-				return nil
-			}
-			if !strings.HasSuffix(path, ".jar") && !strings.HasSuffix(path, ".litemod") {
-				// TODO: make this less bad
-				return nil
-			}
-			fmt.Println("Hashing " + path)
-			bytes, err := os.ReadFile(path)
+		var folder string
+		if len(args) > 0 {
+			folder = args[0]
+		} else {
+			modsFolder, err := core.GetProjectTypeFolder(core.ProjectTypeMod, "mods")
 			if err != nil {
-				return err
+				cmdshared.ExitWithError(err)
 			}
-			hash := getByteArrayHash(bytes)
-			hashes = append(hashes, hash)
-			modPaths[hash] = path
-			return nil
-		})
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			folder = filepath.Join(viper.GetString("meta-folder-base"), modsFolder)
 		}
-		fmt.Printf("Found %d files, submitting...\n", len(hashes))
 
-		res, err := cfDefaultClient.getFingerprintInfo(hashes)
+		modPaths, err := fingerprintLooseFiles(folder)
 		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		fmt.Printf("Successfully matched %d files\n", len(res.ExactFingerprints))
-		if len(res.PartialMatches) > 0 {
-			fmt.Println("The following fingerprints were partial and I don't know what to do!!!")
-			for _, v := range res.PartialMatches {
-				fmt.Printf("%s (%d)", modPaths[v], v)
-			}
+			cmdshared.ExitWithError(err)
 		}
-		if len(res.UnmatchedFingerprints) > 0 {
-			fmt.Printf("Failed to match the following %d files:\n", len(res.UnmatchedFingerprints))
-			for _, v := range res.UnmatchedFingerprints {
-				fmt.Printf("%s (%d)\n", modPaths[v], v)
-			}
-		}
-
-		fmt.Println("Retrieving metadata...")
-		ids := make([]uint32, len(res.ExactMatches))
-		for i, v := range res.ExactMatches {
-			ids[i] = v.ID
+		if len(modPaths) == 0 {
+			fmt.Printf("No .jar files found in %s\n", folder)
+			return
 		}
-		modInfos, err := cfDefaultClient.getModInfoMultiple(ids)
+		matched, err := matchFingerprints(modPaths, pack, &index)
 		if err != nil {
-			fmt.Printf("Failed to retrieve metadata: %v", err)
-			os.Exit(1)
-		}
-		modInfosMap := make(map[uint32]modInfo)
-		for _, v := range modInfos {
-			modInfosMap[v.ID] = v
+			cmdshared.ExitWithError(err)
 		}
 
-		fmt.Println("Creating metadata files...")
-		for _, v := range res.ExactMatches {
-			err = createModFile(modInfosMap[v.ID], v.File, &index, false)
+		var unmatched []string
+		for _, path := range slices.Sorted(maps.Keys(matched)) {
+			err = os.Remove(path)
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				cmdshared.ExitWithError(err)
 			}
-
-			path, ok := modPaths[v.File.Fingerprint]
-			if ok {
-				err = os.Remove(path)
-				if err != nil {
-					fmt.Println(err)
-					os.Exit(1)
+			fmt.Printf("Replaced %s with %s\n", path, matched[path])
+		}
+		for _, paths := range modPaths {
+			for _, path := range paths {
+				if _, ok := matched[path]; !ok {
+					unmatched = append(unmatched, path)
 				}
 			}
 		}
-		fmt.Println("Detection complete!")
+
+		if len(unmatched) > 0 {
+			slices.Sort(unmatched)
+			fmt.Printf("The following %d files couldn't be matched and were left alone:\n", len(unmatched))
+			for _, path := range unmatched {
+				fmt.Println(path)
+			}
+		}
 
 		err = index.Refresh()
 		if err != nil {
-			fmt.Println(err)
-			return
+			cmdshared.ExitWithError(err)
 		}
 		err = index.Write()
 		if err != nil {
-			fmt.Println(err)
-			return
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.UpdateIndexHash()
 		if err != nil {
-			fmt.Println(err)
-			return
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.Write()
 		if err != nil {
-			fmt.Println(err)
-			return
+			cmdshared.ExitWithError(err)
 		}
+		fmt.Println("Detection complete!")
 	},
 }
 
@@ -141,20 +107,117 @@ func init() {
 	curseforgeCmd.AddCommand(detectCmd)
 }
 
-func getByteArrayHash(bytes []byte) uint32 {
-	return murmur.MurmurHash2(computeNormalizedArray(bytes), 1)
+// matchFingerprints looks up files grouped by their CurseForge fingerprint, creating metadata files for the files that
+// exactly match a file on CurseForge. The names of the matched projects are returned, keyed by the path of the file.
+func matchFingerprints(modPaths map[uint32][]string, pack core.Pack, index *core.Index) (map[string]string, error) {
+	hashes := make([]uint32, 0, len(modPaths))
+	for hash := range modPaths {
+		hashes = append(hashes, hash)
+	}
+	slices.Sort(hashes)
+	fmt.Printf("Found %d files, submitting...\n", len(hashes))
+
+	res, err := cfDefaultClient.getFingerprintInfo(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only use the first match for each fingerprint
+	matches := res.ExactMatches[:0]
+	matchedHashes := make(map[uint32]bool)
+	for _, v := range res.ExactMatches {
+		if _, ok := modPaths[v.File.Fingerprint]; ok && !matchedHashes[v.File.Fingerprint] {
+			matchedHashes[v.File.Fingerprint] = true
+			matches = append(matches, v)
+		}
+	}
+	fmt.Printf("Successfully matched %d files\n", len(matches))
+
+	matched := make(map[string]string)
+	if len(matches) == 0 {
+		return matched, nil
+	}
+	fmt.Println("Retrieving metadata...")
+	ids := make([]uint32, len(matches))
+	for i, v := range matches {
+		ids[i] = v.ID
+	}
+	modInfos, err := cfDefaultClient.getModInfoMultiple(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve metadata: %w", err)
+	}
+	modInfosMap := make(map[uint32]modInfo)
+	for _, v := range modInfos {
+		modInfosMap[v.ID] = v
+	}
+
+	fmt.Println("Creating metadata files...")
+	for _, v := range matches {
+		info, ok := modInfosMap[v.ID]
+		if !ok {
+			fmt.Printf("Failed to retrieve metadata for project %d\n", v.ID)
+			continue
+		}
+		err = createModFile(info, v.File, index, false, getRequiredDependencyIDs(v.File, pack), false)
+		if err != nil {
+			fmt.Printf("Failed to create metadata file for %s: %v\n", info.Name, err)
+			continue
+		}
+		for _, path := range modPaths[v.File.Fingerprint] {
+			matched[path] = info.Name + " (" + v.File.FileName + ")"
+		}
+	}
+	return matched, nil
 }
 
-func computeNormalizedArray(bytes []byte) []byte {
-	var newArray []byte
-	for _, b := range bytes {
-		if !isWhitespaceCharacter(b) {
-			newArray = append(newArray, b)
+type cfFileMatcher struct{}
+
+func (cfFileMatcher) MatchFiles(paths []string, pack core.Pack, index *core.Index) (map[string]string, error) {
+	modPaths := make(map[uint32][]string)
+	for _, path := range paths {
+		hash, err := getFileFingerprint(path)
+		if err != nil {
+			return nil, err
 		}
+		modPaths[hash] = append(modPaths[hash], path)
 	}
-	return newArray
+	return matchFingerprints(modPaths, pack, index)
 }
 
-func isWhitespaceCharacter(b byte) bool {
-	return b == 9 || b == 10 || b == 13 || b == 32
+// fingerprintLooseFiles returns the paths of the .jar and .litemod files in the given folder (and its subfolders),
+// grouped by their CurseForge fingerprint
+func fingerprintLooseFiles(folder string) (map[uint32][]string, error) {
+	modPaths := make(map[uint32][]string)
+	err := filepath.WalkDir(folder, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !(strings.HasSuffix(path, ".jar") || strings.HasSuffix(path, ".litemod")) {
+			return nil
+		}
+		hash, err := getFileFingerprint(path)
+		if err != nil {
+			return err
+		}
+		modPaths[hash] = append(modPaths[hash], path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files in %s: %w", folder, err)
+	}
+	return modPaths, nil
+}
+
+// getFileFingerprint computes the CurseForge fingerprint (murmur2 hash, ignoring whitespace) of a file
+func getFileFingerprint(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	h := murmur2.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return 0, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return h.Sum32(), nil
 }
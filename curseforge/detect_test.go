@@ -0,0 +1,53 @@
+package curseforge
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFingerprintLooseFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.jar":            "some mod",
+		"sub/b.jar":        "some\tmod\n",
+		"c.litemod":        "another mod",
+		"a.pw.toml":        "name = \"A\"",
+		"notes.txt":        "not a mod",
+		"disabled.jar.old": "not a mod",
+	}
+	for name, contents := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modPaths, err := fingerprintLooseFiles(dir)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint files: %v", err)
+	}
+	if len(modPaths) != 2 {
+		t.Fatalf("Expected 2 distinct fingerprints, got %v", modPaths)
+	}
+
+	// Fingerprints ignore whitespace, so files differing only in whitespace are grouped together
+	hash, err := getFileFingerprint(filepath.Join(dir, "a.jar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := modPaths[hash]
+	slices.Sort(paths)
+	expected := []string{filepath.Join(dir, "a.jar"), filepath.Join(dir, "sub", "b.jar")}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("Expected %v to have the same fingerprint, got %v", expected, paths)
+	}
+
+	if _, err := fingerprintLooseFiles(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing folder")
+	}
+}
@@ -23,47 +23,52 @@ var exportCmd = &cobra.Command{
 		side := viper.GetString("curseforge.export.side")
 		if side != core.UniversalSide && side != core.ServerSide && side != core.ClientSide {
 			fmt.Printf("Invalid side %q, must be one of client, server, or both (default)\n", side)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitUsage)
 		}
 
 		fmt.Println("Loading modpack...")
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		overrideRules, err := cmdshared.GetOverrideRules(pack, side, "curseforge.export")
+		if err != nil {
+			cmdshared.ExitWithError(err)
 		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		// Do a refresh to ensure files are up to date
 		err = index.Refresh()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = index.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.UpdateIndexHash()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+
+		loaders := viper.GetStringSlice("curseforge.export.recommended-loader")
+		err = packinterop.CheckModLoaders(pack, loaders)
+		if err != nil {
+			fmt.Printf("Invalid --recommended-loader: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitUsage)
 		}
 
 		fmt.Println("Reading external files...")
 		mods, err := index.LoadAllMods()
 		if err != nil {
 			fmt.Printf("Error reading file: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		i := 0
 		// Filter mods by side
@@ -82,7 +87,7 @@ var exportCmd = &cobra.Command{
 			exportData, err = parseExportData(exportDataUnparsed)
 			if err != nil {
 				fmt.Printf("Failed to parse export metadata: %s\n", err.Error())
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 		}
 
@@ -94,7 +99,7 @@ var exportCmd = &cobra.Command{
 		expFile, err := os.Create(fileName)
 		if err != nil {
 			fmt.Printf("Failed to create zip: %s\n", err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		exp := zip.NewWriter(expFile)
 
@@ -102,7 +107,7 @@ var exportCmd = &cobra.Command{
 		_, err = exp.Create("overrides/")
 		if err != nil {
 			fmt.Printf("Failed to add overrides folder: %s\n", err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		cfFileRefs := make([]packinterop.AddonFileReference, 0, len(mods))
@@ -130,19 +135,21 @@ var exportCmd = &cobra.Command{
 			session, err := core.CreateDownloadSession(nonCfMods, []string{})
 			if err != nil {
 				fmt.Printf("Error retrieving external files: %v\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 
-			cmdshared.ListManualDownloads(session)
+			if err := cmdshared.ListManualDownloads(session); err != nil {
+				cmdshared.ExitWithError(err)
+			}
 
-			for dl := range session.StartDownloads() {
+			for dl := range session.StartDownloadsContext(cmd.Context(), cmdshared.DownloadProgressBar) {
 				_ = cmdshared.AddToZip(dl, exp, "overrides", &index)
 			}
 
 			err = session.SaveIndex()
 			if err != nil {
 				fmt.Printf("Error saving cache index: %v\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 		}
 
@@ -151,15 +158,15 @@ var exportCmd = &cobra.Command{
 			_ = exp.Close()
 			_ = expFile.Close()
 			fmt.Println("Error creating manifest: " + err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
-		err = packinterop.WriteManifestFromPack(pack, cfFileRefs, exportData.ProjectID, manifestFile)
+		err = packinterop.WriteManifestFromPack(pack, cfFileRefs, exportData.ProjectID, loaders, manifestFile)
 		if err != nil {
 			_ = exp.Close()
 			_ = expFile.Close()
 			fmt.Println("Error writing manifest: " + err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		err = createModlist(exp, mods)
@@ -167,20 +174,20 @@ var exportCmd = &cobra.Command{
 			_ = exp.Close()
 			_ = expFile.Close()
 			fmt.Println("Error creating mod list: " + err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
-		cmdshared.AddNonMetafileOverrides(&index, exp)
+		cmdshared.AddNonMetafileOverrides(&index, exp, overrideRules)
 
 		err = exp.Close()
 		if err != nil {
 			fmt.Println("Error writing export file: " + err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		err = expFile.Close()
 		if err != nil {
 			fmt.Println("Error writing export file: " + err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		fmt.Println("Modpack exported to " + fileName)
@@ -230,4 +237,7 @@ func init() {
 	_ = viper.BindPFlag("curseforge.export.side", exportCmd.Flags().Lookup("side"))
 	exportCmd.Flags().StringP("output", "o", "", "The file to export the modpack to")
 	_ = viper.BindPFlag("curseforge.export.output", exportCmd.Flags().Lookup("output"))
+	exportCmd.Flags().StringSlice("recommended-loader", nil, "The mod loaders to list in manifest.json (e.g. forge,neoforge), which must be in pack.toml; the first is marked as primary, and the others are added as secondary loaders (default the pack's loader)")
+	_ = viper.BindPFlag("curseforge.export.recommended-loader", exportCmd.Flags().Lookup("recommended-loader"))
+	cmdshared.AddOverrideFlags(exportCmd, "curseforge.export")
 }
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/curseforge/packinterop"
 	"io"
 	"os"
@@ -20,117 +21,23 @@ import (
 
 // importCmd represents the import command
 var importCmd = &cobra.Command{
-	Use:   "import [modpack path]",
-	Short: "Import a curseforge modpack from a downloaded pack zip or an installed metadata json file",
+	Use:   "import [modpack path or URL]",
+	Short: "Import a curseforge modpack from a pack zip (downloaded, or at a URL) or an installed metadata json file",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		inputFile := args[0]
-		var packImport packinterop.ImportPackMetadata
-
-		// TODO: refactor/extract file checking?
-		if strings.HasPrefix(inputFile, "http") {
-			// TODO: implement
-			fmt.Println("HTTP not supported (yet)")
-			os.Exit(1)
-		} else {
-			// Attempt to read from file
-			var f *os.File
-			inputFileStat, err := os.Stat(inputFile)
-			if err == nil && inputFileStat.IsDir() {
-				// Apparently os.Open doesn't fail when file given is a directory, only when it gets read
-				err = errors.New("cannot open directory")
-			}
-			if err == nil {
-				f, err = os.Open(inputFile)
-			}
-			if err != nil {
-				found := false
-				var errInstance error
-				var errManifest error
-				var errCurse error
-
-				// Look for other files/folders
-				if _, errInstance = os.Stat(filepath.Join(inputFile, "minecraftinstance.json")); errInstance == nil {
-					inputFile = filepath.Join(inputFile, "minecraftinstance.json")
-					found = true
-				} else if _, errManifest = os.Stat(filepath.Join(inputFile, "manifest.json")); errManifest == nil {
-					inputFile = filepath.Join(inputFile, "manifest.json")
-					found = true
-				} else if runtime.GOOS == "windows" {
-					var dir string
-					dir, errCurse = getCurseDir()
-					if errCurse == nil {
-						curseInstanceFile := filepath.Join(dir, "Minecraft", "Instances", inputFile, "minecraftinstance.json")
-						if _, errCurse = os.Stat(curseInstanceFile); errCurse == nil {
-							inputFile = curseInstanceFile
-							found = true
-						}
-					}
-				}
-
-				if found {
-					f, err = os.Open(inputFile)
-					if err != nil {
-						fmt.Printf("Error opening file: %s\n", err)
-						os.Exit(1)
-					}
-				} else {
-					fmt.Printf("Error opening file: %s\n", err)
-					fmt.Printf("Also attempted minecraftinstance.json: %s\n", errInstance)
-					fmt.Printf("Also attempted manifest.json: %s\n", errManifest)
-					if errCurse != nil {
-						fmt.Printf("Also attempted to load a Curse/Twitch modpack named \"%s\": %s\n", inputFile, errCurse)
-					}
-					os.Exit(1)
-				}
-			}
-			defer f.Close()
-
-			buf := bufio.NewReader(f)
-			header, err := buf.Peek(2)
+		if strings.HasPrefix(inputFile, "http://") || strings.HasPrefix(inputFile, "https://") {
+			fmt.Printf("Downloading %s...\n", inputFile)
+			var err error
+			inputFile, err = cmdshared.DownloadModpack(cmd.Context(), inputFile)
 			if err != nil {
-				fmt.Printf("Error reading file: %s\n", err)
-				os.Exit(1)
-			}
-
-			// Check if file is a zip
-			if string(header) == "PK" {
-				// Read the whole file (as bufio doesn't work for zips)
-				zipData, err := io.ReadAll(buf)
-				if err != nil {
-					fmt.Printf("Error reading file: %s\n", err)
-					os.Exit(1)
-				}
-				// Get zip size
-				stat, err := f.Stat()
-				if err != nil {
-					fmt.Printf("Error reading file: %s\n", err)
-					os.Exit(1)
-				}
-				zr, err := zip.NewReader(bytes.NewReader(zipData), stat.Size())
-				if err != nil {
-					fmt.Printf("Error parsing zip: %s\n", err)
-					os.Exit(1)
-				}
-
-				// Search the zip for minecraftinstance.json or manifest.json
-				var metaFile *zip.File
-				for _, v := range zr.File {
-					if v.Name == "minecraftinstance.json" || v.Name == "manifest.json" {
-						metaFile = v
-					}
-				}
-
-				if metaFile == nil {
-					fmt.Println("Can't find manifest.json or minecraftinstance.json, is this a valid pack?")
-					os.Exit(1)
-				}
-
-				packImport = packinterop.ReadMetadata(packinterop.GetZipPackSource(metaFile, zr))
-			} else {
-				packImport = packinterop.ReadMetadata(packinterop.GetDiskPackSource(buf, filepath.ToSlash(filepath.Base(inputFile)), filepath.Dir(inputFile)))
+				return fmt.Errorf("error downloading modpack: %w", err)
 			}
 		}
+		packImport, err := readImportPack(inputFile)
+		if err != nil {
+			return err
+		}
 
 		pack, err := core.LoadPack()
 		if err != nil {
@@ -143,13 +50,11 @@ var importCmd = &cobra.Command{
 				// Create file
 				err = os.WriteFile(indexFilePath, []byte{}, 0644)
 				if err != nil {
-					fmt.Printf("Error creating index file: %s\n", err)
-					os.Exit(1)
+					return fmt.Errorf("error creating index file: %w", err)
 				}
 				fmt.Println(indexFilePath + " created!")
 			} else if err != nil {
-				fmt.Printf("Error checking index file: %s\n", err)
-				os.Exit(1)
+				return fmt.Errorf("error checking index file: %w", err)
 			}
 
 			pack = core.Pack{
@@ -179,196 +84,352 @@ var importCmd = &cobra.Command{
 		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
 
-		modsList := packImport.Mods()
-		modIDs := make([]uint32, len(modsList))
-		for i, v := range modsList {
-			modIDs[i] = v.ProjectID
+		err = importPackFiles(packImport, &index)
+		if err != nil {
+			return err
 		}
 
-		fmt.Println("Querying Curse API for dependency info...")
-
-		modInfos, err := cfDefaultClient.getModInfoMultiple(modIDs)
+		err = index.Write()
 		if err != nil {
-			fmt.Printf("Failed to obtain project information: %s\n", err)
-			os.Exit(1)
+			return err
 		}
-
-		modInfosMap := make(map[uint32]modInfo)
-		for _, v := range modInfos {
-			modInfosMap[v.ID] = v
+		err = pack.UpdateIndexHash()
+		if err != nil {
+			return err
 		}
+		return pack.Write()
+	},
+}
 
-		// TODO: multithreading????
-
-		modFileInfosMap := make(map[uint32]modFileInfo)
-		referencedModPaths := make([]string, 0, len(modsList))
-		successes := 0
-		remainingFileIDs := make([]uint32, 0, len(modsList))
-
-		// 1st pass: query mod metadata for every CurseForge file
-		for _, v := range modsList {
-			modInfoValue, ok := modInfosMap[v.ProjectID]
-			if !ok {
-				fmt.Printf("Failed to obtain information for project/file IDs %d/%d\n", v.ProjectID, v.FileID)
-				continue
-			}
-
-			found := false
-			var fileInfo modFileInfo
-			for _, fileInfo = range modInfoValue.LatestFiles {
-				if fileInfo.ID == v.FileID {
+// readImportPack reads the modpack at the given path (a pack zip, an installed metadata json file, or a folder
+// containing one)
+func readImportPack(inputFile string) (packinterop.ImportPackMetadata, error) {
+	// TODO: refactor/extract file checking?
+	// Attempt to read from file
+	var f *os.File
+	inputFileStat, err := os.Stat(inputFile)
+	if err == nil && inputFileStat.IsDir() {
+		// Apparently os.Open doesn't fail when file given is a directory, only when it gets read
+		err = errors.New("cannot open directory")
+	}
+	if err == nil {
+		f, err = os.Open(inputFile)
+	}
+	if err != nil {
+		found := false
+		var errInstance error
+		var errManifest error
+		var errCurse error
+
+		// Look for other files/folders
+		if _, errInstance = os.Stat(filepath.Join(inputFile, "minecraftinstance.json")); errInstance == nil {
+			inputFile = filepath.Join(inputFile, "minecraftinstance.json")
+			found = true
+		} else if _, errManifest = os.Stat(filepath.Join(inputFile, "manifest.json")); errManifest == nil {
+			inputFile = filepath.Join(inputFile, "manifest.json")
+			found = true
+		} else if runtime.GOOS == "windows" {
+			var dir string
+			dir, errCurse = getCurseDir()
+			if errCurse == nil {
+				curseInstanceFile := filepath.Join(dir, "Minecraft", "Instances", inputFile, "minecraftinstance.json")
+				if _, errCurse = os.Stat(curseInstanceFile); errCurse == nil {
+					inputFile = curseInstanceFile
 					found = true
-					break
 				}
 			}
-			if found {
-				modFileInfosMap[v.FileID] = fileInfo
-			} else {
-				remainingFileIDs = append(remainingFileIDs, v.FileID)
-			}
 		}
 
-		// 2nd pass: query files that weren't in the previous results
-		fmt.Println("Querying Curse API for file info...")
-
-		modFileInfos, err := cfDefaultClient.getFileInfoMultiple(remainingFileIDs)
-		if err != nil {
-			fmt.Printf("Failed to obtain project file information: %s\n", err)
-			os.Exit(1)
+		if found {
+			f, err = os.Open(inputFile)
+			if err != nil {
+				return nil, fmt.Errorf("error opening file: %w", err)
+			}
+		} else {
+			msg := fmt.Sprintf("error opening file: %s\nAlso attempted minecraftinstance.json: %s\nAlso attempted manifest.json: %s", err, errInstance, errManifest)
+			if errCurse != nil {
+				msg += fmt.Sprintf("\nAlso attempted to load a Curse/Twitch modpack named \"%s\": %s", inputFile, errCurse)
+			}
+			return nil, errors.New(msg)
 		}
-
-		for _, v := range modFileInfos {
-			modFileInfosMap[v.ID] = v
+	}
+	defer f.Close()
+
+	buf := bufio.NewReader(f)
+	header, err := buf.Peek(2)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	// Check if file is a zip
+	if string(header) != "PK" {
+		return packinterop.ReadMetadata(packinterop.GetDiskPackSource(buf, filepath.ToSlash(filepath.Base(inputFile)), filepath.Dir(inputFile)))
+	}
+
+	// Read the whole file (as bufio doesn't work for zips)
+	zipData, err := io.ReadAll(buf)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	// Get zip size
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(zipData), stat.Size())
+	if err != nil {
+		return nil, fmt.Errorf("error parsing zip: %w", err)
+	}
+
+	// Search the zip for minecraftinstance.json or manifest.json
+	var metaFile *zip.File
+	for _, v := range zr.File {
+		if v.Name == "minecraftinstance.json" || v.Name == "manifest.json" {
+			metaFile = v
 		}
+	}
 
-		// 3rd pass: create mod files for every file
-		for _, v := range modsList {
-			modInfoValue, ok := modInfosMap[v.ProjectID]
-			if !ok {
-				fmt.Printf("Failed to obtain project information for project/file IDs %d/%d\n", v.ProjectID, v.FileID)
-				continue
-			}
+	if metaFile == nil {
+		return nil, cmdshared.WithExitCode(errors.New("can't find manifest.json or minecraftinstance.json, is this a valid pack?"), cmdshared.ExitNotFound)
+	}
 
-			modFileInfoValue, ok := modFileInfosMap[v.FileID]
-			if !ok {
-				fmt.Printf("Failed to obtain project file information for project/file IDs %d/%d\n", v.ProjectID, v.FileID)
-				continue
-			}
+	return packinterop.ReadMetadata(packinterop.GetZipPackSource(metaFile, zr))
+}
 
-			err = createModFile(modInfoValue, modFileInfoValue, &index, v.OptionalDisabled)
-			if err != nil {
-				fmt.Printf("Failed to save project \"%s\": %s\n", modInfoValue.Name, err)
-				os.Exit(1)
-			}
+// importPackFiles creates metadata files for the mods in an imported modpack, and copies its override files
+func importPackFiles(packImport packinterop.ImportPackMetadata, index *core.Index) error {
+	modsList := packImport.Mods()
+	modIDs := make([]uint32, len(modsList))
+	for i, v := range modsList {
+		modIDs[i] = v.ProjectID
+	}
+
+	fmt.Println("Querying Curse API for dependency info...")
+
+	modInfos, err := cfDefaultClient.getModInfoMultiple(modIDs)
+	if err != nil {
+		return fmt.Errorf("failed to obtain project information: %w", err)
+	}
+
+	modInfosMap := make(map[uint32]modInfo)
+	for _, v := range modInfos {
+		modInfosMap[v.ID] = v
+	}
+
+	// TODO: multithreading????
+
+	modFileInfosMap := make(map[uint32]modFileInfo)
+	referencedModPaths := make([]string, 0, len(modsList))
+	successes := 0
+	remainingFileIDs := make([]uint32, 0, len(modsList))
+
+	// 1st pass: query mod metadata for every CurseForge file
+	for _, v := range modsList {
+		modInfoValue, ok := modInfosMap[v.ProjectID]
+		if !ok {
+			fmt.Printf("Failed to obtain information for project/file IDs %d/%d\n", v.ProjectID, v.FileID)
+			continue
+		}
 
-			modFilePath := getPathForFile(modInfoValue.GameID, modInfoValue.ClassID, modInfoValue.PrimaryCategoryID, modInfoValue.Slug)
-			ref, err := filepath.Abs(filepath.Join(filepath.Dir(modFilePath), modFileInfoValue.FileName))
-			if err == nil {
-				referencedModPaths = append(referencedModPaths, ref)
+		found := false
+		var fileInfo modFileInfo
+		for _, fileInfo = range modInfoValue.LatestFiles {
+			if fileInfo.ID == v.FileID {
+				found = true
+				break
 			}
+		}
+		if found {
+			modFileInfosMap[v.FileID] = fileInfo
+		} else {
+			remainingFileIDs = append(remainingFileIDs, v.FileID)
+		}
+	}
+
+	// 2nd pass: query files that weren't in the previous results
+	fmt.Println("Querying Curse API for file info...")
+
+	modFileInfos, err := cfDefaultClient.getFileInfoMultiple(remainingFileIDs)
+	if err != nil {
+		return fmt.Errorf("failed to obtain project file information: %w", err)
+	}
+
+	for _, v := range modFileInfos {
+		modFileInfosMap[v.ID] = v
+	}
+
+	// 3rd pass: create mod files for every file
+	for _, v := range modsList {
+		modInfoValue, ok := modInfosMap[v.ProjectID]
+		if !ok {
+			fmt.Printf("Failed to obtain project information for project/file IDs %d/%d\n", v.ProjectID, v.FileID)
+			continue
+		}
 
-			fmt.Printf("Imported dependency \"%s\" successfully!\n", modInfoValue.Name)
-			successes++
+		modFileInfoValue, ok := modFileInfosMap[v.FileID]
+		if !ok {
+			fmt.Printf("Failed to obtain project file information for project/file IDs %d/%d\n", v.ProjectID, v.FileID)
+			continue
 		}
 
-		fmt.Printf("Successfully imported %d/%d dependencies!\n", successes, len(modsList))
+		err = createModFile(modInfoValue, modFileInfoValue, index, v.OptionalDisabled, nil, false)
+		if err != nil {
+			return fmt.Errorf("failed to save project \"%s\": %w", modInfoValue.Name, err)
+		}
 
-		fmt.Println("Reading override files...")
-		filesList, err := packImport.GetFiles()
+		modFilePath, err := getPathForFile(modInfoValue.GameID, modInfoValue.ClassID, modInfoValue.PrimaryCategoryID, modInfoValue.Slug)
 		if err != nil {
-			fmt.Printf("Failed to read override files: %s\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to save project \"%s\": %w", modInfoValue.Name, err)
+		}
+		ref, err := filepath.Abs(filepath.Join(filepath.Dir(modFilePath), modFileInfoValue.FileName))
+		if err == nil {
+			referencedModPaths = append(referencedModPaths, ref)
 		}
 
-		successes = 0
-		for _, v := range filesList {
-			filePath := index.ResolveIndexPath(v.Name())
-			filePathAbs, err := filepath.Abs(filePath)
-			if err == nil {
-				found := false
-				for _, v := range referencedModPaths {
-					if v == filePathAbs {
-						found = true
-						break
-					}
-				}
-				if found {
-					fmt.Printf("Ignored file \"%s\" (referenced by metadata)\n", filePath)
-					successes++
-					continue
-				}
-				if v.Name() == "manifest.json" || v.Name() == "minecraftinstance.json" || v.Name() == ".curseclient" {
-					fmt.Printf("Ignored file \"%s\"\n", v.Name())
-					successes++
-					continue
-				}
-			}
+		fmt.Printf("Imported dependency \"%s\" successfully!\n", modInfoValue.Name)
+		successes++
+	}
 
-			f, err := os.Create(filePath)
-			if err != nil {
-				// Attempt to create the containing directory
-				err2 := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
-				if err2 == nil {
-					f, err = os.Create(filePath)
-				}
-				if err != nil {
-					fmt.Printf("Failed to write file \"%s\": %s\n", filePath, err)
-					if err2 != nil {
-						fmt.Printf("Failed to create directories: %s\n", err)
-					}
-					continue
+	fmt.Printf("Successfully imported %d/%d dependencies!\n", successes, len(modsList))
+
+	fmt.Println("Reading override files...")
+	filesList, err := packImport.GetFiles()
+	if err != nil {
+		return fmt.Errorf("failed to read override files: %w", err)
+	}
+
+	successes = 0
+	for _, v := range filesList {
+		filePath := index.ResolveIndexPath(v.Name())
+		filePathAbs, err := filepath.Abs(filePath)
+		if err == nil {
+			found := false
+			for _, v := range referencedModPaths {
+				if v == filePathAbs {
+					found = true
+					break
 				}
 			}
-			src, err := v.Open()
-			if err != nil {
-				fmt.Printf("Failed to read file \"%s\": %s\n", filePath, err)
-				f.Close()
+			if found {
+				fmt.Printf("Ignored file \"%s\" (referenced by metadata)\n", filePath)
+				successes++
 				continue
 			}
-			_, err = io.Copy(f, src)
-			if err != nil {
-				fmt.Printf("Failed to copy file \"%s\": %s\n", filePath, err)
-				f.Close()
-				src.Close()
+			if v.Name() == "manifest.json" || v.Name() == "minecraftinstance.json" || v.Name() == ".curseclient" {
+				fmt.Printf("Ignored file \"%s\"\n", v.Name())
+				successes++
 				continue
 			}
-
-			fmt.Printf("Copied file \"%s\" successfully!\n", filePath)
-			f.Close()
-			src.Close()
-			successes++
 		}
-		if len(filesList) > 0 {
-			fmt.Printf("Successfully copied %d/%d files!\n", successes, len(filesList))
-			err = index.Refresh()
+
+		f, err := os.Create(filePath)
+		if err != nil {
+			// Attempt to create the containing directory
+			err2 := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+			if err2 == nil {
+				f, err = os.Create(filePath)
+			}
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				fmt.Printf("Failed to write file \"%s\": %s\n", filePath, err)
+				if err2 != nil {
+					fmt.Printf("Failed to create directories: %s\n", err)
+				}
+				continue
 			}
-		} else {
-			fmt.Println("No files copied!")
 		}
-
-		err = index.Write()
+		src, err := v.Open()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			fmt.Printf("Failed to read file \"%s\": %s\n", filePath, err)
+			f.Close()
+			continue
 		}
-		err = pack.UpdateIndexHash()
+		_, err = io.Copy(f, src)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			fmt.Printf("Failed to copy file \"%s\": %s\n", filePath, err)
+			f.Close()
+			src.Close()
+			continue
 		}
-		err = pack.Write()
+
+		fmt.Printf("Copied file \"%s\" successfully!\n", filePath)
+		f.Close()
+		src.Close()
+		successes++
+	}
+	if len(filesList) > 0 {
+		fmt.Printf("Successfully copied %d/%d files!\n", successes, len(filesList))
+		err = index.Refresh()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
-	},
+	} else {
+		fmt.Println("No files copied!")
+	}
+	return nil
+}
+
+// cfPackImporter allows CurseForge modpacks to be used as the source of a new pack
+type cfPackImporter struct{}
+
+// cfImportedPack is a CurseForge modpack read by cfPackImporter
+type cfImportedPack struct {
+	packinterop.ImportPackMetadata
+}
+
+func (p cfImportedPack) Import(index *core.Index) error {
+	return importPackFiles(p.ImportPackMetadata, index)
+}
+
+func (cfPackImporter) ReadPack(path string) (core.ImportedPack, bool, error) {
+	ok, err := isCursePack(path)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	packImport, err := readImportPack(path)
+	if err != nil {
+		return nil, true, err
+	}
+	return cfImportedPack{packImport}, true, nil
+}
+
+// isCursePack returns true if the given path is a CurseForge modpack zip, a manifest.json or minecraftinstance.json
+// file, or a folder containing one of these files
+func isCursePack(path string) (bool, error) {
+	isMetaFile := func(name string) bool {
+		return name == "manifest.json" || name == "minecraftinstance.json"
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if stat.IsDir() {
+		for _, name := range []string{"manifest.json", "minecraftinstance.json"} {
+			if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if isMetaFile(filepath.Base(path)) {
+		return true, nil
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		// Not a zip file
+		return false, nil
+	}
+	defer zr.Close()
+	for _, v := range zr.File {
+		if isMetaFile(v.Name) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func init() {
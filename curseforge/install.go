@@ -3,8 +3,8 @@ package curseforge
 import (
 	"errors"
 	"fmt"
-	"os"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/0byte-coding/packwiz/cmdshared"
@@ -32,23 +32,38 @@ var installCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		pack = cmdshared.OverrideGameVersion(pack, gameVersionFlag)
+		if err := core.ValidateSide(sideFlag); err != nil {
+			cmdshared.ExitWithError(err)
 		}
 		mcVersions, err := pack.GetSupportedMCVersions()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
-		primaryMCVersion, err := pack.GetMCVersion()
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+
+		if fromFileFlag != "" {
+			if len(args) != 0 || addonIDFlag != 0 || fileIDFlag != 0 {
+				fmt.Println("--from-file cannot be used with a separately specified project")
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
+			entries, err := cmdshared.ReadProjectList(fromFileFlag)
+			if err != nil {
+				fmt.Printf("Failed to read project list: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+			failed := cmdshared.AddFromList(entries, func(entry cmdshared.ListEntry) error {
+				return installListEntry(entry, pack, &index)
+			})
+			if failed > 0 {
+				cmdshared.Exit(cmdshared.ExitGeneric)
+			}
+			return
 		}
 
 		game := gameFlag
@@ -66,13 +81,18 @@ var installCmd = &cobra.Command{
 
 		if (len(args) == 0 || len(args[0]) == 0) && modID == 0 {
 			fmt.Println("You must specify a project; with the ID flags, or by passing a URL, slug or search term directly.")
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+		if modID == 0 && len(args) == 1 {
+			if id, err := strconv.ParseUint(args[0], 10, 32); err == nil {
+				modID = uint32(id)
+			}
 		}
 		if modID == 0 && len(args) == 1 {
 			parsedGame, parsedCategory, parsedSlug, parsedFileID, err := parseSlugOrUrl(args[0])
 			if err != nil {
 				fmt.Printf("Failed to parse URL: %v\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 
 			if parsedGame != "" {
@@ -109,14 +129,14 @@ var installCmd = &cobra.Command{
 
 		if modID == 0 {
 			fmt.Println("No projects found!")
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitNotFound)
 		}
 
 		if !modInfoObtained {
 			modInfoData, err = cfDefaultClient.getModInfo(modID)
 			if err != nil {
 				fmt.Printf("Failed to get project info: %v\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 		}
 
@@ -124,144 +144,331 @@ var installCmd = &cobra.Command{
 		fileInfoData, err = getLatestFile(modInfoData, mcVersions, fileID, pack.GetCompatibleLoaders())
 		if err != nil {
 			fmt.Printf("Failed to get file for project: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
-		if len(fileInfoData.Dependencies) > 0 {
-			isQuilt := slices.Contains(pack.GetCompatibleLoaders(), "quilt")
+		err = installFile(modInfoData, fileInfoData, pack, &index)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
 
-			var depsInstallable []installableDep
-			var depIDPendingQueue []uint32
-			for _, dep := range fileInfoData.Dependencies {
-				if dep.Type == dependencyTypeRequired {
-					depIDPendingQueue = append(depIDPendingQueue, mapDepOverride(dep.ModID, isQuilt, primaryMCVersion))
+		err = index.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.UpdateIndexHash()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		fmt.Printf("Project \"%s\" successfully added! (%s)\n", modInfoData.Name, fileInfoData.FileName)
+	},
+}
+
+// getInstalledProjectIDs returns the IDs of the CurseForge projects in the pack
+func getInstalledProjectIDs(index *core.Index) []uint32 {
+	var installedIDList []uint32
+	// Get modids of all mods
+	mods, err := index.LoadAllMods()
+	if err != nil {
+		fmt.Printf("Failed to determine existing projects: %v\n", err)
+		return nil
+	}
+	for _, mod := range mods {
+		data, ok := mod.GetParsedUpdateData("curseforge")
+		if ok {
+			updateData, ok := data.(cfUpdateData)
+			if ok {
+				if updateData.ProjectID > 0 {
+					installedIDList = append(installedIDList, updateData.ProjectID)
 				}
 			}
+		}
+	}
+	return installedIDList
+}
 
-			if len(depIDPendingQueue) > 0 {
-				fmt.Println("Finding dependencies...")
-
-				cycles := 0
-				var installedIDList []uint32
-				for len(depIDPendingQueue) > 0 && cycles < maxCycles {
-					if installedIDList == nil {
-						// Get modids of all mods
-						mods, err := index.LoadAllMods()
-						if err != nil {
-							fmt.Printf("Failed to determine existing projects: %v\n", err)
-						} else {
-							for _, mod := range mods {
-								data, ok := mod.GetParsedUpdateData("curseforge")
-								if ok {
-									updateData, ok := data.(cfUpdateData)
-									if ok {
-										if updateData.ProjectID > 0 {
-											installedIDList = append(installedIDList, updateData.ProjectID)
-										}
-									}
-								}
-							}
-						}
-					}
-
-					// Remove installed IDs from dep queue
-					i := 0
-					for _, id := range depIDPendingQueue {
-						contains := slices.Contains(installedIDList, id)
-						for _, data := range depsInstallable {
-							if id == data.ID {
-								contains = true
-								break
-							}
-						}
-						if !contains {
-							depIDPendingQueue[i] = id
-							i++
-						}
-					}
-					depIDPendingQueue = depIDPendingQueue[:i]
+// installFile adds a file of a project to the index, along with its required dependencies if the user accepts them (or
+// --dependencies is set). Optional and embedded dependencies are listed, but not added.
+func installFile(modInfoData modInfo, fileInfoData modFileInfo, pack core.Pack, index *core.Index) error {
+	installedIDList := getInstalledProjectIDs(index)
+	if hasDependencyType(fileInfoData, dependencyTypeRequired) {
+		fmt.Println("Finding dependencies...")
+		depsInstallable, err := resolveDependencies(modInfoData.ID, fileInfoData, pack, installedIDList)
+		if err != nil {
+			return err
+		}
 
-					if len(depIDPendingQueue) == 0 {
-						break
-					}
+		if len(depsInstallable) > 0 {
+			fmt.Println("Dependencies found:")
+			for _, v := range depsInstallable {
+				fmt.Println(v.Name)
+			}
 
-					depInfoData, err := cfDefaultClient.getModInfoMultiple(depIDPendingQueue)
+			addDeps := dependenciesFlag
+			if !addDeps {
+				addDeps, err = cmdshared.PromptYesNo("Would you like to add them? [Y/n]: ")
+				if err != nil {
+					return err
+				}
+			}
+			if addDeps {
+				for _, v := range depsInstallable {
+					err := createModFile(v.modInfo, v.fileInfo, index, false, getRequiredDependencyIDs(v.fileInfo, pack), true)
 					if err != nil {
-						fmt.Printf("Error retrieving dependency data: %s\n", err.Error())
-					}
-					depIDPendingQueue = depIDPendingQueue[:0]
-
-					for _, currData := range depInfoData {
-						depFileInfo, err := getLatestFile(currData, mcVersions, 0, pack.GetCompatibleLoaders())
-						if err != nil {
-							fmt.Printf("Error retrieving dependency data: %s\n", err.Error())
-							continue
-						}
-
-						for _, dep := range depFileInfo.Dependencies {
-							if dep.Type == dependencyTypeRequired {
-								depIDPendingQueue = append(depIDPendingQueue, mapDepOverride(dep.ModID, isQuilt, primaryMCVersion))
-							}
-						}
-
-						depsInstallable = append(depsInstallable, installableDep{
-							currData, depFileInfo,
-						})
+						return err
 					}
-
-					cycles++
+					fmt.Printf("Dependency \"%s\" successfully added! (%s)\n", v.modInfo.Name, v.fileInfo.FileName)
 				}
-				if cycles >= maxCycles {
-					fmt.Println("Dependencies recurse too deeply! Try increasing maxCycles.")
-					os.Exit(1)
+			}
+		} else {
+			fmt.Println("All dependencies are already added!")
+		}
+	}
+
+	err := listOtherDependencies(modInfoData.ID, fileInfoData, installedIDList)
+	if err != nil {
+		return err
+	}
+
+	return createModFile(modInfoData, fileInfoData, index, false, getRequiredDependencyIDs(fileInfoData, pack), false)
+}
+
+// hasDependencyType returns true if the file has dependencies of the given type
+func hasDependencyType(fileInfoData modFileInfo, depType dependencyType) bool {
+	for _, dep := range fileInfoData.Dependencies {
+		if dep.Type == depType {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDependencies finds the latest compatible files of the required dependencies of a file of the project with ID
+// rootID, and of their required dependencies in turn. Projects that are already installed, or have already been
+// found, are skipped, so that cycles of dependencies (including those back to the project being added) end.
+func resolveDependencies(rootID uint32, fileInfoData modFileInfo, pack core.Pack, installedIDList []uint32) ([]installableDep, error) {
+	mcVersions, err := pack.GetSupportedMCVersions()
+	if err != nil {
+		return nil, err
+	}
+	primaryMCVersion, err := pack.GetMCVersion()
+	if err != nil {
+		return nil, err
+	}
+	isQuilt := slices.Contains(pack.GetCompatibleLoaders(), "quilt")
+
+	visited := map[uint32]bool{rootID: true}
+	for _, id := range installedIDList {
+		visited[id] = true
+	}
+	var depsInstallable []installableDep
+	var depIDPendingQueue []uint32
+	queueRequired := func(fileInfoData modFileInfo) {
+		for _, dep := range fileInfoData.Dependencies {
+			if dep.Type == dependencyTypeRequired {
+				id := mapDepOverride(dep.ModID, isQuilt, primaryMCVersion)
+				if !visited[id] {
+					visited[id] = true
+					depIDPendingQueue = append(depIDPendingQueue, id)
 				}
+			}
+		}
+	}
+	queueRequired(fileInfoData)
 
-				if len(depsInstallable) > 0 {
-					fmt.Println("Dependencies found:")
-					for _, v := range depsInstallable {
-						fmt.Println(v.Name)
-					}
+	cycles := 0
+	for len(depIDPendingQueue) > 0 && cycles < maxCycles {
+		depInfoData, err := cfDefaultClient.getModInfoMultiple(depIDPendingQueue)
+		if err != nil {
+			fmt.Printf("Error retrieving dependency data: %s\n", err.Error())
+		}
+		depIDPendingQueue = depIDPendingQueue[:0]
 
-					if cmdshared.PromptYesNo("Would you like to add them? [Y/n]: ") {
-						for _, v := range depsInstallable {
-							err = createModFile(v.modInfo, v.fileInfo, &index, false)
-							if err != nil {
-								fmt.Println(err)
-								os.Exit(1)
-							}
-							fmt.Printf("Dependency \"%s\" successfully added! (%s)\n", v.modInfo.Name, v.fileInfo.FileName)
-						}
-					}
+		for _, currData := range depInfoData {
+			depFileInfo, err := getLatestFile(currData, mcVersions, 0, pack.GetCompatibleLoaders())
+			if err != nil {
+				fmt.Printf("Error retrieving dependency data: %s\n", err.Error())
+				continue
+			}
+			queueRequired(depFileInfo)
+			depsInstallable = append(depsInstallable, installableDep{
+				currData, depFileInfo,
+			})
+		}
+
+		cycles++
+	}
+	if len(depIDPendingQueue) > 0 {
+		return nil, errors.New("dependencies recurse too deeply, try increasing maxCycles")
+	}
+	return depsInstallable, nil
+}
+
+// listOtherDependencies prints the optional and embedded dependencies of a file of the project with ID rootID, which
+// aren't added; projects that are already installed aren't listed
+func listOtherDependencies(rootID uint32, fileInfoData modFileInfo, installedIDList []uint32) error {
+	var ids []uint32
+	for _, dep := range fileInfoData.Dependencies {
+		if (dep.Type == dependencyTypeOptional || dep.Type == dependencyTypeEmbedded) && dep.ModID != rootID &&
+			!slices.Contains(installedIDList, dep.ModID) && !slices.Contains(ids, dep.ModID) {
+			ids = append(ids, dep.ModID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	projects, err := cfDefaultClient.getModInfoMultiple(ids)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve optional dependency projects: %w", err)
+	}
+	names := make(map[uint32]string, len(projects))
+	for _, v := range projects {
+		names[v.ID] = v.Name
+	}
+
+	for _, depType := range []dependencyType{dependencyTypeOptional, dependencyTypeEmbedded} {
+		printed := false
+		for _, dep := range fileInfoData.Dependencies {
+			if dep.Type != depType || !slices.Contains(ids, dep.ModID) {
+				continue
+			}
+			if !printed {
+				if depType == dependencyTypeOptional {
+					fmt.Println("Optional dependencies (not added):")
 				} else {
-					fmt.Println("All dependencies are already added!")
+					fmt.Println("Embedded dependencies (included in the file, not added):")
 				}
+				printed = true
+			}
+			name, ok := names[dep.ModID]
+			if !ok {
+				name = "Unknown project"
 			}
+			fmt.Printf("%s (ID %d)\n", name, dep.ModID)
 		}
+	}
+	return nil
+}
+
+// installListEntry adds a project from a project list, given by slug, project ID or URL (but not a search term, as
+// there is no one to pick from the results), optionally with the file ID to add
+func installListEntry(entry cmdshared.ListEntry, pack core.Pack, index *core.Index) error {
+	mcVersions, err := pack.GetSupportedMCVersions()
+	if err != nil {
+		return err
+	}
 
-		err = createModFile(modInfoData, fileInfoData, &index, false)
+	var modID, fileID uint32
+	category := categoryFlag
+	var slug string
+	if id, err := strconv.ParseUint(entry.Project, 10, 32); err == nil {
+		modID = uint32(id)
+	} else {
+		parsedGame, parsedCategory, parsedSlug, parsedFileID, err := parseSlugOrUrl(entry.Project)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return fmt.Errorf("failed to parse URL: %w", err)
 		}
-
-		err = index.Write()
+		if parsedSlug == "" {
+			return errors.New("not a CurseForge slug, project ID or URL")
+		}
+		if parsedGame != "" && parsedGame != "minecraft" {
+			return fmt.Errorf("only Minecraft projects can be added from a list, not %s projects", parsedGame)
+		}
+		if parsedCategory != "" {
+			category = parsedCategory
+		}
+		slug = parsedSlug
+		fileID = parsedFileID
+	}
+	if entry.Version != "" {
+		id, err := strconv.ParseUint(entry.Version, 10, 32)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return fmt.Errorf("invalid file ID %s: versions of CurseForge projects are given by file ID", entry.Version)
 		}
-		err = pack.UpdateIndexHash()
+		fileID = uint32(id)
+	}
+
+	var modInfoData modInfo
+	if modID != 0 {
+		modInfoData, err = cfDefaultClient.getModInfo(modID)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return fmt.Errorf("failed to get project info: %w", err)
 		}
-		err = pack.Write()
+	} else {
+		modInfoData, err = lookupSlug(slug, category, pack)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return err
 		}
+	}
+	if slices.Contains(getInstalledProjectIDs(index), modInfoData.ID) {
+		return cmdshared.ErrAlreadyAdded
+	}
 
-		fmt.Printf("Project \"%s\" successfully added! (%s)\n", modInfoData.Name, fileInfoData.FileName)
-	},
+	fileInfoData, err := getLatestFile(modInfoData, mcVersions, fileID, pack.GetCompatibleLoaders())
+	if err != nil {
+		return fmt.Errorf("failed to get file for project: %w", err)
+	}
+	err = installFile(modInfoData, fileInfoData, pack, index)
+	if err != nil {
+		return err
+	}
+
+	err = index.Write()
+	if err != nil {
+		return err
+	}
+	err = pack.UpdateIndexHash()
+	if err != nil {
+		return err
+	}
+	err = pack.Write()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Project \"%s\" successfully added! (%s)\n", modInfoData.Name, fileInfoData.FileName)
+	return nil
+}
+
+// lookupSlug finds the Minecraft project with the given slug, preferring mods if projects in several categories have it
+func lookupSlug(slug string, category string, pack core.Pack) (modInfo, error) {
+	var classID uint32
+	if category == "mc-mods" {
+		classID = 6
+	}
+	results, err := cfDefaultClient.getSearch("", slug, 432, classID, 0, "", getSearchLoaderType(pack))
+	if err != nil {
+		return modInfo{}, fmt.Errorf("failed to look up slug: %w", err)
+	}
+	if len(results) == 0 {
+		return modInfo{}, fmt.Errorf("no project found with the slug %s", slug)
+	}
+	return results[preferredSlugResult(results)], nil
+}
+
+// preferredSlugResult returns the index of the project to choose by default when several projects in different
+// categories have the same slug, preferring mods
+func preferredSlugResult(results []modInfo) int {
+	for i, v := range results {
+		if v.ClassID == 6 {
+			return i
+		}
+	}
+	return 0
+}
+
+// slugResultLabel describes a project found by its slug, including its type so projects with the same slug can be told
+// apart
+func slugResultLabel(v modInfo) string {
+	projectType := fmt.Sprintf("class %d", v.ClassID)
+	if folder, ok := defaultFolders[v.GameID][v.ClassID]; ok {
+		projectType = folder.projectType
+	}
+	return fmt.Sprintf("%s [%s, ID %d] (%s)", v.Name, projectType, v.ID, v.Summary)
 }
 
 // Used to implement interface for fuzzy matching
@@ -293,17 +500,17 @@ func searchCurseforgeInternal(searchTerm string, isSlug bool, game string, categ
 		games, err := cfDefaultClient.getGames()
 		if err != nil {
 			fmt.Printf("Failed to lookup game %s: %v\n", game, err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		for _, v := range games {
 			if v.Slug == game {
 				if v.Status != gameStatusLive {
 					fmt.Printf("Failed to lookup game %s: selected game is not live!\n", game)
-					os.Exit(1)
+					cmdshared.Exit(cmdshared.ExitGeneric)
 				}
 				if v.APIStatus != gameApiStatusPublic {
 					fmt.Printf("Failed to lookup game %s: selected game does not have a public API!\n", game)
-					os.Exit(1)
+					cmdshared.Exit(cmdshared.ExitGeneric)
 				}
 				gameID = v.ID
 				break
@@ -311,14 +518,14 @@ func searchCurseforgeInternal(searchTerm string, isSlug bool, game string, categ
 		}
 		if gameID == 0 {
 			fmt.Printf("Failed to lookup: game %s could not be found!\n", game)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitNotFound)
 		}
 	}
 	if categoryID == 0 && classID == 0 && category != "" {
 		categories, err := cfDefaultClient.getCategories(gameID)
 		if err != nil {
 			fmt.Printf("Failed to lookup categories: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		for _, v := range categories {
 			if v.Slug == category {
@@ -333,7 +540,7 @@ func searchCurseforgeInternal(searchTerm string, isSlug bool, game string, categ
 		}
 		if categoryID == 0 && classID == 0 {
 			fmt.Printf("Failed to lookup: category %s could not be found!\n", category)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitNotFound)
 		}
 	}
 
@@ -351,14 +558,22 @@ func searchCurseforgeInternal(searchTerm string, isSlug bool, game string, categ
 	results, err := cfDefaultClient.getSearch(search, slug, gameID, classID, categoryID, filterGameVersion, searchLoaderType)
 	if err != nil {
 		fmt.Printf("Failed to search for project: %v\n", err)
-		os.Exit(1)
+		cmdshared.Exit(cmdshared.ExitCode(err))
 	}
 	if len(results) == 0 {
 		fmt.Println("No projects found!")
-		os.Exit(1)
+		cmdshared.Exit(cmdshared.ExitNotFound)
 		return false, modInfo{}
 	} else if len(results) == 1 {
 		return false, results[0]
+	} else if isSlug {
+		// Several types of project can have the same slug
+		def := preferredSlugResult(results)
+		if viper.GetBool("non-interactive") {
+			return false, results[def]
+		}
+		fmt.Printf("Several projects have the slug %s:\n", searchTerm)
+		return chooseProject(results, slugResultLabel, def)
 	} else {
 		// Fuzzy search on results list
 		fuzzySearchResults := fuzzy.FindFrom(searchTerm, modResultsList(results))
@@ -370,47 +585,59 @@ func searchCurseforgeInternal(searchTerm string, isSlug bool, game string, categ
 			return false, results[0]
 		}
 
-		menu := wmenu.NewMenu("Choose a number:")
-
-		menu.Option("Cancel", nil, false, nil)
-		if len(fuzzySearchResults) == 0 {
-			for i, v := range results {
-				menu.Option(v.Name+" ("+v.Summary+")", v, i == 0, nil)
-			}
-		} else {
+		options := results
+		if len(fuzzySearchResults) > 0 {
+			options = make([]modInfo, len(fuzzySearchResults))
 			for i, v := range fuzzySearchResults {
-				menu.Option(results[v.Index].Name+" ("+results[v.Index].Summary+")", results[v.Index], i == 0, nil)
+				options[i] = results[v.Index]
 			}
 		}
+		return chooseProject(options, func(v modInfo) string {
+			return v.Name + " (" + v.Summary + ")"
+		}, 0)
+	}
+}
 
-		var modInfoData modInfo
-		var cancelled bool
-		menu.Action(func(menuRes []wmenu.Opt) error {
-			if len(menuRes) != 1 || menuRes[0].Value == nil {
-				fmt.Println("Cancelled!")
-				cancelled = true
-				return nil
-			}
+// chooseProject asks the user to choose one of the given projects from a menu, with the project at index def as the
+// default option. If the user cancels, true is returned.
+func chooseProject(projects []modInfo, label func(modInfo) string, def int) (bool, modInfo) {
+	if err := cmdshared.CanPrompt(); err != nil {
+		cmdshared.ExitWithError(err)
+	}
 
-			// Why is variable shadowing a thing!!!!
-			var ok bool
-			modInfoData, ok = menuRes[0].Value.(modInfo)
-			if !ok {
-				return errors.New("error converting interface from wmenu")
-			}
+	menu := wmenu.NewMenu("Choose a number:")
+
+	menu.Option("Cancel", nil, false, nil)
+	for i, v := range projects {
+		menu.Option(label(v), v, i == def, nil)
+	}
+
+	var modInfoData modInfo
+	var cancelled bool
+	menu.Action(func(menuRes []wmenu.Opt) error {
+		if len(menuRes) != 1 || menuRes[0].Value == nil {
+			fmt.Println("Cancelled!")
+			cancelled = true
 			return nil
-		})
-		err = menu.Run()
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
 		}
 
-		if cancelled {
-			return true, modInfo{}
+		// Why is variable shadowing a thing!!!!
+		var ok bool
+		modInfoData, ok = menuRes[0].Value.(modInfo)
+		if !ok {
+			return errors.New("error converting interface from wmenu")
 		}
-		return false, modInfoData
+		return nil
+	})
+	err := menu.Run()
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+
+	if cancelled {
+		return true, modInfo{}
 	}
+	return false, modInfoData
 }
 
 func getLatestFile(modInfoData modInfo, mcVersions []string, fileID uint32, packLoaders []string) (modFileInfo, error) {
@@ -443,6 +670,11 @@ var fileIDFlag uint32
 
 var gameFlag string
 var categoryFlag string
+var fromFileFlag string
+var gameVersionFlag string
+var pinFlag bool
+var sideFlag string
+var dependenciesFlag bool
 
 func init() {
 	curseforgeCmd.AddCommand(installCmd)
@@ -451,4 +683,11 @@ func init() {
 	installCmd.Flags().Uint32Var(&fileIDFlag, "file-id", 0, "The CurseForge file ID to use")
 	installCmd.Flags().StringVar(&gameFlag, "game", "minecraft", "The game to add files from (slug, as stored in URLs); the game in the URL takes precedence")
 	installCmd.Flags().StringVar(&categoryFlag, "category", "", "The category to add files from (slug, as stored in URLs); the category in the URL takes precedence")
+	installCmd.Flags().StringVar(&fromFileFlag, "from-file", "", "Add every project listed in a file: one slug, project ID or URL per line, optionally followed by a file ID; text after # is ignored")
+	installCmd.Flags().StringVar(&gameVersionFlag, "game-version", "", "Find files for this Minecraft version instead of the pack's versions; updates still follow the pack's version")
+	installCmd.Flags().BoolVar(&pinFlag, "pin", false, "Pin the added file, so it isn't changed by packwiz update")
+	installCmd.Flags().StringVar(&sideFlag, "side", "", "The side to add the file on (client, server or both); resource packs and shaders default to client")
+	installCmd.Flags().BoolVar(&dependenciesFlag, "dependencies", false, "Add the required dependencies of the project (and their required dependencies) without asking")
+	cmdshared.AddReportFlag(installCmd, "curseforge.add.report")
+	cmdshared.AddHooks(installCmd, "add")
 }
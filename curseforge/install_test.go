@@ -0,0 +1,145 @@
+package curseforge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+func TestParseSlugOrUrl(t *testing.T) {
+	tests := []struct {
+		input    string
+		category string
+		slug     string
+		fileID   uint32
+	}{
+		{"https://www.curseforge.com/minecraft/mc-mods/jei", "mc-mods", "jei", 0},
+		{"www.curseforge.com/minecraft/mc-mods/jei/files/4593548", "mc-mods", "jei", 4593548},
+		{"https://www.curseforge.com/minecraft/texture-packs/faithful-32x?page=2", "texture-packs", "faithful-32x", 0},
+		{"jei", "", "jei", 0},
+		{"3d-skin-layers", "", "3d-skin-layers", 0},
+		{"not a slug", "", "", 0},
+	}
+	for _, tt := range tests {
+		_, category, slug, fileID, err := parseSlugOrUrl(tt.input)
+		if err != nil {
+			t.Errorf("Failed to parse %s: %v", tt.input, err)
+			continue
+		}
+		if category != tt.category || slug != tt.slug || fileID != tt.fileID {
+			t.Errorf("Expected %s to be parsed as category %q slug %q file %d, got category %q slug %q file %d",
+				tt.input, tt.category, tt.slug, tt.fileID, category, slug, fileID)
+		}
+	}
+}
+
+func TestPreferredSlugResult(t *testing.T) {
+	results := []modInfo{
+		{Name: "Pack", GameID: 432, ClassID: 12},
+		{Name: "Mod", GameID: 432, ClassID: 6},
+	}
+	if i := preferredSlugResult(results); i != 1 {
+		t.Errorf("Expected the mod to be preferred, got %s", results[i].Name)
+	}
+	if i := preferredSlugResult(results[:1]); i != 0 {
+		t.Errorf("Expected the first result without a mod, got %d", i)
+	}
+	if label := slugResultLabel(results[0]); label != "Pack [resourcepack, ID 0] ()" {
+		t.Errorf("Unexpected label %q", label)
+	}
+}
+
+func TestInstallFileDependencies(t *testing.T) {
+	// Project 2 is a required dependency of project 1, and requires project 1 in turn; project 3 is optional
+	projects := map[uint32]string{
+		2: `{"id": 2, "name": "Library", "slug": "library", "gameId": 432, "classId": 6, "latestFiles": [
+			{"id": 20, "modId": 2, "fileName": "library.jar", "gameVersions": ["1.20.1", "Fabric"], "fileFingerprint": 2,
+			 "dependencies": [{"modId": 1, "relationType": 3}]},
+			{"id": 21, "modId": 2, "fileName": "library-forge.jar", "gameVersions": ["1.20.1", "Forge"], "fileFingerprint": 3}
+		]}`,
+		3: `{"id": 3, "name": "Optional Addon", "slug": "optional-addon", "gameId": 432, "classId": 6}`,
+	}
+	var requested [][]uint32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/mods" {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			ModIDs []uint32 `json:"modIds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Invalid request: %v", err)
+		}
+		requested = append(requested, body.ModIDs)
+		var data []json.RawMessage
+		for _, id := range body.ModIDs {
+			data = append(data, json.RawMessage(projects[id]))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+	oldURL := cfApiURL
+	cfApiURL = srv.URL
+
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "index.toml")
+	if err := os.WriteFile(indexPath, []byte("hash-format = \"sha256\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	index, err := core.LoadIndex(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("meta-folder-base", dir)
+	dependenciesFlag = true
+	t.Cleanup(func() {
+		cfApiURL = oldURL
+		viper.Set("meta-folder-base", ".")
+		dependenciesFlag = false
+	})
+
+	pack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"}}
+	root := modInfo{ID: 1, Name: "Test Mod", Slug: "test-mod", GameID: 432, ClassID: 6}
+	var rootFile modFileInfo
+	err = json.Unmarshal([]byte(`{"id": 10, "modId": 1, "fileName": "test-mod.jar", "gameVersions": ["1.20.1", "Fabric"],
+		"fileFingerprint": 1, "dependencies": [{"modId": 2, "relationType": 3}, {"modId": 3, "relationType": 2}]}`), &rootFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := installFile(root, rootFile, pack, &index); err != nil {
+		t.Fatalf("Failed to add project: %v", err)
+	}
+	mod, err := core.LoadMod(filepath.Join(dir, "mods", "library"+core.MetaExtension))
+	if err != nil {
+		t.Fatalf("Expected the required dependency to be added: %v", err)
+	}
+	if !mod.AddedAsDependency || mod.FileName != "library.jar" {
+		t.Errorf("Expected the Fabric file of the dependency to be added as a dependency, got %s (added as dependency: %v)", mod.FileName, mod.AddedAsDependency)
+	}
+	mod, err = core.LoadMod(filepath.Join(dir, "mods", "test-mod"+core.MetaExtension))
+	if err != nil {
+		t.Fatalf("Expected the project to be added: %v", err)
+	}
+	if !slices.Equal(mod.Dependencies, []string{"2"}) {
+		t.Errorf("Expected the project to depend on the library, got %v", mod.Dependencies)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "mods", "optional-addon"+core.MetaExtension)); err == nil {
+		t.Error("Expected the optional dependency not to be added")
+	}
+	// The dependency of the library on the project being added isn't looked up again
+	expectedRequests := [][]uint32{{2}, {3}}
+	if fmt.Sprint(requested) != fmt.Sprint(expectedRequests) {
+		t.Errorf("Expected projects %v to be requested, got %v", expectedRequests, requested)
+	}
+}
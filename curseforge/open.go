@@ -2,9 +2,9 @@ package curseforge
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 
+	"github.com/0byte-coding/packwiz/cmd"
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/skratchdot/open-golang/open"
 	"github.com/spf13/cobra"
@@ -16,37 +16,35 @@ var openCmd = &cobra.Command{
 	Short:   "Open the project page for a CurseForge file in your browser",
 	Aliases: []string{"doc"},
 	Args:    cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	Run: func(_ *cobra.Command, args []string) {
 		fmt.Println("Loading modpack...")
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
-		resolvedMod, ok := index.FindMod(args[0])
+		resolvedMod, ok := cmd.ResolveModName(index, args[0])
 		if !ok {
 			// TODO: should this auto-refresh?
 			fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitNotFound)
 		}
 		modData, err := core.LoadMod(resolvedMod)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
-		updateData, ok := modData.GetParsedUpdateData("curseforge")
-		if !ok {
+		if _, ok := modData.GetParsedUpdateData("curseforge"); !ok {
 			fmt.Println("Can't find CurseForge update metadata for this file")
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitNotFound)
+		}
+		url, err := cfUpdater{}.ProjectPage(&modData)
+		if err != nil {
+			cmdshared.ExitWithError(err)
 		}
-		cfUpdateData := updateData.(cfUpdateData)
 		fmt.Println("Opening browser...")
-		url := "https://www.curseforge.com/projects/" + strconv.FormatUint(uint64(cfUpdateData.ProjectID), 10)
 		err = open.Start(url)
 		if err != nil {
 			fmt.Println("Opening page failed, direct link:")
@@ -42,18 +42,41 @@ func (c cursePackMeta) PackAuthor() string {
 func (c cursePackMeta) Versions() map[string]string {
 	vers := make(map[string]string)
 	vers["minecraft"] = c.Minecraft.Version
+	if loader, ok := c.primaryModLoader(); ok {
+		if name, version, ok := parseModLoaderID(loader.ID, c.Minecraft.Version); ok {
+			vers[name] = version
+		}
+	}
+	return vers
+}
+
+// primaryModLoader returns the modloader marked as primary, or the first modloader if none are marked
+func (c cursePackMeta) primaryModLoader() (modLoaderDef, bool) {
 	for _, v := range c.Minecraft.ModLoaders {
-		// Seperate dash-separated modloader/version pairs
-		parts := strings.SplitN(v.ID, "-", 2)
-		if len(parts) == 2 {
-			vers[parts[0]] = parts[1]
+		if v.Primary {
+			return v, true
 		}
 	}
-	if val, ok := vers["forge"]; ok {
-		// Remove the minecraft version prefix, if it exists
-		vers["forge"] = strings.TrimPrefix(val, c.Minecraft.Version+"-")
+	if len(c.Minecraft.ModLoaders) > 0 {
+		return c.Minecraft.ModLoaders[0], true
 	}
-	return vers
+	return modLoaderDef{}, false
+}
+
+// parseModLoaderID splits a manifest modloader ID (e.g. fabric-0.14.21 or forge-43.2.0) into the loader name and
+// version. Legacy Forge IDs that include the Minecraft version (e.g. forge-1.12.2-14.23.5.2860 or
+// forge-10.13.4.1614-1.7.10) have it removed.
+func parseModLoaderID(id string, mcVersion string) (string, string, bool) {
+	name, version, ok := strings.Cut(strings.TrimSpace(id), "-")
+	if !ok || len(name) == 0 || len(version) == 0 {
+		return "", "", false
+	}
+	name = strings.ToLower(name)
+	if len(mcVersion) > 0 {
+		version = strings.TrimPrefix(version, mcVersion+"-")
+		version = strings.TrimSuffix(version, "-"+mcVersion)
+	}
+	return name, version, true
 }
 
 func (c cursePackMeta) Mods() []AddonFileReference {
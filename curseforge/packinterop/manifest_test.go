@@ -0,0 +1,113 @@
+package packinterop
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func TestParseModLoaderID(t *testing.T) {
+	tests := []struct {
+		id        string
+		mcVersion string
+		name      string
+		version   string
+	}{
+		{"forge-43.2.0", "1.19.2", "forge", "43.2.0"},
+		{"forge-1.12.2-14.23.5.2860", "1.12.2", "forge", "14.23.5.2860"},
+		{"forge-10.13.4.1614-1.7.10", "1.7.10", "forge", "10.13.4.1614"},
+		{"fabric-0.14.21", "1.20.1", "fabric", "0.14.21"},
+		{"neoforge-21.1.77", "1.21.1", "neoforge", "21.1.77"},
+		{"neoforge-1.20.1-47.1.84", "1.20.1", "neoforge", "47.1.84"},
+		{"quilt-0.19.0-beta.18", "1.20.1", "quilt", "0.19.0-beta.18"},
+		{"Forge-36.2.39", "1.16.5", "forge", "36.2.39"},
+	}
+	for _, tt := range tests {
+		name, version, ok := parseModLoaderID(tt.id, tt.mcVersion)
+		if !ok || name != tt.name || version != tt.version {
+			t.Errorf("Expected %s to be parsed as %s %s, got %s %s (%v)", tt.id, tt.name, tt.version, name, version, ok)
+		}
+	}
+
+	for _, id := range []string{"", "forge", "forge-", "-1.0"} {
+		if _, _, ok := parseModLoaderID(id, "1.20.1"); ok {
+			t.Errorf("Expected %q to be rejected", id)
+		}
+	}
+}
+
+func TestManifestVersions(t *testing.T) {
+	var meta cursePackMeta
+	meta.Minecraft.Version = "1.20.1"
+	meta.Minecraft.ModLoaders = []modLoaderDef{
+		{ID: "fabric-0.14.21"},
+		{ID: "forge-1.20.1-47.2.0", Primary: true},
+	}
+	versions := meta.Versions()
+	if len(versions) != 2 || versions["minecraft"] != "1.20.1" || versions["forge"] != "47.2.0" {
+		t.Errorf("Expected the primary modloader to be used, got %v", versions)
+	}
+
+	// Without a primary modloader, the first one is used
+	meta.Minecraft.ModLoaders[1].Primary = false
+	versions = meta.Versions()
+	if len(versions) != 2 || versions["fabric"] != "0.14.21" {
+		t.Errorf("Expected the first modloader to be used, got %v", versions)
+	}
+
+	meta.Minecraft.ModLoaders = nil
+	versions = meta.Versions()
+	if len(versions) != 1 || versions["minecraft"] != "1.20.1" {
+		t.Errorf("Expected only the Minecraft version, got %v", versions)
+	}
+}
+
+func TestWriteManifestModLoaders(t *testing.T) {
+	pack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "forge": "47.2.0", "neoforge": "47.1.84"}}
+	writeLoaders := func(loaders []string) ([]modLoaderDef, error) {
+		t.Helper()
+		var out bytes.Buffer
+		if err := WriteManifestFromPack(pack, nil, 0, loaders, &out); err != nil {
+			return nil, err
+		}
+		var manifest cursePackMeta
+		if err := json.Unmarshal(out.Bytes(), &manifest); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), `"modLoaders": [`) || !strings.Contains(out.String(), `"primary": `) {
+			t.Errorf("Expected modLoaders entries with id and primary fields, got:\n%s", out.String())
+		}
+		return manifest.Minecraft.ModLoaders, nil
+	}
+
+	// Without loaders, only the pack's loader is written
+	defs, err := writeLoaders(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(defs, []modLoaderDef{{ID: "forge-47.2.0", Primary: true}}) {
+		t.Errorf("Expected only the pack's loader, got %v", defs)
+	}
+
+	defs, err = writeLoaders([]string{"neoforge", "Forge-47.2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []modLoaderDef{{ID: "neoforge-47.1.84", Primary: true}, {ID: "forge-47.2.0", Primary: false}}
+	if !slices.Equal(defs, expected) {
+		t.Errorf("Expected %v, got %v", expected, defs)
+	}
+
+	for _, loaders := range [][]string{{"fabric"}, {"forge-47.1.0"}, {"rift"}, {"forge", "forge"}} {
+		if _, err := writeLoaders(loaders); err == nil {
+			t.Errorf("Expected loaders %v to be rejected", loaders)
+		}
+		if err := CheckModLoaders(pack, loaders); err == nil {
+			t.Errorf("Expected loaders %v to fail the check", loaders)
+		}
+	}
+}
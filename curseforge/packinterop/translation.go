@@ -5,33 +5,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
+	"slices"
+	"strings"
 
 	"github.com/0byte-coding/packwiz/core"
 )
 
-func ReadMetadata(s ImportPackSource) ImportPackMetadata {
-	var packImport ImportPackMetadata
+// ReadMetadata reads the metadata of a CurseForge modpack (either a manifest.json or a minecraftinstance.json file)
+func ReadMetadata(s ImportPackSource) (ImportPackMetadata, error) {
 	metaFile := s.GetPackFile()
 	rdr, err := metaFile.Open()
 	if err != nil {
-		fmt.Printf("Error reading file: %s\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("error reading file: %w", err)
 	}
 
 	// Read the whole file (as we are going to parse it multiple times)
 	fileData, err := io.ReadAll(rdr)
 	if err != nil {
-		fmt.Printf("Error reading file: %s\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("error reading file: %w", err)
 	}
 
 	// Determine what format the file is
 	var jsonFile map[string]interface{}
 	err = json.Unmarshal(fileData, &jsonFile)
 	if err != nil {
-		fmt.Printf("Error parsing JSON: %s\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("error parsing JSON: %w", err)
 	}
 
 	isManifest := false
@@ -42,23 +40,18 @@ func ReadMetadata(s ImportPackSource) ImportPackMetadata {
 		packMeta := cursePackMeta{importSrc: s}
 		err = json.Unmarshal(fileData, &packMeta)
 		if err != nil {
-			fmt.Printf("Error parsing JSON: %s\n", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("error parsing JSON: %w", err)
 		}
-		packImport = packMeta
-	} else {
-		// Replace FileNameOnDisk with fileNameOnDisk
-		fileData = bytes.ReplaceAll(fileData, []byte("FileNameOnDisk"), []byte("fileNameOnDisk"))
-		packMeta := twitchInstalledPackMeta{importSrc: s}
-		err = json.Unmarshal(fileData, &packMeta)
-		if err != nil {
-			fmt.Printf("Error parsing JSON: %s\n", err)
-			os.Exit(1)
-		}
-		packImport = packMeta
+		return packMeta, nil
 	}
-
-	return packImport
+	// Replace FileNameOnDisk with fileNameOnDisk
+	fileData = bytes.ReplaceAll(fileData, []byte("FileNameOnDisk"), []byte("fileNameOnDisk"))
+	packMeta := twitchInstalledPackMeta{importSrc: s}
+	err = json.Unmarshal(fileData, &packMeta)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %w", err)
+	}
+	return packMeta, nil
 }
 
 // AddonFileReference is a struct to reference a single file on CurseForge
@@ -69,7 +62,55 @@ type AddonFileReference struct {
 	OptionalDisabled bool
 }
 
-func WriteManifestFromPack(pack core.Pack, fileRefs []AddonFileReference, projectID uint32, out io.Writer) error {
+// manifestLoaders lists the mod loaders that can be written to manifest.json, in the order they are chosen from when no
+// loaders are given
+var manifestLoaders = []string{"fabric", "forge", "neoforge", "quilt"}
+
+// manifestModLoaders returns the modloaders written to manifest.json. Loaders are given as names (e.g. fabric) or IDs
+// (e.g. fabric-0.15.0), which must match the versions in pack.toml; they are written in order, with the first marked as
+// primary (the loader that the CurseForge app installs). If no loaders are given, the pack's loader is used.
+func manifestModLoaders(pack core.Pack, loaders []string) ([]modLoaderDef, error) {
+	if len(loaders) == 0 {
+		for _, name := range manifestLoaders {
+			if version, ok := pack.Versions[name]; ok {
+				return []modLoaderDef{{ID: name + "-" + version, Primary: true}}, nil
+			}
+		}
+		return []modLoaderDef{}, nil
+	}
+
+	modLoaders := make([]modLoaderDef, 0, len(loaders))
+	for i, loader := range loaders {
+		name, version, hasVersion := strings.Cut(strings.TrimSpace(loader), "-")
+		name = strings.ToLower(name)
+		if !slices.Contains(manifestLoaders, name) {
+			return nil, fmt.Errorf("unknown mod loader %q, must be one of %s", loader, strings.Join(manifestLoaders, ", "))
+		}
+		packVersion, ok := pack.Versions[name]
+		if !ok {
+			return nil, fmt.Errorf("the pack doesn't use %s; add it to the versions in pack.toml first", name)
+		}
+		if hasVersion && version != packVersion {
+			return nil, fmt.Errorf("%s doesn't match the %s version in pack.toml (%s)", loader, name, packVersion)
+		}
+		id := name + "-" + packVersion
+		if slices.ContainsFunc(modLoaders, func(def modLoaderDef) bool { return def.ID == id }) {
+			return nil, fmt.Errorf("%s is given more than once", name)
+		}
+		modLoaders = append(modLoaders, modLoaderDef{ID: id, Primary: i == 0})
+	}
+	return modLoaders, nil
+}
+
+// CheckModLoaders returns an error if the given loaders can't be written to manifest.json, as with WriteManifestFromPack
+func CheckModLoaders(pack core.Pack, loaders []string) error {
+	_, err := manifestModLoaders(pack, loaders)
+	return err
+}
+
+// WriteManifestFromPack writes a manifest.json for the pack. The loaders are written to it as described by
+// manifestModLoaders; the first is marked as primary, and the pack's loader is used if none are given.
+func WriteManifestFromPack(pack core.Pack, fileRefs []AddonFileReference, projectID uint32, loaders []string, out io.Writer) error {
 	files := make([]struct {
 		ProjectID uint32 `json:"projectID"`
 		FileID    uint32 `json:"fileID"`
@@ -83,27 +124,9 @@ func WriteManifestFromPack(pack core.Pack, fileRefs []AddonFileReference, projec
 		}{ProjectID: fr.ProjectID, FileID: fr.FileID, Required: !fr.OptionalDisabled}
 	}
 
-	modLoaders := make([]modLoaderDef, 0, 1)
-	if fabricVersion, ok := pack.Versions["fabric"]; ok {
-		modLoaders = append(modLoaders, modLoaderDef{
-			ID:      "fabric-" + fabricVersion,
-			Primary: true,
-		})
-	} else if forgeVersion, ok := pack.Versions["forge"]; ok {
-		modLoaders = append(modLoaders, modLoaderDef{
-			ID:      "forge-" + forgeVersion,
-			Primary: true,
-		})
-	} else if neoforgeVersion, ok := pack.Versions["neoforge"]; ok {
-		modLoaders = append(modLoaders, modLoaderDef{
-			ID:      "neoforge-" + neoforgeVersion,
-			Primary: true,
-		})
-	} else if quiltVersion, ok := pack.Versions["quilt"]; ok {
-		modLoaders = append(modLoaders, modLoaderDef{
-			ID:      "quilt-" + quiltVersion,
-			Primary: true,
-		})
+	modLoaders, err := manifestModLoaders(pack, loaders)
+	if err != nil {
+		return err
 	}
 
 	manifest := cursePackMeta{
@@ -126,7 +149,7 @@ func WriteManifestFromPack(pack core.Pack, fileRefs []AddonFileReference, projec
 
 	w := json.NewEncoder(out)
 	w.SetIndent("", "  ") // Match CF export
-	err := w.Encode(manifest)
+	err = w.Encode(manifest)
 	if err != nil {
 		return err
 	}
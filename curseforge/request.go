@@ -4,8 +4,9 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/0byte-coding/packwiz/core"
+	"github.com/0byte-coding/packwiz/settings"
 	"io"
 	"net/http"
 	"net/url"
@@ -13,7 +14,8 @@ import (
 	"time"
 )
 
-const cfApiServer = "api.curseforge.com"
+// cfApiURL is replaced in tests
+var cfApiURL = "https://api.curseforge.com"
 
 // If you fork/derive from packwiz, I request that you obtain your own API key.
 const cfApiKeyDefault = "JDJhJDEwJHNBWVhqblU1N0EzSmpzcmJYM3JVdk92UWk2NHBLS3BnQ2VpbGc1TUM1UGNKL0RYTmlGWWxh"
@@ -29,55 +31,62 @@ func decodeDefaultKey() string {
 	return string(k)
 }
 
+// getApiKey returns the API key configured by the user, falling back to the key provided at build time or the default key
+func getApiKey() (string, error) {
+	key, err := settings.GetCurseForgeAPIKey()
+	if err != nil || key != "" {
+		return key, err
+	}
+	if cfApiKey == "" {
+		cfApiKey = decodeDefaultKey()
+	}
+	return cfApiKey, nil
+}
+
 type cfApiClient struct {
 	httpClient *http.Client
 }
 
-var cfDefaultClient = cfApiClient{&http.Client{}}
+var cfDefaultClient = cfApiClient{settings.EnableServerErrorRetries(settings.NewRateLimitHTTPClient("CurseForge API", 0, nil))}
 
 func (c *cfApiClient) makeGet(endpoint string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", "https://"+cfApiServer+endpoint, nil)
+	req, err := http.NewRequest("GET", cfApiURL+endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", core.UserAgent)
 	req.Header.Set("Accept", "application/json")
-	if cfApiKey == "" {
-		cfApiKey = decodeDefaultKey()
-	}
-	req.Header.Set("X-API-Key", cfApiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("invalid response status: %v", resp.Status)
-	}
-	return resp, nil
+	return c.do(req)
 }
 
 func (c *cfApiClient) makePost(endpoint string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest("POST", "https://"+cfApiServer+endpoint, body)
+	req, err := http.NewRequest("POST", cfApiURL+endpoint, body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", core.UserAgent)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
-	if cfApiKey == "" {
-		cfApiKey = decodeDefaultKey()
+	return c.do(req)
+}
+
+func (c *cfApiClient) do(req *http.Request) (*http.Response, error) {
+	key, err := getApiKey()
+	if err != nil {
+		return nil, err
 	}
-	req.Header.Set("X-API-Key", cfApiKey)
+	req.Header.Set("X-API-Key", key)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusForbidden {
+		_ = resp.Body.Close()
+		return nil, errors.New("the CurseForge API rejected the API key (403 Forbidden); get a key from " +
+			"https://console.curseforge.com/ and run packwiz settings cf-api-key set <key>, or set the CF_API_KEY (or CF_API_KEY_FILE) environment variable")
+	}
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("invalid response status: %v", resp.Status)
 	}
@@ -170,6 +179,14 @@ type modInfo struct {
 	Links      struct {
 		WebsiteURL string `json:"websiteUrl"`
 	} `json:"links"`
+	// AllowModDistribution is false if the author has disabled third-party downloads, so the API doesn't provide
+	// download URLs for the project's files
+	AllowModDistribution *bool `json:"allowModDistribution"`
+}
+
+// distributionDisabled returns true if the author of the project has disabled third-party downloads
+func (m modInfo) distributionDisabled() bool {
+	return m.AllowModDistribution != nil && !*m.AllowModDistribution
 }
 
 func (c *cfApiClient) getModInfo(modID uint32) (modInfo, error) {
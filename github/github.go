@@ -3,8 +3,13 @@ package github
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/url"
+	"path"
+	"strings"
 
+	"github.com/dlclark/regexp2"
 	"github.com/mitchellh/mapstructure"
 	"github.com/0byte-coding/packwiz/cmd"
 	"github.com/0byte-coding/packwiz/core"
@@ -50,9 +55,94 @@ func fetchRepo(slug string) (Repo, error) {
 }
 
 type Repo struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`      // "hello_world"
-	FullName string `json:"full_name"` // "owner/hello_world"
+	ID            int    `json:"id"`
+	Name          string `json:"name"`      // "hello_world"
+	FullName      string `json:"full_name"` // "owner/hello_world"
+	DefaultBranch string `json:"default_branch"`
+}
+
+type Branch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// fetchBranchCommit gets the SHA of the latest commit of a branch
+func fetchBranchCommit(slug string, branch string) (string, error) {
+	var b Branch
+
+	res, err := ghDefaultClient.getBranch(slug, branch)
+	if err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+
+	err = json.NewDecoder(res.Body).Decode(&b)
+	if err != nil {
+		return "", err
+	}
+
+	if b.Commit.SHA == "" {
+		return "", errors.New("invalid json while fetching branch: " + branch)
+	}
+
+	return b.Commit.SHA, nil
+}
+
+// commitFile is a file built from a specific commit, found using the URL template of a project tracking a branch
+type commitFile struct {
+	Commit   string
+	URL      string
+	FileName string
+	Hash     string
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// expandURLTemplate replaces the placeholders in a URL template with the values for the given commit
+func expandURLTemplate(data ghUpdateData, commit string) string {
+	return strings.NewReplacer(
+		"{slug}", data.Slug,
+		"{branch}", data.Branch,
+		"{commit}", commit,
+		"{short-commit}", shortCommit(commit),
+	).Replace(data.URLTemplate)
+}
+
+// getCommitFile downloads the file for a commit using the URL template, to find its name and hash
+func getCommitFile(data ghUpdateData, commit string) (commitFile, error) {
+	fileURL := expandURLTemplate(data, commit)
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return commitFile{}, fmt.Errorf("invalid URL template: %w", err)
+	}
+	fileName := path.Base(u.Path)
+	if fileName == "." || fileName == "/" {
+		return commitFile{}, fmt.Errorf("URL %s doesn't have a file name", fileURL)
+	}
+
+	// Not downloaded with the GitHub client, as the URL may not be on GitHub and shouldn't receive the token
+	resp, err := core.GetWithUA(fileURL, "application/octet-stream")
+	if err != nil {
+		return commitFile{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return commitFile{}, fmt.Errorf("no file found for commit %s at %s (status %v)", shortCommit(commit), fileURL, resp.Status)
+	}
+
+	hash, err := core.HashReader(resp.Body, "sha256")
+	if err != nil {
+		return commitFile{}, err
+	}
+	return commitFile{commit, fileURL, fileName, hash}, nil
 }
 
 type Release struct {
@@ -61,9 +151,28 @@ type Release struct {
 	TargetCommitish string  `json:"target_commitish"` // The branch of the release
 	Name            string  `json:"name"`
 	CreatedAt       string  `json:"created_at"`
+	Draft           bool    `json:"draft"`
+	Prerelease      bool    `json:"prerelease"`
 	Assets          []Asset `json:"assets"`
 }
 
+// matchAssets returns the assets of the release with names matching the given regular expression
+func (r Release) matchAssets(regex string) ([]Asset, error) {
+	expr, err := regexp2.Compile(regex, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset regex %s: %w", regex, err)
+	}
+
+	var assets []Asset
+	for _, v := range r.Assets {
+		bl, _ := expr.MatchString(v.Name)
+		if bl {
+			assets = append(assets, v)
+		}
+	}
+	return assets, nil
+}
+
 type Asset struct {
 	URL                string `json:"url"`
 	BrowserDownloadURL string `json:"browser_download_url"`
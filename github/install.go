@@ -5,14 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/dlclark/regexp2"
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"gopkg.in/dixonwille/wmenu.v4"
 )
 
 var GithubRegex = regexp.MustCompile(`^https?://(?:www\.)?github\.com/([^/]+/[^/]+)`)
@@ -26,13 +28,12 @@ var installCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		if len(args) == 0 || len(args[0]) == 0 {
 			fmt.Println("You must specify a GitHub repository URL.")
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitUsage)
 		}
 
 		// Try interpreting the argument as a slug, or GitHub repository URL.
@@ -62,122 +63,201 @@ var installCmd = &cobra.Command{
 
 		if err != nil {
 			fmt.Printf("Failed to add project: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		if branchFlag != "" {
 			branch = branchFlag
 		}
+		if urlTemplateFlag != "" {
+			if regexFlag != "" || assetPatternFlag != "" || tagPatternFlag != "" || prereleaseFlag {
+				fmt.Println("--url-template can't be used with options for releases")
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
+			if branch == "" {
+				branch = repo.DefaultBranch
+			}
+			err = installCommit(repo, branch, urlTemplateFlag, pack)
+			if err != nil {
+				fmt.Printf("Failed to add project: %s\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+			return
+		}
+
+		if regexFlag != "" && assetPatternFlag != "" {
+			fmt.Println("Only one of --regex and --asset-pattern can be specified")
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
 		if regexFlag != "" {
 			regex = regexFlag
 		}
+		if assetPatternFlag != "" {
+			regex = globToRegex(assetPatternFlag)
+		}
 
-		err = installMod(repo, branch, regex, pack)
+		err = installMod(repo, ghUpdateData{
+			Slug:             repo.FullName,
+			Branch:           branch,
+			Regex:            regex,
+			AllowPrereleases: prereleaseFlag,
+			TagPattern:       tagPatternFlag,
+		}, pack)
 		if err != nil {
 			fmt.Printf("Failed to add project: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 	},
 }
 
-func installMod(repo Repo, branch string, regex string, pack core.Pack) error {
-	latestRelease, err := getLatestRelease(repo.FullName, branch)
+func installMod(repo Repo, filter ghUpdateData, pack core.Pack) error {
+	latestRelease, err := getLatestRelease(filter)
 	if err != nil {
 		return fmt.Errorf("failed to get latest release: %v", err)
 	}
 
-	return installRelease(repo, latestRelease, regex, pack)
+	return installRelease(repo, latestRelease, filter, pack)
 }
 
-func getLatestRelease(slug string, branch string) (Release, error) {
+// getLatestRelease gets the latest release of the repository with the given update data's slug, that is allowed by
+// its branch, pre-release and tag settings
+func getLatestRelease(data ghUpdateData) (Release, error) {
 	var releases []Release
-	var release Release
 
-	resp, err := ghDefaultClient.getReleases(slug)
+	resp, err := ghDefaultClient.getReleases(data.Slug)
 	if err != nil {
-		return release, err
+		return Release{}, err
 	}
 
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return release, err
+		return Release{}, err
 	}
 
 	err = json.Unmarshal(body, &releases)
 	if err != nil {
-		return release, err
+		return Release{}, err
 	}
 
-	if branch != "" {
-		for _, r := range releases {
-			if r.TargetCommitish == branch {
-				return r, nil
+	return selectLatestRelease(releases, data)
+}
+
+// selectLatestRelease returns the first release in the list (which GitHub sorts newest first) that is allowed by the
+// branch, pre-release and tag settings of the given update data
+func selectLatestRelease(releases []Release, data ghUpdateData) (Release, error) {
+	var tagExpr *regexp2.Regexp
+	if data.TagPattern != "" {
+		var err error
+		tagExpr, err = regexp2.Compile(data.TagPattern, 0)
+		if err != nil {
+			return Release{}, fmt.Errorf("invalid tag pattern %s: %w", data.TagPattern, err)
+		}
+	}
+
+	for _, r := range releases {
+		if r.Draft || (r.Prerelease && !data.AllowPrereleases) {
+			continue
+		}
+		if data.Branch != "" && r.TargetCommitish != data.Branch {
+			continue
+		}
+		if tagExpr != nil {
+			if ok, _ := tagExpr.MatchString(r.TagName); !ok {
+				continue
 			}
 		}
-		return release, fmt.Errorf("failed to find release for branch %v", branch)
+		return r, nil
 	}
 
-	return releases[0], nil
+	if data.Branch != "" {
+		return Release{}, fmt.Errorf("failed to find release for branch %v", data.Branch)
+	}
+	return Release{}, errors.New("failed to find a matching release")
 }
 
-func installRelease(repo Repo, release Release, regex string, pack core.Pack) error {
-	expr := regexp2.MustCompile(regex, 0)
-
+func installRelease(repo Repo, release Release, filter ghUpdateData, pack core.Pack) error {
 	if len(release.Assets) == 0 {
 		return errors.New("release doesn't have any assets attached")
 	}
 
-	var files []Asset
-
-	for _, v := range release.Assets {
-		bl, _ := expr.MatchString(v.Name)
-		if bl {
-			files = append(files, v)
-		}
+	files, err := release.matchAssets(filter.Regex)
+	if err != nil {
+		return err
 	}
 
 	if len(files) == 0 {
 		return errors.New("release doesn't have any assets matching regex")
 	}
 
+	file := files[0]
 	if len(files) > 1 {
-		// TODO: also print file names
-		return errors.New("release has more than one asset matching regex")
+		file, err = chooseAsset(files)
+		if err != nil {
+			return err
+		}
 	}
 
-	file := files[0]
-
 	// Install the file
 	fmt.Printf("Installing %s from release %s\n", file.Name, release.TagName)
-	index, err := pack.LoadIndex()
+
+	hash, err := file.getSha256()
 	if err != nil {
 		return err
 	}
 
-	updateMap := make(map[string]map[string]interface{})
+	return writeModMeta(repo, file.Name, file.BrowserDownloadURL, hash, ghUpdateData{
+		Slug:             repo.FullName,
+		Tag:              release.TagName,
+		Branch:           release.TargetCommitish, // TODO: if no branch is specified by the user, we shouldn't record it - in order to remain branch-agnostic in getLatestRelease()
+		Regex:            filter.Regex,            // TODO: ditto!
+		AllowPrereleases: filter.AllowPrereleases,
+		TagPattern:       filter.TagPattern,
+	}, pack)
+}
+
+// installCommit installs the file built from the latest commit of a branch, found using the given URL template
+func installCommit(repo Repo, branch string, urlTemplate string, pack core.Pack) error {
+	commit, err := fetchBranchCommit(repo.FullName, branch)
+	if err != nil {
+		return fmt.Errorf("failed to get latest commit of branch %s: %w", branch, err)
+	}
 
-	updateMap["github"], err = ghUpdateData{
-		Slug:   repo.FullName,
-		Tag:    release.TagName,
-		Branch: release.TargetCommitish, // TODO: if no branch is specified by the user, we shouldn't record it - in order to remain branch-agnostic in getLatestRelease()
-		Regex:  regex,                   // TODO: ditto!
-	}.ToMap()
+	data := ghUpdateData{
+		Slug:        repo.FullName,
+		Branch:      branch,
+		Commit:      commit,
+		URLTemplate: urlTemplate,
+	}
+	file, err := getCommitFile(data, commit)
 	if err != nil {
 		return err
 	}
 
-	hash, err := file.getSha256()
+	fmt.Printf("Installing %s from commit %s of branch %s\n", file.FileName, shortCommit(commit), branch)
+	return writeModMeta(repo, file.FileName, file.URL, file.Hash, data, pack)
+}
+
+// writeModMeta creates the metadata file for a file installed from a repository, and adds it to the index
+func writeModMeta(repo Repo, fileName string, url string, hash string, updateData ghUpdateData, pack core.Pack) error {
+	index, err := pack.LoadIndex()
+	if err != nil {
+		return err
+	}
+
+	updateMap := make(map[string]map[string]interface{})
+
+	updateMap["github"], err = updateData.ToMap()
 	if err != nil {
 		return err
 	}
 
 	modMeta := core.Mod{
 		Name:     repo.Name,
-		FileName: file.Name,
+		FileName: fileName,
 		Side:     core.UniversalSide,
 		Download: core.ModDownload{
-			URL:        file.BrowserDownloadURL,
+			URL:        url,
 			HashFormat: "sha256",
 			Hash:       hash,
 		},
@@ -186,7 +266,10 @@ func installRelease(repo Repo, release Release, regex string, pack core.Pack) er
 	var path string
 	folder := viper.GetString("meta-folder")
 	if folder == "" {
-		folder = "mods"
+		folder, err = core.GetProjectTypeFolder(core.ProjectTypeMod, "mods")
+		if err != nil {
+			return err
+		}
 	}
 	path = modMeta.SetMetaPath(filepath.Join(viper.GetString("meta-folder-base"), folder, core.SlugifyName(repo.Name)+core.MetaExtension))
 
@@ -217,16 +300,77 @@ func installRelease(repo Repo, release Release, regex string, pack core.Pack) er
 		return err
 	}
 
-	fmt.Printf("Project \"%s\" successfully added! (%s)\n", repo.Name, file.Name)
+	fmt.Printf("Project \"%s\" successfully added! (%s)\n", repo.Name, fileName)
 	return nil
 }
 
+// chooseAsset asks the user to choose one of the given assets, or chooses the first in non-interactive mode
+func chooseAsset(assets []Asset) (Asset, error) {
+	if viper.GetBool("non-interactive") {
+		fmt.Printf("Multiple assets match, using %s\n", assets[0].Name)
+		return assets[0], nil
+	}
+	if err := cmdshared.CanPrompt(); err != nil {
+		return Asset{}, err
+	}
+
+	var chosen Asset
+	menu := wmenu.NewMenu("Multiple assets match; choose a number:")
+	menu.Option("Cancel", nil, false, nil)
+	for i, v := range assets {
+		menu.Option(v.Name, v, i == 0, nil)
+	}
+	menu.Action(func(menuRes []wmenu.Opt) error {
+		if len(menuRes) != 1 || menuRes[0].Value == nil {
+			return errors.New("asset selection cancelled")
+		}
+		asset, ok := menuRes[0].Value.(Asset)
+		if !ok {
+			return errors.New("error converting interface from wmenu")
+		}
+		chosen = asset
+		return nil
+	})
+	err := menu.Run()
+	return chosen, err
+}
+
+// globToRegex converts a glob pattern (where * matches any characters and ? matches one character) to a regular
+// expression matching the whole name
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
 var branchFlag string
 var regexFlag string
+var assetPatternFlag string
+var prereleaseFlag bool
+var tagPatternFlag string
+var urlTemplateFlag string
 
 func init() {
 	githubCmd.AddCommand(installCmd)
 
 	installCmd.Flags().StringVar(&branchFlag, "branch", "", "The GitHub repository branch to retrieve releases for")
 	installCmd.Flags().StringVar(&regexFlag, "regex", "", "The regular expression to match releases against")
+	installCmd.Flags().StringVar(&assetPatternFlag, "asset-pattern", "", "A glob pattern (e.g. \"*-fabric.jar\") to match release asset names against, instead of --regex")
+	installCmd.Flags().BoolVar(&prereleaseFlag, "prerelease", false, "Allow installing and updating to releases marked as pre-releases")
+	installCmd.Flags().StringVar(&tagPatternFlag, "tag-pattern", "", "The regular expression that release tags must match")
+	installCmd.Flags().StringVar(&urlTemplateFlag, "url-template", "", "Track the latest commit of --branch (or the default branch) instead of releases, downloading from this URL;\n"+
+		"{slug}, {branch}, {commit} and {short-commit} are replaced (e.g. https://raw.githubusercontent.com/{slug}/{commit}/dist/mod.jar)")
+	cmdshared.AddReportFlag(installCmd, "github.add.report")
+	cmdshared.AddHooks(installCmd, "add")
 }
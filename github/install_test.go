@@ -0,0 +1,57 @@
+package github
+
+import "testing"
+
+func TestAssetPatternMatching(t *testing.T) {
+	release := Release{
+		TagName: "v1.2.0",
+		Assets: []Asset{
+			{Name: "mod-fabric-1.2.0.jar"},
+			{Name: "mod-fabric-1.2.0-sources.jar"},
+			{Name: "mod-forge-1.2.0.jar"},
+			{Name: "mod-fabric-1.2.0.jar.sha256"},
+		},
+	}
+
+	tests := []struct {
+		regex    string
+		expected []string
+	}{
+		{globToRegex("*-fabric-*.jar"), []string{"mod-fabric-1.2.0.jar", "mod-fabric-1.2.0-sources.jar"}},
+		{globToRegex("mod-forge-?.?.?.jar"), []string{"mod-forge-1.2.0.jar"}},
+		{globToRegex("mod-fabric-1.2.0.jar"), []string{"mod-fabric-1.2.0.jar"}},
+		{`^.+(?<!-api|-dev|-dev-preshadow|-sources)\.jar$`, []string{"mod-fabric-1.2.0.jar", "mod-forge-1.2.0.jar"}},
+	}
+	for _, test := range tests {
+		assets, err := release.matchAssets(test.regex)
+		if err != nil {
+			t.Fatalf("Failed to match assets with %s: %v", test.regex, err)
+		}
+		if len(assets) != len(test.expected) {
+			t.Errorf("Expected %v to match %s, got %v", test.expected, test.regex, assets)
+			continue
+		}
+		for i, asset := range assets {
+			if asset.Name != test.expected[i] {
+				t.Errorf("Expected %v to match %s, got %v", test.expected, test.regex, assets)
+				break
+			}
+		}
+	}
+
+	if _, err := release.matchAssets("("); err == nil {
+		t.Error("Expected an error for an invalid regex")
+	}
+}
+
+func TestExpandURLTemplate(t *testing.T) {
+	data := ghUpdateData{
+		Slug:        "owner/repo",
+		Branch:      "main",
+		URLTemplate: "https://example.com/{slug}/{branch}/{short-commit}/mod-{commit}.jar",
+	}
+	expected := "https://example.com/owner/repo/main/0123456/mod-0123456789abcdef.jar"
+	if actual := expandURLTemplate(data, "0123456789abcdef"); actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
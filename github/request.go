@@ -3,9 +3,10 @@ package github
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
-	"github.com/0byte-coding/packwiz/core"
+	"github.com/0byte-coding/packwiz/settings"
 	"github.com/spf13/viper"
 )
 
@@ -15,7 +16,7 @@ type ghApiClient struct {
 	httpClient *http.Client
 }
 
-var ghDefaultClient = ghApiClient{&http.Client{}}
+var ghDefaultClient = ghApiClient{settings.NewRateLimitHTTPClient("GitHub API", 0, nil)}
 
 func (c *ghApiClient) makeGet(url string) (*http.Response, error) {
 	ghApiToken := viper.GetString("github.token")
@@ -25,7 +26,6 @@ func (c *ghApiClient) makeGet(url string) (*http.Response, error) {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", core.UserAgent)
 	req.Header.Set("Accept", "application/vnd.github+json")
 	if ghApiToken != "" {
 		req.Header.Set("Authorization", "Bearer "+ghApiToken)
@@ -71,6 +71,15 @@ func (c *ghApiClient) getRepo(slug string) (*http.Response, error) {
 	return resp, nil
 }
 
+func (c *ghApiClient) getBranch(slug string, branch string) (*http.Response, error) {
+	resp, err := c.getRepo(slug + "/branches/" + url.PathEscape(branch))
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
 func (c *ghApiClient) getReleases(slug string) (*http.Response, error) {
 	resp, err := c.getRepo(slug + "/releases")
 	if err != nil {
@@ -0,0 +1,312 @@
+package github
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmd"
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/unascribed/FlexVer/go/flexver"
+	"golang.org/x/mod/module"
+)
+
+// selfUpdateRepo is the GitHub repository that releases of packwiz are published to
+const selfUpdateRepo = "0byte-coding/packwiz"
+
+// selfUpdateChecksums is the release asset listing the SHA-256 hashes of the other assets, as written by GoReleaser
+const selfUpdateChecksums = "checksums.txt"
+
+// selfUpdatePublicKey is the PEM encoded ed25519 public key that the checksums of releases are signed with, which can be
+// set when building with -ldflags="-X 'github.com/0byte-coding/packwiz/github.selfUpdatePublicKey=...'"
+var selfUpdatePublicKey = ""
+
+// selfUpdateCmd represents the self-update command
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update packwiz to the latest release",
+	Long: `Update packwiz to the latest release on GitHub, replacing the running binary.
+The release archive for this OS and architecture is checked against the checksums published with the release. If the
+checksums are signed (as checksums.txt.sig, in the format written by packwiz sign), the signature is checked with the
+public key built into packwiz or given with --pubkey; signed releases aren't installed without a key to check them with,
+unless --skip-signature is given. Older releases are never installed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		current := core.GetVersion()
+		if isDevVersion(current) {
+			fmt.Printf("This is a development build of packwiz (%s), so it won't be replaced with a release; install a release build instead\n", current)
+			cmdshared.Exit(cmdshared.ExitGeneric)
+		}
+
+		fmt.Println("Checking for updates...")
+		release, err := getLatestRelease(ghUpdateData{Slug: selfUpdateRepo, AllowPrereleases: viper.GetBool("self-update.prerelease")})
+		if err != nil {
+			fmt.Printf("Failed to get latest release: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		latest := strings.TrimPrefix(release.TagName, "v")
+		if c := flexver.Compare(latest, current); c == 0 {
+			fmt.Printf("packwiz is up to date (%s)\n", current)
+			return
+		} else if c < 0 {
+			fmt.Printf("The latest release (%s) is older than this version of packwiz (%s); not downgrading\n", latest, current)
+			return
+		}
+		if viper.GetBool("self-update.check") {
+			fmt.Printf("packwiz %s is available (current version %s); run packwiz self-update to install it\n", latest, current)
+			return
+		}
+
+		key, err := getSelfUpdatePublicKey()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		exe, err := os.Executable()
+		if err == nil {
+			exe, err = filepath.EvalSymlinks(exe)
+		}
+		if err != nil {
+			fmt.Printf("Failed to find the packwiz binary: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+
+		asset, err := findSelfUpdateAsset(release, runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		fmt.Printf("Downloading %s...\n", asset.Name)
+		binary, err := downloadSelfUpdate(release, asset, key, viper.GetBool("self-update.skip-signature"))
+		if err != nil {
+			fmt.Printf("Failed to download update: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		err = replaceExecutable(exe, binary)
+		if err != nil {
+			fmt.Printf("Failed to replace %s: %v\n", exe, err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("Updated packwiz from %s to %s\n", current, latest)
+	},
+}
+
+// isDevVersion returns true if the version isn't from a release: a build with no version, or one built from a checkout,
+// which Go stamps with a pseudo-version (marked +dirty if there are uncommitted changes)
+func isDevVersion(version string) bool {
+	return version == "dev" || module.IsPseudoVersion("v"+version) || strings.Contains(version, "+dirty")
+}
+
+// getSelfUpdatePublicKey returns the public key that the checksums of releases are signed with: the key given with
+// --pubkey, or the key built into packwiz. nil is returned if there is neither.
+func getSelfUpdatePublicKey() (ed25519.PublicKey, error) {
+	if file := viper.GetString("self-update.pubkey"); file != "" {
+		return core.LoadPublicKey(file)
+	}
+	if selfUpdatePublicKey != "" {
+		return core.ParsePublicKey([]byte(selfUpdatePublicKey))
+	}
+	return nil, nil
+}
+
+// findSelfUpdateAsset returns the release archive for the given OS and architecture, which GoReleaser names
+// packwiz_<version>_<GOOS>_<GOARCH>
+func findSelfUpdateAsset(release Release, goos string, goarch string) (Asset, error) {
+	expr := regexp.MustCompile("^packwiz_.+_" + regexp.QuoteMeta(goos) + "_" + regexp.QuoteMeta(goarch) + `\.(tar\.gz|zip)$`)
+	for _, asset := range release.Assets {
+		if expr.MatchString(asset.Name) {
+			return asset, nil
+		}
+	}
+	return Asset{}, fmt.Errorf("release %s has no build for %s/%s", release.TagName, goos, goarch)
+}
+
+// findAsset returns the asset of the release with the given name, if there is one
+func (r Release) findAsset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// downloadAsset downloads the contents of a release asset
+func downloadAsset(asset Asset) ([]byte, error) {
+	resp, err := ghDefaultClient.makeGet(asset.BrowserDownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return data, nil
+}
+
+// downloadSelfUpdate downloads a release archive, checks it against the checksums of the release (and their signature,
+// if the release is signed), and returns the packwiz binary inside it. Signed releases are rejected if there is no public
+// key to check them with, unless skipSignature is set.
+func downloadSelfUpdate(release Release, archive Asset, key ed25519.PublicKey, skipSignature bool) ([]byte, error) {
+	checksumsAsset, ok := release.findAsset(selfUpdateChecksums)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s to check the download against", release.TagName, selfUpdateChecksums)
+	}
+	checksums, err := downloadAsset(checksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	if sigAsset, ok := release.findAsset(selfUpdateChecksums + core.SignatureExtension); ok {
+		if key == nil {
+			if !skipSignature {
+				return nil, fmt.Errorf("release %s is signed, but there is no public key to check it with; use --pubkey to give one, or --skip-signature to install it without checking the signature", release.TagName)
+			}
+			fmt.Printf("Warning: not checking the signature of release %s\n", release.TagName)
+		} else {
+			sigData, err := downloadAsset(sigAsset)
+			if err != nil {
+				return nil, err
+			}
+			valid, err := core.VerifyDetachedSignature(key, checksums, sigData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read signature %s: %w", sigAsset.Name, err)
+			}
+			if !valid {
+				return nil, fmt.Errorf("signature of %s does not match the public key", selfUpdateChecksums)
+			}
+		}
+	} else if key != nil {
+		return nil, fmt.Errorf("release %s isn't signed, but a public key was given to check it with", release.TagName)
+	}
+
+	expected, ok := parseChecksums(checksums)[archive.Name]
+	if !ok {
+		return nil, fmt.Errorf("%s has no checksum for %s", selfUpdateChecksums, archive.Name)
+	}
+	data, err := downloadAsset(archive)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := core.HashReader(bytes.NewReader(data), "sha256")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(hash, expected) {
+		return nil, fmt.Errorf("hash of %s (%s) does not match the checksum of the release (%s)", archive.Name, hash, expected)
+	}
+	return extractBinary(archive.Name, data)
+}
+
+// parseChecksums reads a list of checksums in the format written by sha256sum, returning file name -> hash
+func parseChecksums(data []byte) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 {
+			// Files hashed in binary mode are marked with *
+			checksums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+		}
+	}
+	return checksums
+}
+
+// extractBinary returns the packwiz binary from a release archive
+func extractBinary(name string, data []byte) ([]byte, error) {
+	isBinary := func(p string) bool {
+		base := path.Base(p)
+		return base == "packwiz" || base == "packwiz.exe"
+	}
+	if strings.HasSuffix(name, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		for _, f := range zr.File {
+			if isBinary(f.Name) && !f.FileInfo().IsDir() {
+				r, err := f.Open()
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s: %w", name, err)
+				}
+				defer r.Close()
+				return io.ReadAll(r)
+			}
+		}
+	} else {
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		tr := tar.NewReader(gr)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			if isBinary(header.Name) && header.Typeflag == tar.TypeReg {
+				return io.ReadAll(tr)
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s doesn't contain a packwiz binary", name)
+}
+
+// replaceExecutable writes a new binary to a temporary file next to the executable at exe, which then replaces it
+func replaceExecutable(exe string, binary []byte) error {
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(exe); err == nil {
+		mode = info.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := f.Name()
+	_, err = f.Write(binary)
+	if err == nil {
+		err = f.Chmod(mode)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = renameExecutable(tempPath, exe)
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+func init() {
+	cmd.Add(selfUpdateCmd)
+
+	selfUpdateCmd.Flags().Bool("check", false, "Only check whether a newer release is available")
+	_ = viper.BindPFlag("self-update.check", selfUpdateCmd.Flags().Lookup("check"))
+	selfUpdateCmd.Flags().Bool("prerelease", false, "Allow updating to releases marked as pre-releases")
+	_ = viper.BindPFlag("self-update.prerelease", selfUpdateCmd.Flags().Lookup("prerelease"))
+	selfUpdateCmd.Flags().String("pubkey", "", "The ed25519 public key (in PEM format) to check the signature of releases with, instead of the key built into packwiz")
+	_ = viper.BindPFlag("self-update.pubkey", selfUpdateCmd.Flags().Lookup("pubkey"))
+	selfUpdateCmd.Flags().Bool("skip-signature", false, "Install a signed release even if there is no public key to check its signature with")
+	_ = viper.BindPFlag("self-update.skip-signature", selfUpdateCmd.Flags().Lookup("skip-signature"))
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package github
+
+import "os"
+
+// renameExecutable replaces the executable at exe with the file at newPath; the running process keeps using the old
+// file until it exits
+func renameExecutable(newPath string, exe string) error {
+	return os.Rename(newPath, exe)
+}
@@ -0,0 +1,184 @@
+package github
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testSelfUpdateArchive creates a release archive in the format written by GoReleaser, containing a packwiz binary
+func testSelfUpdateArchive(t *testing.T, binary []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	files := []struct {
+		name string
+		data []byte
+	}{{"README.md", []byte("readme")}, {"packwiz", binary}}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestIsDevVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		dev     bool
+	}{
+		{"dev", true},
+		{"0.0.0-20261016120000-abcdef123456", true},
+		{"0.0.0-20261016120000-abcdef123456+dirty", true},
+		{"1.2.1-0.20261016120000-abcdef123456", true},
+		{"1.2.0+dirty", true},
+		{"1.2.0", false},
+		{"1.3.0-beta.1", false},
+	}
+	for _, test := range tests {
+		if dev := isDevVersion(test.version); dev != test.dev {
+			t.Errorf("%s: expected development build %v, got %v", test.version, test.dev, dev)
+		}
+	}
+}
+
+func TestFindSelfUpdateAsset(t *testing.T) {
+	release := Release{TagName: "v1.2.0", Assets: []Asset{
+		{Name: "checksums.txt"},
+		{Name: "packwiz_1.2.0_darwin_arm64.tar.gz"},
+		{Name: "packwiz_1.2.0_linux_386.tar.gz"},
+		{Name: "packwiz_1.2.0_linux_amd64.tar.gz"},
+		{Name: "packwiz_1.2.0_windows_amd64.zip"},
+	}}
+	tests := []struct {
+		goos, goarch, expected string
+	}{
+		{"linux", "amd64", "packwiz_1.2.0_linux_amd64.tar.gz"},
+		{"linux", "386", "packwiz_1.2.0_linux_386.tar.gz"},
+		{"windows", "amd64", "packwiz_1.2.0_windows_amd64.zip"},
+		{"darwin", "arm64", "packwiz_1.2.0_darwin_arm64.tar.gz"},
+	}
+	for _, test := range tests {
+		asset, err := findSelfUpdateAsset(release, test.goos, test.goarch)
+		if err != nil {
+			t.Errorf("%s/%s: unexpected error: %v", test.goos, test.goarch, err)
+		} else if asset.Name != test.expected {
+			t.Errorf("%s/%s: expected %s, got %s", test.goos, test.goarch, test.expected, asset.Name)
+		}
+	}
+	if _, err := findSelfUpdateAsset(release, "linux", "arm64"); err == nil {
+		t.Error("Expected an error when there is no build for the platform")
+	}
+}
+
+func TestDownloadSelfUpdate(t *testing.T) {
+	binary := []byte("new packwiz binary")
+	archive := testSelfUpdateArchive(t, binary)
+	archiveHash := sha256.Sum256(archive)
+	checksums := hex.EncodeToString(archiveHash[:]) + "  packwiz_1.2.0_linux_amd64.tar.gz\n" +
+		strings.Repeat("0", 64) + "  packwiz_1.2.0_windows_amd64.zip\n"
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(checksums)))
+
+	files := map[string][]byte{
+		"/packwiz_1.2.0_linux_amd64.tar.gz": archive,
+		"/packwiz_1.2.0_windows_amd64.zip":  archive,
+		"/checksums.txt":                    []byte(checksums),
+		"/checksums.txt.sig":                []byte(signature),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+	asset := func(name string) Asset {
+		return Asset{Name: name, BrowserDownloadURL: server.URL + "/" + name}
+	}
+	signed := Release{TagName: "v1.2.0", Assets: []Asset{asset("checksums.txt"), asset("checksums.txt.sig")}}
+	unsigned := Release{TagName: "v1.2.0", Assets: []Asset{asset("checksums.txt")}}
+
+	data, err := downloadSelfUpdate(signed, asset("packwiz_1.2.0_linux_amd64.tar.gz"), publicKey, false)
+	if err != nil {
+		t.Fatalf("Failed to download update: %v", err)
+	}
+	if !bytes.Equal(data, binary) {
+		t.Errorf("Expected the binary from the archive, got %q", data)
+	}
+
+	if _, err := downloadSelfUpdate(signed, asset("packwiz_1.2.0_windows_amd64.zip"), publicKey, false); err == nil {
+		t.Error("Expected an error when the archive doesn't match its checksum")
+	}
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloadSelfUpdate(signed, asset("packwiz_1.2.0_linux_amd64.tar.gz"), otherKey, false); err == nil {
+		t.Error("Expected an error when the signature doesn't match the public key")
+	}
+	if _, err := downloadSelfUpdate(unsigned, asset("packwiz_1.2.0_linux_amd64.tar.gz"), publicKey, false); err == nil {
+		t.Error("Expected an error when a public key is given but the release isn't signed")
+	}
+	if _, err := downloadSelfUpdate(signed, asset("packwiz_1.2.0_linux_amd64.tar.gz"), nil, false); err == nil {
+		t.Error("Expected an error when the release is signed but there is no public key")
+	}
+	if _, err := downloadSelfUpdate(signed, asset("packwiz_1.2.0_linux_amd64.tar.gz"), nil, true); err != nil {
+		t.Errorf("Expected a signed release to be accepted with --skip-signature, got %v", err)
+	}
+	if _, err := downloadSelfUpdate(unsigned, asset("packwiz_1.2.0_linux_amd64.tar.gz"), nil, false); err != nil {
+		t.Errorf("Expected an unsigned release to be accepted without a public key, got %v", err)
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "packwiz")
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceExecutable(exe, []byte("new")); err != nil {
+		t.Fatalf("Failed to replace executable: %v", err)
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("Expected the executable to be replaced, got %q", data)
+	}
+	entries, err := os.ReadDir(filepath.Dir(exe))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("Expected the temporary file to be removed, found %s", entry.Name())
+		}
+	}
+}
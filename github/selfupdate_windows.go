@@ -0,0 +1,19 @@
+package github
+
+import "os"
+
+// renameExecutable replaces the executable at exe with the file at newPath. Windows doesn't allow a running executable
+// to be replaced, but does allow it to be renamed, so it is moved aside to exe.old (which is removed by the next update)
+// first.
+func renameExecutable(newPath string, exe string) error {
+	oldPath := exe + ".old"
+	_ = os.Remove(oldPath)
+	if err := os.Rename(exe, oldPath); err != nil {
+		return err
+	}
+	if err := os.Rename(newPath, exe); err != nil {
+		_ = os.Rename(oldPath, exe)
+		return err
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/dlclark/regexp2"
 	"github.com/mitchellh/mapstructure"
 	"github.com/0byte-coding/packwiz/core"
 )
@@ -15,6 +14,15 @@ type ghUpdateData struct {
 	Tag    string `mapstructure:"tag"`
 	Branch string `mapstructure:"branch"`
 	Regex  string `mapstructure:"regex"`
+	// AllowPrereleases allows updating to releases marked as pre-releases
+	AllowPrereleases bool `mapstructure:"allow-prereleases,omitempty"`
+	// TagPattern is a regular expression that the tags of releases must match
+	TagPattern string `mapstructure:"tag-pattern,omitempty"`
+	// URLTemplate is set for projects tracking the latest commit of Branch rather than releases; the file is
+	// downloaded from this URL (see expandURLTemplate)
+	URLTemplate string `mapstructure:"url-template,omitempty"`
+	// Commit is the SHA of the installed commit, for projects tracking a branch
+	Commit string `mapstructure:"commit,omitempty"`
 }
 
 type ghUpdater struct{}
@@ -25,9 +33,19 @@ func (u ghUpdater) ParseUpdate(updateUnparsed map[string]interface{}) (interface
 	return updateData, err
 }
 
+func (u ghUpdater) ProjectPage(mod *core.Mod) (string, error) {
+	rawData, ok := mod.GetParsedUpdateData("github")
+	if !ok {
+		return "", errors.New("failed to parse update metadata")
+	}
+	data := rawData.(ghUpdateData)
+	return "https://github.com/" + data.Slug, nil
+}
+
 type cachedStateStore struct {
 	Slug    string
 	Release Release
+	Asset   Asset
 }
 
 func (u ghUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateCheck, error) {
@@ -42,7 +60,12 @@ func (u ghUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 
 		data := rawData.(ghUpdateData)
 
-		newRelease, err := getLatestRelease(data.Slug, data.Branch)
+		if data.URLTemplate != "" {
+			results[i] = checkCommitUpdate(mod, data)
+			continue
+		}
+
+		newRelease, err := getLatestRelease(data)
 		if err != nil {
 			results[i] = core.UpdateCheck{Error: fmt.Errorf("failed to get latest release: %v", err)}
 			continue
@@ -53,20 +76,15 @@ func (u ghUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 			continue
 		}
 
-		expr := regexp2.MustCompile(data.Regex, 0)
-
 		if len(newRelease.Assets) == 0 {
 			results[i] = core.UpdateCheck{Error: errors.New("new release doesn't have any assets")}
 			continue
 		}
 
-		var newFiles []Asset
-
-		for _, v := range newRelease.Assets {
-			bl, _ := expr.MatchString(v.Name)
-			if bl {
-				newFiles = append(newFiles, v)
-			}
+		newFiles, err := newRelease.matchAssets(data.Regex)
+		if err != nil {
+			results[i] = core.UpdateCheck{Error: err}
+			continue
 		}
 
 		if len(newFiles) == 0 {
@@ -75,8 +93,11 @@ func (u ghUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 		}
 
 		if len(newFiles) > 1 {
-			// TODO: also print file names
-			results[i] = core.UpdateCheck{Error: errors.New("release has more than one asset matching regex")}
+			names := make([]string, len(newFiles))
+			for j, v := range newFiles {
+				names[j] = v.Name
+			}
+			results[i] = core.UpdateCheck{Error: fmt.Errorf("release has more than one asset matching regex (%s); re-add it with a more specific --asset-pattern", strings.Join(names, ", "))}
 			continue
 		}
 
@@ -85,26 +106,55 @@ func (u ghUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 		results[i] = core.UpdateCheck{
 			UpdateAvailable: true,
 			UpdateString:    mod.FileName + " -> " + newFile.Name,
-			CachedState:     cachedStateStore{data.Slug, newRelease},
+			CurrentVersion:  data.Tag,
+			NewVersion:      newRelease.TagName,
+			CachedState:     cachedStateStore{data.Slug, newRelease, newFile},
 		}
 	}
 
 	return results, nil
 }
 
+// checkCommitUpdate checks for a new commit of the branch tracked by a project
+func checkCommitUpdate(mod *core.Mod, data ghUpdateData) core.UpdateCheck {
+	commit, err := fetchBranchCommit(data.Slug, data.Branch)
+	if err != nil {
+		return core.UpdateCheck{Error: fmt.Errorf("failed to get latest commit: %v", err)}
+	}
+	if commit == data.Commit {
+		return core.UpdateCheck{UpdateAvailable: false}
+	}
+
+	file, err := getCommitFile(data, commit)
+	if err != nil {
+		return core.UpdateCheck{Error: err}
+	}
+	return core.UpdateCheck{
+		UpdateAvailable: true,
+		UpdateString:    mod.FileName + " (" + shortCommit(data.Commit) + ") -> " + file.FileName + " (" + shortCommit(commit) + ")",
+		CurrentVersion:  shortCommit(data.Commit),
+		NewVersion:      shortCommit(commit),
+		CachedState:     file,
+	}
+}
+
 func (u ghUpdater) DoUpdate(mods []*core.Mod, cachedState []interface{}) error {
 	for i, mod := range mods {
-		modState := cachedState[i].(cachedStateStore)
-		var release = modState.Release
-
-		// yes, this is duplicated - i guess we should just cache asset + tag instead of entire release...?
-		var file = release.Assets[0]
-		for _, v := range release.Assets {
-			if strings.HasSuffix(v.Name, ".jar") {
-				file = v
+		if file, ok := cachedState[i].(commitFile); ok {
+			mod.FileName = file.FileName
+			mod.Download = core.ModDownload{
+				URL:        file.URL,
+				HashFormat: "sha256",
+				Hash:       file.Hash,
 			}
+			mod.Update["github"]["commit"] = file.Commit
+			continue
 		}
 
+		modState := cachedState[i].(cachedStateStore)
+		var release = modState.Release
+		var file = modState.Asset
+
 		hash, err := file.getSha256()
 		if err != nil {
 			return err
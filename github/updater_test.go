@@ -0,0 +1,80 @@
+package github
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// testReleasesJSON is a releases list as returned by the GitHub API, newest first
+const testReleasesJSON = `[
+	{"tag_name": "v2.1.0-beta.1", "target_commitish": "main", "prerelease": true},
+	{"tag_name": "v2.0.0", "target_commitish": "main", "prerelease": false},
+	{"tag_name": "1.20.1-v1.9.0", "target_commitish": "1.20.1", "prerelease": false},
+	{"tag_name": "v1.9.0-rc.1", "target_commitish": "main", "prerelease": true},
+	{"tag_name": "v1.8.0", "target_commitish": "main", "prerelease": false}
+]`
+
+func TestSelectLatestRelease(t *testing.T) {
+	var releases []Release
+	if err := json.Unmarshal([]byte(testReleasesJSON), &releases); err != nil {
+		t.Fatalf("Failed to parse releases: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		data     ghUpdateData
+		expected string
+	}{
+		{"skips pre-releases by default", ghUpdateData{}, "v2.0.0"},
+		{"allows pre-releases", ghUpdateData{AllowPrereleases: true}, "v2.1.0-beta.1"},
+		{"filters by tag", ghUpdateData{TagPattern: `^1\.20\.1-`}, "1.20.1-v1.9.0"},
+		{"filters by tag and pre-release", ghUpdateData{TagPattern: `^v1\.`, AllowPrereleases: true}, "v1.9.0-rc.1"},
+		{"filters by tag without pre-releases", ghUpdateData{TagPattern: `^v1\.`}, "v1.8.0"},
+		{"filters by branch", ghUpdateData{Branch: "1.20.1"}, "1.20.1-v1.9.0"},
+	}
+	for _, test := range tests {
+		release, err := selectLatestRelease(releases, test.data)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if release.TagName != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, release.TagName)
+		}
+	}
+
+	if _, err := selectLatestRelease(releases, ghUpdateData{TagPattern: `^v3\.`}); err == nil {
+		t.Error("Expected an error when no release matches")
+	}
+	if _, err := selectLatestRelease(nil, ghUpdateData{}); err == nil {
+		t.Error("Expected an error when there are no releases")
+	}
+}
+
+func TestUpdateDataRoundTrip(t *testing.T) {
+	data := ghUpdateData{Slug: "owner/repo", Tag: "v2.0.0", Regex: `\.jar$`}
+	m, err := data.ToMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["allow-prereleases"]; ok {
+		t.Error("Expected allow-prereleases to be omitted when false")
+	}
+	if _, ok := m["tag-pattern"]; ok {
+		t.Error("Expected tag-pattern to be omitted when empty")
+	}
+
+	data.AllowPrereleases = true
+	data.TagPattern = `^v2\.`
+	m, err = data.ToMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ghUpdater{}.ParseUpdate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.(ghUpdateData) != data {
+		t.Errorf("Expected %+v, got %+v", data, parsed)
+	}
+}
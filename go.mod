@@ -31,6 +31,8 @@ require (
 	codeberg.org/jmansfield/go-modrinth v0.6.0
 	github.com/spf13/pflag v1.0.7
 	github.com/unascribed/FlexVer/go/flexver v1.0.0
+	golang.org/x/mod v0.27.0
+	golang.org/x/time v0.12.0
 )
 
 require (
@@ -1,6 +1,7 @@
 package migrate
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"slices"
@@ -11,41 +12,45 @@ import (
 )
 
 var loaderCommand = &cobra.Command{
-	Use:   "loader [version|latest|recommended]",
+	Use:   "loader [loader] [version|latest|recommended]",
 	Short: "Migrate your modloader version to a newer version.",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		modpack, err := core.LoadPack()
+	Long: `Migrate your modloader version to a newer version.
+If a loader is given (e.g. neoforge) and the pack uses a different loader, the pack is switched to that loader.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modpack, err := loadMigratePack()
 		if err != nil {
-			// Check if it's a no such file or directory error
-			if os.IsNotExist(err) {
-				fmt.Println("No pack.toml file found, run 'packwiz init' to create one!")
-				os.Exit(1)
-			}
-			fmt.Printf("Error loading pack: %s\n", err)
-			os.Exit(1)
+			return err
 		}
 		var currentLoaders = modpack.GetLoaders()
+		version := args[len(args)-1]
+		if len(args) == 2 {
+			currentLoaders, err = switchPackLoader(modpack, currentLoaders, args[0])
+			if err != nil {
+				return err
+			}
+		}
 		// Do some sanity checks on the current loader slice
 		if len(currentLoaders) == 0 {
-			fmt.Println("No loader is currently set in your pack.toml!")
-			os.Exit(1)
+			return errors.New("no loader is currently set in your pack.toml")
 		} else if len(currentLoaders) > 1 {
-			fmt.Println("You have multiple loaders set in your pack.toml, this is not supported!")
-			os.Exit(1)
+			return errors.New("you have multiple loaders set in your pack.toml, this is not supported")
 		}
 		// Get the Minecraft version for the pack
 		mcVersion, err := modpack.GetMCVersion()
 		if err != nil {
-			fmt.Printf("Error getting Minecraft version: %s\n", err)
-			os.Exit(1)
+			return fmt.Errorf("error getting Minecraft version: %w", err)
 		}
-		if args[0] == "latest" {
+		if version == "latest" {
 			fmt.Println("Updating to latest loader version")
 			// We'll be updating to the latest loader version
 			for _, loader := range currentLoaders {
-				_, latest, gottenLoader := getVersionsForLoader(loader, mcVersion)
-				if !updatePackToVersion(latest, modpack, gottenLoader) {
+				_, latest, gottenLoader, err := getVersionsForLoader(loader, mcVersion)
+				if err != nil {
+					return err
+				}
+				if err := updatePackToVersion(latest, modpack, gottenLoader); err != nil {
+					fmt.Println(err)
 					continue
 				}
 				// Write the pack to disk
@@ -55,55 +60,57 @@ var loaderCommand = &cobra.Command{
 					continue
 				}
 			}
-		} else if args[0] == "recommended" {
+			return nil
+		} else if version == "recommended" {
 			// TODO: Figure out a way to get the recommended version, this is Forge only
 			// Ensure we're on Forge
 			if !slices.Contains(currentLoaders, "forge") {
-				fmt.Println("The recommended loader version is only available on Forge!")
-				os.Exit(1)
+				return errors.New("the recommended loader version is only available on Forge")
 			}
 			// We'll be updating to the recommended loader version
 			recommendedVer := core.GetForgeRecommended(mcVersion)
 			if recommendedVer == "" {
-				fmt.Println("Error getting recommended Forge version!")
-				os.Exit(1)
+				return errors.New("error getting recommended Forge version")
 			}
-			if ok := updatePackToVersion(recommendedVer, modpack, core.ModLoaders["forge"]); !ok {
-				os.Exit(1)
-			}
-			// Write the pack to disk
-			err = modpack.Write()
-			if err != nil {
-				fmt.Printf("Error writing pack.toml: %s", err)
-				os.Exit(1)
+			if err := updatePackToVersion(recommendedVer, modpack, core.ModLoaders["forge"]); err != nil {
+				return err
 			}
 		} else {
 			fmt.Println("Updating to explicit loader version")
 			// This one is easy :D
-			versions, _, loader := getVersionsForLoader(currentLoaders[0], mcVersion)
+			versions, _, loader, err := getVersionsForLoader(currentLoaders[0], mcVersion)
+			if err != nil {
+				return err
+			}
 			// Check if the loader happens to be Forge/NeoForge, since there's two version formats
 			if loader.Name == "forge" || loader.Name == "neoforge" {
-				wantedVersion := cmdshared.GetRawForgeVersion(args[0])
-				validateVersion(versions, wantedVersion, loader)
-				_ = updatePackToVersion(wantedVersion, modpack, loader)
+				wantedVersion := cmdshared.GetRawLoaderVersion(loader.Name, mcVersion, version)
+				if err := validateVersion(versions, wantedVersion, loader); err != nil {
+					return err
+				}
+				if err := updatePackToVersion(wantedVersion, modpack, loader); err != nil {
+					fmt.Println(err)
+				}
 			} else if loader.Name == "liteloader" {
 				// These are weird and just have a MC version
 				fmt.Println("LiteLoader only has 1 version per Minecraft version so we're unable to update!")
-				os.Exit(0)
+				return nil
 			} else {
 				// We're on Fabric or quilt
-				validateVersion(versions, args[0], loader)
-				if ok := updatePackToVersion(args[0], modpack, loader); !ok {
-					os.Exit(1)
+				if err := validateVersion(versions, version, loader); err != nil {
+					return err
+				}
+				if err := updatePackToVersion(version, modpack, loader); err != nil {
+					return err
 				}
-			}
-			// Write the pack to disk
-			err = modpack.Write()
-			if err != nil {
-				fmt.Printf("Error writing pack.toml: %s\n", err)
-				os.Exit(1)
 			}
 		}
+		// Write the pack to disk
+		err = modpack.Write()
+		if err != nil {
+			return fmt.Errorf("error writing pack.toml: %w", err)
+		}
+		return nil
 	},
 }
 
@@ -111,35 +118,63 @@ func init() {
 	migrateCmd.AddCommand(loaderCommand)
 }
 
-func getVersionsForLoader(loader, mcVersion string) ([]string, string, core.ModLoaderComponent) {
+// loadMigratePack loads the pack to be migrated, suggesting packwiz init if there isn't one
+func loadMigratePack() (core.Pack, error) {
+	modpack, err := core.LoadPack()
+	if err != nil {
+		// Check if it's a no such file or directory error
+		if os.IsNotExist(err) {
+			return core.Pack{}, fmt.Errorf("no pack.toml file found, run 'packwiz init' to create one: %w", err)
+		}
+		return core.Pack{}, fmt.Errorf("error loading pack: %w", err)
+	}
+	return modpack, nil
+}
+
+// switchPackLoader removes the pack's current loaders if they differ from the given loader, returning the loaders
+// that the pack should be migrated with
+func switchPackLoader(modpack core.Pack, currentLoaders []string, loader string) ([]string, error) {
+	gottenLoader, ok := core.ModLoaders[loader]
+	if !ok {
+		return nil, cmdshared.WithExitCode(fmt.Errorf("unknown loader %s", loader), cmdshared.ExitUsage)
+	}
+	if slices.Equal(currentLoaders, []string{loader}) {
+		return currentLoaders, nil
+	}
+	for _, current := range currentLoaders {
+		fmt.Printf("Switching from %s to %s\n", core.ComponentToFriendlyName(current), gottenLoader.FriendlyName)
+		delete(modpack.Versions, current)
+	}
+	return []string{loader}, nil
+}
+
+func getVersionsForLoader(loader, mcVersion string) ([]string, string, core.ModLoaderComponent, error) {
 	gottenLoader, ok := core.ModLoaders[loader]
 	if !ok {
-		fmt.Printf("Unknown loader %s\n", loader)
-		os.Exit(1)
+		return nil, "", core.ModLoaderComponent{}, cmdshared.WithExitCode(fmt.Errorf("unknown loader %s", loader), cmdshared.ExitUsage)
 	}
 	versions, latestVersion, err := gottenLoader.VersionListGetter(mcVersion)
 	if err != nil {
-		fmt.Printf("Error getting version list for %s: %s\n", gottenLoader.FriendlyName, err)
-		os.Exit(1)
+		return nil, "", gottenLoader, fmt.Errorf("error getting version list for %s: %w", gottenLoader.FriendlyName, err)
 	}
-	return versions, latestVersion, gottenLoader
+	return versions, latestVersion, gottenLoader, nil
 }
 
-func validateVersion(versions []string, version string, gottenLoader core.ModLoaderComponent) {
+func validateVersion(versions []string, version string, gottenLoader core.ModLoaderComponent) error {
 	if !slices.Contains(versions, version) {
-		fmt.Printf("Version %s is not a valid version for %s\n", version, gottenLoader.FriendlyName)
-		os.Exit(1)
+		return cmdshared.WithExitCode(fmt.Errorf("version %s is not a valid version for %s", version, gottenLoader.FriendlyName), cmdshared.ExitNotFound)
 	}
+	return nil
 }
 
-func updatePackToVersion(version string, modpack core.Pack, loader core.ModLoaderComponent) bool {
+// updatePackToVersion sets the version of a loader in the pack, returning an error if it is already on that version
+func updatePackToVersion(version string, modpack core.Pack, loader core.ModLoaderComponent) error {
 	// Check if the version is already set
 	if version == modpack.Versions[loader.Name] {
-		fmt.Printf("%s is already on version %s!\n", loader.FriendlyName, version)
-		return false
+		return fmt.Errorf("%s is already on version %s", loader.FriendlyName, version)
 	}
 	// Set the latest version
 	modpack.Versions[loader.Name] = version
 	fmt.Printf("Updated %s to version %s\n", loader.FriendlyName, version)
-	return true
+	return nil
 }
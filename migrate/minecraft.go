@@ -2,12 +2,12 @@ package migrate
 
 import (
 	"fmt"
-	packCmd "github.com/0byte-coding/packwiz/cmd"
 	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"os"
+	"slices"
+	"strings"
 )
 
 var minecraftCommand = &cobra.Command{
@@ -15,56 +15,174 @@ var minecraftCommand = &cobra.Command{
 	Short:   "Migrate your Minecraft version to a newer version.",
 	Aliases: []string{"mc"},
 	Args:    cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		modpack, err := core.LoadPack()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modpack, err := loadMigratePack()
 		if err != nil {
-			// Check if it's a no such file or directory error
-			if os.IsNotExist(err) {
-				fmt.Println("No pack.toml file found, run 'packwiz init' to create one!")
-				os.Exit(1)
-			}
-			fmt.Printf("Error loading pack: %s\n", err)
-			os.Exit(1)
+			return err
 		}
 		currentVersion, err := modpack.GetMCVersion()
 		if err != nil {
-			fmt.Printf("Error getting Minecraft version from pack: %s\n", err)
-			os.Exit(1)
+			return fmt.Errorf("error getting Minecraft version from pack: %w", err)
 		}
 		wantedMCVersion := args[0]
 		if wantedMCVersion == currentVersion {
 			fmt.Printf("Minecraft version is already %s!\n", wantedMCVersion)
-			os.Exit(0)
+			return nil
 		}
 		mcVersions, err := cmdshared.GetValidMCVersions()
 		if err != nil {
-			fmt.Printf("Error getting Minecraft versions: %s\n", err)
-			os.Exit(1)
+			return fmt.Errorf("error getting Minecraft versions: %w", err)
+		}
+		err = mcVersions.CheckValid(wantedMCVersion)
+		if err != nil {
+			return err
 		}
-		mcVersions.CheckValid(wantedMCVersion)
 		// Set the version in the pack
 		modpack.Versions["minecraft"] = wantedMCVersion
+
+		// Check which mods have versions for the new Minecraft version before writing the pack
+		index, err := modpack.LoadIndex()
+		if err != nil {
+			return fmt.Errorf("error loading index: %w", err)
+		}
+		fmt.Printf("Checking mods for versions compatible with Minecraft %s...\n", wantedMCVersion)
+		updatableFiles, cachedStates, err := checkModCompatibility(modpack, index, wantedMCVersion)
+		if err != nil {
+			return err
+		}
+
+		if viper.GetBool("migrate.minecraft.update") {
+			updateCompatibleMods(&index, updatableFiles, cachedStates)
+			err = index.Write()
+			if err != nil {
+				return fmt.Errorf("error writing index: %w", err)
+			}
+			err = modpack.UpdateIndexHash()
+			if err != nil {
+				return fmt.Errorf("error updating index hash: %w", err)
+			}
+		} else if len(updatableFiles) > 0 {
+			fmt.Println("Some mods have new versions for this Minecraft version; use --update or run 'packwiz update --all' to update them")
+		}
+
 		// Write the pack to disk
 		err = modpack.Write()
 		if err != nil {
-			fmt.Printf("Error writing pack.toml: %s\n", err)
-			os.Exit(1)
+			return fmt.Errorf("error writing pack.toml: %w", err)
 		}
 		fmt.Printf("Successfully updated Minecraft version to %s\n", wantedMCVersion)
 		// Prompt the user if they want to update the loader too while they're at it.
-		if cmdshared.PromptYesNo("Would you like to update your loader version to the latest version for this Minecraft version? [Y/n] ") {
-			// We'll run the loader command to update to latest
-			loaderCommand.Run(loaderCommand, []string{"latest"})
+		updateLoader, err := cmdshared.PromptYesNo("Would you like to update your loader version to the latest version for this Minecraft version? [Y/n] ")
+		if err != nil {
+			return err
 		}
-		// Prompt the user to update their mods too.
-		if cmdshared.PromptYesNo("Would you like to update your mods to the latest versions for this Minecraft version? [Y/n] ") {
-			// Run the update command
-			viper.Set("update.all", true)
-			packCmd.UpdateCmd.Run(packCmd.UpdateCmd, []string{})
+		if updateLoader {
+			// We'll run the loader command to update to latest
+			return loaderCommand.RunE(loaderCommand, []string{"latest"})
 		}
+		return nil
 	},
 }
 
+// checkModCompatibility checks every mod with an update system for a version compatible with the given pack, and
+// prints the mods that don't have one. Mods that need updating to a compatible version are returned, keyed by
+// update system, along with the cached state to update them with.
+func checkModCompatibility(modpack core.Pack, index core.Index, mcVersion string) (map[string][]*core.Mod, map[string][]interface{}, error) {
+	mods, err := index.LoadAllMods()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading mods: %w", err)
+	}
+
+	var unchecked []string
+	filesWithUpdater := make(map[string][]*core.Mod)
+	for _, modData := range mods {
+		updaterFound := false
+		for k := range modData.Update {
+			if _, ok := core.Updaters[k]; ok {
+				filesWithUpdater[k] = append(filesWithUpdater[k], modData)
+				updaterFound = true
+				break
+			}
+		}
+		if !updaterFound {
+			unchecked = append(unchecked, modData.Name)
+		}
+	}
+
+	var incompatible []string
+	updatableFiles := make(map[string][]*core.Mod)
+	cachedStates := make(map[string][]interface{})
+	for k, v := range filesWithUpdater {
+		checks, err := core.Updaters[k].CheckUpdate(v, modpack)
+		if err != nil {
+			fmt.Printf("Failed to check %s files: %s\n", k, err)
+			for _, modData := range v {
+				unchecked = append(unchecked, modData.Name)
+			}
+			continue
+		}
+		for i, check := range checks {
+			if check.Error != nil {
+				// Only show the first line, as some errors include suggestions that don't apply here
+				reason, _, _ := strings.Cut(check.Error.Error(), "\n")
+				incompatible = append(incompatible, fmt.Sprintf("%s (%s)", v[i].Name, reason))
+				continue
+			}
+			if check.UpdateAvailable {
+				if v[i].Pin {
+					fmt.Printf("%s is pinned, so it won't be updated to %s\n", v[i].Name, check.NewVersion)
+					continue
+				}
+				updatableFiles[k] = append(updatableFiles[k], v[i])
+				cachedStates[k] = append(cachedStates[k], check.CachedState)
+			}
+		}
+	}
+
+	if len(incompatible) > 0 {
+		slices.Sort(incompatible)
+		fmt.Printf("Warning: %d mods don't have a version for Minecraft %s:\n", len(incompatible), mcVersion)
+		for _, v := range incompatible {
+			fmt.Println("  " + v)
+		}
+	}
+	if len(unchecked) > 0 {
+		slices.Sort(unchecked)
+		fmt.Printf("Couldn't check %d mods without a supported update system: %s\n", len(unchecked), strings.Join(unchecked, ", "))
+	}
+	if len(incompatible) == 0 && len(unchecked) == 0 {
+		fmt.Printf("All mods have a version for Minecraft %s!\n", mcVersion)
+	}
+	return updatableFiles, cachedStates, nil
+}
+
+// updateCompatibleMods updates the given mods using the state from checkModCompatibility, and refreshes them in the index
+func updateCompatibleMods(index *core.Index, updatableFiles map[string][]*core.Mod, cachedStates map[string][]interface{}) {
+	for k, v := range updatableFiles {
+		err := core.Updaters[k].DoUpdate(v, cachedStates[k])
+		if err != nil {
+			fmt.Println(err.Error())
+			continue
+		}
+		for _, modData := range v {
+			format, hash, err := modData.Write()
+			if err != nil {
+				fmt.Println(err.Error())
+				continue
+			}
+			err = index.RefreshFileWithHash(modData.GetFilePath(), format, hash, true)
+			if err != nil {
+				fmt.Println(err.Error())
+				continue
+			}
+			fmt.Printf("Updated %s to %s\n", modData.Name, modData.FileName)
+		}
+	}
+}
+
 func init() {
 	migrateCmd.AddCommand(minecraftCommand)
+
+	minecraftCommand.Flags().Bool("update", false, "Update mods to their versions for the new Minecraft version")
+	_ = viper.BindPFlag("migrate.minecraft.update", minecraftCommand.Flags().Lookup("update"))
 }
@@ -0,0 +1,72 @@
+package modrinth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
+)
+
+// versionTypes are the Modrinth version types, from most to least stable
+var versionTypes = []string{"release", "beta", "alpha"}
+
+// versionChannel restricts the versions that are selected to those of a version type (release, beta or alpha) or a
+// more stable one
+type versionChannel struct {
+	// Name is the least stable version type allowed; if empty, versions of all types are allowed, as metadata files
+	// created before channels were stored should keep updating as they did before
+	Name string
+	// AllowBeta allows beta versions on the release channel if a project has no releases
+	AllowBeta bool
+}
+
+// validateChannel returns an error if name isn't the name of a version type
+func validateChannel(name string) error {
+	if !slices.Contains(versionTypes, name) {
+		return fmt.Errorf("unknown channel %s, must be one of release, beta or alpha", name)
+	}
+	return nil
+}
+
+// allows returns true if versions of the given version type are on this channel
+func (c versionChannel) allows(versionType string) bool {
+	if c.Name == "" {
+		return true
+	}
+	limit := slices.Index(versionTypes, c.Name)
+	i := slices.Index(versionTypes, versionType)
+	return i >= 0 && i <= limit
+}
+
+// filter returns the versions on this channel. If there aren't any on the release channel and AllowBeta is set, beta
+// versions are returned as well.
+func (c versionChannel) filter(versions []*modrinthApi.Version, name string) ([]*modrinthApi.Version, error) {
+	if c.Name == "" || len(versions) == 0 {
+		return versions, nil
+	}
+	onChannel := func(channel versionChannel) []*modrinthApi.Version {
+		var filtered []*modrinthApi.Version
+		for _, v := range versions {
+			if v.VersionType != nil && channel.allows(*v.VersionType) {
+				filtered = append(filtered, v)
+			}
+		}
+		return filtered
+	}
+
+	filtered := onChannel(c)
+	if len(filtered) == 0 && c.Name == "release" && c.AllowBeta {
+		filtered = onChannel(versionChannel{Name: "beta"})
+		if len(filtered) > 0 {
+			fmt.Printf("No release versions of %s found, using a beta version\n", name)
+		}
+	}
+	if len(filtered) == 0 {
+		if c.Name == "release" && !c.AllowBeta {
+			return nil, errors.New("no release versions found, but there are beta or alpha versions (use --allow-beta or --channel to allow them)")
+		}
+		return nil, fmt.Errorf("no versions found on the %s channel, but there are less stable versions (use --channel to allow them)", c.Name)
+	}
+	return filtered, nil
+}
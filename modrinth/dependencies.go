@@ -0,0 +1,190 @@
+package modrinth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// defaultDependencyJobs is the default number of dependency versions looked up in parallel; requests are still limited
+// by the shared rate limited client
+const defaultDependencyJobs = 4
+
+// dependencySource fetches the data needed to resolve dependencies
+type dependencySource interface {
+	getVersions(ids []string) ([]*modrinthApi.Version, error)
+	getProjects(ids []string) ([]*modrinthApi.Project, error)
+	getLatestVersion(project *modrinthApi.Project) (*modrinthApi.Version, error)
+}
+
+// apiDependencySource fetches dependency data from the Modrinth API, using the shared rate limited client
+type apiDependencySource struct {
+	pack    core.Pack
+	channel versionChannel
+}
+
+func (s apiDependencySource) getVersions(ids []string) ([]*modrinthApi.Version, error) {
+	return mrDefaultClient.Versions.GetMultiple(ids)
+}
+
+func (s apiDependencySource) getProjects(ids []string) ([]*modrinthApi.Project, error) {
+	return mrDefaultClient.Projects.GetMultiple(ids)
+}
+
+func (s apiDependencySource) getLatestVersion(project *modrinthApi.Project) (*modrinthApi.Version, error) {
+	return getLatestVersion(*project.ID, *project.Title, s.pack, isDatapackProject(project), allowFallbackLoaderFlag, s.channel)
+}
+
+// hasRequiredDependencies returns true if any of the given dependencies are required projects or versions
+func hasRequiredDependencies(deps []*modrinthApi.Dependency) bool {
+	return slices.ContainsFunc(deps, func(dep *modrinthApi.Dependency) bool {
+		return dep.DependencyType != nil && *dep.DependencyType == "required" && (dep.VersionID != nil || dep.ProjectID != nil)
+	})
+}
+
+// dependencyResolver finds the required dependencies of a version that aren't already in the pack
+type dependencyResolver struct {
+	source dependencySource
+	// mapID transforms dependency project IDs, e.g. to replace Fabric API with QFAPI in Quilt packs
+	mapID func(id string) string
+	// installed is the list of project IDs that are already in the pack
+	installed []string
+	// jobs is the number of latest versions looked up in parallel
+	jobs int
+}
+
+// resolve returns the metadata of every project required (directly or transitively) by the given dependencies, which
+// are fetched once each. Projects in visited aren't resolved, and resolved projects are added to it.
+func (r dependencyResolver) resolve(deps []*modrinthApi.Dependency, visited map[string]bool) ([]depMetadataStore, error) {
+	var depMetadata []depMetadataStore
+	var depProjectIDPendingQueue []string
+	var depVersionIDPendingQueue []string
+
+	queueRequiredDeps := func(deps []*modrinthApi.Dependency) {
+		for _, dep := range deps {
+			if dep.DependencyType != nil && *dep.DependencyType == "required" {
+				if dep.VersionID != nil {
+					depVersionIDPendingQueue = append(depVersionIDPendingQueue, *dep.VersionID)
+				} else {
+					if dep.ProjectID != nil {
+						depProjectIDPendingQueue = append(depProjectIDPendingQueue, r.mapID(*dep.ProjectID))
+					}
+				}
+			}
+		}
+	}
+	queueRequiredDeps(deps)
+
+	// Dependencies are resolved one level at a time, so each project is only looked up once even when it is
+	// depended on by several projects in the same level
+	cycles := 0
+	for len(depProjectIDPendingQueue)+len(depVersionIDPendingQueue) > 0 && cycles < maxCycles {
+		// Look up version IDs
+		if len(depVersionIDPendingQueue) > 0 {
+			depVersions, err := r.source.getVersions(depVersionIDPendingQueue)
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve dependency versions (this may be due to rate limiting): %w", err)
+			}
+			for _, v := range depVersions {
+				// Add project ID to queue
+				depProjectIDPendingQueue = append(depProjectIDPendingQueue, r.mapID(*v.ProjectID))
+			}
+			depVersionIDPendingQueue = depVersionIDPendingQueue[:0]
+		}
+
+		// Remove installed and already visited project IDs from dep queue
+		i := 0
+		for _, id := range depProjectIDPendingQueue {
+			if !slices.Contains(r.installed, id) && !visited[id] {
+				depProjectIDPendingQueue[i] = id
+				i++
+			}
+		}
+		depProjectIDPendingQueue = depProjectIDPendingQueue[:i]
+
+		// Clean up duplicates from dep queue (from deps on both QFAPI + FAPI, or shared transitive dependencies)
+		slices.Sort(depProjectIDPendingQueue)
+		depProjectIDPendingQueue = slices.Compact(depProjectIDPendingQueue)
+
+		if len(depProjectIDPendingQueue) == 0 {
+			break
+		}
+		for _, id := range depProjectIDPendingQueue {
+			visited[id] = true
+		}
+		depProjects, err := r.source.getProjects(depProjectIDPendingQueue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve dependency projects (this may be due to rate limiting): %w", err)
+		}
+		depProjectIDPendingQueue = depProjectIDPendingQueue[:0]
+
+		for _, project := range depProjects {
+			if project.ID == nil {
+				return nil, errors.New("failed to get dependency data: invalid response")
+			}
+		}
+		// Get latest version - could reuse version lookup data but it's not as easy (particularly since the version won't necessarily be the latest)
+		latestVersions, errs := r.getLatestVersions(depProjects)
+		for i, project := range depProjects {
+			if errs[i] != nil {
+				fmt.Printf("Failed to get latest version of dependency %v: %v\n", *project.Title, errs[i])
+				continue
+			}
+			latestVersion := latestVersions[i]
+
+			// Resolve the dependencies of this dependency in the next cycle
+			queueRequiredDeps(latestVersion.Dependencies)
+
+			var file = latestVersion.Files[0]
+			// Prefer the primary file
+			for _, v := range latestVersion.Files {
+				if *v.Primary {
+					file = v
+				}
+			}
+
+			depMetadata = append(depMetadata, depMetadataStore{
+				projectInfo: project,
+				versionInfo: latestVersion,
+				fileInfo:    file,
+			})
+		}
+
+		cycles++
+	}
+	if cycles >= maxCycles {
+		return nil, errors.New("dependencies recurse too deeply, try increasing maxCycles")
+	}
+	return depMetadata, nil
+}
+
+// getLatestVersions looks up the latest versions of the given projects using a pool of workers, returning them in the
+// same order as the projects, along with an error for each project whose latest version couldn't be found
+func (r dependencyResolver) getLatestVersions(projects []*modrinthApi.Project) ([]*modrinthApi.Version, []error) {
+	versions := make([]*modrinthApi.Version, len(projects))
+	errs := make([]error, len(projects))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(r.jobs, 1), max(len(projects), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				versions[i], errs[i] = r.source.getLatestVersion(projects[i])
+				if errs[i] == nil && len(versions[i].Files) == 0 {
+					errs[i] = errors.New("version doesn't have any files attached")
+				}
+			}
+		}()
+	}
+	for i := range projects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return versions, errs
+}
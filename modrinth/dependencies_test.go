@@ -0,0 +1,104 @@
+package modrinth
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
+)
+
+// fakeDependencySource serves a dependency graph of projects, counting how many times each project is looked up
+type fakeDependencySource struct {
+	graph map[string][]string
+
+	mu             sync.Mutex
+	projectFetches map[string]int
+	versionFetches map[string]int
+}
+
+func (s *fakeDependencySource) getVersions(ids []string) ([]*modrinthApi.Version, error) {
+	var versions []*modrinthApi.Version
+	for _, id := range ids {
+		projectID := id
+		versions = append(versions, &modrinthApi.Version{ProjectID: &projectID})
+	}
+	return versions, nil
+}
+
+func (s *fakeDependencySource) getProjects(ids []string) ([]*modrinthApi.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var projects []*modrinthApi.Project
+	for _, id := range ids {
+		s.projectFetches[id]++
+		projectID := id
+		projects = append(projects, &modrinthApi.Project{ID: &projectID, Title: &projectID})
+	}
+	return projects, nil
+}
+
+func (s *fakeDependencySource) getLatestVersion(project *modrinthApi.Project) (*modrinthApi.Version, error) {
+	s.mu.Lock()
+	s.versionFetches[*project.ID]++
+	s.mu.Unlock()
+
+	primary := true
+	filename := *project.ID + ".jar"
+	return &modrinthApi.Version{
+		ProjectID:    project.ID,
+		Dependencies: requiredDeps(s.graph[*project.ID]...),
+		Files:        []*modrinthApi.File{{Primary: &primary, Filename: &filename}},
+	}, nil
+}
+
+func requiredDeps(ids ...string) []*modrinthApi.Dependency {
+	var deps []*modrinthApi.Dependency
+	for _, id := range ids {
+		projectID := id
+		depType := "required"
+		deps = append(deps, &modrinthApi.Dependency{ProjectID: &projectID, DependencyType: &depType})
+	}
+	return deps
+}
+
+func TestResolveDependenciesDiamond(t *testing.T) {
+	// top depends on left and right, which both depend on bottom; right also depends on bottom's dependency base
+	source := &fakeDependencySource{
+		graph: map[string][]string{
+			"top":    {"left", "right"},
+			"left":   {"bottom"},
+			"right":  {"bottom", "base"},
+			"bottom": {"base"},
+		},
+		projectFetches: map[string]int{},
+		versionFetches: map[string]int{},
+	}
+	resolver := dependencyResolver{
+		source:    source,
+		mapID:     func(id string) string { return id },
+		installed: []string{"installed"},
+		jobs:      4,
+	}
+	deps, err := resolver.resolve(requiredDeps("left", "right", "installed"), map[string]bool{"top": true})
+	if err != nil {
+		t.Fatalf("Failed to resolve dependencies: %v", err)
+	}
+
+	var resolved []string
+	for _, dep := range deps {
+		resolved = append(resolved, *dep.projectInfo.ID)
+	}
+	slices.Sort(resolved)
+	if !slices.Equal(resolved, []string{"base", "bottom", "left", "right"}) {
+		t.Errorf("Unexpected resolved dependencies %v", resolved)
+	}
+	for _, id := range []string{"left", "right", "bottom", "base"} {
+		if source.projectFetches[id] != 1 || source.versionFetches[id] != 1 {
+			t.Errorf("Expected %s to be fetched once, got %d project and %d version fetches", id, source.projectFetches[id], source.versionFetches[id])
+		}
+	}
+	if source.projectFetches["top"]+source.projectFetches["installed"] > 0 {
+		t.Errorf("Expected visited and installed projects not to be fetched, got %v", source.projectFetches)
+	}
+}
@@ -3,13 +3,16 @@ package modrinth
 import (
 	"archive/zip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"slices"
 	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/0byte-coding/packwiz/cmd"
 	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/spf13/viper"
 
@@ -21,56 +24,100 @@ import (
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export the current modpack into a .mrpack for Modrinth",
-	Args:  cobra.NoArgs,
+	Long: `Export the current modpack into a .mrpack for Modrinth.
+Files other than metadata files (such as configs) can be selected with the patterns in [export.overrides] in pack.toml
+(include, exclude, client-include, client-exclude, server-include and server-exclude) and --override-include and
+--override-exclude; files only included for one side are put in client-overrides or server-overrides.
+With --since, a patch pack is exported instead: it only contains the files that were added or changed since the given
+old version of the pack (the path to its index file, a folder containing index.toml, or a git revision, as with
+packwiz diff), and lists the files to remove in packwiz-patch.json. A patch pack is not a standalone pack; it can only
+be applied on top of an installed copy of that old version, and launchers don't remove the listed files by themselves.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range []string{"minecraft-version", "loader-version"} {
+			if cmd.Flags().Changed(name) && strings.TrimSpace(viper.GetString("modrinth.export."+name)) == "" {
+				fmt.Printf("--%s must not be empty\n", name)
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
+		}
+		fileName := viper.GetString("modrinth.export.output")
+		var expFile *os.File
+		if fileName == "-" {
+			// Write the pack to stdout, and all other output to stderr so it doesn't corrupt the zip
+			expFile = os.Stdout
+			os.Stdout = os.Stderr
+		}
+
 		fmt.Println("Loading modpack...")
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		clientRules, err := cmdshared.GetOverrideRules(pack, core.ClientSide, "modrinth.export")
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		serverRules, err := cmdshared.GetOverrideRules(pack, core.ServerSide, "modrinth.export")
+		if err != nil {
+			cmdshared.ExitWithError(err)
 		}
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		// Do a refresh to ensure files are up to date
 		err = index.Refresh()
 		if err != nil {
-			fmt.Println(err)
-			return
+			cmdshared.ExitWithError(err)
 		}
 		err = index.Write()
 		if err != nil {
-			fmt.Println(err)
-			return
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.UpdateIndexHash()
 		if err != nil {
-			fmt.Println(err)
-			return
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.Write()
 		if err != nil {
-			fmt.Println(err)
-			return
+			cmdshared.ExitWithError(err)
 		}
 
 		fmt.Println("Reading external files...")
 		mods, err := index.LoadAllMods()
 		if err != nil {
 			fmt.Printf("Error reading file: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		tagFilter := core.TagFilter{
+			Include: viper.GetStringSlice("modrinth.export.include-tag"),
+			Exclude: viper.GetStringSlice("modrinth.export.exclude-tag"),
+		}
+		if filtered := tagFilter.Filter(mods); len(filtered) != len(mods) {
+			fmt.Printf("Exporting %d of %d files, selected by their tags\n", len(filtered), len(mods))
+			mods = filtered
+		}
+
+		since := viper.GetString("modrinth.export.since")
+		patch, err := getExportPatch(since, index)
+		if err != nil {
+			fmt.Printf("Error comparing with %s: %v\n", since, err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		if patch != nil {
+			mods = patch.FilterMods(mods, index)
+			fmt.Printf("Exporting a patch pack of the changes since %s: %d changed files, %d removed files\n", since, len(patch.Changed), len(patch.Removed))
 		}
 
-		fileName := viper.GetString("modrinth.export.output")
 		if fileName == "" {
 			fileName = pack.GetPackName() + ".mrpack"
 		}
-		expFile, err := os.Create(fileName)
-		if err != nil {
-			fmt.Printf("Failed to create zip: %s\n", err.Error())
-			os.Exit(1)
+		if expFile == nil {
+			expFile, err = os.Create(fileName)
+			if err != nil {
+				fmt.Printf("Failed to create zip: %s\n", err.Error())
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
 		}
 		exp := zip.NewWriter(expFile)
 
@@ -78,7 +125,7 @@ var exportCmd = &cobra.Command{
 		_, err = exp.Create("overrides/")
 		if err != nil {
 			fmt.Printf("Failed to add overrides folder: %s\n", err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		fmt.Printf("Retrieving %v external files...\n", len(mods))
@@ -95,13 +142,20 @@ var exportCmd = &cobra.Command{
 		session, err := core.CreateDownloadSession(mods, []string{"sha1", "sha512", "length-bytes"})
 		if err != nil {
 			fmt.Printf("Error retrieving external files: %v\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
-		cmdshared.ListManualDownloads(session)
+		// Files that packwiz can't download (as their authors have disabled third-party downloads) can't be
+		// redistributed, and Modrinth packs have no way to reference them, so they are left out of the pack
+		notRedistributable := session.GetManualDownloads()
 
 		manifestFiles := make([]PackFile, 0)
-		for dl := range session.StartDownloads() {
+		for dl := range session.StartDownloadsContext(cmd.Context(), cmdshared.DownloadProgressBar) {
+			if dl.Manual != nil {
+				_ = dl.File.Close()
+				notRedistributable = append(notRedistributable, *dl.Manual)
+				continue
+			}
 			if canBeIncludedDirectly(dl.Mod, restrictDomains) {
 				if dl.Error != nil {
 					fmt.Printf("Download of %s (%s) failed: %v\n", dl.Mod.Name, dl.Mod.FileName, dl.Error)
@@ -118,50 +172,12 @@ var exportCmd = &cobra.Command{
 					continue
 				}
 
-				hashes := make(map[string]string)
-				hashes["sha1"] = dl.Hashes["sha1"]
-				hashes["sha512"] = dl.Hashes["sha512"]
-				fileSize, err := strconv.ParseUint(dl.Hashes["length-bytes"], 10, 64)
+				manifestFile, err := getManifestFile(dl.Mod, path, dl.Hashes)
 				if err != nil {
-					panic(err)
-				}
-
-				// Create env options based on configured optional/side
-				var envInstalled string
-				if dl.Mod.Option != nil && dl.Mod.Option.Optional {
-					envInstalled = "optional"
-				} else {
-					envInstalled = "required"
-				}
-				var clientEnv, serverEnv string
-				if dl.Mod.Side == core.UniversalSide || dl.Mod.Side == core.EmptySide {
-					clientEnv = envInstalled
-					serverEnv = envInstalled
-				} else if dl.Mod.Side == core.ClientSide {
-					clientEnv = envInstalled
-					serverEnv = "unsupported"
-				} else if dl.Mod.Side == core.ServerSide {
-					clientEnv = "unsupported"
-					serverEnv = envInstalled
-				}
-
-				// Modrinth URLs must be RFC3986
-				u, err := core.ReencodeURL(dl.Mod.Download.URL)
-				if err != nil {
-					fmt.Printf("Error re-encoding download URL: %s\n", err.Error())
-					u = dl.Mod.Download.URL
+					fmt.Printf("Error adding %s (%s) to manifest: %v\n", dl.Mod.Name, dl.Mod.FileName, err)
+					continue
 				}
-
-				manifestFiles = append(manifestFiles, PackFile{
-					Path:   path,
-					Hashes: hashes,
-					Env: &struct {
-						Client string `json:"client"`
-						Server string `json:"server"`
-					}{Client: clientEnv, Server: serverEnv},
-					Downloads: []string{u},
-					FileSize:  uint32(fileSize),
-				})
+				manifestFiles = append(manifestFiles, manifestFile)
 
 				fmt.Printf("%s (%s) added to manifest\n", dl.Mod.Name, dl.Mod.FileName)
 			} else {
@@ -174,84 +190,208 @@ var exportCmd = &cobra.Command{
 				}
 			}
 		}
-		// sort by `path` property before serialising to ensure reproducibility
-		sort.Slice(manifestFiles, func(i, j int) bool {
-			return manifestFiles[i].Path < manifestFiles[j].Path
-		})
-
 		err = session.SaveIndex()
 		if err != nil {
 			fmt.Printf("Error saving cache index: %v\n", err)
-			os.Exit(1)
-		}
-
-		dependencies := make(map[string]string)
-		dependencies["minecraft"], err = pack.GetMCVersion()
-		if err != nil {
-			_ = exp.Close()
-			_ = expFile.Close()
-			fmt.Println("Error creating manifest: " + err.Error())
-			os.Exit(1)
-		}
-		if quiltVersion, ok := pack.Versions["quilt"]; ok {
-			dependencies["quilt-loader"] = quiltVersion
-		} else if fabricVersion, ok := pack.Versions["fabric"]; ok {
-			dependencies["fabric-loader"] = fabricVersion
-		} else if forgeVersion, ok := pack.Versions["forge"]; ok {
-			dependencies["forge"] = forgeVersion
-		} else if neoforgeVersion, ok := pack.Versions["neoforge"]; ok {
-			dependencies["neoforge"] = neoforgeVersion
-		}
-
-		manifest := Pack{
-			FormatVersion: 1,
-			Game:          "minecraft",
-			VersionID:     pack.Version,
-			Name:          pack.Name,
-			Summary:       pack.Description,
-			Files:         manifestFiles,
-			Dependencies:  dependencies,
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
 		if len(pack.Version) == 0 {
 			fmt.Println("Warning: pack.toml version field must not be empty to create a valid Modrinth pack")
 		}
 
-		manifestFile, err := exp.Create("modrinth.index.json")
+		err = writeManifest(exp, pack, manifestFiles, viper.GetString("modrinth.export.minecraft-version"),
+			viper.GetString("modrinth.export.loader-version"))
 		if err != nil {
 			_ = exp.Close()
 			_ = expFile.Close()
 			fmt.Println("Error creating manifest: " + err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
-		w := json.NewEncoder(manifestFile)
-		w.SetIndent("", "    ") // Documentation uses 4 spaces
-		err = w.Encode(manifest)
-		if err != nil {
-			_ = exp.Close()
-			_ = expFile.Close()
-			fmt.Println("Error writing manifest: " + err.Error())
-			os.Exit(1)
+		if patch != nil {
+			overridesIndex := patch.FilterIndex(index)
+			cmdshared.AddNonMetafileSideOverrides(&overridesIndex, exp, clientRules, serverRules)
+			err = writePatchManifest(exp, *patch)
+			if err != nil {
+				_ = exp.Close()
+				_ = expFile.Close()
+				fmt.Println("Error writing patch manifest: " + err.Error())
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+		} else {
+			cmdshared.AddNonMetafileSideOverrides(&index, exp, clientRules, serverRules)
 		}
 
-		cmdshared.AddNonMetafileOverrides(&index, exp)
-
 		err = exp.Close()
 		if err != nil {
 			fmt.Println("Error writing export file: " + err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		err = expFile.Close()
 		if err != nil {
 			fmt.Println("Error writing export file: " + err.Error())
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 
-		fmt.Println("Modpack exported to " + fileName)
+		if fileName == "-" {
+			fmt.Println("Modpack exported to stdout")
+		} else {
+			fmt.Println("Modpack exported to " + fileName)
+		}
+		if len(notRedistributable) > 0 {
+			fmt.Printf("Warning: the following %d files weren't included in the pack, as their authors have disabled third-party downloads; players must download them manually:\n", len(notRedistributable))
+			for _, dl := range notRedistributable {
+				fmt.Printf("%s (%s) from %s\n", dl.Name, dl.FileName, dl.URL)
+			}
+		}
 	},
 }
 
+// getManifestFile returns the entry in modrinth.index.json for a downloaded file, given its path in the pack and the
+// hashes from downloading it
+func getManifestFile(mod *core.Mod, path string, hashes map[string]string) (PackFile, error) {
+	fileSize, err := strconv.ParseUint(hashes["length-bytes"], 10, 32)
+	if err != nil {
+		return PackFile{}, fmt.Errorf("invalid file size: %w", err)
+	}
+
+	// Modrinth URLs must be RFC3986
+	u, err := core.ReencodeURL(mod.Download.URL)
+	if err != nil {
+		fmt.Printf("Error re-encoding download URL: %s\n", err.Error())
+		u = mod.Download.URL
+	}
+
+	return PackFile{
+		Path:      path,
+		Hashes:    map[string]string{"sha1": hashes["sha1"], "sha512": hashes["sha512"]},
+		Env:       getPackFileEnv(mod),
+		Downloads: []string{u},
+		FileSize:  uint32(fileSize),
+	}, nil
+}
+
+// writeManifest adds modrinth.index.json to an exported pack, listing the given files. The Minecraft and mod loader
+// versions are taken from pack.toml unless they are overridden by mcVersion or loaderVersion (if not empty).
+func writeManifest(exp *zip.Writer, pack core.Pack, files []PackFile, mcVersion string, loaderVersion string) error {
+	dependencies, err := getPackDependencies(pack, mcVersion, loaderVersion)
+	if err != nil {
+		return err
+	}
+
+	// sort by `path` property before serialising to ensure reproducibility
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	manifest := Pack{
+		FormatVersion: 1,
+		Game:          "minecraft",
+		VersionID:     pack.Version,
+		Name:          pack.Name,
+		Summary:       pack.Description,
+		Files:         files,
+		Dependencies:  dependencies,
+	}
+
+	manifestFile, err := exp.Create("modrinth.index.json")
+	if err != nil {
+		return err
+	}
+	w := json.NewEncoder(manifestFile)
+	w.SetIndent("", "    ") // Documentation uses 4 spaces
+	return w.Encode(manifest)
+}
+
+// patchManifest is written to packwiz-patch.json in patch packs, listing the files to remove from the old version
+type patchManifest struct {
+	Since   string   `json:"since"`
+	Removed []string `json:"removed"`
+}
+
+// getExportPatch returns the changes since the given old version of the pack, or nil if since is empty
+func getExportPatch(since string, index core.Index) (*cmd.PackPatch, error) {
+	if since == "" {
+		return nil, nil
+	}
+	patch, err := cmd.GetPackPatch(since, index)
+	if err != nil {
+		return nil, err
+	}
+	return &patch, nil
+}
+
+// writePatchManifest adds packwiz-patch.json to a patch pack
+func writePatchManifest(exp *zip.Writer, patch cmd.PackPatch) error {
+	file, err := exp.Create("packwiz-patch.json")
+	if err != nil {
+		return err
+	}
+	removed := patch.Removed
+	if removed == nil {
+		removed = []string{}
+	}
+	w := json.NewEncoder(file)
+	w.SetIndent("", "    ")
+	return w.Encode(patchManifest{Since: patch.Since, Removed: removed})
+}
+
+// getPackDependencies returns the dependencies written to modrinth.index.json, using the Minecraft and mod loader
+// versions from pack.toml unless they are overridden by mcVersion or loaderVersion (if not empty)
+func getPackDependencies(pack core.Pack, mcVersion string, loaderVersion string) (map[string]string, error) {
+	dependencies := make(map[string]string)
+	if mcVersion != "" {
+		dependencies["minecraft"] = mcVersion
+	} else {
+		var err error
+		dependencies["minecraft"], err = pack.GetMCVersion()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var loader string
+	if quiltVersion, ok := pack.Versions["quilt"]; ok {
+		loader, dependencies["quilt-loader"] = "quilt-loader", quiltVersion
+	} else if fabricVersion, ok := pack.Versions["fabric"]; ok {
+		loader, dependencies["fabric-loader"] = "fabric-loader", fabricVersion
+	} else if forgeVersion, ok := pack.Versions["forge"]; ok {
+		loader, dependencies["forge"] = "forge", forgeVersion
+	} else if neoforgeVersion, ok := pack.Versions["neoforge"]; ok {
+		loader, dependencies["neoforge"] = "neoforge", neoforgeVersion
+	}
+	if loaderVersion != "" {
+		if loader == "" {
+			return nil, errors.New("can't override the loader version, as the pack doesn't have a mod loader")
+		}
+		dependencies[loader] = loaderVersion
+	}
+	return dependencies, nil
+}
+
+// getPackFileEnv creates mrpack env options based on the configured optional/side of a file
+func getPackFileEnv(mod *core.Mod) *PackFileEnv {
+	var envInstalled string
+	if mod.Option != nil && mod.Option.Optional {
+		envInstalled = "optional"
+	} else {
+		envInstalled = "required"
+	}
+	env := &PackFileEnv{}
+	if mod.Side == core.UniversalSide || mod.Side == core.EmptySide {
+		env.Client = envInstalled
+		env.Server = envInstalled
+	} else if mod.Side == core.ClientSide {
+		env.Client = envInstalled
+		env.Server = "unsupported"
+	} else if mod.Side == core.ServerSide {
+		env.Client = "unsupported"
+		env.Server = envInstalled
+	}
+	return env
+}
+
 var whitelistedHosts = []string{
 	"cdn.modrinth.com",
 	"github.com",
@@ -278,7 +418,18 @@ func canBeIncludedDirectly(mod *core.Mod, restrictDomains bool) bool {
 func init() {
 	modrinthCmd.AddCommand(exportCmd)
 	exportCmd.Flags().Bool("restrictDomains", true, "Restricts domains to those allowed by modrinth.com")
-	exportCmd.Flags().StringP("output", "o", "", "The file to export the modpack to")
+	exportCmd.Flags().StringP("output", "o", "", "The file to export the modpack to, or - to write it to stdout")
 	_ = viper.BindPFlag("modrinth.export.restrictDomains", exportCmd.Flags().Lookup("restrictDomains"))
 	_ = viper.BindPFlag("modrinth.export.output", exportCmd.Flags().Lookup("output"))
+	exportCmd.Flags().StringSlice("include-tag", nil, "Only export files with at least one of these tags (see packwiz tag)")
+	_ = viper.BindPFlag("modrinth.export.include-tag", exportCmd.Flags().Lookup("include-tag"))
+	exportCmd.Flags().StringSlice("exclude-tag", nil, "Don't export files with any of these tags, even if they have a tag given with --include-tag")
+	_ = viper.BindPFlag("modrinth.export.exclude-tag", exportCmd.Flags().Lookup("exclude-tag"))
+	exportCmd.Flags().String("minecraft-version", "", "Write this Minecraft version to the exported pack's dependencies instead of the one in pack.toml")
+	_ = viper.BindPFlag("modrinth.export.minecraft-version", exportCmd.Flags().Lookup("minecraft-version"))
+	exportCmd.Flags().String("loader-version", "", "Write this mod loader version to the exported pack's dependencies instead of the one in pack.toml")
+	_ = viper.BindPFlag("modrinth.export.loader-version", exportCmd.Flags().Lookup("loader-version"))
+	exportCmd.Flags().String("since", "", "Export a patch pack containing only the files changed since this old version of the pack (an index file, a folder or a git revision)")
+	_ = viper.BindPFlag("modrinth.export.since", exportCmd.Flags().Lookup("since"))
+	cmdshared.AddOverrideFlags(exportCmd, "modrinth.export")
 }
@@ -0,0 +1,157 @@
+package modrinth
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"maps"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// exportedManifest is the part of modrinth.index.json checked by the export tests, read as an installer would
+type exportedManifest struct {
+	Name  string `json:"name"`
+	Files []struct {
+		Path   string            `json:"path"`
+		Hashes map[string]string `json:"hashes"`
+		Env    struct {
+			Client string `json:"client"`
+			Server string `json:"server"`
+		} `json:"env"`
+		Downloads []string `json:"downloads"`
+		FileSize  uint32   `json:"fileSize"`
+	} `json:"files"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// exportManifest writes modrinth.index.json for the given mods into a zip (as the export command does), and reads it
+// back from the zip
+func exportManifest(t *testing.T, pack core.Pack, mods []*core.Mod, mcVersion string, loaderVersion string) (exportedManifest, error) {
+	t.Helper()
+	var files []PackFile
+	for _, mod := range mods {
+		file, err := getManifestFile(mod, "mods/"+mod.FileName, map[string]string{
+			"sha1":         "sha1-" + mod.FileName,
+			"sha512":       "sha512-" + mod.FileName,
+			"length-bytes": "1234",
+		})
+		if err != nil {
+			t.Fatalf("Failed to add %s to manifest: %v", mod.Name, err)
+		}
+		files = append(files, file)
+	}
+
+	var buf bytes.Buffer
+	exp := zip.NewWriter(&buf)
+	if err := writeManifest(exp, pack, files, mcVersion, loaderVersion); err != nil {
+		return exportedManifest{}, err
+	}
+	if err := exp.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read exported pack: %v", err)
+	}
+	f, err := zr.Open("modrinth.index.json")
+	if err != nil {
+		t.Fatalf("Failed to read modrinth.index.json: %v", err)
+	}
+	defer f.Close()
+	var manifest exportedManifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		t.Fatalf("Failed to read modrinth.index.json: %v", err)
+	}
+	return manifest, nil
+}
+
+// TestExportOptionalClientMod verifies that an optional client mod is exported with optional/unsupported env values
+func TestExportOptionalClientMod(t *testing.T) {
+	mods := []*core.Mod{
+		{
+			Name:     "Required Mod",
+			FileName: "required.jar",
+			Side:     core.UniversalSide,
+			Download: core.ModDownload{URL: "https://cdn.modrinth.com/data/AAAAAAAA/versions/BBBBBBBB/required.jar"},
+		},
+		{
+			Name:     "Optional Client Mod",
+			FileName: "optional-client.jar",
+			Side:     core.ClientSide,
+			Option: &core.ModOption{
+				Optional:    true,
+				Description: "A mod that can be disabled",
+			},
+			Download: core.ModDownload{URL: "https://cdn.modrinth.com/data/CCCCCCCC/versions/DDDDDDDD/optional client.jar"},
+		},
+	}
+	pack := core.Pack{Name: "Test Pack", Version: "1.0.0", Versions: map[string]string{"minecraft": "1.20.1"}}
+
+	manifest, err := exportManifest(t, pack, mods, "", "")
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	if manifest.Name != "Test Pack" || manifest.Dependencies["minecraft"] != "1.20.1" {
+		t.Errorf("Expected the name and Minecraft version from pack.toml, got %s and %v", manifest.Name, manifest.Dependencies)
+	}
+	expected := []struct {
+		path, client, server, download string
+	}{
+		// Files are sorted by path
+		{"mods/optional-client.jar", "optional", "unsupported", "https://cdn.modrinth.com/data/CCCCCCCC/versions/DDDDDDDD/optional%20client.jar"},
+		{"mods/required.jar", "required", "required", "https://cdn.modrinth.com/data/AAAAAAAA/versions/BBBBBBBB/required.jar"},
+	}
+	if len(manifest.Files) != len(expected) {
+		t.Fatalf("Expected %d files, got %d", len(expected), len(manifest.Files))
+	}
+	for i, file := range manifest.Files {
+		e := expected[i]
+		if file.Path != e.path {
+			t.Errorf("Expected file %s, got %s", e.path, file.Path)
+			continue
+		}
+		if file.Env.Client != e.client || file.Env.Server != e.server {
+			t.Errorf("Expected env client=%s server=%s for %s, got client=%s server=%s",
+				e.client, e.server, file.Path, file.Env.Client, file.Env.Server)
+		}
+		if len(file.Downloads) != 1 || file.Downloads[0] != e.download {
+			t.Errorf("Expected download %s for %s, got %v", e.download, file.Path, file.Downloads)
+		}
+		if file.FileSize != 1234 || file.Hashes["sha1"] == "" || file.Hashes["sha512"] == "" || len(file.Hashes) != 2 {
+			t.Errorf("Expected the size and sha1/sha512 hashes of %s, got %d and %v", file.Path, file.FileSize, file.Hashes)
+		}
+	}
+}
+
+// TestExportVersionOverrides verifies that overridden versions are written to the dependencies in the manifest
+func TestExportVersionOverrides(t *testing.T) {
+	pack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"}}
+
+	deps, err := getPackDependencies(pack, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps["minecraft"] != "1.20.1" || deps["fabric-loader"] != "0.15.0" {
+		t.Errorf("Expected the versions from pack.toml, got %v", deps)
+	}
+
+	manifest, err := exportManifest(t, pack, nil, "1.20.2", "0.16.5")
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	expected := map[string]string{"minecraft": "1.20.2", "fabric-loader": "0.16.5"}
+	if !maps.Equal(manifest.Dependencies, expected) {
+		t.Errorf("Expected dependencies %v, got %v", expected, manifest.Dependencies)
+	}
+	if pack.Versions["fabric"] != "0.15.0" {
+		t.Error("Overriding the loader version changed the pack")
+	}
+
+	if _, err := exportManifest(t, core.Pack{Versions: map[string]string{"minecraft": "1.20.1"}}, nil, "", "1.0"); err == nil {
+		t.Error("Expected an error when overriding the loader version of a pack without a loader")
+	}
+}
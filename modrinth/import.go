@@ -0,0 +1,258 @@
+package modrinth
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// mrPackIndexFile is the name of the manifest file in a Modrinth modpack
+const mrPackIndexFile = "modrinth.index.json"
+
+// mrPackDependencies maps the dependency names used in Modrinth modpacks to the component names used in pack.toml
+var mrPackDependencies = map[string]string{
+	"minecraft":     "minecraft",
+	"fabric-loader": "fabric",
+	"quilt-loader":  "quilt",
+	"forge":         "forge",
+	"neoforge":      "neoforge",
+}
+
+// mrPackImporter allows Modrinth modpacks (.mrpack files) to be used as the source of a new pack
+type mrPackImporter struct{}
+
+// mrImportedPack is a Modrinth modpack read by mrPackImporter
+type mrImportedPack struct {
+	manifest Pack
+	path     string
+}
+
+func (mrPackImporter) ReadPack(packPath string) (core.ImportedPack, bool, error) {
+	stat, err := os.Stat(packPath)
+	if err != nil {
+		return nil, false, err
+	}
+	if stat.IsDir() {
+		return nil, false, nil
+	}
+	zr, err := zip.OpenReader(packPath)
+	if err != nil {
+		// Not a zip file
+		return nil, false, nil
+	}
+	defer zr.Close()
+
+	f, err := zr.Open(mrPackIndexFile)
+	if err != nil {
+		return nil, false, nil
+	}
+	defer f.Close()
+	var manifest Pack
+	err = json.NewDecoder(f).Decode(&manifest)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", mrPackIndexFile, err)
+	}
+	if manifest.Game != "minecraft" {
+		return nil, false, fmt.Errorf("unsupported game %s", manifest.Game)
+	}
+	return mrImportedPack{manifest, packPath}, true, nil
+}
+
+func (p mrImportedPack) Name() string {
+	return p.manifest.Name
+}
+
+func (p mrImportedPack) PackAuthor() string {
+	// Modrinth modpacks don't store the author
+	return ""
+}
+
+func (p mrImportedPack) PackVersion() string {
+	return p.manifest.VersionID
+}
+
+func (p mrImportedPack) Versions() map[string]string {
+	versions := make(map[string]string)
+	for dep, version := range p.manifest.Dependencies {
+		if component, ok := mrPackDependencies[dep]; ok {
+			versions[component] = version
+		}
+	}
+	return versions
+}
+
+func (p mrImportedPack) Import(index *core.Index) error {
+	successes := 0
+	for _, file := range p.manifest.Files {
+		err := importPackFile(file, index)
+		if err != nil {
+			fmt.Printf("Failed to import \"%s\": %v\n", file.Path, err)
+			continue
+		}
+		successes++
+	}
+	fmt.Printf("Successfully imported %d/%d files!\n", successes, len(p.manifest.Files))
+
+	fmt.Println("Reading override files...")
+	zr, err := zip.OpenReader(p.path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	copied := 0
+	var skipped []string
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		relPath, ok := strings.CutPrefix(f.Name, "overrides/")
+		if !ok {
+			if strings.HasPrefix(f.Name, "client-overrides/") || strings.HasPrefix(f.Name, "server-overrides/") {
+				skipped = append(skipped, f.Name)
+			}
+			continue
+		}
+		err = importOverrideFile(f, relPath, index)
+		if err != nil {
+			fmt.Printf("Failed to copy file \"%s\": %v\n", relPath, err)
+			continue
+		}
+		copied++
+	}
+	if len(skipped) > 0 {
+		fmt.Println("Warning: side-specific override files aren't supported, so these files were not copied:")
+		for _, name := range skipped {
+			fmt.Println(name)
+		}
+	}
+	if copied == 0 {
+		fmt.Println("No files copied!")
+		return nil
+	}
+	fmt.Printf("Successfully copied %d files!\n", copied)
+	return index.Refresh()
+}
+
+// importPackFile creates a metadata file for a file in a Modrinth modpack, using the Modrinth version that the file
+// belongs to if it can be found, so that it can be updated
+func importPackFile(file PackFile, index *core.Index) error {
+	if len(file.Downloads) == 0 {
+		return errors.New("no download URLs")
+	}
+	relPath, err := cleanPackPath(file.Path)
+	if err != nil {
+		return err
+	}
+	hashFormat, hash := getBestHash(&modrinthApi.File{Hashes: file.Hashes})
+	if hashFormat == "" {
+		return errors.New("no hashes")
+	}
+
+	modMeta := core.Mod{
+		Name:     strings.TrimSuffix(path.Base(relPath), path.Ext(relPath)),
+		FileName: path.Base(relPath),
+		Side:     getPackFileSide(file.Env),
+		Download: core.ModDownload{
+			URL:        file.Downloads[0],
+			HashFormat: hashFormat,
+			Hash:       hash,
+			Mirrors:    file.Downloads[1:],
+		},
+	}
+	if file.Env != nil && (file.Env.Client == "optional" || file.Env.Server == "optional") {
+		modMeta.Option = &core.ModOption{Optional: true}
+	}
+	slug := core.SlugifyName(modMeta.Name)
+
+	if sha1, ok := file.Hashes["sha1"]; ok {
+		version, err := mrDefaultClient.VersionFiles.GetFromHash(sha1, "sha1")
+		if err == nil && version.ProjectID != nil && version.ID != nil {
+			project, err := mrDefaultClient.Projects.Get(*version.ProjectID)
+			if err == nil {
+				modMeta.Name = *project.Title
+				if project.Slug != nil {
+					slug = *project.Slug
+				}
+				updateData, err := mrUpdateData{
+					ProjectID:        *version.ProjectID,
+					InstalledVersion: *version.ID,
+				}.ToMap()
+				if err != nil {
+					return err
+				}
+				modMeta.Update = map[string]map[string]interface{}{"modrinth": updateData}
+			}
+		}
+	}
+	if modMeta.Update == nil {
+		fmt.Printf("Warning: \"%s\" wasn't found on Modrinth, so it can't be updated\n", relPath)
+	}
+
+	metaPath := index.ResolveIndexPath(path.Join(path.Dir(relPath), slug+core.MetaExtension))
+	modMeta.SetMetaPath(metaPath)
+	format, metaHash, err := modMeta.Write()
+	if err != nil {
+		return err
+	}
+	return index.RefreshFileWithHash(metaPath, format, metaHash, true)
+}
+
+// getPackFileSide returns the side of a file in a Modrinth modpack, from its env options
+func getPackFileSide(env *PackFileEnv) string {
+	if env == nil {
+		return core.UniversalSide
+	}
+	if env.Client == "unsupported" && env.Server != "unsupported" {
+		return core.ServerSide
+	}
+	if env.Server == "unsupported" && env.Client != "unsupported" {
+		return core.ClientSide
+	}
+	return core.UniversalSide
+}
+
+// cleanPackPath checks that a path from a Modrinth modpack stays within the pack, returning it in a cleaned form
+func cleanPackPath(p string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	if cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid path %s", p)
+	}
+	return cleaned, nil
+}
+
+// importOverrideFile copies a file from the overrides folder of a Modrinth modpack into the pack
+func importOverrideFile(f *zip.File, relPath string, index *core.Index) error {
+	relPath, err := cleanPackPath(relPath)
+	if err != nil {
+		return err
+	}
+	destPath := index.ResolveIndexPath(relPath)
+	err = os.MkdirAll(filepath.Dir(destPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dest, src)
+	if err != nil {
+		_ = dest.Close()
+		return err
+	}
+	return dest.Close()
+}
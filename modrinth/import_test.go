@@ -0,0 +1,121 @@
+package modrinth
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// writeTestZip creates a zip file containing the given files
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, contents := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestImportPack verifies that a Modrinth modpack is detected, and that its versions and override files are imported
+func TestImportPack(t *testing.T) {
+	dir := t.TempDir()
+	manifest, err := json.Marshal(Pack{
+		FormatVersion: 1,
+		Game:          "minecraft",
+		VersionID:     "1.2.0",
+		Name:          "Test Pack",
+		Dependencies:  map[string]string{"minecraft": "1.20.1", "fabric-loader": "0.15.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packPath := filepath.Join(dir, "test.mrpack")
+	writeTestZip(t, packPath, map[string]string{
+		mrPackIndexFile:                 string(manifest),
+		"overrides/config/test.toml":    "a = 1",
+		"overrides/../escape.txt":       "nope",
+		"client-overrides/options.txt":  "client",
+		"server-overrides/server.txt":   "server",
+		"unrelated/not-an-override.txt": "ignored",
+	})
+
+	imported, ok, err := mrPackImporter{}.ReadPack(packPath)
+	if err != nil || !ok {
+		t.Fatalf("Expected the modpack to be detected, got %v, %v", ok, err)
+	}
+	if imported.Name() != "Test Pack" || imported.PackVersion() != "1.2.0" {
+		t.Errorf("Unexpected name or version: %s %s", imported.Name(), imported.PackVersion())
+	}
+	versions := imported.Versions()
+	if len(versions) != 2 || versions["minecraft"] != "1.20.1" || versions["fabric"] != "0.15.0" {
+		t.Errorf("Unexpected versions: %v", versions)
+	}
+
+	packDir := filepath.Join(dir, "pack")
+	if err := os.MkdirAll(packDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	indexPath := filepath.Join(packDir, "index.toml")
+	if err := os.WriteFile(indexPath, []byte("hash-format = \"sha256\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	index, err := core.LoadIndex(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := imported.Import(&index); err != nil {
+		t.Fatalf("Failed to import modpack: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(packDir, "config", "test.toml")); err != nil || string(data) != "a = 1" {
+		t.Errorf("Expected override file to be copied, got %q, %v", data, err)
+	}
+	for _, name := range []string{"options.txt", "server.txt", "not-an-override.txt", filepath.Join("..", "escape.txt")} {
+		if _, err := os.Stat(filepath.Join(packDir, name)); err == nil {
+			t.Errorf("Expected %s not to be copied", name)
+		}
+	}
+}
+
+func TestReadPackIgnoresOtherZips(t *testing.T) {
+	packPath := filepath.Join(t.TempDir(), "test.zip")
+	writeTestZip(t, packPath, map[string]string{"manifest.json": "{}"})
+	if _, ok, err := (mrPackImporter{}).ReadPack(packPath); ok || err != nil {
+		t.Errorf("Expected a zip without %s not to be detected, got %v, %v", mrPackIndexFile, ok, err)
+	}
+}
+
+func TestGetPackFileSide(t *testing.T) {
+	cases := []struct {
+		env  *PackFileEnv
+		side string
+	}{
+		{nil, core.UniversalSide},
+		{&PackFileEnv{Client: "required", Server: "required"}, core.UniversalSide},
+		{&PackFileEnv{Client: "optional", Server: "unsupported"}, core.ClientSide},
+		{&PackFileEnv{Client: "unsupported", Server: "required"}, core.ServerSide},
+	}
+	for _, c := range cases {
+		if side := getPackFileSide(c.env); side != c.side {
+			t.Errorf("getPackFileSide(%v) = %s, expected %s", c.env, side, c.side)
+		}
+	}
+}
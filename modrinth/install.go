@@ -3,7 +3,6 @@ package modrinth
 import (
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -26,14 +25,59 @@ var installCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
+		}
+		pack = cmdshared.OverrideGameVersion(pack, gameVersionFlag)
+		if err := core.ValidateSide(sideFlag); err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		if err := validateChannel(channelFlag); err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		if projectTypeFlag != "" && !slices.Contains(addProjectTypes, projectTypeFlag) {
+			fmt.Printf("Unknown project type %s, must be one of %s\n", projectTypeFlag, strings.Join(addProjectTypes, ", "))
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+
+		if fileFlag != "" {
+			if len(args) != 0 || projectIDFlag != "" || versionIDFlag != "" || fromFileFlag != "" {
+				fmt.Println("--file cannot be used with a separately specified project")
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
+			err = installFile(fileFlag, pack, &index)
+			if errors.Is(err, errUnknownFile) {
+				fmt.Printf("Failed to add file: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitNotFound)
+			} else if err != nil {
+				fmt.Printf("Failed to add file: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+			return
+		}
+
+		if fromFileFlag != "" {
+			if len(args) != 0 || projectIDFlag != "" || versionIDFlag != "" {
+				fmt.Println("--from-file cannot be used with a separately specified project")
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
+			entries, err := cmdshared.ReadProjectList(fromFileFlag)
+			if err != nil {
+				fmt.Printf("Failed to read project list: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+			failed := cmdshared.AddFromList(entries, func(entry cmdshared.ListEntry) error {
+				return installListEntry(entry, pack, &index)
+			})
+			if failed > 0 {
+				cmdshared.Exit(cmdshared.ExitGeneric)
+			}
+			return
 		}
 
 		// If project/version IDs/version file name is provided in command line, use those
@@ -42,14 +86,14 @@ var installCmd = &cobra.Command{
 			projectID = projectIDFlag
 			if len(args) != 0 {
 				fmt.Println("--project-id cannot be used with a separately specified URL/slug/search term")
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitUsage)
 			}
 		}
 		if versionIDFlag != "" {
 			versionID = versionIDFlag
 			if len(args) != 0 {
 				fmt.Println("--version-id cannot be used with a separately specified URL/slug/search term")
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitUsage)
 			}
 		}
 		if versionFilenameFlag != "" {
@@ -58,7 +102,7 @@ var installCmd = &cobra.Command{
 
 		if (len(args) == 0 || len(args[0]) == 0) && projectID == "" {
 			fmt.Println("You must specify a project; with the ID flags, or by passing a URL, slug or search term directly.")
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitUsage)
 		}
 
 		var version string
@@ -68,7 +112,7 @@ var installCmd = &cobra.Command{
 			parsedSlug, err = parseSlugOrUrl(args[0], &projectID, &version, &versionID, &versionFilename)
 			if err != nil {
 				fmt.Printf("Failed to parse URL: %v\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 		}
 
@@ -77,7 +121,7 @@ var installCmd = &cobra.Command{
 			err = installVersionById(versionID, versionFilename, pack, &index)
 			if err != nil {
 				fmt.Printf("Failed to add project: %s\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 			return
 		}
@@ -94,12 +138,12 @@ var installCmd = &cobra.Command{
 					versionData, err := resolveVersion(project, version)
 					if err != nil {
 						fmt.Printf("Failed to add project: %s\n", err)
-						os.Exit(1)
+						cmdshared.Exit(cmdshared.ExitCode(err))
 					}
 					err = installVersion(project, versionData, versionFilename, pack, &index)
 					if err != nil {
 						fmt.Printf("Failed to add project: %s\n", err)
-						os.Exit(1)
+						cmdshared.Exit(cmdshared.ExitCode(err))
 					}
 					return
 				}
@@ -108,7 +152,7 @@ var installCmd = &cobra.Command{
 				err = installProject(project, versionFilename, pack, &index)
 				if err != nil {
 					fmt.Printf("Failed to add project: %s\n", err)
-					os.Exit(1)
+					cmdshared.Exit(cmdshared.ExitCode(err))
 				}
 				return
 			}
@@ -119,15 +163,52 @@ var installCmd = &cobra.Command{
 			err = installViaSearch(strings.Join(args, " "), versionFilename, !parsedSlug, pack, &index)
 			if err != nil {
 				fmt.Printf("Failed to add project: %s\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 		} else {
 			fmt.Printf("Failed to add project: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 	},
 }
 
+// installListEntry adds a project from a project list, given by slug, project ID or URL (but not a search term, as
+// there is no one to pick from the results)
+func installListEntry(entry cmdshared.ListEntry, pack core.Pack, index *core.Index) error {
+	var projectID, version, versionID, versionFilename string
+	_, err := parseSlugOrUrl(entry.Project, &projectID, &version, &versionID, &versionFilename)
+	if err != nil {
+		return fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if projectID == "" {
+		return errors.New("not a Modrinth slug, project ID or URL")
+	}
+	if entry.Version != "" {
+		// The version given in the list takes precedence over the version in the URL
+		version, versionID = entry.Version, ""
+	}
+
+	project, err := mrDefaultClient.Projects.Get(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch project %s: %w", projectID, err)
+	}
+	if slices.Contains(getInstalledProjectIDs(index), *project.ID) {
+		return cmdshared.ErrAlreadyAdded
+	}
+
+	if versionID != "" {
+		return installVersionById(versionID, versionFilename, pack, index)
+	}
+	if version != "" {
+		versionData, err := resolveVersion(project, version)
+		if err != nil {
+			return err
+		}
+		return installVersion(project, versionData, versionFilename, pack, index)
+	}
+	return installProject(project, versionFilename, pack, index)
+}
+
 func installVersionById(versionId string, versionFilename string, pack core.Pack, index *core.Index) error {
 	version, err := mrDefaultClient.Versions.Get(versionId)
 	if err != nil {
@@ -169,6 +250,10 @@ func installViaSearch(query string, versionFilename string, autoAcceptFirst bool
 		return installProject(project, versionFilename, pack, index)
 	}
 
+	if err := cmdshared.CanPrompt(); err != nil {
+		return err
+	}
+
 	// Create menu for the user to choose the correct project
 	menu := wmenu.NewMenu("Choose a number:")
 	menu.Option("Cancel", nil, false, nil)
@@ -201,7 +286,7 @@ func installViaSearch(query string, versionFilename string, autoAcceptFirst bool
 }
 
 func installProject(project *modrinthApi.Project, versionFilename string, pack core.Pack, index *core.Index) error {
-	latestVersion, err := getLatestVersion(*project.ID, *project.Title, pack)
+	latestVersion, err := getLatestVersion(*project.ID, *project.Title, pack, getAddProjectType(project, false) == "datapack", allowFallbackLoaderFlag, addChannel())
 	if err != nil {
 		return fmt.Errorf("failed to get latest version: %v", err)
 	}
@@ -234,113 +319,30 @@ func installVersion(project *modrinthApi.Project, version *modrinthApi.Version,
 			return err
 		}
 
-		var depMetadata []depMetadataStore
-		var depProjectIDPendingQueue []string
-		var depVersionIDPendingQueue []string
+		var optionalProjectIDs []string
+		// Projects that have already been resolved (or are being added), to avoid looping on circular dependencies
+		visitedProjects := map[string]bool{*project.ID: true}
 
 		for _, dep := range version.Dependencies {
-			// TODO: recommend optional dependencies?
-			if dep.DependencyType != nil && *dep.DependencyType == "required" {
-				if dep.VersionID != nil {
-					depVersionIDPendingQueue = append(depVersionIDPendingQueue, *dep.VersionID)
-				} else {
-					if dep.ProjectID != nil {
-						depProjectIDPendingQueue = append(depProjectIDPendingQueue, mapDepOverride(*dep.ProjectID, isQuilt, mcVersion))
-					}
-				}
+			if dep.DependencyType != nil && *dep.DependencyType == "optional" && dep.ProjectID != nil {
+				optionalProjectIDs = append(optionalProjectIDs, mapDepOverride(*dep.ProjectID, isQuilt, mcVersion))
 			}
 		}
 
-		if len(depProjectIDPendingQueue)+len(depVersionIDPendingQueue) > 0 {
+		if hasRequiredDependencies(version.Dependencies) {
 			fmt.Println("Finding dependencies...")
 
-			cycles := 0
-			for len(depProjectIDPendingQueue)+len(depVersionIDPendingQueue) > 0 && cycles < maxCycles {
-				// Look up version IDs
-				if len(depVersionIDPendingQueue) > 0 {
-					depVersions, err := mrDefaultClient.Versions.GetMultiple(depVersionIDPendingQueue)
-					if err != nil {
-						return fmt.Errorf("failed to retrieve dependency versions (this may be due to rate limiting): %w", err)
-					}
-					for _, v := range depVersions {
-						// Add project ID to queue
-						depProjectIDPendingQueue = append(depProjectIDPendingQueue, mapDepOverride(*v.ProjectID, isQuilt, mcVersion))
-					}
-					depVersionIDPendingQueue = depVersionIDPendingQueue[:0]
-				}
-
-				// Remove installed project IDs from dep queue
-				i := 0
-				for _, id := range depProjectIDPendingQueue {
-					contains := slices.Contains(installedProjects, id)
-					for _, dep := range depMetadata {
-						if *dep.projectInfo.ID == id {
-							contains = true
-							break
-						}
-					}
-					if !contains {
-						depProjectIDPendingQueue[i] = id
-						i++
-					}
-				}
-				depProjectIDPendingQueue = depProjectIDPendingQueue[:i]
-
-				// Clean up duplicates from dep queue (from deps on both QFAPI + FAPI)
-				slices.Sort(depProjectIDPendingQueue)
-				depProjectIDPendingQueue = slices.Compact(depProjectIDPendingQueue)
-
-				if len(depProjectIDPendingQueue) == 0 {
-					break
-				}
-				depProjects, err := mrDefaultClient.Projects.GetMultiple(depProjectIDPendingQueue)
-				if err != nil {
-					return fmt.Errorf("failed to retrieve dependency projects (this may be due to rate limiting): %w", err)
-				}
-				depProjectIDPendingQueue = depProjectIDPendingQueue[:0]
-
-				for _, project := range depProjects {
-					if project.ID == nil {
-						return errors.New("failed to get dependency data: invalid response")
-					}
-					// Get latest version - could reuse version lookup data but it's not as easy (particularly since the version won't necessarily be the latest)
-					latestVersion, err := getLatestVersion(*project.ID, *project.Title, pack)
-					if err != nil {
-						fmt.Printf("Failed to get latest version of dependency %v: %v\n", *project.Title, err)
-						continue
-					}
-
-					for _, dep := range version.Dependencies {
-						// TODO: recommend optional dependencies?
-						if dep.DependencyType != nil && *dep.DependencyType == "required" {
-							if dep.ProjectID != nil {
-								depProjectIDPendingQueue = append(depProjectIDPendingQueue, mapDepOverride(*dep.ProjectID, isQuilt, mcVersion))
-							}
-							if dep.VersionID != nil {
-								depVersionIDPendingQueue = append(depVersionIDPendingQueue, *dep.VersionID)
-							}
-						}
-					}
-
-					var file = latestVersion.Files[0]
-					// Prefer the primary file
-					for _, v := range latestVersion.Files {
-						if *v.Primary {
-							file = v
-						}
-					}
-
-					depMetadata = append(depMetadata, depMetadataStore{
-						projectInfo: project,
-						versionInfo: latestVersion,
-						fileInfo:    file,
-					})
-				}
-
-				cycles++
+			resolver := dependencyResolver{
+				source: apiDependencySource{pack: pack, channel: addChannel()},
+				mapID: func(id string) string {
+					return mapDepOverride(id, isQuilt, mcVersion)
+				},
+				installed: installedProjects,
+				jobs:      dependencyJobsFlag,
 			}
-			if cycles >= maxCycles {
-				return errors.New("dependencies recurse too deeply, try increasing maxCycles")
+			depMetadata, err := resolver.resolve(version.Dependencies, visitedProjects)
+			if err != nil {
+				return err
 			}
 
 			if len(depMetadata) > 0 {
@@ -349,9 +351,13 @@ func installVersion(project *modrinthApi.Project, version *modrinthApi.Version,
 					fmt.Println(*v.projectInfo.Title)
 				}
 
-				if cmdshared.PromptYesNo("Would you like to add them? [Y/n]: ") {
+				addDeps, err := cmdshared.PromptYesNo("Would you like to add them? [Y/n]: ")
+				if err != nil {
+					return err
+				}
+				if addDeps {
 					for _, v := range depMetadata {
-						err := createFileMeta(v.projectInfo, v.versionInfo, v.fileInfo, pack, index)
+						err := createFileMeta(v.projectInfo, v.versionInfo, v.fileInfo, pack, index, true, addChannel())
 						if err != nil {
 							return err
 						}
@@ -362,6 +368,28 @@ func installVersion(project *modrinthApi.Project, version *modrinthApi.Version,
 				fmt.Println("All dependencies are already added!")
 			}
 		}
+
+		// Optional dependencies are listed, but not added
+		i := 0
+		for _, id := range optionalProjectIDs {
+			if !slices.Contains(installedProjects, id) && !visitedProjects[id] {
+				optionalProjectIDs[i] = id
+				i++
+			}
+		}
+		optionalProjectIDs = optionalProjectIDs[:i]
+		slices.Sort(optionalProjectIDs)
+		optionalProjectIDs = slices.Compact(optionalProjectIDs)
+		if len(optionalProjectIDs) > 0 {
+			optionalProjects, err := mrDefaultClient.Projects.GetMultiple(optionalProjectIDs)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve optional dependency projects (this may be due to rate limiting): %w", err)
+			}
+			fmt.Println("Optional dependencies (not added):")
+			for _, v := range optionalProjects {
+				fmt.Printf("%s (%s)\n", *v.Title, *v.Slug)
+			}
+		}
 	}
 
 	var file = version.Files[0]
@@ -374,7 +402,7 @@ func installVersion(project *modrinthApi.Project, version *modrinthApi.Version,
 	// TODO: handle optional/required resource pack files
 
 	// Create the metadata file
-	err := createFileMeta(project, version, file, pack, index)
+	err := createFileMeta(project, version, file, pack, index, false, addChannel())
 	if err != nil {
 		return err
 	}
@@ -396,19 +424,41 @@ func installVersion(project *modrinthApi.Project, version *modrinthApi.Version,
 	return nil
 }
 
-func createFileMeta(project *modrinthApi.Project, version *modrinthApi.Version, file *modrinthApi.File, pack core.Pack, index *core.Index) error {
+// getRequiredDependencyIDs returns the project IDs of the required dependencies of a version, so that they can be
+// stored in its metadata file
+func getRequiredDependencyIDs(version *modrinthApi.Version, pack core.Pack) []string {
+	isQuilt := slices.Contains(pack.GetCompatibleLoaders(), "quilt")
+	mcVersion, _ := pack.GetMCVersion()
+	var ids []string
+	for _, dep := range version.Dependencies {
+		if dep.DependencyType != nil && *dep.DependencyType == "required" && dep.ProjectID != nil {
+			ids = append(ids, mapDepOverride(*dep.ProjectID, isQuilt, mcVersion))
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func createFileMeta(project *modrinthApi.Project, version *modrinthApi.Version, file *modrinthApi.File, pack core.Pack, index *core.Index, addedAsDependency bool, channel versionChannel) error {
 	updateMap := make(map[string]map[string]interface{})
+	projectType := getAddProjectType(project, addedAsDependency)
 
 	var err error
 	updateMap["modrinth"], err = mrUpdateData{
 		ProjectID:        *project.ID,
 		InstalledVersion: *version.ID,
+		Channel:          channel.Name,
+		AllowBeta:        channel.AllowBeta,
+		Datapack:         projectType == "datapack",
 	}.ToMap()
 	if err != nil {
 		return err
 	}
 
-	side := getSide(project)
+	side := getProjectSide(project, projectType)
+	if sideFlag != "" && !addedAsDependency {
+		side = sideFlag
+	}
 	if side == "" {
 		fmt.Println("Warning: Project doesn't have a side that's supported; assuming universal. Server: " + *project.ServerSide + " Client: " + *project.ClientSide)
 		side = core.UniversalSide
@@ -428,12 +478,15 @@ func createFileMeta(project *modrinthApi.Project, version *modrinthApi.Version,
 			HashFormat: algorithm,
 			Hash:       hash,
 		},
-		Update: updateMap,
+		Pin:               pinFlag && !addedAsDependency,
+		Dependencies:      getRequiredDependencyIDs(version, pack),
+		AddedAsDependency: addedAsDependency,
+		Update:            updateMap,
 	}
 	var path string
 	folder := viper.GetString("meta-folder")
 	if folder == "" {
-		folder, err = getProjectTypeFolder(*project.ProjectType, version.Loaders, pack.GetCompatibleLoaders())
+		folder, err = getProjectTypeFolder(projectType, version.Loaders, pack.GetCompatibleLoaders())
 		if err != nil {
 			return err
 		}
@@ -459,6 +512,33 @@ func createFileMeta(project *modrinthApi.Project, version *modrinthApi.Version,
 var projectIDFlag string
 var versionIDFlag string
 var versionFilenameFlag string
+var allowFallbackLoaderFlag bool
+var dependencyJobsFlag int
+var fromFileFlag string
+var channelFlag string
+var allowBetaFlag bool
+var gameVersionFlag string
+var pinFlag bool
+var sideFlag string
+var fileFlag string
+var projectTypeFlag string
+
+// addProjectTypes are the project types that can be given with --project-type
+var addProjectTypes = []string{"mod", "resourcepack", "shader", "datapack"}
+
+// getAddProjectType returns the type that a project is added as, which decides its folder and default side: the type
+// given with --project-type (for the project being added, not its dependencies), or the project's type on Modrinth
+func getAddProjectType(project *modrinthApi.Project, addedAsDependency bool) string {
+	if projectTypeFlag != "" && !addedAsDependency {
+		return projectTypeFlag
+	}
+	return getProjectType(project)
+}
+
+// addChannel returns the channel that projects are added from, and updated from afterwards
+func addChannel() versionChannel {
+	return versionChannel{Name: channelFlag, AllowBeta: allowBetaFlag}
+}
 
 func init() {
 	modrinthCmd.AddCommand(installCmd)
@@ -466,4 +546,16 @@ func init() {
 	installCmd.Flags().StringVar(&projectIDFlag, "project-id", "", "The Modrinth project ID to use")
 	installCmd.Flags().StringVar(&versionIDFlag, "version-id", "", "The Modrinth version ID to use")
 	installCmd.Flags().StringVar(&versionFilenameFlag, "version-filename", "", "The Modrinth version filename to use")
+	installCmd.Flags().BoolVar(&allowFallbackLoaderFlag, "allow-fallback-loader", false, "Allow versions for loaders that the pack's loader is compatible with (e.g. Fabric versions in a Quilt pack), if there are no versions for the pack's loader")
+	installCmd.Flags().StringVar(&channelFlag, "channel", "release", "Only add versions of this type or a more stable one (release, beta or alpha); updates stay on the same channel")
+	installCmd.Flags().BoolVar(&allowBetaFlag, "allow-beta", false, "Allow beta versions on the release channel, if a project has no releases")
+	installCmd.Flags().StringVar(&gameVersionFlag, "game-version", "", "Find files for this Minecraft version instead of the pack's versions; updates still follow the pack's version")
+	installCmd.Flags().BoolVar(&pinFlag, "pin", false, "Pin the added file, so it isn't changed by packwiz update")
+	installCmd.Flags().StringVar(&sideFlag, "side", "", "The side to add the file on (client, server or both); resource packs and shaders default to client")
+	installCmd.Flags().IntVarP(&dependencyJobsFlag, "jobs", "j", defaultDependencyJobs, "The number of dependencies to look up in parallel")
+	installCmd.Flags().StringVar(&projectTypeFlag, "project-type", "", "Add the project as this type (mod, resourcepack, shader or datapack) instead of its type on Modrinth, which decides its folder and default side")
+	installCmd.Flags().StringVar(&fileFlag, "file", "", "Add the project version containing this file (e.g. a jar you already have), found by its hash")
+	installCmd.Flags().StringVar(&fromFileFlag, "from-file", "", "Add every project listed in a file: one slug, project ID or URL per line, optionally followed by a version; text after # is ignored")
+	cmdshared.AddReportFlag(installCmd, "modrinth.add.report")
+	cmdshared.AddHooks(installCmd, "add")
 }
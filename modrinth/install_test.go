@@ -0,0 +1,205 @@
+package modrinth
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+// createTestIndex creates an empty index in a temporary folder, which metadata files are added to
+func createTestIndex(t *testing.T) (string, core.Index) {
+	t.Helper()
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "index.toml")
+	if err := os.WriteFile(indexPath, []byte("hash-format = \"sha256\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	index, err := core.LoadIndex(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("meta-folder-base", dir)
+	t.Cleanup(func() {
+		viper.Set("meta-folder-base", ".")
+	})
+	return dir, index
+}
+
+func str(s string) *string {
+	return &s
+}
+
+func TestAddDatapack(t *testing.T) {
+	dir, index := createTestIndex(t)
+
+	// Modrinth gives datapacks the mod project type, with the datapack loader
+	project := &modrinthApi.Project{
+		ID:          str("AAAAAAAA"),
+		Slug:        str("test-datapack"),
+		ProjectType: str("mod"),
+		Title:       str("Test Datapack"),
+		ClientSide:  str("optional"),
+		ServerSide:  str("required"),
+		Loaders:     []string{"datapack"},
+	}
+	primary := true
+	file := &modrinthApi.File{
+		Hashes:   map[string]string{"sha512": "abcd"},
+		URL:      str("https://cdn.modrinth.com/data/AAAAAAAA/versions/BBBBBBBB/test-datapack.zip"),
+		Filename: str("test-datapack.zip"),
+		Primary:  &primary,
+	}
+	version := &modrinthApi.Version{
+		ID:           str("BBBBBBBB"),
+		ProjectID:    project.ID,
+		GameVersions: []string{"1.20.1"},
+		Loaders:      []string{"datapack"},
+		Files:        []*modrinthApi.File{file},
+	}
+	pack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"}}
+
+	if !isDatapackProject(project) {
+		t.Fatal("Expected project to be a datapack")
+	}
+	if loaders := getCompatibleMRLoaders(pack, true); !slices.Equal(loaders, []string{"datapack"}) {
+		t.Errorf("Expected only datapack files to be compatible, got %v", loaders)
+	}
+
+	if err := createFileMeta(project, version, file, pack, &index, false, versionChannel{}); err != nil {
+		t.Fatalf("Failed to add datapack: %v", err)
+	}
+	mod, err := core.LoadMod(filepath.Join(dir, "datapacks", "test-datapack"+core.MetaExtension))
+	if err != nil {
+		t.Fatalf("Expected datapack to be added to the datapacks folder: %v", err)
+	}
+	if mod.GetDestFilePath() != filepath.Join(dir, "datapacks", "test-datapack.zip") {
+		t.Errorf("Unexpected destination path %s", mod.GetDestFilePath())
+	}
+	data, ok := mod.GetParsedUpdateData("modrinth")
+	if !ok || !data.(mrUpdateData).Datapack {
+		t.Errorf("Expected update data to be marked as a datapack, got %v", data)
+	}
+}
+
+func TestAddResourcePackSide(t *testing.T) {
+	dir, index := createTestIndex(t)
+	pack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"}}
+
+	addPack := func(slug string, projectType string) core.Mod {
+		t.Helper()
+		// Modrinth often marks resource packs as required on servers
+		project := &modrinthApi.Project{
+			ID:          str(slug),
+			Slug:        str(slug),
+			ProjectType: str(projectType),
+			Title:       str(slug),
+			ClientSide:  str("required"),
+			ServerSide:  str("required"),
+		}
+		primary := true
+		file := &modrinthApi.File{
+			Hashes:   map[string]string{"sha512": "abcd"},
+			URL:      str("https://cdn.modrinth.com/data/" + slug + "/versions/BBBBBBBB/" + slug + ".zip"),
+			Filename: str(slug + ".zip"),
+			Primary:  &primary,
+		}
+		version := &modrinthApi.Version{
+			ID:        str("BBBBBBBB"),
+			ProjectID: project.ID,
+			Loaders:   []string{"minecraft"},
+			Files:     []*modrinthApi.File{file},
+		}
+		if err := createFileMeta(project, version, file, pack, &index, false, versionChannel{}); err != nil {
+			t.Fatalf("Failed to add %s: %v", slug, err)
+		}
+		folder, err := getProjectTypeFolder(projectType, version.Loaders, pack.GetCompatibleLoaders())
+		if err != nil {
+			t.Fatal(err)
+		}
+		mod, err := core.LoadMod(filepath.Join(dir, folder, slug+core.MetaExtension))
+		if err != nil {
+			t.Fatalf("Failed to read metadata file of %s: %v", slug, err)
+		}
+		return mod
+	}
+
+	if mod := addPack("test-resourcepack", "resourcepack"); mod.Side != core.ClientSide {
+		t.Errorf("Expected resource pack to default to the client side, got %q", mod.Side)
+	}
+	if mod := addPack("test-shader", "shader"); mod.Side != core.ClientSide {
+		t.Errorf("Expected shader to default to the client side, got %q", mod.Side)
+	}
+
+	sideFlag = core.UniversalSide
+	t.Cleanup(func() {
+		sideFlag = ""
+	})
+	if mod := addPack("test-resourcepack-both", "resourcepack"); mod.Side != core.UniversalSide {
+		t.Errorf("Expected --side to override the default side, got %q", mod.Side)
+	}
+}
+
+func TestAddProjectType(t *testing.T) {
+	dir, index := createTestIndex(t)
+	pack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"}}
+
+	addProject := func(slug string, projectType string) {
+		t.Helper()
+		project := &modrinthApi.Project{
+			ID:          str(slug),
+			Slug:        str(slug),
+			ProjectType: str(projectType),
+			Title:       str(slug),
+			ClientSide:  str("required"),
+			ServerSide:  str("required"),
+		}
+		primary := true
+		file := &modrinthApi.File{
+			Hashes:   map[string]string{"sha512": "abcd"},
+			URL:      str("https://cdn.modrinth.com/data/" + slug + "/versions/BBBBBBBB/" + slug + ".zip"),
+			Filename: str(slug + ".zip"),
+			Primary:  &primary,
+		}
+		version := &modrinthApi.Version{
+			ID:        str("BBBBBBBB"),
+			ProjectID: project.ID,
+			Loaders:   []string{"minecraft"},
+			Files:     []*modrinthApi.File{file},
+		}
+		if err := createFileMeta(project, version, file, pack, &index, false, versionChannel{}); err != nil {
+			t.Fatalf("Failed to add %s: %v", slug, err)
+		}
+	}
+
+	// Without --project-type, the type from Modrinth is used
+	addProject("test-resourcepack", "resourcepack")
+	mod, err := core.LoadMod(filepath.Join(dir, "resourcepacks", "test-resourcepack"+core.MetaExtension))
+	if err != nil {
+		t.Fatalf("Expected resource pack to be added to the resourcepacks folder: %v", err)
+	}
+	if mod.Side != core.ClientSide {
+		t.Errorf("Expected resource pack to default to the client side, got %q", mod.Side)
+	}
+
+	// A slug that Modrinth reports as a mod can be added as a resource pack
+	projectTypeFlag = "resourcepack"
+	t.Cleanup(func() {
+		projectTypeFlag = ""
+	})
+	addProject("test-misclassified", "mod")
+	mod, err = core.LoadMod(filepath.Join(dir, "resourcepacks", "test-misclassified"+core.MetaExtension))
+	if err != nil {
+		t.Fatalf("Expected --project-type to add the project to the resourcepacks folder: %v", err)
+	}
+	if mod.Side != core.ClientSide {
+		t.Errorf("Expected --project-type to decide the default side, got %q", mod.Side)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "mods", "test-misclassified"+core.MetaExtension)); err == nil {
+		t.Error("Expected the project not to be added to the mods folder")
+	}
+}
@@ -0,0 +1,116 @@
+package modrinth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
+	"github.com/0byte-coding/packwiz/core"
+)
+
+type mrFileMatcher struct{}
+
+// MatchFiles looks up the sha1 hash of each file on Modrinth, creating metadata files for the files that are found
+func (mrFileMatcher) MatchFiles(paths []string, pack core.Pack, index *core.Index) (map[string]string, error) {
+	matched := make(map[string]string)
+	if len(paths) == 0 {
+		return matched, nil
+	}
+	fmt.Printf("Looking up %d files on Modrinth...\n", len(paths))
+	for _, path := range paths {
+		sha1, err := hashLocalFile(path, "sha1")
+		if err != nil {
+			return matched, err
+		}
+		version, err := mrDefaultClient.VersionFiles.GetFromHash(sha1, "sha1")
+		if err != nil || version.ProjectID == nil || version.ID == nil {
+			// Files that aren't on Modrinth aren't matched
+			continue
+		}
+		file := findVersionFile(version, "sha1", sha1)
+		if file == nil {
+			continue
+		}
+		project, err := mrDefaultClient.Projects.Get(*version.ProjectID)
+		if err != nil {
+			fmt.Printf("Failed to retrieve metadata for project %s: %v\n", *version.ProjectID, err)
+			continue
+		}
+		err = createFileMeta(project, version, file, pack, index, false, versionChannel{})
+		if err != nil {
+			fmt.Printf("Failed to create metadata file for %s: %v\n", *project.Title, err)
+			continue
+		}
+		matched[path] = *project.Title + " (" + *file.Filename + ")"
+	}
+	fmt.Printf("Successfully matched %d files\n", len(matched))
+	return matched, nil
+}
+
+// errUnknownFile is returned when a file can't be found on Modrinth by its hashes
+var errUnknownFile = errors.New("file isn't on Modrinth")
+
+// lookupFileVersion finds the version of a project on Modrinth containing a file on disk, by its sha512 and sha1
+// hashes, returning the version and its file; if it isn't found, the error includes the hashes of the file
+func lookupFileVersion(path string) (*modrinthApi.Version, *modrinthApi.File, error) {
+	hashes := make(map[string]string)
+	for _, hashFormat := range []string{"sha512", "sha1"} {
+		hash, err := hashLocalFile(path, hashFormat)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes[hashFormat] = hash
+		version, err := mrDefaultClient.VersionFiles.GetFromHash(hash, hashFormat)
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, nil, fmt.Errorf("failed to look up %s: %w", path, err)
+		} else if err != nil || version.ProjectID == nil || version.ID == nil {
+			continue
+		}
+		if file := findVersionFile(version, hashFormat, hash); file != nil {
+			return version, file, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s (sha1 %s, sha512 %s)", errUnknownFile, path, hashes["sha1"], hashes["sha512"])
+}
+
+// installFile adds the version of a project on Modrinth that contains a file on disk
+func installFile(path string, pack core.Pack, index *core.Index) error {
+	version, file, err := lookupFileVersion(path)
+	if err != nil {
+		return err
+	}
+	project, err := mrDefaultClient.Projects.Get(*version.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch project %s: %w", *version.ProjectID, err)
+	}
+	fmt.Printf("Found %s (%s)\n", *project.Title, *file.Filename)
+	return installVersion(project, version, *file.Filename, pack, index)
+}
+
+// findVersionFile returns the file of a version with the given hash
+func findVersionFile(version *modrinthApi.Version, hashFormat string, hash string) *modrinthApi.File {
+	for _, file := range version.Files {
+		if strings.EqualFold(file.Hashes[hashFormat], hash) {
+			return file
+		}
+	}
+	return nil
+}
+
+// hashLocalFile calculates the hash of a file on disk
+func hashLocalFile(path string, hashFormat string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash, err := core.HashReader(f, hashFormat)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hash, nil
+}
@@ -1,12 +1,14 @@
 package modrinth
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"math"
 	"net/url"
 	"regexp"
 	"slices"
+	"strings"
 
 	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
 	"github.com/0byte-coding/packwiz/cmd"
@@ -22,13 +24,13 @@ var modrinthCmd = &cobra.Command{
 	Short:   "Manage modrinth-based mods",
 }
 
-var mrDefaultClient = modrinthApi.NewClient(newRateLimitHTTPClient())
+var mrDefaultClient = modrinthApi.NewClient(newRateLimitHTTPClient(mrDefaultRequestsPerMinute))
 
 func init() {
 	cmd.Add(modrinthCmd)
 	core.Updaters["modrinth"] = mrUpdater{}
-
-	mrDefaultClient.UserAgent = core.UserAgent
+	core.PackImporters["modrinth"] = mrPackImporter{}
+	core.FileMatchers["modrinth"] = mrFileMatcher{}
 }
 
 func getProjectIdsViaSearch(query string, versions []string) ([]*modrinthApi.SearchResult, error) {
@@ -141,7 +143,9 @@ func getProjectTypeFolder(projectType string, fileLoaders []string, packLoaders
 	if projectType == "modpack" {
 		return "", errors.New("this command should not be used to add Modrinth modpacks, and importing of Modrinth modpacks is not yet supported")
 	} else if projectType == "resourcepack" {
-		return "resourcepacks", nil
+		return core.GetProjectTypeFolder(core.ProjectTypeResourcePack, "resourcepacks")
+	} else if projectType == "datapack" {
+		return core.GetProjectTypeFolder(core.ProjectTypeDatapack, cmp.Or(viper.GetString("datapack-folder"), "datapacks"))
 	} else if projectType == "shader" {
 		bestLoaderIdx := math.MaxInt
 		for _, v := range fileLoaders {
@@ -151,9 +155,9 @@ func getProjectTypeFolder(projectType string, fileLoaders []string, packLoaders
 			}
 		}
 		if bestLoaderIdx > -1 && bestLoaderIdx < math.MaxInt {
-			return loaderFolders[loaderPreferenceList[bestLoaderIdx]], nil
+			return core.GetProjectTypeFolder(core.ProjectTypeShaderPack, loaderFolders[loaderPreferenceList[bestLoaderIdx]])
 		}
-		return "shaderpacks", nil
+		return core.GetProjectTypeFolder(core.ProjectTypeShaderPack, "shaderpacks")
 	} else if projectType == "mod" {
 		// Look up pack loaders in the list of loaders (note this is currently filtered to quilt/fabric/neoforge/forge)
 		bestLoaderIdx := math.MaxInt
@@ -166,19 +170,26 @@ func getProjectTypeFolder(projectType string, fileLoaders []string, packLoaders
 			}
 		}
 		if bestLoaderIdx > -1 && bestLoaderIdx < math.MaxInt {
-			return loaderFolders[loaderPreferenceList[bestLoaderIdx]], nil
+			folder := loaderFolders[loaderPreferenceList[bestLoaderIdx]]
+			if folder == "plugins" {
+				return core.GetProjectTypeFolder(core.ProjectTypePlugin, folder)
+			}
+			return core.GetProjectTypeFolder(core.ProjectTypeMod, folder)
 		}
 
 		// Datapack loader is "datapack"
 		if slices.Contains(fileLoaders, "datapack") {
-			if viper.GetString("datapack-folder") != "" {
-				return viper.GetString("datapack-folder"), nil
-			} else {
+			folder, err := getDatapackFolder()
+			if err != nil {
+				return "", err
+			}
+			if folder == "" {
 				return "", errors.New("set the datapack-folder option to use datapacks")
 			}
+			return folder, nil
 		}
 		// Default to "mods" for mod type
-		return "mods", nil
+		return core.GetProjectTypeFolder(core.ProjectTypeMod, "mods")
 	} else {
 		return "", fmt.Errorf("unknown project type %s", projectType)
 	}
@@ -306,17 +317,106 @@ func findLatestVersion(versions []*modrinthApi.Version, gameVersions []string, u
 	return latestValidVersion
 }
 
-func getLatestVersion(projectID string, name string, pack core.Pack) (*modrinthApi.Version, error) {
-	gameVersions, err := pack.GetSupportedMCVersions()
+// getDatapackFolder returns the folder that datapacks are added to: the datapack folder in the folders field of
+// pack.toml, or the datapack-folder option. An empty string is returned if neither is set.
+func getDatapackFolder() (string, error) {
+	return core.GetProjectTypeFolder(core.ProjectTypeDatapack, viper.GetString("datapack-folder"))
+}
+
+// isDatapackProject returns true if the project is a datapack: Modrinth gives datapacks the mod project type, so
+// projects whose only loader is "datapack" are also datapacks
+func isDatapackProject(project *modrinthApi.Project) bool {
+	if project.ProjectType != nil && *project.ProjectType == "datapack" {
+		return true
+	}
+	return len(project.Loaders) > 0 && !slices.ContainsFunc(project.Loaders, func(loader string) bool {
+		return loader != "datapack"
+	})
+}
+
+// getProjectType returns the type of the project, for choosing the folder it is added to
+func getProjectType(project *modrinthApi.Project) string {
+	if isDatapackProject(project) {
+		return "datapack"
+	}
+	return *project.ProjectType
+}
+
+// getCompatibleMRLoaders returns the Modrinth loaders that files can use to be compatible with the pack; datapacks
+// only match datapack files
+func getCompatibleMRLoaders(pack core.Pack, datapack bool) []string {
+	if datapack {
+		return []string{"datapack"}
+	}
+	if folder, err := getDatapackFolder(); err == nil && folder != "" {
+		return append(pack.GetCompatibleLoaders(), withDatapackPathMRLoaders...)
+	}
+	return append(pack.GetCompatibleLoaders(), defaultMRLoaders...)
+}
+
+// splitVersionsByLoader separates versions for the pack's loaders from versions for loaders that the pack's loaders are
+// compatible with (e.g. Fabric versions in a Quilt pack). Versions that aren't for a mod loader, such as resource packs
+// and shaders, are treated as versions for the pack's loaders.
+func splitVersionsByLoader(versions []*modrinthApi.Version, pack core.Pack) (native []*modrinthApi.Version, fallback []*modrinthApi.Version) {
+	packLoaders := pack.GetLoaders()
+	var fallbackLoaders []string
+	for _, v := range pack.GetCompatibleLoaders() {
+		if !slices.Contains(packLoaders, v) {
+			fallbackLoaders = append(fallbackLoaders, v)
+		}
+	}
+	for _, v := range versions {
+		isNative := slices.ContainsFunc(v.Loaders, func(l string) bool {
+			return slices.Contains(packLoaders, l)
+		})
+		isFallback := slices.ContainsFunc(v.Loaders, func(l string) bool {
+			return slices.Contains(fallbackLoaders, l)
+		})
+		if isNative || !isFallback {
+			native = append(native, v)
+		} else {
+			fallback = append(fallback, v)
+		}
+	}
+	return
+}
+
+// selectLatestVersion picks the latest version on the given channel for the pack, preferring versions for the pack's
+// loaders. Versions for compatible loaders are only used if allowFallback is set and there are no versions for the
+// pack's loaders.
+func selectLatestVersion(versions []*modrinthApi.Version, name string, pack core.Pack, gameVersions []string, allowFallback bool, channel versionChannel) (*modrinthApi.Version, error) {
+	versions, err := channel.filter(versions, name)
 	if err != nil {
 		return nil, err
 	}
-	var loaders []string
-	if viper.GetString("datapack-folder") != "" {
-		loaders = append(pack.GetCompatibleLoaders(), withDatapackPathMRLoaders...)
-	} else {
-		loaders = append(pack.GetCompatibleLoaders(), defaultMRLoaders...)
+	native, fallback := splitVersionsByLoader(versions, pack)
+	if len(native) == 0 {
+		if !allowFallback {
+			return nil, fmt.Errorf("no versions found for %s, but there are versions for a compatible loader (use --allow-fallback-loader to allow them)",
+				strings.Join(pack.GetLoaders(), "/"))
+		}
+		fmt.Printf("No %s versions of %s found, using a version for a compatible loader\n", strings.Join(pack.GetLoaders(), "/"), name)
+		native = fallback
+	}
+
+	// TODO: option to always compare using flexver?
+	// TODO: ask user which one to use?
+	flexverLatest := findLatestVersion(native, gameVersions, true)
+	releaseDateLatest := findLatestVersion(native, gameVersions, false)
+	if flexverLatest != releaseDateLatest && releaseDateLatest.VersionNumber != nil && flexverLatest.VersionNumber != nil {
+		fmt.Printf("Warning: Modrinth versions for %s inconsistent between latest version number and newest release date (%s vs %s)\n", name, *flexverLatest.VersionNumber, *releaseDateLatest.VersionNumber)
+	}
+
+	return releaseDateLatest, nil
+}
+
+// getLatestVersion returns the latest version of a project for the pack; see selectLatestVersion
+func getLatestVersion(projectID string, name string, pack core.Pack, datapack bool, allowFallback bool, channel versionChannel) (*modrinthApi.Version, error) {
+	gameVersions, err := pack.GetSupportedMCVersions()
+	if err != nil {
+		return nil, err
 	}
+	loaders := getCompatibleMRLoaders(pack, datapack)
 
 	result, err := mrDefaultClient.Versions.ListVersions(projectID, modrinthApi.ListVersionsOptions{
 		GameVersions: gameVersions,
@@ -330,15 +430,19 @@ func getLatestVersion(projectID string, name string, pack core.Pack) (*modrinthA
 		return nil, errors.New("no valid versions found\n\tUse the 'packwiz settings acceptable-versions' command to accept more game versions\n\tTo use datapacks, add a datapack loader mod and specify the datapack-folder option with the folder this mod loads datapacks from")
 	}
 
-	// TODO: option to always compare using flexver?
-	// TODO: ask user which one to use?
-	flexverLatest := findLatestVersion(result, gameVersions, true)
-	releaseDateLatest := findLatestVersion(result, gameVersions, false)
-	if flexverLatest != releaseDateLatest && releaseDateLatest.VersionNumber != nil && flexverLatest.VersionNumber != nil {
-		fmt.Printf("Warning: Modrinth versions for %s inconsistent between latest version number and newest release date (%s vs %s)\n", name, *flexverLatest.VersionNumber, *releaseDateLatest.VersionNumber)
-	}
+	return selectLatestVersion(result, name, pack, gameVersions, allowFallback, channel)
+}
 
-	return releaseDateLatest, nil
+// getProjectSide returns the side that a project is added on when added as the given type: resource packs and shaders
+// are only used by the client, other projects use the sides set on Modrinth
+func getProjectSide(project *modrinthApi.Project, projectType string) string {
+	switch projectType {
+	case "resourcepack":
+		return core.DefaultProjectTypeSide(core.ProjectTypeResourcePack)
+	case "shader":
+		return core.DefaultProjectTypeSide(core.ProjectTypeShaderPack)
+	}
+	return getSide(project)
 }
 
 func getSide(mod *modrinthApi.Project) string {
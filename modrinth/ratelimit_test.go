@@ -393,3 +393,111 @@ func TestRateLimitConcurrentRequests(t *testing.T) {
 	t.Logf("Concurrent requests completed successfully: %d total attempts, %d rate limited",
 		attemptCount.Load(), rateLimitCount.Load())
 }
+
+// TestRateLimitModrinthClient verifies that the Modrinth client still uses the shared transport with Modrinth's settings
+func TestRateLimitModrinthClient(t *testing.T) {
+	var attemptCount atomic.Int32
+
+	// Create a test server that returns 429 with a Modrinth style message once, then succeeds
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := attemptCount.Add(1)
+		if attempt == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"rate_limit","description":"You are being rate-limited. Please wait 100 milliseconds. 0/300 remaining."}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client := newRateLimitHTTPClient(mrDefaultRequestsPerMinute)
+	transport, ok := client.Transport.(*rateLimitTransport)
+	if !ok {
+		t.Fatalf("Expected client to use rateLimitTransport, got %T", client.Transport)
+	}
+	if transport.MaxRetries != 100 {
+		t.Errorf("Expected 100 max retries, got %d", transport.MaxRetries)
+	}
+	if transport.Limiter == nil || transport.Limiter.Limit() != 5 {
+		t.Errorf("Expected requests to be limited to 300 per minute")
+	}
+	if transport.ParseWaitTime == nil {
+		t.Fatal("Expected Modrinth wait time parser to be set")
+	}
+	if waitTime := transport.ParseWaitTime("Please wait 20 milliseconds"); waitTime != 20*time.Millisecond {
+		t.Errorf("Expected Modrinth wait time parser to return 20ms, got %v", waitTime)
+	}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	duration := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status OK, got %d", resp.StatusCode)
+	}
+
+	if attemptCount.Load() != 2 {
+		t.Errorf("Expected 2 attempts (1 rate limit + 1 success), got %d", attemptCount.Load())
+	}
+
+	// Should have waited at least the 100ms from the message
+	if duration < 100*time.Millisecond {
+		t.Errorf("Expected at least 100ms duration, got %v", duration)
+	}
+
+	t.Logf("Test completed successfully with %d attempts in %v", attemptCount.Load(), duration)
+}
+
+// TestRateLimitThrottle verifies that requests are slowed down as the X-Ratelimit-Remaining header approaches zero,
+// rather than waiting to be rate limited
+func TestRateLimitThrottle(t *testing.T) {
+	const requests = 10
+	var remaining atomic.Int32
+	remaining.Store(requests)
+	var times []time.Time
+
+	// Create a test server that decrements the remaining request count, with the limit resetting after 200ms
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.Header().Set("X-Ratelimit-Remaining", fmt.Sprint(remaining.Add(-1)))
+		w.Header().Set("X-Ratelimit-Reset", "0.2")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newRateLimitHTTPClient(0)
+	transport := client.Transport.(*rateLimitTransport)
+	if transport.ThrottleThreshold != 20 {
+		t.Errorf("Expected Modrinth client to slow down below 20 remaining requests, got %d", transport.ThrottleThreshold)
+	}
+	transport.ThrottleThreshold = 5
+
+	for range requests {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	for i := 1; i < requests; i++ {
+		// The previous response reported this many remaining requests
+		prevRemaining := requests - i
+		gap := times[i].Sub(times[i-1])
+		if prevRemaining >= 5 {
+			if gap > 100*time.Millisecond {
+				t.Errorf("Expected no delay with %d requests remaining, got %v", prevRemaining, gap)
+			}
+			continue
+		}
+		if expected := 200 * time.Millisecond / time.Duration(prevRemaining+1); gap < expected {
+			t.Errorf("Expected a delay of at least %v with %d requests remaining, got %v", expected, prevRemaining, gap)
+		}
+	}
+}
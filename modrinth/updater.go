@@ -4,9 +4,13 @@ import (
 	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
 )
 
 type mrUpdateData struct {
@@ -14,6 +18,27 @@ type mrUpdateData struct {
 	ProjectID string `mapstructure:"mod-id"`
 	// TODO(format): change to "version-id"
 	InstalledVersion string `mapstructure:"version"`
+	// Channel is the least stable version type (release, beta or alpha) that the file is updated to; if empty, all
+	// version types are allowed
+	Channel string `mapstructure:"channel,omitempty"`
+	// AllowBeta allows beta versions on the release channel if the project has no releases
+	AllowBeta bool `mapstructure:"allow-beta,omitempty"`
+	// Datapack is set for datapack projects, so that they are only updated to datapack files
+	Datapack bool `mapstructure:"datapack,omitempty"`
+}
+
+// channel returns the channel that the file is updated from: the channel given with --channel, or the stored one
+func (u mrUpdateData) channel() (versionChannel, error) {
+	channel := versionChannel{Name: u.Channel, AllowBeta: u.AllowBeta}
+	if name := viper.GetString("update.channel"); name != "" {
+		channel.Name = name
+	}
+	if channel.Name != "" {
+		if err := validateChannel(channel.Name); err != nil {
+			return versionChannel{}, err
+		}
+	}
+	return channel, nil
 }
 
 func (u mrUpdateData) ToMap() (map[string]interface{}, error) {
@@ -30,6 +55,16 @@ func (u mrUpdater) ParseUpdate(updateUnparsed map[string]interface{}) (interface
 	return updateData, err
 }
 
+func (u mrUpdater) ProjectPage(mod *core.Mod) (string, error) {
+	rawData, ok := mod.GetParsedUpdateData("modrinth")
+	if !ok {
+		return "", errors.New("failed to parse update metadata")
+	}
+	data := rawData.(mrUpdateData)
+	// Modrinth redirects to the page for the project's type
+	return "https://modrinth.com/project/" + url.PathEscape(data.ProjectID), nil
+}
+
 type cachedStateStore struct {
 	ProjectID string
 	Version   *modrinthApi.Version
@@ -47,7 +82,13 @@ func (u mrUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 
 		data := rawData.(mrUpdateData)
 
-		newVersion, err := getLatestVersion(data.ProjectID, mod.Name, pack)
+		channel, err := data.channel()
+		if err != nil {
+			results[i] = core.UpdateCheck{Error: err}
+			continue
+		}
+		// Versions for compatible loaders are allowed, as the installed version may already be one
+		newVersion, err := getLatestVersion(data.ProjectID, mod.Name, pack, data.Datapack, true, channel)
 		if err != nil {
 			results[i] = core.UpdateCheck{Error: fmt.Errorf("failed to get latest version: %v", err)}
 			continue
@@ -74,6 +115,8 @@ func (u mrUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 		results[i] = core.UpdateCheck{
 			UpdateAvailable: true,
 			UpdateString:    mod.FileName + " -> " + *newFilename,
+			CurrentVersion:  mod.FileName,
+			NewVersion:      *newFilename,
 			CachedState:     cachedStateStore{data.ProjectID, newVersion},
 		}
 	}
@@ -81,6 +124,58 @@ func (u mrUpdater) CheckUpdate(mods []*core.Mod, pack core.Pack) ([]core.UpdateC
 	return results, nil
 }
 
+func (u mrUpdater) CheckUpdateToVersion(mod *core.Mod, versionID string, pack core.Pack) (core.UpdateCheck, error) {
+	rawData, ok := mod.GetParsedUpdateData("modrinth")
+	if !ok {
+		return core.UpdateCheck{}, errors.New("failed to parse update metadata")
+	}
+	data := rawData.(mrUpdateData)
+
+	version, err := mrDefaultClient.Versions.Get(versionID)
+	if err != nil {
+		return core.UpdateCheck{}, fmt.Errorf("failed to get version %s: %w", versionID, err)
+	}
+	if *version.ProjectID != data.ProjectID {
+		return core.UpdateCheck{}, fmt.Errorf("version %s is not a version of %s", versionID, mod.Name)
+	}
+	if *version.ID == data.InstalledVersion {
+		return core.UpdateCheck{UpdateAvailable: false}, nil
+	}
+	if len(version.Files) == 0 {
+		return core.UpdateCheck{}, errors.New("version doesn't have any files")
+	}
+
+	gameVersions, err := pack.GetSupportedMCVersions()
+	if err != nil {
+		return core.UpdateCheck{}, err
+	}
+	if core.HighestSliceIndex(gameVersions, version.GameVersions) < 0 {
+		fmt.Printf("Warning: version %s of %s doesn't support any of the pack's Minecraft versions (%s)\n",
+			*version.VersionNumber, mod.Name, strings.Join(gameVersions, ", "))
+	}
+	loaders := getCompatibleMRLoaders(pack, data.Datapack)
+	if !slices.ContainsFunc(version.Loaders, func(loader string) bool { return slices.Contains(loaders, loader) }) {
+		fmt.Printf("Warning: version %s of %s doesn't support any of the pack's loaders (supports %s)\n",
+			*version.VersionNumber, mod.Name, strings.Join(version.Loaders, ", "))
+	}
+
+	newFilename := version.Files[0].Filename
+	// Prefer the primary file
+	for _, v := range version.Files {
+		if *v.Primary {
+			newFilename = v.Filename
+		}
+	}
+
+	return core.UpdateCheck{
+		UpdateAvailable: true,
+		UpdateString:    mod.FileName + " -> " + *newFilename,
+		CurrentVersion:  mod.FileName,
+		NewVersion:      *newFilename,
+		CachedState:     cachedStateStore{data.ProjectID, version},
+	}, nil
+}
+
 func (u mrUpdater) DoUpdate(mods []*core.Mod, cachedState []interface{}) error {
 	for i, mod := range mods {
 		modState := cachedState[i].(cachedStateStore)
@@ -106,6 +201,9 @@ func (u mrUpdater) DoUpdate(mods []*core.Mod, cachedState []interface{}) error {
 			Hash:       hash,
 		}
 		mod.Update["modrinth"]["version"] = version.ID
+		if name := viper.GetString("update.channel"); name != "" {
+			mod.Update["modrinth"]["channel"] = name
+		}
 	}
 
 	return nil
@@ -0,0 +1,185 @@
+package modrinth
+
+import (
+	"testing"
+	"time"
+
+	modrinthApi "codeberg.org/jmansfield/go-modrinth/modrinth"
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func versionTestVersion(id string, daysAgo int, loaders ...string) *modrinthApi.Version {
+	published := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -daysAgo)
+	number := "1.0.0"
+	return &modrinthApi.Version{
+		ID:            &id,
+		VersionNumber: &number,
+		GameVersions:  []string{"1.20.1"},
+		Loaders:       loaders,
+		DatePublished: &published,
+	}
+}
+
+func TestSelectLatestVersionByLoader(t *testing.T) {
+	fabricPack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"}}
+	quiltPack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "quilt": "0.20.0"}}
+	gameVersions := []string{"1.20.1"}
+
+	tests := []struct {
+		name          string
+		pack          core.Pack
+		versions      []*modrinthApi.Version
+		allowFallback bool
+		expected      string
+	}{
+		{
+			name: "newest version for the pack's loader",
+			pack: fabricPack,
+			versions: []*modrinthApi.Version{
+				versionTestVersion("old-fabric", 10, "fabric"),
+				versionTestVersion("new-both", 1, "fabric", "quilt"),
+				versionTestVersion("mid-fabric", 5, "fabric"),
+			},
+			expected: "new-both",
+		},
+		{
+			name: "pack's loader preferred over newer fallback versions",
+			pack: quiltPack,
+			versions: []*modrinthApi.Version{
+				versionTestVersion("new-fabric", 1, "fabric"),
+				versionTestVersion("old-quilt", 10, "quilt"),
+			},
+			allowFallback: true,
+			expected:      "old-quilt",
+		},
+		{
+			name: "fallback loader when allowed",
+			pack: quiltPack,
+			versions: []*modrinthApi.Version{
+				versionTestVersion("old-fabric", 10, "fabric"),
+				versionTestVersion("new-fabric", 1, "fabric"),
+			},
+			allowFallback: true,
+			expected:      "new-fabric",
+		},
+		{
+			name: "fallback loader not allowed",
+			pack: quiltPack,
+			versions: []*modrinthApi.Version{
+				versionTestVersion("new-fabric", 1, "fabric"),
+			},
+			expected: "",
+		},
+		{
+			name: "resource packs aren't filtered",
+			pack: quiltPack,
+			versions: []*modrinthApi.Version{
+				versionTestVersion("resourcepack", 1, "minecraft"),
+				versionTestVersion("fabric", 0, "fabric"),
+			},
+			expected: "resourcepack",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := selectLatestVersion(tt.versions, "Test", tt.pack, gameVersions, tt.allowFallback, versionChannel{})
+			if tt.expected == "" {
+				if err == nil {
+					t.Errorf("Expected an error, got version %s", *version.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected version %s, got error %v", tt.expected, err)
+			}
+			if *version.ID != tt.expected {
+				t.Errorf("Expected version %s, got %s", tt.expected, *version.ID)
+			}
+		})
+	}
+}
+
+func channelTestVersion(id string, daysAgo int, versionType string) *modrinthApi.Version {
+	v := versionTestVersion(id, daysAgo, "fabric")
+	v.VersionType = &versionType
+	return v
+}
+
+func TestSelectLatestVersionByChannel(t *testing.T) {
+	pack := core.Pack{Versions: map[string]string{"minecraft": "1.20.1", "fabric": "0.15.0"}}
+	gameVersions := []string{"1.20.1"}
+	mixed := []*modrinthApi.Version{
+		channelTestVersion("old-release", 10, "release"),
+		channelTestVersion("beta", 5, "beta"),
+		channelTestVersion("release", 3, "release"),
+		channelTestVersion("alpha", 1, "alpha"),
+	}
+	prerelease := []*modrinthApi.Version{
+		channelTestVersion("old-beta", 5, "beta"),
+		channelTestVersion("beta", 3, "beta"),
+		channelTestVersion("alpha", 1, "alpha"),
+	}
+	alphaOnly := []*modrinthApi.Version{
+		channelTestVersion("alpha", 1, "alpha"),
+	}
+
+	tests := []struct {
+		name     string
+		versions []*modrinthApi.Version
+		channel  versionChannel
+		expected string
+	}{
+		{"release channel skips newer prereleases", mixed, versionChannel{Name: "release"}, "release"},
+		{"beta channel allows betas", mixed, versionChannel{Name: "beta"}, "release"},
+		{"beta channel skips alphas", prerelease, versionChannel{Name: "beta"}, "beta"},
+		{"alpha channel allows everything", mixed, versionChannel{Name: "alpha"}, "alpha"},
+		{"no channel allows everything", mixed, versionChannel{}, "alpha"},
+		{"release channel without releases", prerelease, versionChannel{Name: "release"}, ""},
+		{"release channel falls back to betas", prerelease, versionChannel{Name: "release", AllowBeta: true}, "beta"},
+		{"beta fallback prefers releases", mixed, versionChannel{Name: "release", AllowBeta: true}, "release"},
+		{"beta fallback doesn't allow alphas", alphaOnly, versionChannel{Name: "release", AllowBeta: true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := selectLatestVersion(tt.versions, "Test", pack, gameVersions, false, tt.channel)
+			if tt.expected == "" {
+				if err == nil {
+					t.Errorf("Expected an error, got version %s", *version.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected version %s, got error %v", tt.expected, err)
+			}
+			if *version.ID != tt.expected {
+				t.Errorf("Expected version %s, got %s", tt.expected, *version.ID)
+			}
+		})
+	}
+}
+
+func TestUpdateDataChannel(t *testing.T) {
+	data := mrUpdateData{ProjectID: "AAAAAAAA", InstalledVersion: "BBBBBBBB", Channel: "beta", AllowBeta: true}
+	m, err := data.ToMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["channel"] != "beta" || m["allow-beta"] != true {
+		t.Errorf("Expected the channel to be stored, got %v", m)
+	}
+	parsed, err := mrUpdater{}.ParseUpdate(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.(mrUpdateData) != data {
+		t.Errorf("Expected %v after parsing, got %v", data, parsed)
+	}
+
+	m, err = mrUpdateData{ProjectID: "AAAAAAAA", InstalledVersion: "BBBBBBBB"}.ToMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["channel"]; ok {
+		t.Errorf("Expected no channel to be stored for files without one, got %v", m)
+	}
+}
@@ -23,10 +23,10 @@ var acceptableVersionsCommand = &cobra.Command{
 			// Check if it's a no such file or directory error
 			if os.IsNotExist(err) {
 				fmt.Println("No pack.toml file found, run 'packwiz init' to create one!")
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitNotFound)
 			}
 			fmt.Printf("Error loading pack: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		var currentVersions []string
 		// Check if they have no options whatsoever
@@ -47,7 +47,7 @@ var acceptableVersionsCommand = &cobra.Command{
 			// Check if the version is already in the list
 			if slices.Contains(currentVersions, acceptableVersion) {
 				fmt.Printf("Version %s is already in your acceptable versions list!\n", acceptableVersion)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitGeneric)
 			}
 			// Add the version to the list and re-sort it
 			currentVersions = append(currentVersions, acceptableVersion)
@@ -58,7 +58,7 @@ var acceptableVersionsCommand = &cobra.Command{
 			err = modpack.Write()
 			if err != nil {
 				fmt.Printf("Error writing pack: %s\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 			// Print success message
 			prettyList := strings.Join(currentVersions, ", ")
@@ -69,7 +69,7 @@ var acceptableVersionsCommand = &cobra.Command{
 			// Check if the version is in the list
 			if !slices.Contains(currentVersions, acceptableVersion) {
 				fmt.Printf("Version %s is not in your acceptable versions list!\n", acceptableVersion)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitNotFound)
 			}
 			// Remove the version from the list
 			i := slices.Index(currentVersions, acceptableVersion)
@@ -82,7 +82,7 @@ var acceptableVersionsCommand = &cobra.Command{
 			err = modpack.Write()
 			if err != nil {
 				fmt.Printf("Error writing pack: %s\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 			// Print success message
 			prettyList := strings.Join(currentVersions, ", ")
@@ -112,7 +112,11 @@ var acceptableVersionsCommand = &cobra.Command{
 						copy(acceptableVersionsDedupedClone, acceptableVersionsDeduped)
 						flexver.VersionSlice(acceptableVersionsDedupedClone).Sort()
 						fmt.Printf("Did you mean %s?\n", strings.Join(acceptableVersionsDedupedClone, ", "))
-						if cmdshared.PromptYesNo("Would you like to fix this automatically? [Y/n] ") {
+						fix, err := cmdshared.PromptYesNo("Would you like to fix this automatically? [Y/n] ")
+						if err != nil {
+							cmdshared.ExitWithError(err)
+						}
+						if fix {
 							// If yes we'll just set the list to the sorted one
 							acceptableVersionsDeduped = acceptableVersionsDedupedClone
 							break
@@ -127,7 +131,7 @@ var acceptableVersionsCommand = &cobra.Command{
 			err = modpack.Write()
 			if err != nil {
 				fmt.Printf("Error writing pack: %s\n", err)
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitCode(err))
 			}
 			// Print success message
 			prettyList := strings.Join(acceptableVersionsDeduped, ", ")
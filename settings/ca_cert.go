@@ -0,0 +1,77 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// applyBaseTransport sets up the transport used for all HTTP requests with the configured CA certificate. If it can't
+// be read, a warning is printed and only the system roots are trusted.
+func applyBaseTransport() {
+	transport, err := core.NewBaseTransport(viper.GetString("ca-cert"))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		transport, _ = core.NewBaseTransport("")
+	}
+	core.SetBaseTransport(transport)
+}
+
+var caCertCmd = &cobra.Command{
+	Use:   "ca-cert [path]",
+	Short: "Set a CA certificate to trust for HTTPS requests",
+	Long: `Set a PEM encoded CA certificate to trust for HTTPS requests (as well as the system roots), saved in your user
+config. This is needed if your network intercepts HTTPS connections with an internal CA.
+Proxies are configured with the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+Without arguments, the current CA certificate is printed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetBool("settings.ca-cert.reset") {
+			err := setUserConfigValue("ca-cert", nil)
+			if err != nil {
+				fmt.Printf("Error saving CA certificate: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+			fmt.Println("Custom CA certificate removed; only the system roots are trusted")
+			return
+		}
+		if len(args) == 0 {
+			if file := viper.GetString("ca-cert"); file != "" {
+				fmt.Println(file)
+			} else {
+				fmt.Println("No custom CA certificate is set")
+			}
+			return
+		}
+
+		file, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Printf("Invalid CA certificate path: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		// Check that the certificate can be loaded before saving it
+		_, err = core.NewBaseTransport(file)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = setUserConfigValue("ca-cert", file)
+		if err != nil {
+			fmt.Printf("Error saving CA certificate: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("CA certificate set to %s\n", file)
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(caCertCmd)
+	cobra.OnInitialize(applyBaseTransport)
+
+	caCertCmd.Flags().Bool("reset", false, "Remove the custom CA certificate")
+	_ = viper.BindPFlag("settings.ca-cert.reset", caCertCmd.Flags().Lookup("reset"))
+}
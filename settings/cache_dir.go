@@ -0,0 +1,58 @@
+package settings
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var cacheDirCmd = &cobra.Command{
+	Use:   "cache-dir [directory]",
+	Short: "Set the directory where downloaded files are cached",
+	Long: `Set the directory where downloaded files are cached, saved in your user config.
+Files in the cache are stored by their hash, so they can be reused by any pack (and in offline mode) without being
+downloaded again. Without arguments, the current cache directory is printed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetBool("settings.cache-dir.reset") {
+			err := setUserConfigValue("cache.directory", nil)
+			if err != nil {
+				fmt.Printf("Error saving cache directory: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+			fmt.Println("Cache directory reset to the default location")
+			return
+		}
+		if len(args) == 0 {
+			dir, err := core.GetPackwizCache()
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
+			fmt.Println(dir)
+			return
+		}
+
+		dir, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Printf("Invalid cache directory: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		err = setUserConfigValue("cache.directory", dir)
+		if err != nil {
+			fmt.Printf("Error saving cache directory: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("Cache directory set to %s\n", dir)
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(cacheDirCmd)
+
+	cacheDirCmd.Flags().Bool("reset", false, "Use the default cache directory again")
+	_ = viper.BindPFlag("settings.cache-dir.reset", cacheDirCmd.Flags().Lookup("reset"))
+}
@@ -0,0 +1,126 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// cfApiKeyEnv is the environment variable that overrides the CurseForge API key stored in the user config
+const cfApiKeyEnv = "CF_API_KEY"
+
+// cfApiKeyFileEnv is the environment variable naming a file that contains the CurseForge API key, for secret managers
+// that provide credentials as files
+const cfApiKeyFileEnv = "CF_API_KEY_FILE"
+
+// GetCurseForgeAPIKey returns the CurseForge API key given by the --cf-api-key flag, the CF_API_KEY environment
+// variable, the file named by CF_API_KEY_FILE or the user config, in that order, or an empty string if no key is
+// configured
+func GetCurseForgeAPIKey() (string, error) {
+	key, _, err := getCurseForgeAPIKey()
+	return key, err
+}
+
+// getCurseForgeAPIKey returns the configured CurseForge API key, and a description of where it was found
+func getCurseForgeAPIKey() (string, string, error) {
+	if key := viper.GetString("cf-api-key"); key != "" {
+		return key, "the --cf-api-key flag", nil
+	}
+	if key := os.Getenv(cfApiKeyEnv); key != "" {
+		return key, "the " + cfApiKeyEnv + " environment variable", nil
+	}
+	if path := os.Getenv(cfApiKeyFileEnv); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read the CurseForge API key from %s (set by %s): %w", path, cfApiKeyFileEnv, err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return "", "", fmt.Errorf("the CurseForge API key file %s (set by %s) is empty", path, cfApiKeyFileEnv)
+		}
+		return key, path + " (set by the " + cfApiKeyFileEnv + " environment variable)", nil
+	}
+	return viper.GetString("curseforge.api-key"), "", nil
+}
+
+// SetCurseForgeAPIKey stores the CurseForge API key in the user config, or removes it if key is empty
+func SetCurseForgeAPIKey(key string) error {
+	if key == "" {
+		return setUserConfigValue("curseforge.api-key", nil)
+	}
+	return setUserConfigValue("curseforge.api-key", key)
+}
+
+var cfApiKeyCmd = &cobra.Command{
+	Use:   "cf-api-key",
+	Short: "Manage the CurseForge API key used by packwiz",
+	Long: `Manage the CurseForge API key used by packwiz.
+The key is stored in your user config file, which is only readable by you. The --cf-api-key flag, the ` + cfApiKeyEnv + `
+environment variable and the ` + cfApiKeyFileEnv + ` environment variable (naming a file that contains the key) override the
+stored key, in that order. You can get a key from https://console.curseforge.com/`,
+}
+
+var cfApiKeySetCmd = &cobra.Command{
+	Use:   "set [key]",
+	Short: "Store the CurseForge API key in your user config",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := SetCurseForgeAPIKey(args[0])
+		if err != nil {
+			fmt.Printf("Error saving API key: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		file, _ := userConfigFile()
+		fmt.Printf("CurseForge API key saved to %s\n", file)
+		if _, source, err := getCurseForgeAPIKey(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		} else if source != "" {
+			fmt.Printf("Note: the key from %s will be used instead of the saved key\n", source)
+		}
+	},
+}
+
+var cfApiKeyGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Print the configured CurseForge API key",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		key, source, err := getCurseForgeAPIKey()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		if key == "" {
+			fmt.Println("No CurseForge API key is configured; run packwiz settings cf-api-key set <key> to set one")
+			cmdshared.Exit(cmdshared.ExitNotFound)
+		}
+		if source != "" {
+			fmt.Printf("(from %s)\n", source)
+		}
+		fmt.Println(key)
+	},
+}
+
+var cfApiKeyUnsetCmd = &cobra.Command{
+	Use:   "unset",
+	Short: "Remove the CurseForge API key from your user config",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := SetCurseForgeAPIKey("")
+		if err != nil {
+			fmt.Printf("Error removing API key: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Println("CurseForge API key removed")
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(cfApiKeyCmd)
+	cfApiKeyCmd.AddCommand(cfApiKeySetCmd)
+	cfApiKeyCmd.AddCommand(cfApiKeyGetCmd)
+	cfApiKeyCmd.AddCommand(cfApiKeyUnsetCmd)
+}
@@ -0,0 +1,115 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCurseForgeAPIKeyRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".packwiz.toml")
+	if err := os.WriteFile(file, []byte("[rate-limit]\nmax-retries = 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(file)
+	t.Cleanup(func() {
+		viper.SetConfigFile("")
+		viper.Set("curseforge.api-key", "")
+	})
+	t.Setenv(cfApiKeyEnv, "")
+	t.Setenv(cfApiKeyFileEnv, "")
+
+	if err := SetCurseForgeAPIKey("test-key"); err != nil {
+		t.Fatalf("Failed to set API key: %v", err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected config file permissions 0600, got %o", perm)
+	}
+
+	cfg, err := loadUserConfig(file)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if key := cfg["curseforge"].(map[string]any)["api-key"]; key != "test-key" {
+		t.Errorf("Expected stored key test-key, got %v", key)
+	}
+	if retries := cfg["rate-limit"].(map[string]any)["max-retries"]; retries != int64(3) {
+		t.Errorf("Expected other settings to be kept, got %v", cfg)
+	}
+	if key, _ := GetCurseForgeAPIKey(); key != "test-key" {
+		t.Errorf("Expected API key test-key, got %q", key)
+	}
+
+	t.Setenv(cfApiKeyEnv, "env-key")
+	if key, _ := GetCurseForgeAPIKey(); key != "env-key" {
+		t.Errorf("Expected API key from environment, got %q", key)
+	}
+	t.Setenv(cfApiKeyEnv, "")
+
+	if err := SetCurseForgeAPIKey(""); err != nil {
+		t.Fatalf("Failed to unset API key: %v", err)
+	}
+	cfg, err = loadUserConfig(file)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if cf, ok := cfg["curseforge"].(map[string]any); ok && cf["api-key"] != nil {
+		t.Errorf("Expected key to be removed, got %v", cfg)
+	}
+	if key, _ := GetCurseForgeAPIKey(); key != "" {
+		t.Errorf("Expected no API key, got %q", key)
+	}
+}
+
+func TestCurseForgeAPIKeyPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "cf-api-key")
+	if err := os.WriteFile(keyFile, []byte("  file-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("curseforge.api-key", "stored-key")
+	viper.Set("cf-api-key", "flag-key")
+	t.Cleanup(func() {
+		viper.Set("curseforge.api-key", "")
+		viper.Set("cf-api-key", "")
+	})
+	t.Setenv(cfApiKeyEnv, "env-key")
+	t.Setenv(cfApiKeyFileEnv, keyFile)
+
+	expectKey := func(expected string) {
+		t.Helper()
+		key, err := GetCurseForgeAPIKey()
+		if err != nil {
+			t.Fatalf("Failed to get API key: %v", err)
+		}
+		if key != expected {
+			t.Errorf("Expected API key %q, got %q", expected, key)
+		}
+	}
+	expectKey("flag-key")
+	viper.Set("cf-api-key", "")
+	expectKey("env-key")
+	t.Setenv(cfApiKeyEnv, "")
+	expectKey("file-key")
+	t.Setenv(cfApiKeyFileEnv, "")
+	expectKey("stored-key")
+
+	// A key file that can't be read is reported rather than ignored
+	t.Setenv(cfApiKeyFileEnv, filepath.Join(dir, "missing"))
+	if _, err := GetCurseForgeAPIKey(); err == nil {
+		t.Error("Expected an error for a missing key file")
+	}
+	if err := os.WriteFile(keyFile, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(cfApiKeyFileEnv, keyFile)
+	if _, err := GetCurseForgeAPIKey(); err == nil {
+		t.Error("Expected an error for an empty key file")
+	}
+}
@@ -0,0 +1,49 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// GetDefaultHashFormat returns the configured hash format for new files and the index, or sha256 if none is configured
+func GetDefaultHashFormat() string {
+	return core.GetDefaultHashFormat()
+}
+
+var defaultHashCmd = &cobra.Command{
+	Use:   "default-hash [sha1|sha256|sha512|blake3|murmur2]",
+	Short: "Set the hash format used for new files and the index",
+	Long: `Set the hash format used for new files and the index, saved in your user config.
+When a default hash format is set, refresh also converts existing packs to use it, so that all hashes in a pack use
+the same format. Without arguments, the current default is printed.
+Note that blake3 hashes can only be read by tools that support them.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"sha1", "sha256", "sha512", "blake3", "murmur2"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			fmt.Println(GetDefaultHashFormat())
+			return
+		}
+
+		format := strings.ToLower(args[0])
+		err := core.ValidateHashFormat(format)
+		if err != nil {
+			fmt.Printf("Invalid hash format: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		err = setUserConfigValue("default-hash-format", format)
+		if err != nil {
+			fmt.Printf("Error saving default hash format: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("Default hash format set to %s; run packwiz refresh to update existing packs\n", format)
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(defaultHashCmd)
+}
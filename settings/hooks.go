@@ -0,0 +1,88 @@
+package settings
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage shell commands run before and after packwiz operations",
+	Long: `Manage shell commands run before and after packwiz operations.
+Hooks are stored in your user config (not the pack, so packs can't run commands on your computer) and run with the
+pack root as the working directory. Hooks are named pre-<operation> or post-<operation>, for the operations: ` + strings.Join(cmdshared.HookOperations, ", ") + `.
+If a pre- hook exits with a non-zero code, the operation is aborted; if a post- hook does, a warning is shown.
+Hooks are given these environment variables:
+  PACKWIZ_HOOK           the name of the hook
+  PACKWIZ_ARGS           the arguments of the command, separated by spaces
+  PACKWIZ_CHANGED_FILES  (post- hooks only) the files changed by the operation, one per line
+  PACKWIZ_MOD_NAMES      (post- hooks only) the names of the changed metadata files, one per line`,
+}
+
+// validateHookName exits if name isn't the name of a hook
+func validateHookName(name string) {
+	if !slices.Contains(cmdshared.HookNames(), name) {
+		fmt.Printf("Unknown hook %s; must be one of %s\n", name, strings.Join(cmdshared.HookNames(), ", "))
+		cmdshared.Exit(cmdshared.ExitUsage)
+	}
+}
+
+var hooksSetCmd = &cobra.Command{
+	Use:   "set [hook] [command]",
+	Short: "Set the shell command run by a hook",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		validateHookName(args[0])
+		err := setUserConfigValue("hooks."+args[0], args[1])
+		if err != nil {
+			fmt.Printf("Error saving hook: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("%s hook set\n", args[0])
+	},
+}
+
+var hooksUnsetCmd = &cobra.Command{
+	Use:   "unset [hook]",
+	Short: "Remove the shell command run by a hook",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		validateHookName(args[0])
+		err := setUserConfigValue("hooks."+args[0], nil)
+		if err != nil {
+			fmt.Printf("Error removing hook: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("%s hook removed\n", args[0])
+	},
+}
+
+var hooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured hooks",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		found := false
+		for _, name := range cmdshared.HookNames() {
+			if command := viper.GetString("hooks." + name); command != "" {
+				fmt.Printf("%s: %s\n", name, command)
+				found = true
+			}
+		}
+		if !found {
+			fmt.Println("No hooks are configured")
+		}
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksSetCmd)
+	hooksCmd.AddCommand(hooksUnsetCmd)
+	hooksCmd.AddCommand(hooksListCmd)
+}
@@ -0,0 +1,79 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// GetHTTPTimeout returns the configured time to wait for the response to an HTTP request, or zero if the timeout is
+// disabled
+func GetHTTPTimeout() time.Duration {
+	if !viper.IsSet("http-timeout") {
+		return core.DefaultHTTPTimeout
+	}
+	return max(viper.GetDuration("http-timeout"), 0)
+}
+
+var httpTimeoutCmd = &cobra.Command{
+	Use:   "http-timeout [duration]",
+	Short: "Set how long to wait for a response to HTTP requests",
+	Long: fmt.Sprintf(`Set how long to wait for a response to each HTTP request (such as 10s or 2m), saved in your user
+config. The timeout applies to each attempt separately, so retries of rate limited requests aren't affected, and
+restarts whenever more of a download is received, so large downloads aren't cut off as long as data keeps arriving.
+A duration of 0 disables the timeout. The default is %v; without arguments, the current timeout is printed.`, core.DefaultHTTPTimeout),
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.GetBool("settings.http-timeout.reset") {
+			err := setUserConfigValue("http-timeout", nil)
+			if err != nil {
+				fmt.Printf("Error saving HTTP timeout: %v\n", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+			fmt.Printf("HTTP timeout reset to the default of %v\n", core.DefaultHTTPTimeout)
+			return
+		}
+		if len(args) == 0 {
+			if timeout := GetHTTPTimeout(); timeout > 0 {
+				fmt.Println(timeout)
+			} else {
+				fmt.Println("The HTTP timeout is disabled")
+			}
+			return
+		}
+
+		timeout, err := time.ParseDuration(args[0])
+		if err == nil && timeout < 0 {
+			err = errors.New("duration must not be negative")
+		}
+		if err != nil {
+			fmt.Printf("Invalid HTTP timeout: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+		err = setUserConfigValue("http-timeout", timeout.String())
+		if err != nil {
+			fmt.Printf("Error saving HTTP timeout: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		if timeout == 0 {
+			fmt.Println("HTTP timeout disabled")
+		} else {
+			fmt.Printf("HTTP timeout set to %v\n", timeout)
+		}
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(httpTimeoutCmd)
+	cobra.OnInitialize(func() {
+		core.SetHTTPTimeout(GetHTTPTimeout())
+	})
+
+	httpTimeoutCmd.Flags().Bool("reset", false, "Use the default HTTP timeout again")
+	_ = viper.BindPFlag("settings.http-timeout.reset", httpTimeoutCmd.Flags().Lookup("reset"))
+}
@@ -0,0 +1,96 @@
+package settings
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// GetMaxRetries returns the configured maximum number of retries for rate limited requests
+func GetMaxRetries() int {
+	maxRetries := viper.GetInt("rate-limit.max-retries")
+	if maxRetries <= 0 {
+		return core.DefaultMaxRetries
+	}
+	return maxRetries
+}
+
+// GetBackoffBase returns the configured wait time before the first retry of a rate limited request
+func GetBackoffBase() time.Duration {
+	backoffBase := viper.GetDuration("rate-limit.backoff-base")
+	if backoffBase <= 0 {
+		return core.DefaultBackoffBase
+	}
+	return backoffBase
+}
+
+// GetServerErrorRetries returns the configured number of times idempotent requests are retried after a server error
+func GetServerErrorRetries() int {
+	return max(viper.GetInt("rate-limit.server-error-retries"), 0)
+}
+
+// GetThrottleThreshold returns the configured number of remaining requests below which requests are slowed down, or
+// zero if slowing down is disabled
+func GetThrottleThreshold() int {
+	if !viper.GetBool("rate-limit.throttle") {
+		return 0
+	}
+	return max(viper.GetInt("rate-limit.throttle-threshold"), 0)
+}
+
+// rateLimitLogger returns the logger used to report rate limit retries, which writes to stderr so that it doesn't
+// interfere with command output, or discards messages if --quiet is set
+func rateLimitLogger() func(format string, args ...any) {
+	if viper.GetBool("quiet") {
+		return func(format string, args ...any) {}
+	}
+	return func(format string, args ...any) {
+		_, _ = fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// NewRateLimitHTTPClient creates a new HTTP client with rate limit retry logic for the given API, using the configured
+// retry settings. Settings are applied again once flags and config files have been read.
+func NewRateLimitHTTPClient(apiName string, requestsPerMinute int, parseWaitTime core.WaitTimeParser) *http.Client {
+	client := core.NewRateLimitHTTPClient(apiName, GetMaxRetries(), requestsPerMinute, parseWaitTime)
+	transport := client.Transport.(*core.RateLimitTransport)
+	transport.BaseBackoff = GetBackoffBase()
+	transport.Logger = rateLimitLogger()
+	cobra.OnInitialize(func() {
+		transport.MaxRetries = GetMaxRetries()
+		transport.BaseBackoff = GetBackoffBase()
+		transport.Logger = rateLimitLogger()
+	})
+	return client
+}
+
+// EnableServerErrorRetries makes a client created by NewRateLimitHTTPClient retry idempotent requests that fail with a
+// transient server error, using the configured retry count
+func EnableServerErrorRetries(client *http.Client) *http.Client {
+	transport := client.Transport.(*core.RateLimitTransport)
+	transport.ServerErrorRetries = GetServerErrorRetries()
+	cobra.OnInitialize(func() {
+		transport.ServerErrorRetries = GetServerErrorRetries()
+	})
+	return client
+}
+
+// EnableThrottling makes a client created by NewRateLimitHTTPClient slow down when responses report that few requests
+// remain before being rate limited, using the configured threshold
+func EnableThrottling(client *http.Client) *http.Client {
+	transport := client.Transport.(*core.RateLimitTransport)
+	transport.ThrottleThreshold = GetThrottleThreshold()
+	cobra.OnInitialize(func() {
+		transport.ThrottleThreshold = GetThrottleThreshold()
+	})
+	return client
+}
+
+func init() {
+	core.DownloadHTTPClient = NewRateLimitHTTPClient("download server", 0, nil)
+}
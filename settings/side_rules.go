@@ -0,0 +1,111 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// loadSideRules loads the pack and its side rules, exiting if either can't be read
+func loadSideRules() (core.Pack, []core.SideRule) {
+	modpack, err := core.LoadPack()
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("No pack.toml file found, run 'packwiz init' to create one!")
+			cmdshared.Exit(cmdshared.ExitNotFound)
+		}
+		fmt.Printf("Error loading pack: %s\n", err)
+		cmdshared.Exit(cmdshared.ExitCode(err))
+	}
+	rules, err := modpack.GetSideRules()
+	if err != nil {
+		cmdshared.ExitWithError(err)
+	}
+	return modpack, rules
+}
+
+// writeSideRules stores the given side rules in the pack, exiting if it can't be written
+func writeSideRules(modpack core.Pack, rules []core.SideRule) {
+	modpack.SetSideRules(rules)
+	err := modpack.Write()
+	if err != nil {
+		fmt.Printf("Error writing pack: %s\n", err)
+		cmdshared.Exit(cmdshared.ExitCode(err))
+	}
+}
+
+var sideRuleCmd = &cobra.Command{
+	Use:     "side-rule",
+	Short:   "Manage rules that set the side of metadata files from their names",
+	Aliases: []string{"side-rules"},
+	Long: `Manage rules that set the side of metadata files from their names.
+When packwiz refresh is run, metadata files without a side are given the side of the first rule whose glob pattern
+(e.g. "*-client") matches the metadata file name (without .pw.toml) or the name of the file it downloads.
+Metadata files with a side set are never changed.`,
+}
+
+var sideRuleAddCmd = &cobra.Command{
+	Use:   "add [pattern] [client|server|both]",
+	Short: "Add a side rule, or change the side of an existing rule",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rule := core.SideRule{Pattern: args[0], Side: args[1]}
+		if err := rule.Validate(); err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		modpack, rules := loadSideRules()
+		i := slices.IndexFunc(rules, func(r core.SideRule) bool { return r.Pattern == rule.Pattern })
+		if i >= 0 {
+			rules[i] = rule
+		} else {
+			rules = append(rules, rule)
+		}
+		writeSideRules(modpack, rules)
+		fmt.Printf("Files matching %s will be set to %s; run packwiz refresh to apply this\n", rule.Pattern, rule.Side)
+	},
+}
+
+var sideRuleRemoveCmd = &cobra.Command{
+	Use:     "remove [pattern]",
+	Short:   "Remove a side rule",
+	Aliases: []string{"rm"},
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		modpack, rules := loadSideRules()
+		i := slices.IndexFunc(rules, func(r core.SideRule) bool { return r.Pattern == args[0] })
+		if i < 0 {
+			fmt.Printf("There is no side rule for %s\n", args[0])
+			cmdshared.Exit(cmdshared.ExitNotFound)
+		}
+		writeSideRules(modpack, slices.Delete(rules, i, i+1))
+		fmt.Printf("Removed the side rule for %s; sides that have already been set aren't changed\n", args[0])
+	},
+}
+
+var sideRuleListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the side rules, in the order they are applied",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, rules := loadSideRules()
+		if len(rules) == 0 {
+			fmt.Println("No side rules are set")
+			return
+		}
+		for _, rule := range rules {
+			fmt.Printf("%s: %s\n", rule.Pattern, rule.Side)
+		}
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(sideRuleCmd)
+	sideRuleCmd.AddCommand(sideRuleAddCmd)
+	sideRuleCmd.AddCommand(sideRuleRemoveCmd)
+	sideRuleCmd.AddCommand(sideRuleListCmd)
+}
@@ -0,0 +1,73 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// SetUserAgentContact stores the contact details added to the User-Agent in the user config, or removes them if contact
+// is empty
+func SetUserAgentContact(contact string) error {
+	contact = strings.TrimSpace(contact)
+	if contact == "" {
+		return setUserConfigValue("user-agent.contact", nil)
+	}
+	return setUserConfigValue("user-agent.contact", contact)
+}
+
+var userAgentCmd = &cobra.Command{
+	Use:   "user-agent",
+	Short: "Manage the contact details sent in the User-Agent of HTTP requests",
+	Long: `Manage the contact details sent in the User-Agent of HTTP requests.
+All requests identify packwiz with a User-Agent of packwiz/<version>. Some APIs ask for contact details (such as an
+email address or a link to your pack) to be included, so they can get in touch if there is a problem with your requests;
+these are stored in your user config.`,
+}
+
+var userAgentSetCmd = &cobra.Command{
+	Use:   "set [contact]",
+	Short: "Set the contact details added to the User-Agent",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := SetUserAgentContact(args[0])
+		if err != nil {
+			fmt.Printf("Error saving User-Agent contact: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("User-Agent set to %s\n", core.GetUserAgent())
+	},
+}
+
+var userAgentGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Print the User-Agent sent with HTTP requests",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(core.GetUserAgent())
+	},
+}
+
+var userAgentUnsetCmd = &cobra.Command{
+	Use:   "unset",
+	Short: "Remove the contact details from the User-Agent",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := SetUserAgentContact("")
+		if err != nil {
+			fmt.Printf("Error removing User-Agent contact: %v\n", err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("User-Agent set to %s\n", core.GetUserAgent())
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(userAgentCmd)
+	userAgentCmd.AddCommand(userAgentSetCmd)
+	userAgentCmd.AddCommand(userAgentGetCmd)
+	userAgentCmd.AddCommand(userAgentUnsetCmd)
+}
@@ -0,0 +1,91 @@
+package settings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+// userConfigFile returns the path of the user config file: the file given by --config or found in the local store,
+// or the default location if there isn't one yet
+func userConfigFile() (string, error) {
+	if file := viper.ConfigFileUsed(); file != "" {
+		return file, nil
+	}
+	dir, err := core.GetPackwizLocalStore()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ".packwiz.toml"), nil
+}
+
+// loadUserConfig reads only the values stored in the user config file, so flags and environment variables aren't
+// written back to it
+func loadUserConfig(file string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]any{}, nil
+		}
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+// setUserConfigValue stores a value in the user config file (or removes it, if value is nil), which is only readable
+// by the current user as it may contain secrets. The value is also applied to the current configuration.
+func setUserConfigValue(key string, value any) error {
+	file, err := userConfigFile()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadUserConfig(file)
+	if err != nil {
+		return err
+	}
+
+	// Keys are nested by their dotted path, as viper reads them
+	path := strings.Split(strings.ToLower(key), ".")
+	parent := cfg
+	for _, k := range path[:len(path)-1] {
+		child, ok := parent[k].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			parent[k] = child
+		}
+		parent = child
+	}
+	if value == nil {
+		delete(parent, path[len(path)-1])
+	} else {
+		parent[path[len(path)-1]] = value
+	}
+
+	v := viper.New()
+	v.SetConfigPermissions(0600)
+	if err := v.MergeConfigMap(cfg); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := v.WriteConfigAs(file); err != nil {
+		return err
+	}
+	// Permissions are only applied when a file is created, so tighten them on existing files too
+	if err := os.Chmod(file, 0600); err != nil {
+		return err
+	}
+
+	if value == nil {
+		viper.Set(key, "")
+	} else {
+		viper.Set(key, value)
+	}
+	return nil
+}
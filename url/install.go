@@ -1,13 +1,16 @@
 package url
 
 import (
+	"context"
 	"fmt"
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/0byte-coding/packwiz/core"
+	"github.com/0byte-coding/packwiz/settings"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"io"
+	"mime"
 	"net/url"
-	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -21,18 +24,17 @@ var installCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		pack, err := core.LoadPack()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
 		dl, err := url.Parse(args[1])
 		if err != nil {
 			fmt.Println("Failed to parse URL:", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		if dl.Scheme != "https" && dl.Scheme != "http" {
 			fmt.Println("Unsupported URL scheme:", dl.Scheme)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitUsage)
 		}
 
 		// TODO: consider using colors for these warnings but those can have issues on windows
@@ -52,42 +54,61 @@ var installCmd = &cobra.Command{
 			}
 			if msg != "" {
 				fmt.Println("Consider using packwiz", msg, "instead; if you know what you are doing use --force to add this file without update metadata.")
-				os.Exit(1)
+				cmdshared.Exit(cmdshared.ExitGeneric)
 			}
 		}
 
-		hash, err := getHash(args[1])
-		if err != nil {
-			fmt.Println("Failed to retrieve SHA256 hash for file", err)
-			os.Exit(1)
+		mirrors := viper.GetStringSlice("url.add.mirror")
+		for _, mirror := range mirrors {
+			u, err := url.Parse(mirror)
+			if err != nil {
+				fmt.Println("Failed to parse mirror URL:", err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+			if u.Scheme != "https" && u.Scheme != "http" {
+				fmt.Println("Unsupported mirror URL scheme:", u.Scheme)
+				cmdshared.Exit(cmdshared.ExitUsage)
+			}
+		}
+
+		hashFormat := settings.GetDefaultHashFormat()
+		file := downloadedFile{FileName: path.Base(dl.Path)}
+		if !viper.GetBool("url.add.no-download") {
+			file, err = downloadFile(cmd.Context(), args[1], hashFormat)
+			if err != nil {
+				fmt.Printf("Failed to retrieve %s hash for file: %s\n", hashFormat, err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
 		}
 
 		index, err := pack.LoadIndex()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 
-		filename := path.Base(dl.Path)
 		modMeta := core.Mod{
 			Name:     args[0],
-			FileName: filename,
+			FileName: file.FileName,
 			Side:     core.UniversalSide,
 			Download: core.ModDownload{
 				URL:        args[1],
-				HashFormat: "sha256",
-				Hash:       hash,
+				HashFormat: hashFormat,
+				Hash:       file.Hash,
+				FileSize:   file.Size,
+				Mirrors:    mirrors,
 			},
 		}
 
 		folder := viper.GetString("meta-folder")
 		if folder == "" {
-			folder = "mods"
+			folder, err = core.GetProjectTypeFolder(core.ProjectTypeMod, "mods")
+			if err != nil {
+				cmdshared.ExitWithError(err)
+			}
 		}
 		destPathName, err := cmd.Flags().GetString("meta-name")
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		if destPathName == "" {
 			destPathName = core.SlugifyName(args[0])
@@ -97,53 +118,73 @@ var installCmd = &cobra.Command{
 
 		format, hash, err := modMeta.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = index.RefreshFileWithHash(destPath, format, hash, true)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = index.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.UpdateIndexHash()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		err = pack.Write()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			cmdshared.ExitWithError(err)
 		}
 		fmt.Printf("Successfully added %s (%s) from: %s\n", args[0], destPath, args[1])
 	}}
 
-func getHash(url string) (string, error) {
-	mainHasher, err := core.GetHashImpl("sha256")
+var urlDefaultClient = settings.NewRateLimitHTTPClient("download server", 0, nil)
+
+type downloadedFile struct {
+	FileName string
+	Hash     string
+	Size     int64
+}
+
+// downloadFile downloads the file at the given URL, returning its hash in the given format, its size and its file
+// name (from the Content-Disposition header if given, otherwise from the URL)
+func downloadFile(ctx context.Context, fileURL string, hashFormat string) (downloadedFile, error) {
+	mainHasher, err := core.GetHashImpl(hashFormat)
 	if err != nil {
-		return "", err
+		return downloadedFile{}, err
 	}
-	resp, err := core.GetWithUA(url, "application/octet-stream")
+	u, err := url.Parse(fileURL)
 	if err != nil {
-		return "", err
+		return downloadedFile{}, err
 	}
-
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("failed to download: unexpected response status: %v", resp.Status)
+	fileName := path.Base(u.Path)
+	resp, err := core.GetWithProgress(ctx, urlDefaultClient, fileURL, "application/octet-stream", cmdshared.NewProgressBar(fileName))
+	if err != nil {
+		return downloadedFile{}, fmt.Errorf("failed to download: %w", err)
 	}
+	defer resp.Body.Close()
 
-	_, err = io.Copy(mainHasher, resp.Body)
+	size, err := io.Copy(mainHasher, resp.Body)
 	if err != nil {
-		return "", err
+		return downloadedFile{}, err
+	}
+	if resp.ContentLength >= 0 && resp.ContentLength != size {
+		return downloadedFile{}, fmt.Errorf("failed to download: expected %d bytes, got %d", resp.ContentLength, size)
+	}
+
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		// Only use the base name, as the header could contain a path
+		if name := path.Base(filepath.ToSlash(params["filename"])); params["filename"] != "" && name != "." && name != "/" {
+			fileName = name
+		}
 	}
 
-	return mainHasher.HashToString(mainHasher.Sum(nil)), nil
+	return downloadedFile{
+		FileName: fileName,
+		Hash:     mainHasher.HashToString(mainHasher.Sum(nil)),
+		Size:     size,
+	}, nil
 }
 
 func init() {
@@ -151,4 +192,10 @@ func init() {
 
 	installCmd.Flags().Bool("force", false, "Add a file even if the download URL is supported by packwiz in an alternative command (which may support dependencies and updates)")
 	installCmd.Flags().String("meta-name", "", "Filename to use for the created metadata file (defaults to a name generated from the name you supply)")
+	installCmd.Flags().Bool("no-download", false, "Don't download the file, leaving its hash empty (it must be filled in before the pack is used)")
+	_ = viper.BindPFlag("url.add.no-download", installCmd.Flags().Lookup("no-download"))
+	installCmd.Flags().StringArray("mirror", nil, "An alternative URL to download the file from if the main URL fails (can be given multiple times)")
+	_ = viper.BindPFlag("url.add.mirror", installCmd.Flags().Lookup("mirror"))
+	cmdshared.AddReportFlag(installCmd, "url.add.report")
+	cmdshared.AddHooks(installCmd, "add")
 }
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// dedupeCmd represents the dedupe command
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Merge duplicate entries for the same file in the index",
+	Long: `Merge duplicate entries for the same file in the index, such as those left by hand-editing or a bad merge.
+For each path with more than one entry, the entry whose hash matches the file on disk is kept; if none match, the first
+entry is kept and a warning is shown. The index and its hash in pack.toml are then rewritten.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		duplicates, err := dedupeIndex(&pack, &index)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		if len(duplicates) == 0 {
+			fmt.Println("No duplicate entries found")
+			return
+		}
+		for _, dup := range duplicates {
+			name := dup.Path
+			if dup.Alias != "" {
+				name += " (alias " + dup.Alias + ")"
+			}
+			if dup.Ambiguous {
+				fmt.Printf("Warning: none of the %d entries for %s match the file on disk; kept the first (hash %s)\n", dup.Count, name, dup.Kept)
+			} else {
+				fmt.Printf("Merged %d entries for %s, keeping the one matching the file on disk (hash %s)\n", dup.Count, name, dup.Kept)
+			}
+		}
+		fmt.Printf("Merged duplicate entries for %d files\n", len(duplicates))
+	},
+}
+
+// dedupeIndex merges duplicate entries in the index, writing the index and updating its hash in pack.toml if any were
+// found
+func dedupeIndex(pack *core.Pack, index *core.Index) ([]core.IndexDuplicate, error) {
+	duplicates, err := index.MergeDuplicates()
+	if err != nil || len(duplicates) == 0 {
+		return duplicates, err
+	}
+	err = index.Write()
+	if err != nil {
+		return nil, err
+	}
+	err = pack.UpdateIndexHash()
+	if err != nil {
+		return nil, err
+	}
+	return duplicates, pack.Write()
+}
+
+func init() {
+	utilsCmd.AddCommand(dedupeCmd)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func TestDedupeIndex(t *testing.T) {
+	// sha256 of "hello"
+	helloHash := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	dir, pack, index := loadUtilsTestPack(t, map[string]string{
+		"index.toml": "hash-format = \"sha256\"\n" +
+			"\n[[files]]\nfile = \"config/a.txt\"\nhash = \"0000\"\n" +
+			"\n[[files]]\nfile = \"config/b.txt\"\nhash = \"1111\"\n" +
+			"\n[[files]]\nfile = \"config/a.txt\"\nhash = \"" + helloHash + "\"\n" +
+			"\n[[files]]\nfile = \"config/c.txt\"\nhash = \"2222\"\n" +
+			"\n[[files]]\nfile = \"./config/c.txt\"\nhash = \"3333\"\n",
+		"config/a.txt": "hello",
+		"config/b.txt": "b",
+		"config/c.txt": "c",
+	})
+
+	duplicates, err := dedupeIndex(&pack, &index)
+	if err != nil {
+		t.Fatalf("Failed to dedupe index: %v", err)
+	}
+	expected := []core.IndexDuplicate{
+		{Path: "config/a.txt", Count: 2, Kept: helloHash},
+		{Path: "config/c.txt", Count: 2, Kept: "2222", Ambiguous: true},
+	}
+	if len(duplicates) != len(expected) || duplicates[0] != expected[0] || duplicates[1] != expected[1] {
+		t.Errorf("Expected duplicates %v, got %v", expected, duplicates)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "index.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedIndex := "hash-format = \"sha256\"\n" +
+		"\n[[files]]\nfile = \"config/a.txt\"\nhash = \"" + helloHash + "\"\n" +
+		"\n[[files]]\nfile = \"config/b.txt\"\nhash = \"1111\"\n" +
+		"\n[[files]]\nfile = \"config/c.txt\"\nhash = \"2222\"\n"
+	if string(data) != expectedIndex {
+		t.Errorf("Expected the index to have one entry per file, got:\n%s", data)
+	}
+	reloaded, err := core.LoadPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Index.Hash == "" {
+		t.Error("Expected the index hash in pack.toml to be updated")
+	}
+
+	// Running again finds nothing to merge
+	duplicates, err = dedupeIndex(&pack, &index)
+	if err != nil || len(duplicates) != 0 {
+		t.Errorf("Expected no duplicates, got %v (%v)", duplicates, err)
+	}
+}
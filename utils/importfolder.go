@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// importFolderSources lists the order that sources are tried in; sources that aren't listed are tried afterwards
+var importFolderSources = []string{"curseforge", "modrinth"}
+
+// importFolderCmd represents the import-folder command
+var importFolderCmd = &cobra.Command{
+	Use:   "import-folder <folder>",
+	Short: "Add the .jar files in a folder (e.g. an existing .minecraft/mods folder) to the pack",
+	Long: `Add the .jar files in a folder (e.g. an existing .minecraft/mods folder) to the pack.
+Each file is looked up on CurseForge by its fingerprint, then on Modrinth by its hash; a metadata file is created for
+each file that is found, so it can be updated. Files that can't be found are copied into the mods folder of the pack
+as they are. Files in the given folder are only removed if the folder is inside the pack and they were matched.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		modsFolder, err := core.GetProjectTypeFolder(core.ProjectTypeMod, "mods")
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		modsFolder = filepath.Join(viper.GetString("meta-folder-base"), modsFolder)
+
+		paths, err := findJarFiles(args[0])
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		if len(paths) == 0 {
+			fmt.Printf("No .jar files found in %s\n", args[0])
+			return
+		}
+		fmt.Printf("Found %d .jar files in %s\n", len(paths), args[0])
+
+		matched, matchedCounts, remaining := matchFolderFiles(paths, pack, &index)
+
+		inPack := isInFolder(index.ResolveIndexPath("."), args[0])
+		for _, path := range slices.Sorted(maps.Keys(matched)) {
+			if inPack {
+				err = os.Remove(path)
+				if err != nil {
+					cmdshared.ExitWithError(err)
+				}
+			}
+			fmt.Printf("Matched %s to %s\n", path, matched[path])
+		}
+
+		var copied, failed []string
+		for _, path := range remaining {
+			if isInFolder(modsFolder, path) {
+				// Already in the pack, so it is added to the index when it is refreshed
+				copied = append(copied, path)
+				continue
+			}
+			err = copyFileTo(path, modsFolder)
+			if err != nil {
+				fmt.Printf("Failed to copy %s: %v\n", path, err)
+				failed = append(failed, path)
+				continue
+			}
+			copied = append(copied, path)
+		}
+
+		err = index.Refresh()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = index.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.UpdateIndexHash()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		var counts []string
+		for _, source := range getImportFolderSources() {
+			counts = append(counts, fmt.Sprintf("%d on %s", matchedCounts[source], source))
+		}
+		fmt.Printf("\nMatched %d of %d files (%s)\n", len(matched), len(paths), strings.Join(counts, ", "))
+		if len(copied) > 0 {
+			fmt.Printf("The following %d files couldn't be matched and were added to %s as they are; they can't be updated by packwiz:\n", len(copied), modsFolder)
+			for _, path := range copied {
+				fmt.Println(path)
+			}
+		}
+		if len(failed) > 0 {
+			fmt.Printf("The following %d files couldn't be matched or copied:\n", len(failed))
+			for _, path := range failed {
+				fmt.Println(path)
+			}
+			cmdshared.Exit(cmdshared.ExitGeneric)
+		}
+	},
+}
+
+// matchFolderFiles tries to match files with each source in turn, returning the names of the projects that were
+// matched keyed by the path of the file, the number of files matched by each source, and the files that weren't matched
+func matchFolderFiles(paths []string, pack core.Pack, index *core.Index) (map[string]string, map[string]int, []string) {
+	matched := make(map[string]string)
+	matchedCounts := make(map[string]int)
+	remaining := slices.Clone(paths)
+	for _, source := range getImportFolderSources() {
+		if len(remaining) == 0 {
+			break
+		}
+		sourceMatched, err := core.FileMatchers[source].MatchFiles(remaining, pack, index)
+		if err != nil {
+			fmt.Printf("Failed to match files on %s: %v\n", source, err)
+		}
+		for path, name := range sourceMatched {
+			matched[path] = name
+		}
+		matchedCounts[source] = len(sourceMatched)
+		remaining = slices.DeleteFunc(remaining, func(path string) bool {
+			_, ok := sourceMatched[path]
+			return ok
+		})
+	}
+	return matched, matchedCounts, remaining
+}
+
+// getImportFolderSources returns the sources that files are matched against, in the order they are tried
+func getImportFolderSources() []string {
+	var sources []string
+	for _, source := range importFolderSources {
+		if _, ok := core.FileMatchers[source]; ok {
+			sources = append(sources, source)
+		}
+	}
+	for _, source := range slices.Sorted(maps.Keys(core.FileMatchers)) {
+		if !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// findJarFiles returns the paths of the .jar and .litemod files in the given folder and its subfolders, sorted
+func findJarFiles(folder string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && (strings.HasSuffix(path, ".jar") || strings.HasSuffix(path, ".litemod")) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files in %s: %w", folder, err)
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// isInFolder returns true if path is inside folder (or is folder)
+func isInFolder(folder string, path string) bool {
+	absFolder, err := filepath.Abs(folder)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absFolder, absPath)
+	return err == nil && (rel == "." || filepath.IsLocal(rel))
+}
+
+// copyFileTo copies a file into a folder, keeping its name; existing files aren't overwritten
+func copyFileTo(path string, folder string) error {
+	err := os.MkdirAll(folder, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	destPath := filepath.Join(folder, filepath.Base(path))
+	dest, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists", destPath)
+	} else if err != nil {
+		return err
+	}
+	_, err = io.Copy(dest, src)
+	if err != nil {
+		_ = dest.Close()
+		return err
+	}
+	return dest.Close()
+}
+
+func init() {
+	utilsCmd.AddCommand(importFolderCmd)
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+// importFolderTestMatcher matches files whose names contain its name
+type importFolderTestMatcher struct {
+	name  string
+	err   error
+	calls *[][]string
+}
+
+func (m importFolderTestMatcher) MatchFiles(paths []string, pack core.Pack, index *core.Index) (map[string]string, error) {
+	*m.calls = append(*m.calls, slices.Clone(paths))
+	matched := make(map[string]string)
+	for _, path := range paths {
+		if strings.Contains(filepath.Base(path), m.name) {
+			matched[path] = m.name + " project"
+		}
+	}
+	return matched, m.err
+}
+
+func TestMatchFolderFiles(t *testing.T) {
+	oldMatchers := core.FileMatchers
+	t.Cleanup(func() {
+		core.FileMatchers = oldMatchers
+	})
+	var cfCalls, mrCalls, otherCalls [][]string
+	core.FileMatchers = map[string]core.FileMatcher{
+		"other":      importFolderTestMatcher{name: "other", calls: &otherCalls},
+		"modrinth":   importFolderTestMatcher{name: "modrinth", calls: &mrCalls, err: errors.New("rate limited")},
+		"curseforge": importFolderTestMatcher{name: "curseforge", calls: &cfCalls},
+	}
+	if sources := getImportFolderSources(); !slices.Equal(sources, []string{"curseforge", "modrinth", "other"}) {
+		t.Errorf("Unexpected source order %v", sources)
+	}
+
+	paths := []string{"mods/curseforge-a.jar", "mods/modrinth-curseforge.jar", "mods/modrinth-b.jar", "mods/unknown.jar"}
+	matched, counts, remaining := matchFolderFiles(paths, core.Pack{}, &core.Index{})
+
+	// Files matched on CurseForge aren't looked up on Modrinth, and matches are kept when a source fails
+	if len(mrCalls) != 1 || !slices.Equal(mrCalls[0], []string{"mods/modrinth-b.jar", "mods/unknown.jar"}) {
+		t.Errorf("Expected unmatched files to be looked up on Modrinth, got %v", mrCalls)
+	}
+	expected := map[string]string{
+		"mods/curseforge-a.jar":        "curseforge project",
+		"mods/modrinth-curseforge.jar": "curseforge project",
+		"mods/modrinth-b.jar":          "modrinth project",
+	}
+	if !maps.Equal(matched, expected) {
+		t.Errorf("Expected matches %v, got %v", expected, matched)
+	}
+	if counts["curseforge"] != 2 || counts["modrinth"] != 1 || counts["other"] != 0 {
+		t.Errorf("Unexpected counts %v", counts)
+	}
+	if !slices.Equal(remaining, []string{"mods/unknown.jar"}) {
+		t.Errorf("Expected only unknown.jar to be unmatched, got %v", remaining)
+	}
+	if !slices.Equal(paths, []string{"mods/curseforge-a.jar", "mods/modrinth-curseforge.jar", "mods/modrinth-b.jar", "mods/unknown.jar"}) {
+		t.Errorf("Expected paths not to be modified, got %v", paths)
+	}
+}
+
+func TestCopyFileTo(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source", "a.jar")
+	if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("jar"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "pack", "mods")
+	if err := copyFileTo(src, dest); err != nil {
+		t.Fatalf("Failed to copy file: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "a.jar")); err != nil || string(data) != "jar" {
+		t.Errorf("Expected file to be copied, got %q (%v)", data, err)
+	}
+	if err := copyFileTo(src, dest); err == nil {
+		t.Error("Expected existing file not to be overwritten")
+	}
+	if !isInFolder(dest, filepath.Join(dest, "a.jar")) || isInFolder(dest, src) {
+		t.Error("Unexpected result from isInFolder")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/0byte-coding/packwiz/cmdshared"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 	"github.com/spf13/viper"
@@ -20,13 +21,13 @@ var markdownCmd = &cobra.Command{
 		err := os.MkdirAll(outDir, os.ModePerm)
 		if err != nil {
 			fmt.Printf("Error creating directory: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		disableTag(cmd.Root())
 		err = doc.GenMarkdownTree(cmd.Root(), outDir)
 		if err != nil {
 			fmt.Printf("Error generating markdown: %s\n", err)
-			os.Exit(1)
+			cmdshared.Exit(cmdshared.ExitCode(err))
 		}
 		fmt.Println("Generated markdown successfully!")
 	},
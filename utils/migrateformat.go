@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// migrateFormatCmd represents the migrate-format command
+var migrateFormatCmd = &cobra.Command{
+	Use:   "migrate-format",
+	Short: "Upgrade pack.toml to the current pack format",
+	Long: fmt.Sprintf(`Upgrade pack.toml from an older pack format to the current format (%s), adjusting any fields that have
+changed. Older packs are migrated automatically when they are loaded, but the new format is only saved when pack.toml is
+next written; this saves it straight away. Packs with a newer format than this version of packwiz supports are left
+unchanged.`, core.CurrentPackFormat),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		from, changed, err := migratePackFormat(&pack)
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		if !changed {
+			fmt.Printf("pack.toml already uses the current format (%s)\n", core.CurrentPackFormat)
+			return
+		}
+		if from == "" {
+			from = "no pack-format"
+		}
+		fmt.Printf("Migrated pack.toml from %s to %s\n", from, core.CurrentPackFormat)
+	},
+}
+
+// migratePackFormat writes a loaded pack in the current format, if it had an older format when it was loaded (and was
+// migrated by core.LoadPack); the format the pack was loaded with is returned
+func migratePackFormat(pack *core.Pack) (string, bool, error) {
+	from := pack.LoadedFormat()
+	if _, err := pack.MigrateFormat(); err != nil {
+		return from, false, err
+	}
+	if from == pack.PackFormat {
+		return from, false, nil
+	}
+	return from, true, pack.Write()
+}
+
+func init() {
+	utilsCmd.AddCommand(migrateFormatCmd)
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// migrateHashFormats lists the hash formats that a pack can be converted to
+var migrateHashFormats = []string{"sha1", "sha256", "sha512", "blake3"}
+
+// migrateHashCmd represents the migrate-hash command
+var migrateHashCmd = &cobra.Command{
+	Use:   "migrate-hash [sha1|sha256|sha512|blake3]",
+	Short: "Convert all the hashes in the pack to a single hash format",
+	Long: `Convert all the hashes in the pack to a single hash format.
+Files downloaded by metadata files that use a different format are downloaded (or read from the cache) and hashed again,
+then the metadata files, the index and pack.toml are rewritten to use the new format. If any file can't be retrieved,
+nothing is changed. Files that already use the format are skipped, so this can be run again safely.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: migrateHashFormats,
+	Run: func(cmd *cobra.Command, args []string) {
+		format := strings.ToLower(args[0])
+		if !slices.Contains(migrateHashFormats, format) {
+			fmt.Printf("Hash format '%s' is not supported, must be one of %v\n", args[0], migrateHashFormats)
+			cmdshared.Exit(cmdshared.ExitUsage)
+		}
+
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		mods, err := index.LoadAllMods()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		hashes, err := computeMigratedHashes(cmd.Context(), mods, format)
+		if err != nil {
+			fmt.Println(err)
+			fmt.Println("The pack hasn't been changed")
+			cmdshared.Exit(cmdshared.ExitGeneric)
+		}
+
+		// The hashes of metadata files and the index are stored using the default hash format
+		viper.Set("default-hash-format", format)
+		for _, mod := range mods {
+			hash, ok := hashes[mod]
+			if !ok {
+				continue
+			}
+			mod.Download.HashFormat = format
+			mod.Download.Hash = hash
+			_, _, err = mod.Write()
+			if err != nil {
+				fmt.Printf("Error saving %s: %v\n", mod.Name, err)
+				cmdshared.Exit(cmdshared.ExitCode(err))
+			}
+		}
+
+		index.HashFormat = format
+		err = index.Refresh()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = index.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.UpdateIndexHash()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		err = pack.Write()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+
+		if len(hashes) == 0 {
+			fmt.Printf("All files already use %s; the index has been updated\n", format)
+		} else {
+			fmt.Printf("Converted %d files to %s\n", len(hashes), format)
+		}
+	},
+}
+
+// computeMigratedHashes downloads (or reads from the cache) the files of the mods that don't use the given hash format,
+// returning their hashes in that format. If any file can't be retrieved, an error is returned.
+func computeMigratedHashes(ctx context.Context, mods []*core.Mod, format string) (map[*core.Mod]string, error) {
+	var toMigrate []*core.Mod
+	for _, mod := range mods {
+		if mod.Download.HashFormat != format {
+			toMigrate = append(toMigrate, mod)
+		}
+	}
+	hashes := make(map[*core.Mod]string, len(toMigrate))
+	if len(toMigrate) == 0 {
+		return hashes, nil
+	}
+
+	session, err := core.CreateDownloadSession(toMigrate, []string{format})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving external files: %w", err)
+	}
+	if err := cmdshared.ListManualDownloads(session); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Hashing %d files...\n", len(toMigrate))
+	var errs []error
+	for dl := range session.StartDownloadsContext(ctx, cmdshared.DownloadProgressBar) {
+		if dl.Error != nil {
+			errs = append(errs, fmt.Errorf("failed to retrieve %s: %w", dl.Mod.Name, dl.Error))
+			continue
+		}
+		_ = dl.File.Close()
+		hash, ok := dl.Hashes[format]
+		if !ok {
+			errs = append(errs, fmt.Errorf("failed to hash %s", dl.Mod.Name))
+			continue
+		}
+		hashes[dl.Mod] = hash
+	}
+	if err := session.SaveIndex(); err != nil {
+		errs = append(errs, fmt.Errorf("error saving cache index: %w", err))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return hashes, nil
+}
+
+func init() {
+	utilsCmd.AddCommand(migrateHashCmd)
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+func TestComputeMigratedHashes(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/missing.jar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	viper.Set("cache.directory", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("cache.directory", "")
+	})
+
+	converted := &core.Mod{Name: "Converted", Download: core.ModDownload{
+		URL:        srv.URL + "/hello.jar",
+		HashFormat: "sha256",
+		// sha256 of "hello"
+		Hash: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}}
+	skipped := &core.Mod{Name: "Skipped", Download: core.ModDownload{
+		URL:        srv.URL + "/other.jar",
+		HashFormat: "sha1",
+		Hash:       "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+	}}
+
+	hashes, err := computeMigratedHashes(context.Background(), []*core.Mod{converted, skipped}, "sha1")
+	if err != nil {
+		t.Fatalf("Expected hashes to be computed, got %v", err)
+	}
+	if len(hashes) != 1 || hashes[converted] != "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d" {
+		t.Errorf("Expected only the sha256 file to be converted, got %v", hashes)
+	}
+	if requests != 1 {
+		t.Errorf("Expected files already using the format to be skipped, got %d requests", requests)
+	}
+
+	// If any file can't be retrieved, no hashes are returned
+	missing := &core.Mod{Name: "Missing", Download: core.ModDownload{
+		URL:        srv.URL + "/missing.jar",
+		HashFormat: "sha256",
+		Hash:       "0000000000000000000000000000000000000000000000000000000000000000",
+	}}
+	hashes, err = computeMigratedHashes(context.Background(), []*core.Mod{converted, missing}, "sha512")
+	if err == nil || hashes != nil {
+		t.Errorf("Expected an error when a file can't be retrieved, got %v, %v", hashes, err)
+	}
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/0byte-coding/packwiz/cmd"
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// renameCmd represents the rename command
+var renameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename or move the metadata file of an external file in the pack",
+	Long: `Rename or move the metadata file of an external file in the pack, updating its entry in the index.
+<old> is the name of the metadata file (as used by packwiz remove). <new> is either a new name, which keeps the file in
+the same folder, or a path ending in .pw.toml (or containing a folder) to move it elsewhere. The download and update
+data of the file are kept; existing files are never overwritten.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		oldPath, ok := resolveMetaFile(index, args[0])
+		if !ok {
+			fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
+			cmdshared.Exit(cmdshared.ExitNotFound)
+		}
+		newPath := resolveRenameTarget(oldPath, args[1])
+
+		err = renameMetaFile(&pack, &index, oldPath, newPath)
+		if err != nil {
+			fmt.Printf("Failed to rename %s: %v\n", args[0], err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		fmt.Printf("Renamed %s to %s\n", oldPath, newPath)
+	},
+}
+
+// resolveMetaFile finds a metadata file in the index, given its name or path
+func resolveMetaFile(index core.Index, name string) (string, bool) {
+	if strings.HasSuffix(name, core.MetaExtensionOld) {
+		relPath, err := index.RelIndexPath(name)
+		if err == nil {
+			if holder, found := index.Files[relPath]; found && holder.IsMetaFile() {
+				return name, true
+			}
+		}
+	}
+	return cmd.ResolveModName(index, name)
+}
+
+// resolveRenameTarget returns the path a metadata file is renamed to; plain names keep the file in the same folder
+func resolveRenameTarget(oldPath string, target string) string {
+	if strings.HasSuffix(target, core.MetaExtensionOld) || strings.ContainsAny(target, `/\`) {
+		return target
+	}
+	return filepath.Join(filepath.Dir(oldPath), target+core.MetaExtension)
+}
+
+// renameMetaFile moves a metadata file and its index entry, then writes the index and updates its hash in pack.toml
+func renameMetaFile(pack *core.Pack, index *core.Index, oldPath string, newPath string) error {
+	err := index.MoveFile(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+	err = index.Write()
+	if err != nil {
+		return err
+	}
+	err = pack.UpdateIndexHash()
+	if err != nil {
+		return err
+	}
+	return pack.Write()
+}
+
+func init() {
+	utilsCmd.AddCommand(renameCmd)
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/viper"
+)
+
+// loadUtilsTestPack writes a pack.toml and the given files (keyed by path) to a temporary folder, and loads the pack
+func loadUtilsTestPack(t *testing.T, files map[string]string) (string, core.Pack, core.Index) {
+	t.Helper()
+	dir := t.TempDir()
+	files["pack.toml"] = "name = \"Test\"\npack-format = \"packwiz:1.1.0\"\n[index]\nfile = \"index.toml\"\nhash-format = \"sha256\"\nhash = \"\"\n"
+	for name, contents := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldPackFile := viper.GetString("pack-file")
+	viper.Set("pack-file", filepath.Join(dir, "pack.toml"))
+	t.Cleanup(func() { viper.Set("pack-file", oldPackFile) })
+
+	pack, err := core.LoadPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := pack.LoadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, pack, index
+}
+
+func TestRenameMetaFile(t *testing.T) {
+	dir, pack, index := loadUtilsTestPack(t, map[string]string{
+		"index.toml":         "hash-format = \"sha256\"\n\n[[files]]\nfile = \"mods/old.pw.toml\"\nhash = \"abcd\"\nmetafile = true\n\n[[files]]\nfile = \"mods/other.pw.toml\"\nhash = \"ef01\"\nmetafile = true\n",
+		"mods/old.pw.toml":   "name = \"Old\"\nfilename = \"old.jar\"\n",
+		"mods/other.pw.toml": "name = \"Other\"\nfilename = \"other.jar\"\n",
+	})
+
+	oldPath := filepath.Join(dir, "mods", "old.pw.toml")
+	newPath := resolveRenameTarget(oldPath, "renamed")
+	if newPath != filepath.Join(dir, "mods", "renamed.pw.toml") {
+		t.Fatalf("Expected a plain name to keep the file in its folder, got %s", newPath)
+	}
+	if err := renameMetaFile(&pack, &index, oldPath, newPath); err != nil {
+		t.Fatalf("Failed to rename: %v", err)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the old file to be moved, got %v", err)
+	}
+	if mod, err := core.LoadMod(newPath); err != nil || mod.Name != "Old" {
+		t.Errorf("Expected the metadata file to be moved intact, got %v (%v)", mod, err)
+	}
+
+	written, err := pack.LoadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := written.Files["mods/old.pw.toml"]; found {
+		t.Error("Expected the old path to be removed from the index")
+	}
+	if holder, found := written.Files["mods/renamed.pw.toml"]; !found || !holder.IsMetaFile() {
+		t.Errorf("Expected the index to reference the new path as a metadata file, got %v", written.Files)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "index.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "hash-format = \"sha256\"\n\n[[files]]\nfile = \"mods/other.pw.toml\"\nhash = \"ef01\"\nmetafile = true\n\n[[files]]\nfile = \"mods/renamed.pw.toml\"\nhash = \"abcd\"\nmetafile = true\n"
+	if string(data) != expected {
+		t.Errorf("Expected the index to be written sorted with hashes kept, got:\n%s", data)
+	}
+
+	reloaded, err := core.LoadPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Index.Hash == "" || reloaded.Index.Hash != pack.Index.Hash {
+		t.Errorf("Expected the index hash in pack.toml to be updated, got %q", reloaded.Index.Hash)
+	}
+
+	// Existing files aren't overwritten
+	err = renameMetaFile(&pack, &index, newPath, filepath.Join(dir, "mods", "other.pw.toml"))
+	if err == nil {
+		t.Error("Expected renaming onto an existing file to fail")
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("Expected the file to be left in place, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/0byte-coding/packwiz/cmdshared"
+	"github.com/0byte-coding/packwiz/core"
+	"github.com/spf13/cobra"
+)
+
+// setCmd represents the set command
+var setCmd = &cobra.Command{
+	Use:   "set <name> <key> <value>",
+	Short: "Set a field of the metadata file of an external file",
+	Long: `Set a field of the metadata file of an external file, without editing the TOML by hand, and update its hash in the
+index. <key> is the key of the field as written in the file, with dots for nested keys (e.g. side, download.url,
+option.optional or update.modrinth.version). The value is converted to the type of the field (true/false, integers,
+or comma separated lists) and checked, e.g. side must be client, server or both. Unknown keys are rejected.`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Loading modpack...")
+		pack, err := core.LoadPack()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		index, err := pack.LoadIndex()
+		if err != nil {
+			cmdshared.ExitWithError(err)
+		}
+		modPath, ok := resolveMetaFile(index, args[0])
+		if !ok {
+			fmt.Println("Can't find this file; please ensure you have run packwiz refresh and use the name of the .pw.toml file (defaults to the project slug)")
+			cmdshared.Exit(cmdshared.ExitNotFound)
+		}
+
+		changed, err := setModField(&pack, &index, modPath, args[1], args[2])
+		if err != nil {
+			fmt.Printf("Failed to set %s: %v\n", args[1], err)
+			cmdshared.Exit(cmdshared.ExitCode(err))
+		}
+		if !changed {
+			fmt.Printf("%s of %s is already %s\n", args[1], args[0], args[2])
+			return
+		}
+		fmt.Printf("Set %s of %s to %s\n", args[1], args[0], args[2])
+	},
+}
+
+// setModField sets a field of a metadata file; if its value changed, the file is written and its hash is updated in
+// the index and pack.toml
+func setModField(pack *core.Pack, index *core.Index, modPath string, key string, value string) (bool, error) {
+	modData, err := core.LoadMod(modPath)
+	if err != nil {
+		return false, err
+	}
+	changed, err := modData.SetField(key, value)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	format, hash, err := modData.Write()
+	if err != nil {
+		return false, err
+	}
+	err = index.RefreshFileWithHash(modPath, format, hash, true)
+	if err != nil {
+		return false, err
+	}
+	err = index.Write()
+	if err != nil {
+		return false, err
+	}
+	err = pack.UpdateIndexHash()
+	if err != nil {
+		return false, err
+	}
+	return true, pack.Write()
+}
+
+func init() {
+	utilsCmd.AddCommand(setCmd)
+}
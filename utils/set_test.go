@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0byte-coding/packwiz/core"
+)
+
+func TestSetModField(t *testing.T) {
+	contents := "name = \"Test\"\nfilename = \"test.jar\"\nside = \"both\"\n\n[download]\nurl = \"https://example.com/test.jar\"\nhash-format = \"sha256\"\nhash = \"abcd\"\n"
+	dir, pack, index := loadUtilsTestPack(t, map[string]string{
+		"index.toml":        "hash-format = \"sha256\"\n\n[[files]]\nfile = \"mods/test.pw.toml\"\nhash = \"0000\"\nmetafile = true\n",
+		"mods/test.pw.toml": contents,
+	})
+	modPath := filepath.Join(dir, "mods", "test.pw.toml")
+
+	changed, err := setModField(&pack, &index, modPath, "side", "client")
+	if err != nil {
+		t.Fatalf("Failed to set side: %v", err)
+	}
+	if !changed {
+		t.Error("Expected side to be changed")
+	}
+	mod, err := core.LoadMod(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mod.Side != core.ClientSide {
+		t.Errorf("Expected side to be client, got %q", mod.Side)
+	}
+	if mod.Name != "Test" || mod.Download.URL != "https://example.com/test.jar" || mod.Download.Hash != "abcd" {
+		t.Errorf("Expected other fields to be kept, got %+v", mod)
+	}
+	// The hash of the metadata file is updated in the index
+	format, hash, err := mod.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexData, err := os.ReadFile(filepath.Join(dir, "index.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "sha256" || !strings.Contains(string(indexData), hash) {
+		t.Errorf("Expected the index to contain the new hash %s, got:\n%s", hash, indexData)
+	}
+
+	// Setting the same value again doesn't change anything
+	if changed, err := setModField(&pack, &index, modPath, "side", "client"); err != nil || changed {
+		t.Errorf("Expected no change when setting the same value, got %v (%v)", changed, err)
+	}
+
+	// Values are converted to the type of the field, and nested tables are created
+	if _, err := setModField(&pack, &index, modPath, "option.optional", "true"); err != nil {
+		t.Fatalf("Failed to set option.optional: %v", err)
+	}
+	if mod, err := core.LoadMod(modPath); err != nil || mod.Option == nil || !mod.Option.Optional {
+		t.Errorf("Expected the file to be optional, got %+v (%v)", mod.Option, err)
+	}
+
+	invalid := []struct {
+		key, value string
+	}{
+		{"side", "everywhere"},
+		{"sides", "client"},
+		{"download.size", "10"},
+		{"download", "url"},
+		{"side.client", "true"},
+		{"option.optional", "maybe"},
+		{"pin", "yes please"},
+	}
+	before, err := os.ReadFile(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range invalid {
+		if _, err := setModField(&pack, &index, modPath, v.key, v.value); err == nil {
+			t.Errorf("Expected an error setting %s to %s", v.key, v.value)
+		}
+	}
+	after, err := os.ReadFile(modPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("Expected the file not to be changed by invalid keys or values, got:\n%s", after)
+	}
+}